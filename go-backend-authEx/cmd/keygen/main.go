@@ -0,0 +1,145 @@
+// cmd/keygen/main.go
+//
+// keygen mengelola file key set JWT (JWT_KEYS_FILE) untuk penandatanganan asimetris.
+//
+// Contoh rotasi tanpa downtime:
+//
+//	keygen add -alg ES256        # key baru dipublikasikan di JWKS, belum dipakai menandatangani
+//	kill -HUP <pid server>       # (tunggu cache JWKS verifier kedaluwarsa)
+//	keygen promote               # key terbaru menjadi aktif, key lama tetap untuk verifikasi
+//	kill -HUP <pid server>
+//	keygen prune -older-than 2h  # setelah token lama kedaluwarsa
+//	kill -HUP <pid server>
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"go-auth-example/internal/auth"
+
+	"github.com/joho/godotenv"
+)
+
+const usage = `Usage: keygen <command> [flags]
+
+Commands:
+  init     create a new key set file with one active key
+  add      add a new (inactive) key to the key set
+  promote  make a key active (default: newest inactive key)
+  rotate   add a new key and promote it immediately
+  prune    remove retired keys older than -older-than
+  list     list keys in the key set
+
+Flags:
+  -file        key set file (default: $JWT_KEYS_FILE or jwt-keys.json)
+  -alg         signing algorithm for new keys: RS256, ES256 or EdDSA (default ES256)
+  -kid         key ID to promote
+  -older-than  minimum age since retirement before a key is pruned (default 2h)
+`
+
+func main() {
+	_ = godotenv.Load()
+
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	command := os.Args[1]
+
+	defaultFile := os.Getenv("JWT_KEYS_FILE")
+	if defaultFile == "" {
+		defaultFile = "jwt-keys.json"
+	}
+
+	fs := flag.NewFlagSet(command, flag.ExitOnError)
+	fs.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	file := fs.String("file", defaultFile, "key set file")
+	alg := fs.String("alg", auth.AlgES256, "signing algorithm for new keys")
+	kid := fs.String("kid", "", "key ID to promote")
+	olderThan := fs.Duration("older-than", 2*auth.DefaultAccessTokenTTL, "minimum age since retirement before pruning")
+	_ = fs.Parse(os.Args[2:])
+
+	if err := run(command, *file, *alg, *kid, *olderThan); err != nil {
+		fmt.Fprintf(os.Stderr, "keygen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(command, file, alg, kid string, olderThan time.Duration) error {
+	if command == "init" {
+		if _, err := os.Stat(file); err == nil {
+			return fmt.Errorf("%s already exists", file)
+		}
+		keySetFile := &auth.KeySetFile{}
+		entry, err := keySetFile.AddKey(alg)
+		if err != nil {
+			return err
+		}
+		if err := keySetFile.Save(file); err != nil {
+			return err
+		}
+		fmt.Printf("Created %s with active key %s (%s)\n", file, entry.KeyID, entry.Algorithm)
+		return nil
+	}
+
+	keySetFile, err := auth.ReadKeySetFile(file)
+	if err != nil {
+		return err
+	}
+
+	switch command {
+	case "add":
+		entry, err := keySetFile.AddKey(alg)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Added key %s (%s). Reload the server, wait for JWKS caches to refresh, then run 'promote'.\n", entry.KeyID, entry.Algorithm)
+	case "promote":
+		if kid == "" {
+			entry, err := keySetFile.NewestInactiveKey()
+			if err != nil {
+				return err
+			}
+			kid = entry.KeyID
+		}
+		if err := keySetFile.Promote(kid); err != nil {
+			return err
+		}
+		fmt.Printf("Key %s is now active. Reload the server to start signing with it.\n", kid)
+	case "rotate":
+		entry, err := keySetFile.AddKey(alg)
+		if err != nil {
+			return err
+		}
+		if err := keySetFile.Promote(entry.KeyID); err != nil {
+			return err
+		}
+		fmt.Printf("Added and promoted key %s (%s). Reload the server to start signing with it.\n", entry.KeyID, entry.Algorithm)
+	case "prune":
+		removed := keySetFile.Prune(olderThan)
+		if len(removed) == 0 {
+			fmt.Println("No keys to prune.")
+			return nil
+		}
+		fmt.Printf("Removed keys: %v. Reload the server to stop accepting them.\n", removed)
+	case "list":
+		for _, k := range keySetFile.SortedKeys() {
+			status := "verify-only"
+			if k.KeyID == keySetFile.ActiveKeyID {
+				status = "active"
+			} else if k.RetiredAt != nil {
+				status = "retired " + k.RetiredAt.Format(time.RFC3339)
+			}
+			fmt.Printf("%s\t%s\tcreated %s\t%s\n", k.KeyID, k.Algorithm, k.CreatedAt.Format(time.RFC3339), status)
+		}
+		return nil
+	default:
+		return errors.New("unknown command " + command + "\n\n" + usage)
+	}
+
+	return keySetFile.Save(file)
+}
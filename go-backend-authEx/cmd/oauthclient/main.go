@@ -0,0 +1,98 @@
+// cmd/oauthclient/main.go
+//
+// oauthclient mendaftarkan OAuth client di database (DATABASE_URL).
+// Client secret hanya ditampilkan sekali saat client dibuat; simpan di tempat yang aman.
+//
+// Contoh:
+//
+//	oauthclient -name "SPA" -public -redirect-uri http://localhost:3000/callback -scope "openid profile email"
+//	oauthclient -name "Worker" -grant client_credentials -scope "reports:read"
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"go-auth-example/internal/model"
+	"go-auth-example/internal/repository"
+	"go-auth-example/internal/service"
+	"go-auth-example/internal/storage"
+
+	"github.com/joho/godotenv"
+)
+
+// listFlag mengumpulkan flag yang boleh diulang atau dipisah koma
+type listFlag []string
+
+func (l *listFlag) String() string { return strings.Join(*l, ",") }
+
+func (l *listFlag) Set(value string) error {
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*l = append(*l, item)
+		}
+	}
+	return nil
+}
+
+func main() {
+	_ = godotenv.Load()
+
+	var redirectURIs, grantTypes, scopes listFlag
+	name := flag.String("name", "", "client name (required)")
+	public := flag.Bool("public", false, "register a public client without a secret (SPA, native app)")
+	flag.Var(&redirectURIs, "redirect-uri", "allowed redirect URI (repeatable)")
+	flag.Var(&grantTypes, "grant", "allowed grant type (repeatable, default authorization_code,refresh_token)")
+	flag.Var(&scopes, "scope", "scope the client may request (repeatable or space separated)")
+	flag.Parse()
+
+	if *name == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if len(grantTypes) == 0 {
+		grantTypes = listFlag{model.GrantTypeAuthorizationCode, model.GrantTypeRefreshToken}
+	}
+
+	if err := run(model.OAuthClientInput{
+		Name:         *name,
+		RedirectURIs: redirectURIs,
+		GrantTypes:   grantTypes,
+		Scopes:       scopes,
+		Public:       *public,
+	}); err != nil {
+		fmt.Fprintf(os.Stderr, "oauthclient: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(input model.OAuthClientInput) error {
+	db, err := storage.ConnectDB()
+	if err != nil {
+		return err
+	}
+	defer storage.CloseDB(db)
+
+	if err := storage.CreateTableIfNotExists(db); err != nil {
+		return err
+	}
+
+	clientService := service.NewOAuthClientService(repository.NewPostgresOAuthClientRepository(db))
+	client, secret, err := clientService.RegisterClient(input)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("client_id:     %s\n", client.ClientID)
+	if secret != "" {
+		fmt.Printf("client_secret: %s\n", secret)
+	} else {
+		fmt.Println("client_secret: (public client, PKCE required)")
+	}
+	fmt.Printf("grant_types:   %s\n", strings.Join(client.GrantTypes, " "))
+	fmt.Printf("redirect_uris: %s\n", strings.Join(client.RedirectURIs, " "))
+	fmt.Printf("scopes:        %s\n", strings.Join(client.Scopes, " "))
+	return nil
+}
@@ -0,0 +1,263 @@
+// cmd/server/config.go
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-auth-example/internal/api"
+	"go-auth-example/internal/auth"
+	"go-auth-example/internal/mail"
+	"go-auth-example/internal/service"
+	"go-auth-example/internal/social"
+
+	"github.com/go-webauthn/webauthn/webauthn"
+)
+
+// loadKeySet memuat key penandatangan JWT.
+// Jika JWT_KEYS_FILE di-set, key asimetris dari file tersebut dipakai; JWT_SECRET_KEY (jika ada) tetap diterima
+// untuk verifikasi agar token HS256 lama masih valid selama masa migrasi.
+// Tanpa JWT_KEYS_FILE, aplikasi kembali ke mode HS256 dengan JWT_SECRET_KEY.
+func loadKeySet() (*auth.KeySet, error) {
+	secret := os.Getenv("JWT_SECRET_KEY")
+	keysFile := os.Getenv("JWT_KEYS_FILE")
+
+	if keysFile == "" {
+		if secret == "" {
+			return nil, fmt.Errorf("either JWT_KEYS_FILE or JWT_SECRET_KEY environment variable must be set")
+		}
+		return auth.NewHMACKeySet([]byte(secret)), nil
+	}
+
+	keySet, err := auth.LoadKeySetFile(keysFile)
+	if err != nil {
+		return nil, err
+	}
+	if secret != "" {
+		keySet = keySet.WithVerificationKey(auth.NewHMACKey([]byte(secret)))
+	}
+	return keySet, nil
+}
+
+// loadTokenConfig membaca konfigurasi JWT dari environment:
+// JWT_ISSUER, JWT_AUDIENCE (dipisah koma), JWT_ACCESS_TOKEN_TTL, JWT_LEEWAY,
+// JWT_ALGORITHM dan JWT_ACCEPTED_ALGORITHMS (dipisah koma).
+func loadTokenConfig() (auth.TokenConfig, error) {
+	ttl, err := getEnvDuration("JWT_ACCESS_TOKEN_TTL", auth.DefaultAccessTokenTTL)
+	if err != nil {
+		return auth.TokenConfig{}, err
+	}
+	leeway, err := getEnvDuration("JWT_LEEWAY", 30*time.Second)
+	if err != nil {
+		return auth.TokenConfig{}, err
+	}
+
+	return auth.TokenConfig{
+		Issuer:             getEnv("JWT_ISSUER", "go-auth-example"),
+		Audience:           getEnvList("JWT_AUDIENCE", []string{"go-auth-example"}),
+		TTL:                ttl,
+		Leeway:             leeway,
+		Algorithm:          os.Getenv("JWT_ALGORITHM"),
+		AcceptedAlgorithms: getEnvList("JWT_ACCEPTED_ALGORITHMS", nil),
+	}, nil
+}
+
+// loadCookieConfig membaca konfigurasi mode cookie dari environment:
+// AUTH_MODE ("header" atau "cookie"), CSRF_MODE ("double-submit" atau "synchronizer"),
+// COOKIE_DOMAIN, COOKIE_SECURE dan COOKIE_SAMESITE ("lax", "strict" atau "none").
+func loadCookieConfig() (api.CookieConfig, error) {
+	config := api.CookieConfig{
+		CSRFMode: getEnv("CSRF_MODE", api.CSRFModeDoubleSubmit),
+		Domain:   os.Getenv("COOKIE_DOMAIN"),
+		Secure:   getEnv("COOKIE_SECURE", "true") != "false",
+	}
+
+	switch mode := getEnv("AUTH_MODE", "header"); mode {
+	case "header":
+	case "cookie":
+		config.Enabled = true
+	default:
+		return api.CookieConfig{}, fmt.Errorf("invalid AUTH_MODE %q: must be header or cookie", mode)
+	}
+
+	switch sameSite := strings.ToLower(getEnv("COOKIE_SAMESITE", "lax")); sameSite {
+	case "lax":
+		config.SameSite = http.SameSiteLaxMode
+	case "strict":
+		config.SameSite = http.SameSiteStrictMode
+	case "none":
+		config.SameSite = http.SameSiteNoneMode
+	default:
+		return api.CookieConfig{}, fmt.Errorf("invalid COOKIE_SAMESITE %q: must be lax, strict or none", sameSite)
+	}
+	return config, nil
+}
+
+// loadMailer memilih implementasi Mailer dari MAIL_DRIVER: "smtp" (SMTP_HOST, SMTP_PORT, SMTP_USERNAME, SMTP_PASSWORD),
+// "file" (MAIL_DIR) atau "log" (default, hanya untuk development). Alamat pengirim dari MAIL_FROM.
+func loadMailer() (mail.Mailer, error) {
+	from := getEnv("MAIL_FROM", "Go Auth Example <no-reply@localhost>")
+
+	switch driver := getEnv("MAIL_DRIVER", "log"); driver {
+	case "smtp":
+		return mail.NewSMTPMailer(mail.SMTPConfig{
+			Host:     os.Getenv("SMTP_HOST"),
+			Port:     os.Getenv("SMTP_PORT"),
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     from,
+		})
+	case "file":
+		return mail.NewFileMailer(getEnv("MAIL_DIR", "mail"), from)
+	case "log":
+		return mail.NewLogMailer(), nil
+	default:
+		return nil, fmt.Errorf("invalid MAIL_DRIVER %q: must be smtp, file or log", driver)
+	}
+}
+
+// loadAuthOptions membaca kebijakan login: LOGIN_REQUIRE_VERIFIED_EMAIL ("true" untuk menolak user yang belum verifikasi email),
+// LOGIN_MAX_ATTEMPTS, LOGIN_LOCKOUT_DURATION dan LOGIN_BACKOFF_BASE
+func loadAuthOptions() (service.AuthOptions, error) {
+	options := service.AuthOptions{
+		RequireVerifiedEmail: getEnv("LOGIN_REQUIRE_VERIFIED_EMAIL", "false") == "true",
+	}
+
+	maxAttempts := getEnv("LOGIN_MAX_ATTEMPTS", strconv.Itoa(service.DefaultLoginMaxAttempts))
+	n, err := strconv.Atoi(maxAttempts)
+	if err != nil || n < 1 {
+		return options, fmt.Errorf("invalid LOGIN_MAX_ATTEMPTS %q: must be a positive number", maxAttempts)
+	}
+	options.Throttle.MaxAttempts = n
+
+	if options.Throttle.LockoutDuration, err = getEnvDuration("LOGIN_LOCKOUT_DURATION", service.DefaultLoginLockoutDuration); err != nil {
+		return options, err
+	}
+	if options.Throttle.BackoffBase, err = getEnvDuration("LOGIN_BACKOFF_BASE", service.DefaultLoginBackoffBase); err != nil {
+		return options, err
+	}
+	return options, nil
+}
+
+// loadWebAuthn membaca konfigurasi relying party passkey dari environment:
+// WEBAUTHN_RP_ID (domain frontend tanpa skema/port), WEBAUTHN_RP_NAME dan
+// WEBAUTHN_RP_ORIGINS (origin frontend yang memanggil navigator.credentials, dipisah koma).
+func loadWebAuthn() (*webauthn.WebAuthn, error) {
+	webAuthn, err := webauthn.New(&webauthn.Config{
+		RPID:          getEnv("WEBAUTHN_RP_ID", "localhost"),
+		RPDisplayName: getEnv("WEBAUTHN_RP_NAME", "Go Auth Example"),
+		RPOrigins:     getEnvList("WEBAUTHN_RP_ORIGINS", []string{"http://localhost:5173"}),
+		Timeouts: webauthn.TimeoutsConfig{
+			Login:        webauthn.TimeoutConfig{Enforce: true, Timeout: service.PasskeyCeremonyTTL},
+			Registration: webauthn.TimeoutConfig{Enforce: true, Timeout: service.PasskeyCeremonyTTL},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid WebAuthn configuration: %w", err)
+	}
+	return webAuthn, nil
+}
+
+// socialProviderNamePattern membatasi nama provider karena dipakai di URL dan disimpan di database
+var socialProviderNamePattern = regexp.MustCompile(`^[a-z0-9-]{1,50}$`)
+
+// loadSocialProviders membaca identity provider untuk login sosial dari environment. Provider aktif jika client ID-nya di-set:
+// SOCIAL_GOOGLE_CLIENT_ID/_SECRET, SOCIAL_GITHUB_CLIENT_ID/_SECRET (opsional SOCIAL_GITHUB_AUTH_URL, _TOKEN_URL, _API_URL
+// untuk GitHub Enterprise), dan OIDC generik lewat SOCIAL_OIDC_ISSUER, SOCIAL_OIDC_CLIENT_ID/_SECRET, SOCIAL_OIDC_NAME,
+// SOCIAL_OIDC_DISPLAY_NAME dan SOCIAL_OIDC_SCOPES (dipisah koma).
+// Redirect URL tiap provider adalah SOCIAL_REDIRECT_BASE_URL/<nama>/callback (halaman frontend).
+func loadSocialProviders() (*social.Registry, error) {
+	redirectBase := strings.TrimSuffix(getEnv("SOCIAL_REDIRECT_BASE_URL", "http://localhost:5173/social"), "/")
+	redirectURL := func(name string) string { return redirectBase + "/" + name + "/callback" }
+	var providers []social.Provider
+
+	if clientID := os.Getenv("SOCIAL_GOOGLE_CLIENT_ID"); clientID != "" {
+		google, err := social.NewGoogleProvider(social.ClientConfig{
+			ClientID:     clientID,
+			ClientSecret: os.Getenv("SOCIAL_GOOGLE_CLIENT_SECRET"),
+			RedirectURL:  redirectURL("google"),
+		})
+		if err != nil {
+			return nil, err
+		}
+		providers = append(providers, google)
+	}
+
+	if clientID := os.Getenv("SOCIAL_GITHUB_CLIENT_ID"); clientID != "" {
+		github, err := social.NewGitHubProvider(social.ClientConfig{
+			ClientID:     clientID,
+			ClientSecret: os.Getenv("SOCIAL_GITHUB_CLIENT_SECRET"),
+			RedirectURL:  redirectURL("github"),
+		}, social.GitHubEndpoints{
+			AuthURL:  os.Getenv("SOCIAL_GITHUB_AUTH_URL"),
+			TokenURL: os.Getenv("SOCIAL_GITHUB_TOKEN_URL"),
+			APIURL:   os.Getenv("SOCIAL_GITHUB_API_URL"),
+		})
+		if err != nil {
+			return nil, err
+		}
+		providers = append(providers, github)
+	}
+
+	if clientID := os.Getenv("SOCIAL_OIDC_CLIENT_ID"); clientID != "" {
+		name := getEnv("SOCIAL_OIDC_NAME", "oidc")
+		if !socialProviderNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("invalid SOCIAL_OIDC_NAME %q: use lowercase letters, digits and dashes", name)
+		}
+		oidcProvider, err := social.NewOIDCProvider(os.Getenv("SOCIAL_OIDC_ISSUER"), social.ClientConfig{
+			Name:         name,
+			DisplayName:  os.Getenv("SOCIAL_OIDC_DISPLAY_NAME"),
+			ClientID:     clientID,
+			ClientSecret: os.Getenv("SOCIAL_OIDC_CLIENT_SECRET"),
+			RedirectURL:  redirectURL(name),
+			Scopes:       getEnvList("SOCIAL_OIDC_SCOPES", nil),
+		})
+		if err != nil {
+			return nil, err
+		}
+		providers = append(providers, oidcProvider)
+	}
+
+	return social.NewRegistry(providers...), nil
+}
+
+// getEnv mengembalikan nilai env var atau fallback jika kosong
+func getEnv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// getEnvDuration mem-parsing env var dengan format time.ParseDuration (misal "15m", "1h")
+func getEnvDuration(key string, fallback time.Duration) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q: %w", key, value, err)
+	}
+	return d, nil
+}
+
+// getEnvList mem-parsing env var berisi daftar yang dipisah koma
+func getEnvList(key string, fallback []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
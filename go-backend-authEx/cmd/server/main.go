@@ -6,12 +6,15 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	// Import internal packages
 	"go-auth-example/internal/api"
+	"go-auth-example/internal/auth"
 	"go-auth-example/internal/logger" // <-- IMPORT LOGGER
+	"go-auth-example/internal/model"
 	"go-auth-example/internal/repository"
 	"go-auth-example/internal/service"
 	"go-auth-example/internal/storage"
@@ -32,9 +35,29 @@ func main() {
 	// Anda bisa menambahkan pesan log pertama di sini jika mau.
 	// logger.Log.Info("Application starting...") // Pesan ini sudah ada di init logger
 
-	if os.Getenv("JWT_SECRET_KEY") == "" {
-		logger.Log.Fatal("FATAL: JWT_SECRET_KEY environment variable is not set.")
+	keySet, err := loadKeySet()
+	if err != nil {
+		logger.Log.Fatalf("FATAL: Could not load JWT signing keys: %v", err)
+	}
+	keyStore := auth.NewKeyStore(keySet)
+	logger.Log.Infof("JWT signing key %q (%s) active", keySet.ActiveKey().ID, keySet.ActiveKey().Algorithm)
+	if keySet.ActiveKey().Algorithm == auth.AlgHS256 {
+		logger.Log.Warn("Active JWT key is HMAC; OpenID Connect ID tokens cannot be issued until JWT_KEYS_FILE is configured")
+	}
+
+	tokenConfig, err := loadTokenConfig()
+	if err != nil {
+		logger.Log.Fatalf("FATAL: Invalid JWT configuration: %v", err)
 	}
+	jwtService, err := auth.NewJWTService(tokenConfig, keyStore)
+	if err != nil {
+		logger.Log.Fatalf("FATAL: Invalid JWT configuration: %v", err)
+	}
+	// Client OIDC mengambil discovery dari <iss>/.well-known/openid-configuration, jadi issuer harus berupa URL
+	if !strings.HasPrefix(tokenConfig.Issuer, "https://") && !strings.HasPrefix(tokenConfig.Issuer, "http://") {
+		logger.Log.Warnf("JWT_ISSUER %q is not a URL; OpenID Connect clients will reject it", tokenConfig.Issuer)
+	}
+
 	dbURL := os.Getenv("DATABASE_URL")
 	if dbURL == "" {
 		logger.Log.Fatal("FATAL: DATABASE_URL environment variable is not set.")
@@ -50,10 +73,110 @@ func main() {
 	}
 
 	userRepo := repository.NewPostgresUserRepository(db)
-	authService := service.NewAuthService(userRepo)
+	refreshTokenRepo := repository.NewPostgresRefreshTokenRepository(db)
+	revokedTokenRepo := repository.NewPostgresRevokedTokenRepository(db)
+	oauthClientRepo := repository.NewPostgresOAuthClientRepository(db)
+	authorizationCodeRepo := repository.NewPostgresAuthorizationCodeRepository(db)
+	personalAccessTokenRepo := repository.NewPostgresPersonalAccessTokenRepository(db)
+	sessionRepo := repository.NewPostgresSessionRepository(db)
+	emailTokenRepo := repository.NewPostgresEmailTokenRepository(db)
+	accountExportRepo := repository.NewPostgresAccountExportRepository(db)
+	mfaRepo := repository.NewPostgresMFARepository(db)
+	passkeyRepo := repository.NewPostgresPasskeyRepository(db)
+	identityRepo := repository.NewPostgresIdentityRepository(db)
+	roleRepo := repository.NewPostgresRoleRepository(db)
+	organizationRepo := repository.NewPostgresOrganizationRepository(db)
+	loginFailureRepo := repository.NewPostgresLoginFailureRepository(db)
+
+	revocationService := service.NewTokenRevocationService(revokedTokenRepo)
+	if err := revocationService.LoadActive(); err != nil {
+		logger.Log.Fatalf("FATAL: Could not load revoked tokens: %v", err)
+	}
+	stopRevocationSweeper := revocationService.StartSweeper(15 * time.Minute)
+
+	mailer, err := loadMailer()
+	if err != nil {
+		logger.Log.Fatalf("FATAL: Invalid mail configuration: %v", err)
+	}
+
+	sessionService := service.NewSessionService(sessionRepo, refreshTokenRepo)
+	emailVerificationService := service.NewEmailVerificationService(userRepo, emailTokenRepo, mailer,
+		getEnv("EMAIL_VERIFICATION_URL", "http://localhost:5173/verify-email"))
+	authOptions, err := loadAuthOptions()
+	if err != nil {
+		logger.Log.Fatalf("FATAL: Invalid login configuration: %v", err)
+	}
+	mfaService := service.NewMFAService(userRepo, mfaRepo, mailer, getEnv("MFA_ISSUER", "Go Auth Example"))
+	webAuthn, err := loadWebAuthn()
+	if err != nil {
+		logger.Log.Fatalf("FATAL: Invalid passkey configuration: %v", err)
+	}
+	passkeyService := service.NewPasskeyService(userRepo, passkeyRepo, webAuthn, mailer)
+	magicLinkService := service.NewMagicLinkService(userRepo, emailTokenRepo, mailer,
+		getEnv("MAGIC_LINK_URL", "http://localhost:5173/magic-link"), getEnv("MAGIC_LINK_BIND_BROWSER", "false") == "true")
+	socialProviders, err := loadSocialProviders()
+	if err != nil {
+		logger.Log.Fatalf("FATAL: Invalid social login configuration: %v", err)
+	}
+	for _, provider := range socialProviders.List() {
+		logger.Log.Infof("Social login provider %q enabled", provider.Name())
+	}
+	socialLoginService := service.NewSocialLoginService(userRepo, identityRepo, socialProviders, mailer)
+	roleService := service.NewRoleService(roleRepo, userRepo)
+	bootstrapAdmins(userRepo, roleService, getEnvList("ADMIN_EMAILS", nil))
+	organizationService := service.NewOrganizationService(organizationRepo, userRepo, mailer,
+		getEnv("ORGANIZATION_INVITATIONS_URL", "http://localhost:5173/invitations"))
+	authService := service.NewAuthService(userRepo, refreshTokenRepo, loginFailureRepo, revocationService, sessionService, emailVerificationService,
+		mfaService, passkeyService, magicLinkService, socialLoginService, roleService, organizationService, jwtService, authOptions)
 	userService := service.NewUserService(userRepo)
-	authHandler := api.NewAuthHandler(authService, userService)
-	router := api.SetupRouter(authHandler)
+	personalAccessTokenService := service.NewPersonalAccessTokenService(personalAccessTokenRepo, userRepo)
+	passwordService := service.NewPasswordService(userRepo, emailTokenRepo, refreshTokenRepo, personalAccessTokenRepo, revocationService, sessionService,
+		mailer, getEnv("PASSWORD_RESET_URL", "http://localhost:5173/reset-password"))
+	profileService := service.NewProfileService(userRepo, emailTokenRepo, mailer,
+		getEnv("EMAIL_CHANGE_URL", "http://localhost:5173/confirm-email"))
+	deletionGracePeriod, err := getEnvDuration("ACCOUNT_DELETION_GRACE_PERIOD", service.DefaultAccountDeletionGracePeriod)
+	if err != nil {
+		logger.Log.Fatalf("FATAL: Invalid account deletion configuration: %v", err)
+	}
+	accountService := service.NewAccountService(userRepo, accountExportRepo, refreshTokenRepo, personalAccessTokenRepo, revocationService, sessionService,
+		mailer, deletionGracePeriod)
+	stopDeletionWorker := accountService.StartDeletionWorker(time.Hour)
+	userAdminService := service.NewUserAdminService(userRepo, identityRepo, refreshTokenRepo, personalAccessTokenRepo, revocationService, sessionService,
+		passwordService, mfaService, roleService)
+	oauthService := service.NewOAuthService(oauthClientRepo, authorizationCodeRepo, userRepo, refreshTokenRepo, jwtService, jwtService)
+
+	cookieConfig, err := loadCookieConfig()
+	if err != nil {
+		logger.Log.Fatalf("FATAL: Invalid cookie configuration: %v", err)
+	}
+	cookieAuth, err := api.NewCookieAuth(cookieConfig, sessionService)
+	if err != nil {
+		logger.Log.Fatalf("FATAL: Invalid cookie configuration: %v", err)
+	}
+	if cookieConfig.Enabled {
+		logger.Log.Infof("Cookie authentication enabled with %s CSRF protection", cookieConfig.CSRFMode)
+	}
+
+	handlers := api.Handlers{
+		Auth:      api.NewAuthHandler(authService, userService, cookieAuth),
+		WellKnown: api.NewWellKnownHandler(keyStore),
+		OAuth:     api.NewOAuthHandler(oauthService, getEnv("OAUTH_CONSENT_URL", "http://localhost:5173/oauth/consent")),
+		Tokens:    api.NewPersonalAccessTokenHandler(personalAccessTokenService),
+		Sessions:  api.NewSessionHandler(sessionService),
+		Cookies:   cookieAuth,
+		Email:     api.NewEmailVerificationHandler(emailVerificationService),
+		Password:  api.NewPasswordHandler(passwordService),
+		Account:   api.NewAccountHandler(profileService, accountService, cookieAuth),
+		MFA:       api.NewMFAHandler(mfaService),
+		Passkeys:  api.NewPasskeyHandler(passkeyService),
+		Roles:     api.NewRoleHandler(roleService),
+		Users:     api.NewAdminUserHandler(userAdminService, accountService),
+		Orgs:      api.NewOrganizationHandler(organizationService),
+		OIDC:      api.NewOIDCHandler(userService, keyStore, tokenConfig.Issuer, getEnv("PUBLIC_BASE_URL", "http://localhost:8080")),
+	}
+	router := api.SetupRouter(handlers,
+		api.AuthMiddleware(jwtService, revocationService, personalAccessTokenService, sessionService, roleService, cookieAuth),
+		api.OrganizationMiddleware(organizationService))
 
 	port := os.Getenv("PORT")
 	if port == "" {
@@ -75,6 +198,21 @@ func main() {
 		}
 	}()
 
+	// SIGHUP membaca ulang JWT_KEYS_FILE, dipakai setelah rotasi key dengan cmd/keygen
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+	go func() {
+		for range reload {
+			keySet, err := loadKeySet()
+			if err != nil {
+				logger.Log.Errorf("Could not reload JWT signing keys, keeping current keys: %v", err)
+				continue
+			}
+			keyStore.Set(keySet)
+			logger.Log.Infof("JWT signing keys reloaded, key %q (%s) active", keySet.ActiveKey().ID, keySet.ActiveKey().Algorithm)
+		}
+	}()
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
@@ -83,6 +221,9 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
+	stopRevocationSweeper()
+	stopDeletionWorker()
+
 	if err := storage.CloseDB(db); err != nil {
 		logger.Log.Errorf("Error closing database: %v", err)
 	}
@@ -93,3 +234,23 @@ func main() {
 
 	logger.Log.Info("Server exiting")
 }
+
+// bootstrapAdmins memberikan role admin ke akun yang terdaftar di ADMIN_EMAILS,
+// supaya admin pertama bisa dibuat tanpa mengubah database secara manual
+func bootstrapAdmins(userRepo repository.UserRepository, roleService service.RoleService, emails []string) {
+	for _, email := range emails {
+		user, err := userRepo.GetByEmail(email)
+		if err != nil {
+			logger.Log.Errorf("Could not look up admin account %q: %v", email, err)
+			continue
+		}
+		if user == nil {
+			logger.Log.Warnf("ADMIN_EMAILS entry %q does not match any account; register it and restart to grant admin", email)
+			continue
+		}
+		if err := roleService.Assign(user.ID, model.RoleAdmin); err != nil {
+			logger.Log.Errorf("Could not grant admin role to %q: %v", email, err)
+			continue
+		}
+	}
+}
@@ -0,0 +1,97 @@
+// cmd/useradmin/main.go
+//
+// useradmin menjalankan operasi admin terhadap akun user di database (DATABASE_URL).
+//
+// Contoh:
+//
+//	useradmin -unlock -username alice
+//	useradmin -unlock -email alice@example.com
+//	useradmin -unlock -id 42
+//	useradmin -reset-mfa -username alice
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"go-auth-example/internal/mail"
+	"go-auth-example/internal/model"
+	"go-auth-example/internal/repository"
+	"go-auth-example/internal/service"
+	"go-auth-example/internal/storage"
+
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	_ = godotenv.Load()
+
+	unlock := flag.Bool("unlock", false, "unlock an account locked after repeated failed logins")
+	resetMFA := flag.Bool("reset-mfa", false, "disable two-factor authentication for a user who lost their authenticator and recovery codes")
+	id := flag.Int("id", 0, "user ID")
+	username := flag.String("username", "", "username")
+	email := flag.String("email", "", "email address")
+	flag.Parse()
+
+	if (!*unlock && !*resetMFA) || (*id == 0 && *username == "" && *email == "") {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := run(*id, *username, *email, *unlock, *resetMFA); err != nil {
+		fmt.Fprintf(os.Stderr, "useradmin: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(id int, username, email string, unlock, resetMFA bool) error {
+	db, err := storage.ConnectDB()
+	if err != nil {
+		return err
+	}
+	defer storage.CloseDB(db)
+
+	if err := storage.CreateTableIfNotExists(db); err != nil {
+		return err
+	}
+
+	userRepo := repository.NewPostgresUserRepository(db)
+	var user *model.User
+	switch {
+	case id != 0:
+		user, err = userRepo.GetByID(id)
+	case username != "":
+		user, err = userRepo.GetByUsername(username)
+	default:
+		user, err = userRepo.GetByEmail(email)
+	}
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return fmt.Errorf("user not found")
+	}
+
+	if unlock {
+		refreshTokenRepo := repository.NewPostgresRefreshTokenRepository(db)
+		sessionService := service.NewSessionService(repository.NewPostgresSessionRepository(db), refreshTokenRepo)
+		revocationService := service.NewTokenRevocationService(repository.NewPostgresRevokedTokenRepository(db))
+		accountService := service.NewAccountService(userRepo, repository.NewPostgresAccountExportRepository(db), refreshTokenRepo,
+			repository.NewPostgresPersonalAccessTokenRepository(db), revocationService, sessionService, mail.NewLogMailer(), 0)
+		if err := accountService.Unlock(user.ID); err != nil {
+			return err
+		}
+		fmt.Printf("unlocked: %s <%s> (id %d)\n", user.Username, user.Email, user.ID)
+	}
+
+	if resetMFA {
+		// Pemberitahuan ke user hanya dicatat di log; admin yang menjalankan reset menghubungi user secara langsung
+		mfaService := service.NewMFAService(userRepo, repository.NewPostgresMFARepository(db), mail.NewLogMailer(), "")
+		if err := mfaService.Reset(user.ID); err != nil {
+			return err
+		}
+		fmt.Printf("mfa reset: %s <%s> (id %d)\n", user.Username, user.Email, user.ID)
+	}
+	return nil
+}
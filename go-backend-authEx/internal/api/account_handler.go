@@ -0,0 +1,181 @@
+// internal/api/account_handler.go
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"go-auth-example/internal/logger"
+	"go-auth-example/internal/model"
+	"go-auth-example/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// AccountHandler melayani perubahan data akun milik user yang sedang login
+type AccountHandler struct {
+	profileService service.ProfileService
+	accountService service.AccountService
+	cookieAuth     *CookieAuth
+}
+
+// NewAccountHandler constructor untuk AccountHandler
+func NewAccountHandler(profileService service.ProfileService, accountService service.AccountService, cookieAuth *CookieAuth) *AccountHandler {
+	return &AccountHandler{profileService: profileService, accountService: accountService, cookieAuth: cookieAuth}
+}
+
+// UpdateProfileHandler mengubah username dan/atau meminta perubahan email (PATCH /api/profile)
+func (h *AccountHandler) UpdateProfileHandler(c *gin.Context) {
+	var input model.UpdateProfileInput
+	logFields := logrus.Fields{
+		"handler": "UpdateProfileHandler",
+	}
+
+	principal, ok := requireFirstPartyUser(c, logFields)
+	if !ok {
+		return
+	}
+	logFields["user_id"] = principal.UserID
+
+	if validationErrors := ValidateAndBind(c, &input); validationErrors != nil {
+		logger.Log.WithFields(logFields).Warnf("Validation failed for profile update: %v", validationErrors)
+		RespondWithValidationErrors(c, http.StatusBadRequest, validationErrors)
+		return
+	}
+
+	user, pendingEmail, err := h.profileService.UpdateProfile(principal.UserID, input)
+	if err != nil {
+		switch err.Error() {
+		case "username already exists":
+			RespondWithError(c, NewAPIError(http.StatusConflict, ErrCodeUsernameTaken, "The username is already taken."))
+		case "email already registered":
+			RespondWithError(c, NewAPIError(http.StatusConflict, ErrCodeEmailTaken, "The email address is already in use."))
+		case "user associated with token not found":
+			RespondWithError(c, NewAPIError(http.StatusNotFound, ErrCodeUserNotFound, "User profile not found."))
+		default:
+			logger.Log.WithFields(logFields).Errorf("Unhandled profile update error: %v", err)
+			RespondWithError(c, NewAPIError(http.StatusInternalServerError, ErrCodeInternalServer, "Failed to update profile. Please try again later."))
+		}
+		return
+	}
+
+	response := gin.H{"message": "Profile updated successfully", "user": user}
+	if pendingEmail != "" {
+		response["pending_email"] = pendingEmail
+		response["message"] = "Profile updated. Check your new email address to confirm the change."
+	}
+	logger.Log.WithFields(logFields).Info("Profile updated successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+// ConfirmEmailChangeHandler menerapkan email baru dari link konfirmasi (POST /auth/email/confirm).
+// Tidak membutuhkan login karena link bisa dibuka di perangkat lain.
+func (h *AccountHandler) ConfirmEmailChangeHandler(c *gin.Context) {
+	var input model.ConfirmEmailChangeInput
+	logFields := logrus.Fields{
+		"handler": "ConfirmEmailChangeHandler",
+	}
+
+	if validationErrors := ValidateAndBind(c, &input); validationErrors != nil {
+		logger.Log.WithFields(logFields).Warnf("Validation failed for email change confirmation: %v", validationErrors)
+		RespondWithValidationErrors(c, http.StatusBadRequest, validationErrors)
+		return
+	}
+
+	user, err := h.profileService.ConfirmEmailChange(input.Token)
+	if err != nil {
+		switch err.Error() {
+		case "invalid or expired email change token":
+			logger.Log.WithFields(logFields).Warn("Invalid email change token presented.")
+			RespondWithError(c, NewAPIError(http.StatusBadRequest, ErrCodeEmailChangeTokenInvalid, "The confirmation link is invalid or has expired."))
+		case "email already registered":
+			RespondWithError(c, NewAPIError(http.StatusConflict, ErrCodeEmailTaken, "The email address is already in use."))
+		default:
+			logger.Log.WithFields(logFields).Errorf("Unhandled email change error: %v", err)
+			RespondWithError(c, NewAPIError(http.StatusInternalServerError, ErrCodeInternalServer, "Failed to change email. Please try again later."))
+		}
+		return
+	}
+
+	logFields["user_id"] = user.ID
+	logger.Log.WithFields(logFields).Info("Email changed successfully")
+	c.JSON(http.StatusOK, gin.H{"message": "Email address changed successfully", "user": user})
+}
+
+// DeleteAccountHandler menjadwalkan penghapusan akun setelah password dikonfirmasi ulang (DELETE /api/account).
+// Akun baru dihapus permanen setelah masa tenggang; login sebelum itu membatalkan penghapusan.
+func (h *AccountHandler) DeleteAccountHandler(c *gin.Context) {
+	var input model.DeleteAccountInput
+	logFields := logrus.Fields{
+		"handler": "DeleteAccountHandler",
+	}
+
+	principal, ok := requireFirstPartyUser(c, logFields)
+	if !ok {
+		return
+	}
+	logFields["user_id"] = principal.UserID
+
+	if validationErrors := ValidateAndBind(c, &input); validationErrors != nil {
+		logger.Log.WithFields(logFields).Warnf("Validation failed for account deletion: %v", validationErrors)
+		RespondWithValidationErrors(c, http.StatusBadRequest, validationErrors)
+		return
+	}
+
+	deletionAt, err := h.accountService.RequestDeletion(principal.UserID, input.Password, principal.AuthTime)
+	if err != nil {
+		switch err.Error() {
+		case "invalid password":
+			RespondWithError(c, NewAPIError(http.StatusBadRequest, ErrCodeInvalidCredentials, "The password is incorrect."))
+		case "recent login required":
+			respondRecentLoginRequired(c)
+		case "user not found":
+			RespondWithError(c, NewAPIError(http.StatusNotFound, ErrCodeUserNotFound, "User profile not found."))
+		default:
+			logger.Log.WithFields(logFields).Errorf("Unhandled account deletion error: %v", err)
+			RespondWithError(c, NewAPIError(http.StatusInternalServerError, ErrCodeInternalServer, "Failed to delete account. Please try again later."))
+		}
+		return
+	}
+
+	// Semua session sudah dicabut, jadi cookie di browser ini juga tidak berguna lagi
+	if h.cookieAuth.Enabled() {
+		h.cookieAuth.clearCookies(c)
+	}
+	logger.Log.WithFields(logFields).Info("Account deletion scheduled")
+	c.JSON(http.StatusAccepted, gin.H{
+		"message":               "Your account is scheduled for deletion. Log in before the deletion date to cancel.",
+		"deletion_scheduled_at": deletionAt,
+	})
+}
+
+// ExportHandler mengunduh arsip JSON berisi semua data yang disimpan tentang user (GET /api/account/export)
+func (h *AccountHandler) ExportHandler(c *gin.Context) {
+	logFields := logrus.Fields{
+		"handler": "ExportHandler",
+	}
+
+	principal, ok := requireFirstPartyUser(c, logFields)
+	if !ok {
+		return
+	}
+	logFields["user_id"] = principal.UserID
+
+	export, err := h.accountService.Export(principal.UserID)
+	if err != nil {
+		switch err.Error() {
+		case "user not found":
+			RespondWithError(c, NewAPIError(http.StatusNotFound, ErrCodeUserNotFound, "User profile not found."))
+		default:
+			logger.Log.WithFields(logFields).Errorf("Unhandled account export error: %v", err)
+			RespondWithError(c, NewAPIError(http.StatusInternalServerError, ErrCodeInternalServer, "Failed to export account data. Please try again later."))
+		}
+		return
+	}
+
+	logger.Log.WithFields(logFields).Info("Account data exported")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="account-export-%d.json"`, principal.UserID))
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, export)
+}
@@ -0,0 +1,147 @@
+// internal/api/admin_user_handler.go
+package api
+
+import (
+	"net/http"
+
+	"go-auth-example/internal/logger"
+	"go-auth-example/internal/model"
+	"go-auth-example/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// AdminUserHandler melayani pengelolaan akun user di /api/admin/users; akses dibatasi lewat RequirePermission di router
+type AdminUserHandler struct {
+	userAdminService service.UserAdminService
+	accountService   service.AccountService
+}
+
+// NewAdminUserHandler constructor untuk AdminUserHandler
+func NewAdminUserHandler(userAdminService service.UserAdminService, accountService service.AccountService) *AdminUserHandler {
+	return &AdminUserHandler{userAdminService: userAdminService, accountService: accountService}
+}
+
+// ListHandler menampilkan daftar user dengan filter, urutan dan halaman dari query string (GET /api/admin/users)
+func (h *AdminUserHandler) ListHandler(c *gin.Context) {
+	var query model.UserListQuery
+	logFields := logrus.Fields{
+		"handler": "AdminListUsersHandler",
+	}
+
+	if validationErrors := ValidateAndBindQuery(c, &query); validationErrors != nil {
+		logger.Log.WithFields(logFields).Warnf("Validation failed for user listing: %v", validationErrors)
+		RespondWithValidationErrors(c, http.StatusBadRequest, validationErrors)
+		return
+	}
+	if query.CreatedAfter != nil && query.CreatedBefore != nil && !query.CreatedAfter.Before(*query.CreatedBefore) {
+		RespondWithError(c, NewAPIError(http.StatusBadRequest, ErrCodeBadRequest, "created_after must be before created_before."))
+		return
+	}
+
+	users, err := h.userAdminService.List(query)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Unhandled user listing error: %v", err)
+		RespondWithError(c, NewAPIError(http.StatusInternalServerError, ErrCodeInternalServer, "Failed to list users. Please try again later."))
+		return
+	}
+	c.JSON(http.StatusOK, users)
+}
+
+// GetHandler menampilkan detail user (GET /api/admin/users/:id)
+func (h *AdminUserHandler) GetHandler(c *gin.Context) {
+	logFields := logrus.Fields{
+		"handler": "AdminGetUserHandler",
+	}
+
+	userID, ok := parseUserIDParam(c)
+	if !ok {
+		return
+	}
+	logFields["target_user_id"] = userID
+
+	detail, err := h.userAdminService.Get(userID)
+	if err != nil {
+		switch err.Error() {
+		case "user not found":
+			RespondWithError(c, NewAPIError(http.StatusNotFound, ErrCodeUserNotFound, "User not found."))
+		default:
+			logger.Log.WithFields(logFields).Errorf("Unhandled user detail error: %v", err)
+			RespondWithError(c, NewAPIError(http.StatusInternalServerError, ErrCodeInternalServer, "Failed to load user. Please try again later."))
+		}
+		return
+	}
+	c.JSON(http.StatusOK, detail)
+}
+
+// DisableHandler menonaktifkan akun user dan mengeluarkannya dari semua perangkat (POST /api/admin/users/:id/disable)
+func (h *AdminUserHandler) DisableHandler(c *gin.Context) {
+	h.runAction(c, "AdminDisableUserHandler", h.userAdminService.Disable, "Account disabled successfully")
+}
+
+// EnableHandler mengaktifkan kembali akun user (POST /api/admin/users/:id/enable)
+func (h *AdminUserHandler) EnableHandler(c *gin.Context) {
+	h.runAction(c, "AdminEnableUserHandler", h.userAdminService.Enable, "Account enabled successfully")
+}
+
+// ForcePasswordResetHandler mengosongkan password user dan mengirim link reset ke email-nya
+// (POST /api/admin/users/:id/password-reset)
+func (h *AdminUserHandler) ForcePasswordResetHandler(c *gin.Context) {
+	h.runAction(c, "AdminForcePasswordResetHandler", h.userAdminService.ForcePasswordReset,
+		"Password cleared. A reset link has been sent to the user's email address.")
+}
+
+// RevokeSessionsHandler mencabut semua session dan token user (DELETE /api/admin/users/:id/sessions)
+func (h *AdminUserHandler) RevokeSessionsHandler(c *gin.Context) {
+	h.runAction(c, "AdminRevokeSessionsHandler", h.userAdminService.RevokeSessions, "All sessions revoked successfully")
+}
+
+// DeleteHandler menghapus akun user secara permanen (DELETE /api/admin/users/:id)
+func (h *AdminUserHandler) DeleteHandler(c *gin.Context) {
+	h.runAction(c, "AdminDeleteUserHandler", h.userAdminService.Delete, "Account deleted successfully")
+}
+
+// UnlockHandler membuka kunci login akun yang terkunci karena login gagal berulang (POST /api/admin/users/:id/unlock)
+func (h *AdminUserHandler) UnlockHandler(c *gin.Context) {
+	h.runAction(c, "AdminUnlockUserHandler", func(_, userID int) error {
+		return h.accountService.Unlock(userID)
+	}, "Account unlocked successfully")
+}
+
+// runAction menjalankan operasi admin terhadap user :id dan menerjemahkan error-nya menjadi respons
+func (h *AdminUserHandler) runAction(c *gin.Context, handlerName string, action func(actorID, userID int) error, message string) {
+	logFields := logrus.Fields{
+		"handler": handlerName,
+	}
+
+	principal, _ := GetPrincipal(c)
+	logFields["user_id"] = principal.UserID
+
+	userID, ok := parseUserIDParam(c)
+	if !ok {
+		return
+	}
+	logFields["target_user_id"] = userID
+
+	if err := action(principal.UserID, userID); err != nil {
+		switch err.Error() {
+		case "user not found":
+			RespondWithError(c, NewAPIError(http.StatusNotFound, ErrCodeUserNotFound, "User not found."))
+		case "cannot modify own account":
+			RespondWithError(c, NewAPIError(http.StatusConflict, ErrCodeAdminSelfAction, "Use your own account settings to change your account."))
+		case "failed to send password reset email":
+			RespondWithError(c, NewAPIError(http.StatusInternalServerError, ErrCodeInternalServer,
+				"The password was cleared but the reset email could not be sent. The user can request a new link with \"Forgot password\"."))
+		case "account pending deletion":
+			RespondWithError(c, NewAPIError(http.StatusConflict, ErrCodeAccountPendingDeletion, "The account is scheduled for deletion at the user's request."))
+		default:
+			logger.Log.WithFields(logFields).Errorf("Unhandled admin user action error: %v", err)
+			RespondWithError(c, NewAPIError(http.StatusInternalServerError, ErrCodeInternalServer, "Failed to update the account. Please try again later."))
+		}
+		return
+	}
+
+	logger.Log.WithFields(logFields).Info(message)
+	c.JSON(http.StatusOK, gin.H{"message": message})
+}
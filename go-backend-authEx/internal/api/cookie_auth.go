@@ -0,0 +1,207 @@
+// internal/api/cookie_auth.go
+package api
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+
+	"go-auth-example/internal/auth"
+	"go-auth-example/internal/logger"
+	"go-auth-example/internal/model"
+	"go-auth-example/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Strategi proteksi CSRF untuk mode cookie
+const (
+	// CSRFModeDoubleSubmit: token acak disimpan di cookie yang bisa dibaca JavaScript dan harus dikirim ulang di header
+	CSRFModeDoubleSubmit = "double-submit"
+	// CSRFModeSynchronizer: token disimpan di server per session dan harus dikirim di header
+	CSRFModeSynchronizer = "synchronizer"
+)
+
+const (
+	accessTokenCookie  = "access_token"
+	refreshTokenCookie = "refresh_token"
+	csrfCookie         = "csrf_token"
+	// CSRFHeader adalah header tempat client mengirim token CSRF
+	CSRFHeader = "X-CSRF-Token"
+
+	// refreshTokenCookiePath membatasi cookie refresh token hanya terkirim ke /auth/refresh
+	refreshTokenCookiePath = "/auth/refresh"
+
+	// authViaCookieKey menandai request yang diautentikasi dengan cookie (bukan header Authorization)
+	authViaCookieKey = "auth_via_cookie"
+)
+
+// CookieConfig mengatur mode autentikasi berbasis cookie
+type CookieConfig struct {
+	Enabled  bool // false = token hanya dikirim di body dan header Authorization (perilaku lama)
+	CSRFMode string
+	Domain   string // Kosong = cookie hanya untuk host backend
+	Secure   bool
+	SameSite http.SameSite
+}
+
+// CookieAuth menerbitkan, membaca dan menghapus cookie autentikasi, serta memverifikasi token CSRF.
+// Cookie access token dan refresh token bersifat HttpOnly sehingga tidak bisa dicuri lewat XSS.
+type CookieAuth struct {
+	config         CookieConfig
+	sessionService service.SessionService
+}
+
+// NewCookieAuth constructor untuk CookieAuth
+func NewCookieAuth(config CookieConfig, sessionService service.SessionService) (*CookieAuth, error) {
+	if config.CSRFMode == "" {
+		config.CSRFMode = CSRFModeDoubleSubmit
+	}
+	if config.CSRFMode != CSRFModeDoubleSubmit && config.CSRFMode != CSRFModeSynchronizer {
+		return nil, fmt.Errorf("unsupported CSRF mode %q", config.CSRFMode)
+	}
+	if config.SameSite == http.SameSiteNoneMode && !config.Secure {
+		return nil, fmt.Errorf("SameSite=None cookies must be Secure")
+	}
+	return &CookieAuth{config: config, sessionService: sessionService}, nil
+}
+
+// Enabled mengecek apakah mode cookie aktif
+func (a *CookieAuth) Enabled() bool {
+	return a != nil && a.config.Enabled
+}
+
+// setTokenCookies menyimpan pasangan token di cookie HttpOnly dan mengembalikan token CSRF untuk client
+func (a *CookieAuth) setTokenCookies(c *gin.Context, tokens *model.TokenPair) (string, error) {
+	a.setCookie(c, accessTokenCookie, tokens.AccessToken, "/", int(tokens.ExpiresIn), true)
+	a.setCookie(c, refreshTokenCookie, tokens.RefreshToken, refreshTokenCookiePath, int(service.RefreshTokenTTL.Seconds()), true)
+	return a.issueCSRFToken(c, tokens.SessionID)
+}
+
+// clearCookies menghapus semua cookie autentikasi (logout)
+func (a *CookieAuth) clearCookies(c *gin.Context) {
+	a.setCookie(c, accessTokenCookie, "", "/", -1, true)
+	a.setCookie(c, refreshTokenCookie, "", refreshTokenCookiePath, -1, true)
+	if a.config.CSRFMode == CSRFModeDoubleSubmit {
+		a.setCookie(c, csrfCookie, "", "/", -1, false)
+	}
+}
+
+// issueCSRFToken menyiapkan token CSRF sesuai mode: double-submit membuat cookie baru,
+// synchronizer mengambil token yang tersimpan di session.
+func (a *CookieAuth) issueCSRFToken(c *gin.Context, sessionID string) (string, error) {
+	if a.config.CSRFMode == CSRFModeSynchronizer {
+		return a.sessionService.CSRFToken(sessionID)
+	}
+
+	token, err := auth.GenerateOpaqueToken(32)
+	if err != nil {
+		return "", err
+	}
+	// Tidak HttpOnly: JavaScript frontend perlu membacanya untuk dikirim ulang di header
+	a.setCookie(c, csrfCookie, token, "/", int(service.RefreshTokenTTL.Seconds()), false)
+	return token, nil
+}
+
+func (a *CookieAuth) setCookie(c *gin.Context, name, value, path string, maxAge int, httpOnly bool) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		Domain:   a.config.Domain,
+		MaxAge:   maxAge,
+		Secure:   a.config.Secure,
+		HttpOnly: httpOnly,
+		SameSite: a.config.SameSite,
+	})
+}
+
+// cookieValue membaca cookie; kosong jika mode cookie tidak aktif atau cookie tidak ada
+func (a *CookieAuth) cookieValue(c *gin.Context, name string) string {
+	if !a.Enabled() {
+		return ""
+	}
+	value, err := c.Cookie(name)
+	if err != nil {
+		return ""
+	}
+	return value
+}
+
+// CSRFMiddleware menolak request yang mengubah state (selain GET, HEAD, OPTIONS) jika diautentikasi dengan cookie
+// tetapi tidak membawa token CSRF yang valid. Request dengan header Authorization tidak rentan CSRF sehingga dilewati.
+// Harus dipasang setelah AuthMiddleware.
+func (a *CookieAuth) CSRFMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !c.GetBool(authViaCookieKey) {
+			c.Next()
+			return
+		}
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+
+		token := c.GetHeader(CSRFHeader)
+		if token == "" {
+			RespondWithError(c, NewAPIError(http.StatusForbidden, ErrCodeCSRFInvalid, "Missing CSRF token."))
+			return
+		}
+
+		if a.config.CSRFMode == CSRFModeSynchronizer {
+			principal, ok := GetPrincipal(c)
+			if !ok || principal.SessionID == "" {
+				RespondWithError(c, NewAPIError(http.StatusForbidden, ErrCodeCSRFInvalid, "Invalid CSRF token."))
+				return
+			}
+			if err := a.sessionService.VerifyCSRFToken(principal.SessionID, token); err != nil {
+				if err.Error() == "invalid csrf token" {
+					RespondWithError(c, NewAPIError(http.StatusForbidden, ErrCodeCSRFInvalid, "Invalid CSRF token."))
+					return
+				}
+				logger.Log.WithField("session_id", principal.SessionID).Errorf("Error verifying CSRF token: %v", err)
+				RespondWithError(c, NewAPIError(http.StatusInternalServerError, ErrCodeInternalServer, "Could not verify CSRF token."))
+				return
+			}
+			c.Next()
+			return
+		}
+
+		cookieToken := a.cookieValue(c, csrfCookie)
+		if cookieToken == "" || subtle.ConstantTimeCompare([]byte(cookieToken), []byte(token)) != 1 {
+			RespondWithError(c, NewAPIError(http.StatusForbidden, ErrCodeCSRFInvalid, "Invalid CSRF token."))
+			return
+		}
+		c.Next()
+	}
+}
+
+// CSRFTokenHandler mengembalikan token CSRF untuk session saat ini (GET /api/csrf),
+// misalnya setelah halaman frontend dimuat ulang.
+func (a *CookieAuth) CSRFTokenHandler(c *gin.Context) {
+	if !c.GetBool(authViaCookieKey) {
+		RespondWithError(c, NewAPIError(http.StatusBadRequest, ErrCodeBadRequest, "CSRF tokens are only used with cookie authentication."))
+		return
+	}
+
+	if a.config.CSRFMode == CSRFModeDoubleSubmit {
+		if token := a.cookieValue(c, csrfCookie); token != "" {
+			c.JSON(http.StatusOK, gin.H{"csrf_token": token})
+			return
+		}
+	}
+
+	principal, ok := GetPrincipal(c)
+	if !ok {
+		RespondWithError(c, NewAPIError(http.StatusUnauthorized, ErrCodeUnauthorized, "Authentication required."))
+		return
+	}
+	token, err := a.issueCSRFToken(c, principal.SessionID)
+	if err != nil {
+		logger.Log.WithField("session_id", principal.SessionID).Errorf("Error issuing CSRF token: %v", err)
+		RespondWithError(c, NewAPIError(http.StatusInternalServerError, ErrCodeInternalServer, "Could not issue CSRF token."))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"csrf_token": token})
+}
@@ -0,0 +1,76 @@
+// internal/api/email_verification_handler.go
+package api
+
+import (
+	"net/http"
+
+	"go-auth-example/internal/logger"
+	"go-auth-example/internal/model"
+	"go-auth-example/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// EmailVerificationHandler melayani konfirmasi alamat email
+type EmailVerificationHandler struct {
+	verificationService service.EmailVerificationService
+}
+
+// NewEmailVerificationHandler constructor untuk EmailVerificationHandler
+func NewEmailVerificationHandler(verificationService service.EmailVerificationService) *EmailVerificationHandler {
+	return &EmailVerificationHandler{verificationService: verificationService}
+}
+
+// VerifyHandler memakai token dari link email (POST /auth/verify-email)
+func (h *EmailVerificationHandler) VerifyHandler(c *gin.Context) {
+	var input model.VerifyEmailInput
+	logFields := logrus.Fields{
+		"handler": "VerifyEmailHandler",
+	}
+
+	if validationErrors := ValidateAndBind(c, &input); validationErrors != nil {
+		logger.Log.WithFields(logFields).Warnf("Validation failed for email verification: %v", validationErrors)
+		RespondWithValidationErrors(c, http.StatusBadRequest, validationErrors)
+		return
+	}
+
+	user, err := h.verificationService.Verify(input.Token)
+	if err != nil {
+		switch err.Error() {
+		case "invalid or expired verification token":
+			logger.Log.WithFields(logFields).Warn("Invalid email verification token presented.")
+			RespondWithError(c, NewAPIError(http.StatusBadRequest, ErrCodeVerificationTokenInvalid, "The verification link is invalid or has expired."))
+		default:
+			logger.Log.WithFields(logFields).Errorf("Unhandled email verification error: %v", err)
+			RespondWithError(c, NewAPIError(http.StatusInternalServerError, ErrCodeInternalServer, "Failed to verify email. Please try again later."))
+		}
+		return
+	}
+
+	logFields["user_id"] = user.ID
+	logger.Log.WithFields(logFields).Info("Email verified successfully")
+	c.JSON(http.StatusOK, gin.H{"message": "Email verified successfully", "user": user})
+}
+
+// ResendHandler mengirim ulang link verifikasi (POST /auth/verify-email/resend).
+// Respons selalu sama agar endpoint ini tidak bisa dipakai untuk mengecek email terdaftar.
+func (h *EmailVerificationHandler) ResendHandler(c *gin.Context) {
+	var input model.ResendVerificationInput
+	logFields := logrus.Fields{
+		"handler": "ResendVerificationHandler",
+	}
+
+	if validationErrors := ValidateAndBind(c, &input); validationErrors != nil {
+		logger.Log.WithFields(logFields).Warnf("Validation failed for verification resend: %v", validationErrors)
+		RespondWithValidationErrors(c, http.StatusBadRequest, validationErrors)
+		return
+	}
+
+	if err := h.verificationService.Resend(input.Email); err != nil {
+		logger.Log.WithFields(logFields).Errorf("Unhandled verification resend error: %v", err)
+		RespondWithError(c, NewAPIError(http.StatusInternalServerError, ErrCodeInternalServer, "Failed to send verification email. Please try again later."))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "If the address is registered and not yet verified, a verification email has been sent."})
+}
@@ -8,15 +8,59 @@ const (
 	ErrCodeInternalServer   = "INTERNAL_SERVER_ERROR"
 	ErrCodeUnauthorized     = "UNAUTHORIZED"
 	ErrCodeNotFound         = "NOT_FOUND"
+	ErrCodeForbidden        = "FORBIDDEN"
 	ErrCodeValidationFailed = "VALIDATION_FAILED"
 
 	// Auth Specific Errors
-	ErrCodeEmailTaken         = "AUTH_EMAIL_TAKEN"
-	ErrCodeUsernameTaken      = "AUTH_USERNAME_TAKEN"
-	ErrCodeInvalidCredentials = "AUTH_INVALID_CREDENTIALS"
-	ErrCodeUserNotFound       = "AUTH_USER_NOT_FOUND" // Bisa digunakan jika profil tidak ditemukan
-	ErrCodeTokenExpired       = "AUTH_TOKEN_EXPIRED"
-	ErrCodeTokenInvalid       = "AUTH_TOKEN_INVALID"
-	ErrCodeMissingAuthHeader  = "AUTH_MISSING_HEADER"
-	ErrCodeInvalidAuthHeader  = "AUTH_INVALID_HEADER"
+	ErrCodeEmailTaken               = "AUTH_EMAIL_TAKEN"
+	ErrCodeUsernameTaken            = "AUTH_USERNAME_TAKEN"
+	ErrCodeInvalidCredentials       = "AUTH_INVALID_CREDENTIALS"
+	ErrCodeUserNotFound             = "AUTH_USER_NOT_FOUND" // Bisa digunakan jika profil tidak ditemukan
+	ErrCodeTokenExpired             = "AUTH_TOKEN_EXPIRED"
+	ErrCodeTokenInvalid             = "AUTH_TOKEN_INVALID"
+	ErrCodeMissingAuthHeader        = "AUTH_MISSING_HEADER"
+	ErrCodeInvalidAuthHeader        = "AUTH_INVALID_HEADER"
+	ErrCodeRefreshTokenInvalid      = "AUTH_REFRESH_TOKEN_INVALID"
+	ErrCodeRefreshTokenReused       = "AUTH_REFRESH_TOKEN_REUSED"
+	ErrCodeTokenRevoked             = "AUTH_TOKEN_REVOKED"
+	ErrCodeOAuthInvalidRequest      = "OAUTH_INVALID_REQUEST"
+	ErrCodeInsufficientScope        = "AUTH_INSUFFICIENT_SCOPE"
+	ErrCodeSessionRevoked           = "AUTH_SESSION_REVOKED"
+	ErrCodeCSRFInvalid              = "AUTH_CSRF_INVALID"
+	ErrCodeEmailNotVerified         = "AUTH_EMAIL_NOT_VERIFIED"
+	ErrCodeVerificationTokenInvalid = "AUTH_VERIFICATION_TOKEN_INVALID"
+	ErrCodeResetTokenInvalid        = "AUTH_RESET_TOKEN_INVALID"
+	ErrCodePasswordReused           = "AUTH_PASSWORD_REUSED"
+	ErrCodeEmailChangeTokenInvalid  = "AUTH_EMAIL_CHANGE_TOKEN_INVALID"
+	ErrCodeAccountDisabled          = "AUTH_ACCOUNT_DISABLED"
+	ErrCodeAccountLocked            = "AUTH_ACCOUNT_LOCKED"
+	ErrCodeMFATokenInvalid          = "AUTH_MFA_TOKEN_INVALID"
+	ErrCodeMFACodeInvalid           = "AUTH_MFA_CODE_INVALID"
+	ErrCodeMFAAlreadyEnabled        = "AUTH_MFA_ALREADY_ENABLED"
+	ErrCodeMFANotEnabled            = "AUTH_MFA_NOT_ENABLED"
+	ErrCodePasskeySessionInvalid    = "AUTH_PASSKEY_SESSION_INVALID"
+	ErrCodePasskeyInvalid           = "AUTH_PASSKEY_INVALID"
+	ErrCodePasskeyExists            = "AUTH_PASSKEY_ALREADY_REGISTERED"
+	ErrCodePasskeyLimit             = "AUTH_PASSKEY_LIMIT_REACHED"
+	ErrCodeMagicLinkInvalid         = "AUTH_MAGIC_LINK_INVALID"
+	ErrCodeMagicLinkBrowserMismatch = "AUTH_MAGIC_LINK_BROWSER_MISMATCH"
+	ErrCodeSocialProviderUnknown    = "AUTH_SOCIAL_PROVIDER_UNKNOWN"
+	ErrCodeSocialStateInvalid       = "AUTH_SOCIAL_STATE_INVALID"
+	ErrCodeSocialLoginFailed        = "AUTH_SOCIAL_LOGIN_FAILED"
+	ErrCodeSocialEmailUnverified    = "AUTH_SOCIAL_EMAIL_UNVERIFIED"
+	ErrCodeLastAdmin                = "AUTH_LAST_ADMIN"
+	ErrCodeAdminSelfAction          = "AUTH_ADMIN_SELF_ACTION"
+	ErrCodeAccountPendingDeletion   = "AUTH_ACCOUNT_PENDING_DELETION"
+	ErrCodeRecentLoginRequired      = "AUTH_RECENT_LOGIN_REQUIRED"
+
+	// Organization Errors
+	ErrCodeOrgRequired        = "ORG_REQUIRED"
+	ErrCodeOrgAccessDenied    = "ORG_ACCESS_DENIED"
+	ErrCodeOrgNotFound        = "ORG_NOT_FOUND"
+	ErrCodeOrgSlugTaken       = "ORG_SLUG_TAKEN"
+	ErrCodeOrgInvalidSlug     = "ORG_INVALID_SLUG"
+	ErrCodeOrgLastOwner       = "ORG_LAST_OWNER"
+	ErrCodeOrgAlreadyMember   = "ORG_ALREADY_MEMBER"
+	ErrCodeOrgMemberNotFound  = "ORG_MEMBER_NOT_FOUND"
+	ErrCodeInvitationNotFound = "ORG_INVITATION_NOT_FOUND"
 )
@@ -2,8 +2,12 @@
 package api
 
 import (
+	"errors"
+	"math"
 	"net/http"
+	"strconv"
 
+	"go-auth-example/internal/auth"
 	"go-auth-example/internal/logger" // Dari Tugas 1.3
 	"go-auth-example/internal/model"
 	"go-auth-example/internal/service" // <-- PASTIKAN IMPORT INI ADA DAN DIGUNAKAN
@@ -17,14 +21,16 @@ import (
 type AuthHandler struct {
 	authService service.AuthService // Menggunakan service.AuthService
 	userService service.UserService // Menggunakan service.UserService
+	cookieAuth  *CookieAuth         // Mode cookie; token tidak dikirim di body jika aktif
 }
 
 // NewAuthHandler constructor untuk AuthHandler
 // PASTIKAN FUNGSI INI ADA DAN DIEKSPOR
-func NewAuthHandler(auth service.AuthService, user service.UserService) *AuthHandler {
+func NewAuthHandler(auth service.AuthService, user service.UserService, cookieAuth *CookieAuth) *AuthHandler {
 	return &AuthHandler{
 		authService: auth,
 		userService: user,
+		cookieAuth:  cookieAuth,
 	}
 }
 
@@ -77,24 +83,31 @@ func (h *AuthHandler) LoginHandler(c *gin.Context) {
 	logFields := logrus.Fields{
 		"handler": "LoginHandler",
 		// "request_id": requestID,
-		"email": input.Email,
 	}
 
 	validationErrors := ValidateAndBind(c, &input)
 	if validationErrors != nil {
-		logFields["email"] = input.Email
+		logFields["identifier"] = input.Identifier
 		logger.Log.WithFields(logFields).Warnf("Validation failed for login: %v", validationErrors)
 		RespondWithValidationErrors(c, http.StatusBadRequest, validationErrors)
 		return
 	}
-	logFields["email"] = input.Email
+	logFields["identifier"] = input.Identifier
 
-	token, err := h.authService.Login(input)
+	tokens, err := h.authService.Login(input, clientInfo(c))
 	if err != nil {
 		switch err.Error() {
-		case "invalid email or password":
+		case "invalid credentials":
 			logger.Log.WithFields(logFields).Warn("Invalid login attempt.")
-			RespondWithError(c, NewAPIError(http.StatusUnauthorized, ErrCodeInvalidCredentials, "Invalid email or password."))
+			RespondWithError(c, NewAPIError(http.StatusUnauthorized, ErrCodeInvalidCredentials, "Invalid username, email or password."))
+		case "mfa required":
+			respondMFARequired(c, err)
+		case "account locked":
+			respondAccountLocked(c, err)
+		case "account disabled":
+			RespondWithError(c, NewAPIError(http.StatusForbidden, ErrCodeAccountDisabled, "This account has been disabled."))
+		case "email not verified":
+			RespondWithError(c, NewAPIError(http.StatusForbidden, ErrCodeEmailNotVerified, "Please confirm your email address before logging in."))
 		default:
 			logger.Log.WithFields(logFields).Errorf("Unhandled login error: %v", err)
 			RespondWithError(c, NewAPIError(http.StatusInternalServerError, ErrCodeInternalServer, "An error occurred during login. Please try again later."))
@@ -102,25 +115,280 @@ func (h *AuthHandler) LoginHandler(c *gin.Context) {
 		return
 	}
 	logger.Log.WithFields(logFields).Info("User logged in successfully")
-	c.JSON(http.StatusOK, gin.H{"token": token})
+	h.respondWithTokenPair(c, tokens, logFields)
+}
+
+// respondAccountLocked mengirim 429 dengan header Retry-After (detik) untuk login yang ditolak karena backoff atau lockout
+func respondAccountLocked(c *gin.Context, err error) {
+	retryAfter := 1
+	var lockedErr *service.AccountLockedError
+	if errors.As(err, &lockedErr) {
+		retryAfter = int(math.Ceil(lockedErr.RetryAfter.Seconds()))
+	}
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+	apiErr := NewAPIError(http.StatusTooManyRequests, ErrCodeAccountLocked, "Too many failed login attempts. Please try again later.")
+	apiErr.Details = gin.H{"retry_after": retryAfter}
+	RespondWithError(c, apiErr)
+}
+
+// respondRecentLoginRequired menolak operasi sensitif dari akun tanpa password yang login-nya sudah terlalu lama;
+// client diminta login ulang (mis. lewat social login) lalu mengulang permintaan
+func respondRecentLoginRequired(c *gin.Context) {
+	apiErr := NewAPIError(http.StatusForbidden, ErrCodeRecentLoginRequired,
+		"This account has no password. Please log in again, then retry within a few minutes, or set a password first.")
+	apiErr.Details = gin.H{"max_login_age": int(service.RecentLoginMaxAge.Seconds())}
+	RespondWithError(c, apiErr)
+}
+
+// respondMFARequired mengirim token challenge MFA sebagai pengganti access token
+func respondMFARequired(c *gin.Context, err error) {
+	var mfaErr *service.MFARequiredError
+	if !errors.As(err, &mfaErr) {
+		RespondWithError(c, NewAPIError(http.StatusInternalServerError, ErrCodeInternalServer, "An error occurred during login. Please try again later."))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"mfa_required": true,
+		"mfa_token":    mfaErr.Token,
+		"expires_in":   int64(mfaErr.ExpiresIn.Seconds()),
+		"methods":      mfaErr.Methods,
+	})
+}
+
+// MFAVerifyHandler menyelesaikan login tahap kedua dengan kode TOTP atau kode pemulihan (POST /auth/mfa/verify)
+func (h *AuthHandler) MFAVerifyHandler(c *gin.Context) {
+	var input model.MFAVerifyInput
+	logFields := logrus.Fields{
+		"handler": "MFAVerifyHandler",
+	}
+
+	if validationErrors := ValidateAndBind(c, &input); validationErrors != nil {
+		logger.Log.WithFields(logFields).Warnf("Validation failed for MFA verification: %v", validationErrors)
+		RespondWithValidationErrors(c, http.StatusBadRequest, validationErrors)
+		return
+	}
+
+	tokens, err := h.authService.VerifyMFA(input, clientInfo(c))
+	if err != nil {
+		switch err.Error() {
+		case "invalid or expired mfa token":
+			RespondWithError(c, NewAPIError(http.StatusUnauthorized, ErrCodeMFATokenInvalid, "The login attempt has expired. Please log in again."))
+		case "invalid mfa code":
+			RespondWithError(c, NewAPIError(http.StatusUnauthorized, ErrCodeMFACodeInvalid, "The verification code is invalid."))
+		case "account locked":
+			respondAccountLocked(c, err)
+		default:
+			logger.Log.WithFields(logFields).Errorf("Unhandled MFA verification error: %v", err)
+			RespondWithError(c, NewAPIError(http.StatusInternalServerError, ErrCodeInternalServer, "An error occurred during login. Please try again later."))
+		}
+		return
+	}
+	logger.Log.WithFields(logFields).Info("User completed MFA login")
+	h.respondWithTokenPair(c, tokens, logFields)
+}
+
+// PasskeyLoginOptionsHandler memulai login dengan passkey (POST /auth/passkey/options).
+// Options diteruskan ke navigator.credentials.get(), session_token dikirim balik ke PasskeyLoginHandler.
+func (h *AuthHandler) PasskeyLoginOptionsHandler(c *gin.Context) {
+	logFields := logrus.Fields{
+		"handler": "PasskeyLoginOptionsHandler",
+	}
+
+	ceremony, err := h.authService.BeginPasskeyLogin()
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Unhandled passkey login options error: %v", err)
+		RespondWithError(c, NewAPIError(http.StatusInternalServerError, ErrCodeInternalServer, "Failed to start passkey login. Please try again later."))
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, ceremony)
+}
+
+// PasskeyLoginHandler menyelesaikan login dengan passkey dan menerbitkan token seperti LoginHandler (POST /auth/passkey/login)
+func (h *AuthHandler) PasskeyLoginHandler(c *gin.Context) {
+	var input model.PasskeyLoginInput
+	logFields := logrus.Fields{
+		"handler": "PasskeyLoginHandler",
+	}
+
+	if validationErrors := ValidateAndBind(c, &input); validationErrors != nil {
+		logger.Log.WithFields(logFields).Warnf("Validation failed for passkey login: %v", validationErrors)
+		RespondWithValidationErrors(c, http.StatusBadRequest, validationErrors)
+		return
+	}
+
+	tokens, err := h.authService.LoginWithPasskey(input, clientInfo(c))
+	if err != nil {
+		switch err.Error() {
+		case "invalid passkey session":
+			RespondWithError(c, NewAPIError(http.StatusUnauthorized, ErrCodePasskeySessionInvalid, "The passkey request has expired. Please try again."))
+		case "passkey verification failed":
+			RespondWithError(c, NewAPIError(http.StatusUnauthorized, ErrCodePasskeyInvalid, "This passkey could not be verified."))
+		case "account locked":
+			respondAccountLocked(c, err)
+		case "account disabled":
+			RespondWithError(c, NewAPIError(http.StatusForbidden, ErrCodeAccountDisabled, "This account has been disabled."))
+		case "email not verified":
+			RespondWithError(c, NewAPIError(http.StatusForbidden, ErrCodeEmailNotVerified, "Please confirm your email address before logging in."))
+		default:
+			logger.Log.WithFields(logFields).Errorf("Unhandled passkey login error: %v", err)
+			RespondWithError(c, NewAPIError(http.StatusInternalServerError, ErrCodeInternalServer, "An error occurred during login. Please try again later."))
+		}
+		return
+	}
+	logger.Log.WithFields(logFields).Info("User logged in with passkey")
+	h.respondWithTokenPair(c, tokens, logFields)
+}
+
+// RefreshHandler menukar refresh token dengan pasangan token baru (rotasi)
+func (h *AuthHandler) RefreshHandler(c *gin.Context) {
+	var input model.RefreshInput
+	logFields := logrus.Fields{
+		"handler": "RefreshHandler",
+	}
+
+	// Di mode cookie, refresh token dibaca dari cookie HttpOnly jika body kosong.
+	// Endpoint ini tidak memerlukan token CSRF: penyerang tidak bisa membaca token hasil rotasi.
+	input.RefreshToken = h.cookieAuth.cookieValue(c, refreshTokenCookie)
+	if input.RefreshToken == "" || c.Request.ContentLength > 0 {
+		validationErrors := ValidateAndBind(c, &input)
+		if validationErrors != nil {
+			logger.Log.WithFields(logFields).Warnf("Validation failed for token refresh: %v", validationErrors)
+			RespondWithValidationErrors(c, http.StatusBadRequest, validationErrors)
+			return
+		}
+	}
+
+	tokens, err := h.authService.Refresh(input.RefreshToken, clientInfo(c))
+	if err != nil {
+		switch err.Error() {
+		case "invalid refresh token":
+			logger.Log.WithFields(logFields).Warn("Invalid refresh token presented.")
+			RespondWithError(c, NewAPIError(http.StatusUnauthorized, ErrCodeRefreshTokenInvalid, "Invalid or expired refresh token."))
+		case "refresh token reuse detected":
+			logger.Log.WithFields(logFields).Warn("Refresh token reuse detected.")
+			RespondWithError(c, NewAPIError(http.StatusUnauthorized, ErrCodeRefreshTokenReused, "Refresh token has already been used. Please log in again."))
+		default:
+			logger.Log.WithFields(logFields).Errorf("Unhandled token refresh error: %v", err)
+			RespondWithError(c, NewAPIError(http.StatusInternalServerError, ErrCodeInternalServer, "An error occurred while refreshing the token. Please try again later."))
+		}
+		return
+	}
+	logger.Log.WithFields(logFields).Info("Token refreshed successfully")
+	h.respondWithTokenPair(c, tokens, logFields)
+}
+
+// MeHandler mengembalikan identitas pemanggil langsung dari claims token, tanpa query ke database
+func (h *AuthHandler) MeHandler(c *gin.Context) {
+	principal, ok := GetPrincipal(c)
+	if !ok {
+		logger.Log.WithField("handler", "MeHandler").Error("Principal not found in context")
+		RespondWithError(c, NewAPIError(http.StatusInternalServerError, ErrCodeInternalServer, "Could not identify user."))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"sub":        principal.Subject,
+		"user_id":    principal.UserID,
+		"username":   principal.Username,
+		"email":      principal.Email,
+		"roles":      principal.Roles,
+		"expires_at": principal.ExpiresAt,
+	})
+}
+
+// LogoutHandler mencabut access token yang sedang dipakai dan (opsional) refresh token-nya
+func (h *AuthHandler) LogoutHandler(c *gin.Context) {
+	var input model.LogoutInput
+	logFields := logrus.Fields{
+		"handler": "LogoutHandler",
+	}
+
+	principal, errCtx := getUserPrincipalFromContext(c)
+	if errCtx != nil {
+		logger.Log.WithFields(logFields).Errorf("Error getting principal from context in LogoutHandler: %v", errCtx)
+		RespondWithError(c, NewAPIError(http.StatusInternalServerError, ErrCodeInternalServer, "Could not identify user."))
+		return
+	}
+	logFields["user_id"] = principal.UserID
+
+	// Personal access token tidak punya sesi; pencabutannya lewat DELETE /api/tokens/:id
+	if principal.AuthMethod == auth.AuthMethodPersonalAccessToken {
+		RespondWithError(c, NewAPIError(http.StatusBadRequest, ErrCodeBadRequest, "Personal access tokens cannot log out. Revoke the token instead."))
+		return
+	}
+
+	// Body bersifat opsional; hanya di-bind jika ada
+	if c.Request.ContentLength > 0 {
+		if validationErrors := ValidateAndBind(c, &input); validationErrors != nil {
+			logger.Log.WithFields(logFields).Warnf("Validation failed for logout: %v", validationErrors)
+			RespondWithValidationErrors(c, http.StatusBadRequest, validationErrors)
+			return
+		}
+	}
+
+	if err := h.authService.Logout(principal.UserID, principal.TokenID, principal.ExpiresAt, principal.SessionID, input.RefreshToken); err != nil {
+		logger.Log.WithFields(logFields).Errorf("Unhandled logout error: %v", err)
+		RespondWithError(c, NewAPIError(http.StatusInternalServerError, ErrCodeInternalServer, "Failed to logout. Please try again later."))
+		return
+	}
+	if h.cookieAuth.Enabled() {
+		h.cookieAuth.clearCookies(c)
+	}
+	logger.Log.WithFields(logFields).Info("User logged out successfully")
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
+}
+
+// clientInfo mengambil IP dan user agent pemanggil untuk dicatat pada session
+func clientInfo(c *gin.Context) model.ClientInfo {
+	return model.ClientInfo{IP: c.ClientIP(), UserAgent: c.Request.UserAgent()}
+}
+
+// respondWithTokenPair mengirim pasangan token ke client.
+// Field "token" dipertahankan agar client lama yang hanya membaca access token tetap berfungsi.
+// Di mode cookie, token hanya dikirim lewat cookie HttpOnly dan body berisi token CSRF.
+func (h *AuthHandler) respondWithTokenPair(c *gin.Context, tokens *model.TokenPair, logFields logrus.Fields) {
+	if h.cookieAuth.Enabled() {
+		csrfToken, err := h.cookieAuth.setTokenCookies(c, tokens)
+		if err != nil {
+			logger.Log.WithFields(logFields).Errorf("Error issuing CSRF token: %v", err)
+			RespondWithError(c, NewAPIError(http.StatusInternalServerError, ErrCodeInternalServer, "An error occurred during login. Please try again later."))
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"token_type": tokens.TokenType,
+			"expires_in": tokens.ExpiresIn,
+			"csrf_token": csrfToken,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"token":         tokens.AccessToken,
+		"access_token":  tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"token_type":    tokens.TokenType,
+		"expires_in":    tokens.ExpiresIn,
+	})
 }
 
 // ProfileHandler contoh handler untuk route yang dilindungi
 // PASTIKAN METHOD INI TERKAIT DENGAN POINTER KE AuthHandler (*AuthHandler)
 func (h *AuthHandler) ProfileHandler(c *gin.Context) {
 	// requestID := c.GetString("requestID")
-	userID, errCtx := getUserIDFromContext(c)
 	logFields := logrus.Fields{
 		"handler": "ProfileHandler",
 		// "request_id": requestID,
-		"user_id": userID,
 	}
 
+	principal, errCtx := getUserPrincipalFromContext(c)
 	if errCtx != nil {
-		logger.Log.WithFields(logFields).Errorf("Error getting userID from context in ProfileHandler: %v", errCtx)
+		logger.Log.WithFields(logFields).Errorf("Error getting principal from context in ProfileHandler: %v", errCtx)
 		RespondWithError(c, NewAPIError(http.StatusInternalServerError, ErrCodeInternalServer, "Could not identify user."))
 		return
 	}
+	userID := principal.UserID
+	logFields["user_id"] = userID
 
 	user, err := h.userService.GetUserProfile(userID)
 	if err != nil {
@@ -0,0 +1,96 @@
+// internal/api/magic_link_handler.go
+package api
+
+import (
+	"net/http"
+
+	"go-auth-example/internal/auth"
+	"go-auth-example/internal/logger"
+	"go-auth-example/internal/model"
+	"go-auth-example/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// magicLinkBindingCookie menyimpan nilai acak yang mengikat link login ke browser yang memintanya
+	magicLinkBindingCookie = "magic_link_binding"
+	// magicLinkCookiePath membatasi cookie binding hanya terkirim ke endpoint magic link
+	magicLinkCookiePath = "/auth/magic-link"
+)
+
+// MagicLinkRequestHandler mengirim link login ke email (POST /auth/magic-link).
+// Respons selalu sama agar endpoint ini tidak bisa dipakai untuk mengecek email terdaftar.
+func (h *AuthHandler) MagicLinkRequestHandler(c *gin.Context) {
+	var input model.MagicLinkRequestInput
+	logFields := logrus.Fields{
+		"handler": "MagicLinkRequestHandler",
+	}
+
+	if validationErrors := ValidateAndBind(c, &input); validationErrors != nil {
+		logger.Log.WithFields(logFields).Warnf("Validation failed for magic link request: %v", validationErrors)
+		RespondWithValidationErrors(c, http.StatusBadRequest, validationErrors)
+		return
+	}
+
+	// Cookie binding selalu dipasang (dan dipakai ulang jika sudah ada), juga untuk email yang tidak terdaftar,
+	// agar respons tidak membedakan keduanya dan link yang masih berlaku tetap cocok dengan cookie browser
+	binding, err := c.Cookie(magicLinkBindingCookie)
+	if err != nil || binding == "" {
+		if binding, err = auth.GenerateOpaqueToken(32); err != nil {
+			logger.Log.WithFields(logFields).Errorf("Error generating magic link binding: %v", err)
+			RespondWithError(c, NewAPIError(http.StatusInternalServerError, ErrCodeInternalServer, "Failed to process the request. Please try again later."))
+			return
+		}
+	}
+
+	if err := h.authService.RequestMagicLink(input.Email, binding); err != nil {
+		logger.Log.WithFields(logFields).Errorf("Unhandled magic link request error: %v", err)
+		RespondWithError(c, NewAPIError(http.StatusInternalServerError, ErrCodeInternalServer, "Failed to process the request. Please try again later."))
+		return
+	}
+	h.cookieAuth.setCookie(c, magicLinkBindingCookie, binding, magicLinkCookiePath, int(service.MagicLinkTTL.Seconds()), true)
+	c.JSON(http.StatusOK, gin.H{"message": "If the address is registered, a login link has been sent."})
+}
+
+// MagicLinkConsumeHandler menukar token dari link email dengan token login (POST /auth/magic-link/consume)
+func (h *AuthHandler) MagicLinkConsumeHandler(c *gin.Context) {
+	var input model.ConsumeMagicLinkInput
+	logFields := logrus.Fields{
+		"handler": "MagicLinkConsumeHandler",
+	}
+
+	if validationErrors := ValidateAndBind(c, &input); validationErrors != nil {
+		logger.Log.WithFields(logFields).Warnf("Validation failed for magic link login: %v", validationErrors)
+		RespondWithValidationErrors(c, http.StatusBadRequest, validationErrors)
+		return
+	}
+
+	binding, _ := c.Cookie(magicLinkBindingCookie)
+	tokens, err := h.authService.LoginWithMagicLink(input, binding, clientInfo(c))
+	if err != nil {
+		switch err.Error() {
+		case "invalid or expired magic link":
+			logger.Log.WithFields(logFields).Warn("Invalid magic link token presented.")
+			RespondWithError(c, NewAPIError(http.StatusBadRequest, ErrCodeMagicLinkInvalid, "The login link is invalid or has expired."))
+		case "magic link browser mismatch":
+			RespondWithError(c, NewAPIError(http.StatusBadRequest, ErrCodeMagicLinkBrowserMismatch, "Open the login link in the same browser where you requested it, or request a new link."))
+		case "mfa required":
+			respondMFARequired(c, err)
+		case "account locked":
+			respondAccountLocked(c, err)
+		case "account disabled":
+			RespondWithError(c, NewAPIError(http.StatusForbidden, ErrCodeAccountDisabled, "This account has been disabled."))
+		default:
+			logger.Log.WithFields(logFields).Errorf("Unhandled magic link login error: %v", err)
+			RespondWithError(c, NewAPIError(http.StatusInternalServerError, ErrCodeInternalServer, "An error occurred during login. Please try again later."))
+		}
+		return
+	}
+
+	// Binding hanya berlaku untuk satu login
+	h.cookieAuth.setCookie(c, magicLinkBindingCookie, "", magicLinkCookiePath, -1, true)
+	logger.Log.WithFields(logFields).Info("User logged in with magic link")
+	h.respondWithTokenPair(c, tokens, logFields)
+}
@@ -0,0 +1,197 @@
+// internal/api/mfa_handler.go
+package api
+
+import (
+	"net/http"
+
+	"go-auth-example/internal/logger"
+	"go-auth-example/internal/model"
+	"go-auth-example/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// MFAHandler melayani pengaturan MFA milik user yang sedang login
+type MFAHandler struct {
+	mfaService service.MFAService
+}
+
+// NewMFAHandler constructor untuk MFAHandler
+func NewMFAHandler(mfaService service.MFAService) *MFAHandler {
+	return &MFAHandler{mfaService: mfaService}
+}
+
+// StatusHandler menampilkan status MFA user (GET /api/mfa)
+func (h *MFAHandler) StatusHandler(c *gin.Context) {
+	logFields := logrus.Fields{
+		"handler": "MFAStatusHandler",
+	}
+
+	principal, ok := requireFirstPartyUser(c, logFields)
+	if !ok {
+		return
+	}
+	logFields["user_id"] = principal.UserID
+
+	status, err := h.mfaService.Status(principal.UserID)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Unhandled MFA status error: %v", err)
+		RespondWithError(c, NewAPIError(http.StatusInternalServerError, ErrCodeInternalServer, "Failed to load MFA status. Please try again later."))
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+// EnrollTOTPHandler memulai pendaftaran aplikasi authenticator (POST /api/mfa/totp)
+func (h *MFAHandler) EnrollTOTPHandler(c *gin.Context) {
+	logFields := logrus.Fields{
+		"handler": "EnrollTOTPHandler",
+	}
+
+	principal, ok := requireFirstPartyUser(c, logFields)
+	if !ok {
+		return
+	}
+	logFields["user_id"] = principal.UserID
+
+	enrollment, err := h.mfaService.BeginTOTPEnrollment(principal.UserID)
+	if err != nil {
+		switch err.Error() {
+		case "mfa already enabled":
+			RespondWithError(c, NewAPIError(http.StatusConflict, ErrCodeMFAAlreadyEnabled, "Two-factor authentication is already enabled. Disable it first to use a new authenticator."))
+		case "user not found":
+			RespondWithError(c, NewAPIError(http.StatusNotFound, ErrCodeUserNotFound, "User profile not found."))
+		default:
+			logger.Log.WithFields(logFields).Errorf("Unhandled TOTP enrollment error: %v", err)
+			RespondWithError(c, NewAPIError(http.StatusInternalServerError, ErrCodeInternalServer, "Failed to start two-factor setup. Please try again later."))
+		}
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, enrollment)
+}
+
+// ConfirmTOTPHandler mengaktifkan MFA dengan kode pertama dari authenticator (POST /api/mfa/totp/confirm).
+// Kode pemulihan hanya dikirim sekali di respons ini.
+func (h *MFAHandler) ConfirmTOTPHandler(c *gin.Context) {
+	var input model.ConfirmTOTPInput
+	logFields := logrus.Fields{
+		"handler": "ConfirmTOTPHandler",
+	}
+
+	principal, ok := requireFirstPartyUser(c, logFields)
+	if !ok {
+		return
+	}
+	logFields["user_id"] = principal.UserID
+
+	if validationErrors := ValidateAndBind(c, &input); validationErrors != nil {
+		logger.Log.WithFields(logFields).Warnf("Validation failed for TOTP confirmation: %v", validationErrors)
+		RespondWithValidationErrors(c, http.StatusBadRequest, validationErrors)
+		return
+	}
+
+	codes, err := h.mfaService.ConfirmTOTPEnrollment(principal.UserID, input.Code)
+	if err != nil {
+		switch err.Error() {
+		case "invalid mfa code":
+			RespondWithError(c, NewAPIError(http.StatusBadRequest, ErrCodeMFACodeInvalid, "The verification code is invalid."))
+		case "mfa enrollment not started":
+			RespondWithError(c, NewAPIError(http.StatusBadRequest, ErrCodeMFANotEnabled, "Start two-factor setup first."))
+		case "mfa already enabled":
+			RespondWithError(c, NewAPIError(http.StatusConflict, ErrCodeMFAAlreadyEnabled, "Two-factor authentication is already enabled."))
+		default:
+			logger.Log.WithFields(logFields).Errorf("Unhandled TOTP confirmation error: %v", err)
+			RespondWithError(c, NewAPIError(http.StatusInternalServerError, ErrCodeInternalServer, "Failed to enable two-factor authentication. Please try again later."))
+		}
+		return
+	}
+
+	logger.Log.WithFields(logFields).Info("MFA enabled")
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "Two-factor authentication enabled. Store these recovery codes somewhere safe.",
+		"recovery_codes": codes,
+	})
+}
+
+// RegenerateRecoveryCodesHandler mengganti semua kode pemulihan (POST /api/mfa/recovery-codes)
+func (h *MFAHandler) RegenerateRecoveryCodesHandler(c *gin.Context) {
+	var input model.RegenerateRecoveryCodesInput
+	logFields := logrus.Fields{
+		"handler": "RegenerateRecoveryCodesHandler",
+	}
+
+	principal, ok := requireFirstPartyUser(c, logFields)
+	if !ok {
+		return
+	}
+	logFields["user_id"] = principal.UserID
+
+	if validationErrors := ValidateAndBind(c, &input); validationErrors != nil {
+		logger.Log.WithFields(logFields).Warnf("Validation failed for recovery code regeneration: %v", validationErrors)
+		RespondWithValidationErrors(c, http.StatusBadRequest, validationErrors)
+		return
+	}
+
+	codes, err := h.mfaService.RegenerateRecoveryCodes(principal.UserID, input.Password, principal.AuthTime)
+	if err != nil {
+		h.respondWithMFAChangeError(c, err, logFields)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "New recovery codes generated. Previous codes no longer work.",
+		"recovery_codes": codes,
+	})
+}
+
+// DisableHandler menonaktifkan MFA setelah password dan kode MFA dikonfirmasi (DELETE /api/mfa)
+func (h *MFAHandler) DisableHandler(c *gin.Context) {
+	var input model.DisableMFAInput
+	logFields := logrus.Fields{
+		"handler": "DisableMFAHandler",
+	}
+
+	principal, ok := requireFirstPartyUser(c, logFields)
+	if !ok {
+		return
+	}
+	logFields["user_id"] = principal.UserID
+
+	if validationErrors := ValidateAndBind(c, &input); validationErrors != nil {
+		logger.Log.WithFields(logFields).Warnf("Validation failed for MFA disable: %v", validationErrors)
+		RespondWithValidationErrors(c, http.StatusBadRequest, validationErrors)
+		return
+	}
+
+	if err := h.mfaService.Disable(principal.UserID, input.Password, input.Code, principal.AuthTime); err != nil {
+		h.respondWithMFAChangeError(c, err, logFields)
+		return
+	}
+
+	logger.Log.WithFields(logFields).Info("MFA disabled")
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+}
+
+// respondWithMFAChangeError memetakan error operasi MFA yang membutuhkan konfirmasi password
+func (h *MFAHandler) respondWithMFAChangeError(c *gin.Context, err error, logFields logrus.Fields) {
+	switch err.Error() {
+	case "invalid password":
+		RespondWithError(c, NewAPIError(http.StatusBadRequest, ErrCodeInvalidCredentials, "The password is incorrect."))
+	case "recent login required":
+		respondRecentLoginRequired(c)
+	case "invalid mfa code":
+		RespondWithError(c, NewAPIError(http.StatusBadRequest, ErrCodeMFACodeInvalid, "The verification code is invalid."))
+	case "mfa not enabled":
+		RespondWithError(c, NewAPIError(http.StatusBadRequest, ErrCodeMFANotEnabled, "Two-factor authentication is not enabled."))
+	case "user not found":
+		RespondWithError(c, NewAPIError(http.StatusNotFound, ErrCodeUserNotFound, "User profile not found."))
+	default:
+		logger.Log.WithFields(logFields).Errorf("Unhandled MFA error: %v", err)
+		RespondWithError(c, NewAPIError(http.StatusInternalServerError, ErrCodeInternalServer, "Failed to update two-factor authentication. Please try again later."))
+	}
+}
@@ -8,81 +8,314 @@ import (
 	"strings"
 
 	"go-auth-example/internal/auth" // <- Import auth package
+	"go-auth-example/internal/logger"
+	"go-auth-example/internal/model"
+	"go-auth-example/internal/service"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5" // <- Pindahkan import jwt ke sini jika getUserIDFromContext membutuhkannya
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/sirupsen/logrus"
 )
 
-// AuthMiddleware (bagian error handlingnya)
-func AuthMiddleware() gin.HandlerFunc {
+// principalContextKey adalah key context Gin tempat AuthMiddleware menyimpan *auth.Principal
+const principalContextKey = "principal"
+
+// organizationContextKey adalah key context Gin tempat OrganizationMiddleware menyimpan *model.Membership
+const organizationContextKey = "organization"
+
+// OrganizationHeader adalah header untuk memilih organisasi per request, menggantikan klaim org_id
+const OrganizationHeader = "X-Organization-ID"
+
+// AuthMiddleware memvalidasi JWT atau personal access token, menolak token yang sudah dicabut (logout),
+// lalu menyimpan principal pemanggil di context Gin beserta permission dari role-nya.
+// Jika mode cookie aktif, access token juga diterima dari cookie ketika header Authorization tidak ada.
+func AuthMiddleware(tokenVerifier auth.TokenVerifier, revocationService service.TokenRevocationService,
+	patService service.PersonalAccessTokenService, sessionService service.SessionService, roleService service.RoleService,
+	cookieAuth *CookieAuth) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		var tokenString string
 		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			// Gunakan helper error baru
-			RespondWithError(c, NewAPIError(http.StatusUnauthorized, ErrCodeMissingAuthHeader, "Authorization header is required."))
-			return
-		}
+		if authHeader != "" {
+			parts := strings.Split(authHeader, " ")
+			if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
+				// Gunakan helper error baru
+				RespondWithError(c, NewAPIError(http.StatusUnauthorized, ErrCodeInvalidAuthHeader, "Authorization header format must be Bearer {token}."))
+				return
+			}
+			tokenString = parts[1]
 
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
+			if auth.IsPersonalAccessToken(tokenString) {
+				authenticatePersonalAccessToken(c, patService, tokenString)
+				return
+			}
+		} else if tokenString = cookieAuth.cookieValue(c, accessTokenCookie); tokenString != "" {
+			// Request dengan cookie wajib lolos CSRFMiddleware untuk method yang mengubah state
+			c.Set(authViaCookieKey, true)
+		} else {
 			// Gunakan helper error baru
-			RespondWithError(c, NewAPIError(http.StatusUnauthorized, ErrCodeInvalidAuthHeader, "Authorization header format must be Bearer {token}."))
+			RespondWithError(c, NewAPIError(http.StatusUnauthorized, ErrCodeMissingAuthHeader, "Authorization header is required."))
 			return
 		}
 
-		tokenString := parts[1]
-		token, err := auth.ValidateToken(tokenString) // Dari internal/auth
+		claims, err := tokenVerifier.Verify(tokenString) // Dari internal/auth
 		if err != nil {
-			// Error dari ValidateToken mungkin perlu dipetakan ke kode error kita
-			// Contoh sederhana:
+			// Petakan error verifikasi ke kode error kita
 			errMsg := "Invalid or expired token."
 			errCode := ErrCodeTokenInvalid
-			if strings.Contains(err.Error(), "expired") { // Ini cara deteksi sederhana, bisa lebih baik
+			if errors.Is(err, jwt.ErrTokenExpired) {
 				errCode = ErrCodeTokenExpired
 			}
 			RespondWithError(c, NewAPIError(http.StatusUnauthorized, errCode, errMsg))
 			return
 		}
 
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok || !token.Valid { // Sebenarnya !token.Valid sudah dicek oleh jwt.Parse
+		principal, err := claims.Principal()
+		if err != nil {
 			RespondWithError(c, NewAPIError(http.StatusUnauthorized, ErrCodeTokenInvalid, "Invalid token claims."))
 			return
 		}
 
-		userIDFloat, ok := claims["sub"].(float64)
+		// Token tanpa jti tidak bisa dicabut, jadi tidak diterima
+		if principal.TokenID == "" {
+			RespondWithError(c, NewAPIError(http.StatusUnauthorized, ErrCodeTokenInvalid, "Invalid token ID in token."))
+			return
+		}
+
+		revoked, err := revocationService.IsRevoked(principal.TokenID)
+		if err != nil {
+			logger.Log.WithField("subject", principal.Subject).Errorf("Error checking token revocation: %v", err)
+			RespondWithError(c, NewAPIError(http.StatusInternalServerError, ErrCodeInternalServer, "Could not verify token."))
+			return
+		}
+		if revoked {
+			RespondWithError(c, NewAPIError(http.StatusUnauthorized, ErrCodeTokenRevoked, "Token has been revoked."))
+			return
+		}
+
+		// Token yang terikat ke session langsung ditolak begitu session-nya dicabut
+		if principal.SessionID != "" {
+			if err := sessionService.Validate(principal.SessionID, clientInfo(c)); err != nil {
+				if err.Error() == "session revoked" {
+					RespondWithError(c, NewAPIError(http.StatusUnauthorized, ErrCodeSessionRevoked, "Session has been signed out."))
+					return
+				}
+				logger.Log.WithField("subject", principal.Subject).Errorf("Error checking session: %v", err)
+				RespondWithError(c, NewAPIError(http.StatusInternalServerError, ErrCodeInternalServer, "Could not verify token."))
+				return
+			}
+		} else if principal.IsUser() {
+			// Token tanpa session (token OAuth, token lama) dicabut lewat batas waktu terbit per user,
+			// mis. setelah reset password atau akun dinonaktifkan
+			revoked, err := revocationService.IsRevokedForUser(principal.UserID, principal.IssuedAt)
+			if err != nil {
+				logger.Log.WithField("subject", principal.Subject).Errorf("Error checking user token revocation: %v", err)
+				RespondWithError(c, NewAPIError(http.StatusInternalServerError, ErrCodeInternalServer, "Could not verify token."))
+				return
+			}
+			if revoked {
+				RespondWithError(c, NewAPIError(http.StatusUnauthorized, ErrCodeTokenRevoked, "Token has been revoked."))
+				return
+			}
+		}
+
+		// Role hanya berlaku untuk token first-party; token OAuth dan personal access token dibatasi scope-nya
+		if !principal.IsFirstParty() {
+			principal.Roles = nil
+		} else if principal.IsUser() {
+			// Klaim roles bisa basi sampai token kedaluwarsa, jadi role dibaca ulang dari database
+			// agar user yang rolenya dicabut langsung kehilangan permission-nya
+			roles, err := roleService.UserRoles(principal.UserID)
+			if err != nil {
+				logger.Log.WithField("subject", principal.Subject).Errorf("Error loading user roles: %v", err)
+				RespondWithError(c, NewAPIError(http.StatusInternalServerError, ErrCodeInternalServer, "Could not verify token."))
+				return
+			}
+			principal.Roles = roles
+		}
+		if len(principal.Roles) > 0 {
+			permissions, err := roleService.Permissions(principal.Roles)
+			if err != nil {
+				logger.Log.WithField("subject", principal.Subject).Errorf("Error resolving role permissions: %v", err)
+				RespondWithError(c, NewAPIError(http.StatusInternalServerError, ErrCodeInternalServer, "Could not verify token."))
+				return
+			}
+			principal.Permissions = permissions
+		}
+
+		c.Set(principalContextKey, principal)
+		c.Next()
+	}
+}
+
+// authenticatePersonalAccessToken memverifikasi personal access token lalu melanjutkan request
+func authenticatePersonalAccessToken(c *gin.Context, patService service.PersonalAccessTokenService, tokenString string) {
+	principal, err := patService.Authenticate(tokenString, c.ClientIP())
+	if err != nil {
+		switch err.Error() {
+		case "invalid personal access token":
+			RespondWithError(c, NewAPIError(http.StatusUnauthorized, ErrCodeTokenInvalid, "Invalid or revoked personal access token."))
+		case "personal access token expired":
+			RespondWithError(c, NewAPIError(http.StatusUnauthorized, ErrCodeTokenExpired, "Personal access token has expired."))
+		default:
+			RespondWithError(c, NewAPIError(http.StatusInternalServerError, ErrCodeInternalServer, "Could not verify token."))
+		}
+		return
+	}
+
+	c.Set(principalContextKey, principal)
+	c.Next()
+}
+
+// RequireScope menolak principal yang tidak memiliki scope tertentu.
+// Token first-party selalu lolos; token OAuth dan personal access token harus memiliki scope tersebut.
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := GetPrincipal(c)
 		if !ok {
-			RespondWithError(c, NewAPIError(http.StatusUnauthorized, ErrCodeTokenInvalid, "Invalid user ID format in token."))
+			RespondWithError(c, NewAPIError(http.StatusUnauthorized, ErrCodeUnauthorized, "Authentication required."))
 			return
 		}
-		userID := int(userIDFloat)
+		if !principal.HasScope(scope) {
+			c.Header("WWW-Authenticate", `Bearer error="insufficient_scope", scope="`+scope+`"`)
+			RespondWithError(c, NewAPIError(http.StatusForbidden, ErrCodeInsufficientScope, "The token does not have the required scope: "+scope+"."))
+			return
+		}
+		c.Next()
+	}
+}
 
-		c.Set("userID", userID)
+// RequireRole menolak principal yang tidak memiliki role tertentu
+func RequireRole(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := GetPrincipal(c)
+		if !ok {
+			RespondWithError(c, NewAPIError(http.StatusUnauthorized, ErrCodeUnauthorized, "Authentication required."))
+			return
+		}
+		if !principal.HasRole(role) {
+			logger.Log.WithFields(logrus.Fields{"subject": principal.Subject, "role": role}).Warn("Request rejected: missing role")
+			RespondWithError(c, NewAPIError(http.StatusForbidden, ErrCodeForbidden, "You do not have permission to access this resource."))
+			return
+		}
 		c.Next()
 	}
 }
 
-// getUserIDFromContext helper untuk mendapatkan User ID dari context Gin
-func getUserIDFromContext(c *gin.Context) (int, error) {
-	idInterface, exists := c.Get("userID")
-	if !exists {
-		return 0, errors.New("user ID not found in context")
+// RequirePermission menolak principal yang role-nya tidak memberikan permission tertentu
+func RequirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := GetPrincipal(c)
+		if !ok {
+			RespondWithError(c, NewAPIError(http.StatusUnauthorized, ErrCodeUnauthorized, "Authentication required."))
+			return
+		}
+		if !principal.HasPermission(permission) {
+			logger.Log.WithFields(logrus.Fields{"subject": principal.Subject, "permission": permission}).Warn("Request rejected: missing permission")
+			RespondWithError(c, NewAPIError(http.StatusForbidden, ErrCodeForbidden, "You do not have permission to access this resource."))
+			return
+		}
+		c.Next()
 	}
+}
 
-	userID, ok := idInterface.(int)
-	if !ok {
-		// Coba konversi dari string jika perlu (tergantung bagaimana disimpan)
-		idStr, okStr := idInterface.(string)
-		if okStr {
-			var errConv error
-			userID, errConv = strconv.Atoi(idStr)
-			if errConv != nil {
-				return 0, errors.New("invalid user ID type in context (string conversion failed)")
+// OrganizationMiddleware menentukan organisasi yang sedang dipakai request: header X-Organization-ID jika dikirim,
+// selain itu klaim org_id dari access token. Keanggotaan user selalu diperiksa ulang ke database,
+// lalu disimpan di context Gin untuk RequireOrgRole dan GetOrganization.
+// Klaim org_id yang sudah tidak berlaku (user dikeluarkan) diabaikan; header untuk organisasi lain ditolak.
+func OrganizationMiddleware(organizationService service.OrganizationService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := GetPrincipal(c)
+		if !ok || !principal.IsUser() {
+			c.Next()
+			return
+		}
+
+		orgID := principal.OrganizationID
+		explicit := false
+		if header := c.GetHeader(OrganizationHeader); header != "" {
+			id, err := strconv.Atoi(header)
+			if err != nil || id <= 0 {
+				RespondWithError(c, NewAPIError(http.StatusBadRequest, ErrCodeBadRequest, "Invalid "+OrganizationHeader+" header."))
+				return
+			}
+			orgID, explicit = id, true
+		}
+		if orgID == 0 {
+			c.Next()
+			return
+		}
+
+		membership, err := organizationService.Membership(orgID, principal.UserID)
+		if err != nil {
+			logger.Log.WithField("subject", principal.Subject).Errorf("Error resolving organization: %v", err)
+			RespondWithError(c, NewAPIError(http.StatusInternalServerError, ErrCodeInternalServer, "Could not verify organization membership."))
+			return
+		}
+		if membership == nil {
+			if explicit {
+				logger.Log.WithFields(logrus.Fields{"subject": principal.Subject, "organization_id": orgID}).Warn("Request rejected: not an organization member")
+				RespondWithError(c, NewAPIError(http.StatusForbidden, ErrCodeOrgAccessDenied, "You are not a member of this organization."))
+				return
 			}
-			return userID, nil
+			c.Next()
+			return
 		}
-		return 0, errors.New("invalid user ID type in context")
+
+		c.Set(organizationContextKey, membership)
+		c.Next()
+	}
+}
+
+// RequireOrgRole menolak request tanpa organisasi aktif atau yang role-nya di organisasi tersebut di bawah minimum
+func RequireOrgRole(minimum string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		membership, ok := GetOrganization(c)
+		if !ok {
+			RespondWithError(c, NewAPIError(http.StatusForbidden, ErrCodeOrgRequired,
+				"No active organization. Switch to an organization or send the "+OrganizationHeader+" header."))
+			return
+		}
+		if !model.OrgRoleAtLeast(membership.Role, minimum) {
+			logger.Log.WithFields(logrus.Fields{
+				"user_id":         membership.UserID,
+				"organization_id": membership.OrganizationID,
+				"role":            minimum,
+			}).Warn("Request rejected: insufficient organization role")
+			RespondWithError(c, NewAPIError(http.StatusForbidden, ErrCodeForbidden, "Your role in this organization does not allow this action."))
+			return
+		}
+		c.Next()
 	}
+}
+
+// GetOrganization mengambil keanggotaan organisasi aktif yang disimpan OrganizationMiddleware dari context Gin
+func GetOrganization(c *gin.Context) (*model.Membership, bool) {
+	value, exists := c.Get(organizationContextKey)
+	if !exists {
+		return nil, false
+	}
+	membership, ok := value.(*model.Membership)
+	return membership, ok
+}
+
+// GetPrincipal mengambil principal yang disimpan AuthMiddleware dari context Gin
+func GetPrincipal(c *gin.Context) (*auth.Principal, bool) {
+	value, exists := c.Get(principalContextKey)
+	if !exists {
+		return nil, false
+	}
+	principal, ok := value.(*auth.Principal)
+	return principal, ok
+}
 
-	return userID, nil
+// getUserPrincipalFromContext helper untuk handler yang membutuhkan principal berupa user aplikasi
+func getUserPrincipalFromContext(c *gin.Context) (*auth.Principal, error) {
+	principal, ok := GetPrincipal(c)
+	if !ok {
+		return nil, errors.New("principal not found in context")
+	}
+	if !principal.IsUser() {
+		return nil, errors.New("principal is not a user")
+	}
+	return principal, nil
 }
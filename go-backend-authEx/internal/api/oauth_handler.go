@@ -0,0 +1,243 @@
+// internal/api/oauth_handler.go
+package api
+
+import (
+	"net/http"
+	"net/url"
+
+	"go-auth-example/internal/auth"
+	"go-auth-example/internal/logger"
+	"go-auth-example/internal/model"
+	"go-auth-example/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// OAuthHandler melayani endpoint authorization server OAuth 2.1
+type OAuthHandler struct {
+	oauthService service.OAuthService
+	consentURL   string // Halaman consent di frontend; query authorization request diteruskan apa adanya
+}
+
+// NewOAuthHandler constructor untuk OAuthHandler
+func NewOAuthHandler(oauthService service.OAuthService, consentURL string) *OAuthHandler {
+	return &OAuthHandler{
+		oauthService: oauthService,
+		consentURL:   consentURL,
+	}
+}
+
+// AuthorizeHandler adalah authorization endpoint publik (GET /oauth/authorize).
+// Request divalidasi lalu browser diarahkan ke halaman consent, tempat user login dan menyetujui akses.
+func (h *OAuthHandler) AuthorizeHandler(c *gin.Context) {
+	var req model.AuthorizeRequest
+	logFields := logrus.Fields{
+		"handler": "OAuthAuthorizeHandler",
+	}
+
+	_ = c.ShouldBindQuery(&req)
+	logFields["client_id"] = req.ClientID
+
+	authCtx, err := h.oauthService.ValidateAuthorizeRequest(req)
+	if err != nil {
+		h.respondWithAuthorizeError(c, authCtx, err, logFields)
+		return
+	}
+
+	consentURL, err := url.Parse(h.consentURL)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Invalid OAuth consent URL %q: %v", h.consentURL, err)
+		RespondWithError(c, NewAPIError(http.StatusInternalServerError, ErrCodeInternalServer, "OAuth consent page is not configured."))
+		return
+	}
+	consentURL.RawQuery = c.Request.URL.RawQuery
+	c.Redirect(http.StatusFound, consentURL.String())
+}
+
+// ConsentDetailsHandler mengembalikan detail authorization request untuk ditampilkan di halaman consent (GET /api/oauth/authorize)
+func (h *OAuthHandler) ConsentDetailsHandler(c *gin.Context) {
+	var req model.AuthorizeRequest
+	logFields := logrus.Fields{
+		"handler": "OAuthConsentDetailsHandler",
+	}
+
+	if _, ok := requireFirstPartyUser(c, logFields); !ok {
+		return
+	}
+
+	_ = c.ShouldBindQuery(&req)
+	logFields["client_id"] = req.ClientID
+
+	authCtx, err := h.oauthService.ValidateAuthorizeRequest(req)
+	if err != nil {
+		if authCtx == nil {
+			h.respondWithAuthorizeError(c, nil, err, logFields)
+			return
+		}
+		// Error yang bisa di-redirect dikembalikan ke frontend agar browser diarahkan kembali ke client
+		oauthErr, _ := err.(*service.OAuthError)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":             oauthErr.Code,
+			"error_description": oauthErr.Description,
+			"redirect_to":       service.ErrorRedirectURL(authCtx, oauthErr),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"client": gin.H{
+			"client_id": authCtx.Client.ClientID,
+			"name":      authCtx.Client.Name,
+		},
+		"redirect_uri": authCtx.RedirectURI,
+		"scopes":       authCtx.Scopes,
+	})
+}
+
+// ConsentHandler menyimpan keputusan user (POST /api/oauth/authorize) dan mengembalikan URL redirect ke client
+func (h *OAuthHandler) ConsentHandler(c *gin.Context) {
+	var input model.ConsentInput
+	logFields := logrus.Fields{
+		"handler": "OAuthConsentHandler",
+	}
+
+	principal, ok := requireFirstPartyUser(c, logFields)
+	if !ok {
+		return
+	}
+	logFields["user_id"] = principal.UserID
+
+	validationErrors := ValidateAndBind(c, &input)
+	if validationErrors != nil {
+		logger.Log.WithFields(logFields).Warnf("Validation failed for OAuth consent: %v", validationErrors)
+		RespondWithValidationErrors(c, http.StatusBadRequest, validationErrors)
+		return
+	}
+	logFields["client_id"] = input.ClientID
+
+	var redirectTo string
+	var err error
+	if input.Approve {
+		redirectTo, err = h.oauthService.Approve(principal.UserID, principal.AuthTime, input.AuthorizeRequest)
+	} else {
+		redirectTo, err = h.oauthService.Deny(input.AuthorizeRequest)
+	}
+	if err != nil {
+		h.respondWithAuthorizeError(c, nil, err, logFields)
+		return
+	}
+
+	logFields["approved"] = input.Approve
+	logger.Log.WithFields(logFields).Info("OAuth consent recorded.")
+	c.JSON(http.StatusOK, gin.H{"redirect_to": redirectTo})
+}
+
+// TokenHandler adalah token endpoint (POST /oauth/token, application/x-www-form-urlencoded)
+func (h *OAuthHandler) TokenHandler(c *gin.Context) {
+	var req model.OAuthTokenRequest
+	logFields := logrus.Fields{
+		"handler": "OAuthTokenHandler",
+	}
+
+	// Response token endpoint tidak boleh di-cache (RFC 6749 section 5.1)
+	c.Header("Cache-Control", "no-store")
+	c.Header("Pragma", "no-cache")
+
+	if err := c.ShouldBind(&req); err != nil {
+		respondWithOAuthError(c, &service.OAuthError{Code: service.OAuthErrInvalidRequest, Description: "malformed token request"})
+		return
+	}
+
+	// Client boleh autentikasi via HTTP Basic atau body, tetapi tidak keduanya sekaligus
+	clientID, clientSecret, hasBasic := c.Request.BasicAuth()
+	if hasBasic {
+		if (req.ClientID != "" && req.ClientID != clientID) || req.ClientSecret != "" {
+			respondWithOAuthError(c, &service.OAuthError{Code: service.OAuthErrInvalidRequest, Description: "multiple client authentication methods used"})
+			return
+		}
+		// Kredensial di header Basic di-encode dengan form-urlencoding (RFC 6749 section 2.3.1)
+		if unescaped, err := url.QueryUnescape(clientID); err == nil {
+			clientID = unescaped
+		}
+		if unescaped, err := url.QueryUnescape(clientSecret); err == nil {
+			clientSecret = unescaped
+		}
+	} else {
+		clientID, clientSecret = req.ClientID, req.ClientSecret
+	}
+	logFields["client_id"] = clientID
+	logFields["grant_type"] = req.GrantType
+
+	response, err := h.oauthService.Token(req, clientID, clientSecret)
+	if err != nil {
+		oauthErr, ok := err.(*service.OAuthError)
+		if !ok {
+			logger.Log.WithFields(logFields).Errorf("Unhandled OAuth token error: %v", err)
+			oauthErr = &service.OAuthError{Code: service.OAuthErrServerError, Description: "an internal error occurred"}
+		}
+		logger.Log.WithFields(logFields).Warnf("OAuth token request rejected: %v", oauthErr)
+		if oauthErr.Code == service.OAuthErrInvalidClient && hasBasic {
+			c.Header("WWW-Authenticate", `Basic realm="oauth"`)
+		}
+		respondWithOAuthError(c, oauthErr)
+		return
+	}
+
+	logger.Log.WithFields(logFields).Info("OAuth token issued.")
+	c.JSON(http.StatusOK, response)
+}
+
+// respondWithAuthorizeError menangani error authorization request.
+// Jika client dan redirect URI valid, error dikirim ke client lewat redirect; jika tidak, ditampilkan langsung.
+func (h *OAuthHandler) respondWithAuthorizeError(c *gin.Context, authCtx *service.AuthorizationContext, err error, logFields logrus.Fields) {
+	oauthErr, ok := err.(*service.OAuthError)
+	if !ok {
+		logger.Log.WithFields(logFields).Errorf("Unhandled OAuth authorize error: %v", err)
+		RespondWithError(c, NewAPIError(http.StatusInternalServerError, ErrCodeInternalServer, "An error occurred. Please try again later."))
+		return
+	}
+	logger.Log.WithFields(logFields).Warnf("Invalid OAuth authorization request: %v", oauthErr)
+
+	if authCtx != nil {
+		c.Redirect(http.StatusFound, service.ErrorRedirectURL(authCtx, oauthErr))
+		return
+	}
+	if oauthErr.Code == service.OAuthErrServerError {
+		RespondWithError(c, NewAPIError(http.StatusInternalServerError, ErrCodeInternalServer, "An error occurred. Please try again later."))
+		return
+	}
+	RespondWithError(c, NewAPIError(http.StatusBadRequest, ErrCodeOAuthInvalidRequest, oauthErr.Description))
+}
+
+// respondWithOAuthError mengirim error dalam format RFC 6749 section 5.2
+func respondWithOAuthError(c *gin.Context, oauthErr *service.OAuthError) {
+	status := http.StatusBadRequest
+	switch oauthErr.Code {
+	case service.OAuthErrInvalidClient:
+		status = http.StatusUnauthorized
+	case service.OAuthErrServerError:
+		status = http.StatusInternalServerError
+	}
+	c.AbortWithStatusJSON(status, gin.H{
+		"error":             oauthErr.Code,
+		"error_description": oauthErr.Description,
+	})
+}
+
+// requireFirstPartyUser memastikan pemanggil adalah user yang login langsung ke aplikasi ini,
+// bukan token yang diterbitkan untuk client OAuth pihak ketiga
+func requireFirstPartyUser(c *gin.Context, logFields logrus.Fields) (*auth.Principal, bool) {
+	principal, err := getUserPrincipalFromContext(c)
+	if err != nil {
+		logger.Log.WithFields(logFields).Warnf("Rejected non-user principal: %v", err)
+		RespondWithError(c, NewAPIError(http.StatusForbidden, ErrCodeUnauthorized, "This endpoint requires a user session."))
+		return nil, false
+	}
+	if !principal.IsFirstParty() {
+		logger.Log.WithFields(logFields).Warnf("Rejected token issued to OAuth client %q.", principal.ClientID)
+		RespondWithError(c, NewAPIError(http.StatusForbidden, ErrCodeUnauthorized, "Tokens issued to OAuth clients cannot be used here."))
+		return nil, false
+	}
+	return principal, true
+}
@@ -0,0 +1,540 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"go-auth-example/internal/auth"
+	"go-auth-example/internal/logger"
+	"go-auth-example/internal/model"
+	"go-auth-example/internal/repository/memrepo"
+	"go-auth-example/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testIssuer      = "http://auth.test"
+	testConsentURL  = "http://frontend.test/oauth/consent"
+	testRedirectURI = "https://client.test/callback"
+	testVerifier    = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+)
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	logger.Log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+// oauthTestEnv adalah authorization server lengkap di dalam proses: handler asli, service asli dan repository in-memory
+type oauthTestEnv struct {
+	t             *testing.T
+	server        *httptest.Server
+	jwt           *auth.JWTService
+	signingKey    *auth.SigningKey
+	clients       service.OAuthClientService
+	refreshTokens *memrepo.RefreshTokens
+	user          model.User
+	userToken     string // Access token first-party milik user, dipakai halaman consent
+}
+
+func newOAuthTestEnv(t *testing.T) *oauthTestEnv {
+	t.Helper()
+
+	signingKey, err := auth.GenerateSigningKey(auth.AlgES256)
+	if err != nil {
+		t.Fatalf("GenerateSigningKey: %v", err)
+	}
+	keySet, err := auth.NewKeySet(signingKey.ID, signingKey)
+	if err != nil {
+		t.Fatalf("NewKeySet: %v", err)
+	}
+	return newOAuthTestEnvWithKeys(t, keySet)
+}
+
+func newOAuthTestEnvWithKeys(t *testing.T, keySet *auth.KeySet) *oauthTestEnv {
+	t.Helper()
+
+	jwtService, err := auth.NewJWTService(auth.TokenConfig{Issuer: testIssuer, Audience: []string{testIssuer}}, auth.NewKeyStore(keySet))
+	if err != nil {
+		t.Fatalf("NewJWTService: %v", err)
+	}
+
+	user := model.User{ID: 7, Username: "alice", Email: "alice@example.com"}
+	clientRepo := memrepo.NewOAuthClients()
+	refreshTokens := memrepo.NewRefreshTokens()
+	oauthService := service.NewOAuthService(clientRepo, memrepo.NewAuthorizationCodes(), memrepo.NewUsers(user),
+		refreshTokens, jwtService, jwtService)
+	handler := NewOAuthHandler(oauthService, testConsentURL)
+
+	router := gin.New()
+	router.GET("/oauth/authorize", handler.AuthorizeHandler)
+	router.POST("/oauth/token", handler.TokenHandler)
+	authorized := router.Group("/api", bearerPrincipalMiddleware(jwtService))
+	authorized.GET("/oauth/authorize", handler.ConsentDetailsHandler)
+	authorized.POST("/oauth/authorize", handler.ConsentHandler)
+
+	userToken, err := jwtService.Issue(user)
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+
+	env := &oauthTestEnv{
+		t:             t,
+		server:        httptest.NewServer(router),
+		jwt:           jwtService,
+		signingKey:    keySet.ActiveKey(),
+		clients:       service.NewOAuthClientService(clientRepo),
+		refreshTokens: refreshTokens,
+		user:          user,
+		userToken:     userToken.Token,
+	}
+	t.Cleanup(env.server.Close)
+	return env
+}
+
+// bearerPrincipalMiddleware adalah pengganti AuthMiddleware untuk test: hanya memverifikasi JWT lalu menyimpan principal
+func bearerPrincipalMiddleware(verifier auth.TokenVerifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, err := verifier.Verify(strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer "))
+		if err != nil {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		principal, err := claims.Principal()
+		if err != nil {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		c.Set(principalContextKey, principal)
+		c.Next()
+	}
+}
+
+// registerClient mendaftarkan client lewat OAuthClientService, sama seperti cmd/oauthclient
+func (e *oauthTestEnv) registerClient(input model.OAuthClientInput) (*model.OAuthClient, string) {
+	e.t.Helper()
+	client, secret, err := e.clients.RegisterClient(input)
+	if err != nil {
+		e.t.Fatalf("RegisterClient: %v", err)
+	}
+	return client, secret
+}
+
+func (e *oauthTestEnv) registerPublicClient() *model.OAuthClient {
+	client, _ := e.registerClient(model.OAuthClientInput{
+		Name:         "In-process SPA",
+		RedirectURIs: []string{testRedirectURI},
+		GrantTypes:   []string{model.GrantTypeAuthorizationCode, model.GrantTypeRefreshToken},
+		Scopes:       []string{"openid profile email"},
+		Public:       true,
+	})
+	return client
+}
+
+// noRedirectClient tidak mengikuti redirect agar Location bisa diperiksa
+var noRedirectClient = &http.Client{
+	CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+}
+
+func (e *oauthTestEnv) authorizeRequest(client *model.OAuthClient, redirectURI, scope string) model.AuthorizeRequest {
+	return model.AuthorizeRequest{
+		ResponseType:        "code",
+		ClientID:            client.ClientID,
+		RedirectURI:         redirectURI,
+		Scope:               scope,
+		State:               "xyz",
+		CodeChallenge:       auth.PKCEChallengeS256(testVerifier),
+		CodeChallengeMethod: auth.PKCEMethodS256,
+		Nonce:               "n-0S6_WzA2Mj",
+	}
+}
+
+func authorizeQuery(req model.AuthorizeRequest) url.Values {
+	return url.Values{
+		"response_type":         {req.ResponseType},
+		"client_id":             {req.ClientID},
+		"redirect_uri":          {req.RedirectURI},
+		"scope":                 {req.Scope},
+		"state":                 {req.State},
+		"code_challenge":        {req.CodeChallenge},
+		"code_challenge_method": {req.CodeChallengeMethod},
+		"nonce":                 {req.Nonce},
+	}
+}
+
+// consent menjalankan /oauth/authorize lalu menyetujui consent sebagai user, dan mengembalikan kode dari redirect
+func (e *oauthTestEnv) consent(req model.AuthorizeRequest) string {
+	e.t.Helper()
+
+	resp, err := noRedirectClient.Get(e.server.URL + "/oauth/authorize?" + authorizeQuery(req).Encode())
+	if err != nil {
+		e.t.Fatalf("GET /oauth/authorize: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusFound || !strings.HasPrefix(resp.Header.Get("Location"), testConsentURL+"?") {
+		e.t.Fatalf("GET /oauth/authorize: status %d, Location %q; want redirect to consent page", resp.StatusCode, resp.Header.Get("Location"))
+	}
+
+	var details struct {
+		Client struct {
+			ClientID string `json:"client_id"`
+		} `json:"client"`
+		Scopes []string `json:"scopes"`
+	}
+	status := e.doJSON(http.MethodGet, "/api/oauth/authorize?"+authorizeQuery(req).Encode(), nil, &details)
+	if status != http.StatusOK || details.Client.ClientID != req.ClientID {
+		e.t.Fatalf("GET /api/oauth/authorize: status %d, client %q", status, details.Client.ClientID)
+	}
+
+	var decision struct {
+		RedirectTo string `json:"redirect_to"`
+	}
+	status = e.doJSON(http.MethodPost, "/api/oauth/authorize", model.ConsentInput{AuthorizeRequest: req, Approve: true}, &decision)
+	if status != http.StatusOK {
+		e.t.Fatalf("POST /api/oauth/authorize: status %d", status)
+	}
+	redirect, err := url.Parse(decision.RedirectTo)
+	if err != nil {
+		e.t.Fatalf("parse redirect_to %q: %v", decision.RedirectTo, err)
+	}
+	if got := redirect.Scheme + "://" + redirect.Host + redirect.Path; got != req.RedirectURI {
+		e.t.Fatalf("redirect_to = %q; want redirect to %q", decision.RedirectTo, req.RedirectURI)
+	}
+	if redirect.Query().Get("state") != req.State {
+		e.t.Fatalf("redirect_to state = %q; want %q", redirect.Query().Get("state"), req.State)
+	}
+	code := redirect.Query().Get("code")
+	if code == "" {
+		e.t.Fatalf("redirect_to %q carries no code", decision.RedirectTo)
+	}
+	return code
+}
+
+// doJSON mengirim request JSON ke API dengan access token user
+func (e *oauthTestEnv) doJSON(method, path string, body any, out any) int {
+	e.t.Helper()
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			e.t.Fatalf("marshal body: %v", err)
+		}
+		reader = strings.NewReader(string(encoded))
+	}
+	req, err := http.NewRequest(method, e.server.URL+path, reader)
+	if err != nil {
+		e.t.Fatalf("NewRequest: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+e.userToken)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		e.t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+	if out != nil {
+		_ = json.NewDecoder(resp.Body).Decode(out)
+	}
+	return resp.StatusCode
+}
+
+// tokenResult adalah response token endpoint, sukses maupun error
+type tokenResult struct {
+	Status int
+	model.OAuthTokenResponse
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+	WWWAuthenticate  string `json:"-"`
+}
+
+// token memanggil /oauth/token; jika secret tidak kosong, client diautentikasi dengan HTTP Basic
+func (e *oauthTestEnv) token(clientID, secret string, form url.Values) tokenResult {
+	e.t.Helper()
+	if secret == "" {
+		form.Set("client_id", clientID)
+	}
+	req, err := http.NewRequest(http.MethodPost, e.server.URL+"/oauth/token", strings.NewReader(form.Encode()))
+	if err != nil {
+		e.t.Fatalf("NewRequest: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if secret != "" {
+		req.SetBasicAuth(url.QueryEscape(clientID), url.QueryEscape(secret))
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		e.t.Fatalf("POST /oauth/token: %v", err)
+	}
+	defer resp.Body.Close()
+
+	result := tokenResult{Status: resp.StatusCode, WWWAuthenticate: resp.Header.Get("WWW-Authenticate")}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		e.t.Fatalf("decode token response: %v", err)
+	}
+	if got := resp.Header.Get("Cache-Control"); got != "no-store" {
+		e.t.Errorf("Cache-Control = %q; want no-store", got)
+	}
+	return result
+}
+
+func (e *oauthTestEnv) exchangeCode(client *model.OAuthClient, code, redirectURI, verifier string) tokenResult {
+	e.t.Helper()
+	form := url.Values{"grant_type": {model.GrantTypeAuthorizationCode}, "code": {code}, "redirect_uri": {redirectURI}}
+	if verifier != "" {
+		form.Set("code_verifier", verifier)
+	}
+	return e.token(client.ClientID, "", form)
+}
+
+func (e *oauthTestEnv) refresh(client *model.OAuthClient, refreshToken, scope string) tokenResult {
+	e.t.Helper()
+	form := url.Values{"grant_type": {model.GrantTypeRefreshToken}, "refresh_token": {refreshToken}}
+	if scope != "" {
+		form.Set("scope", scope)
+	}
+	return e.token(client.ClientID, "", form)
+}
+
+func expectTokenError(t *testing.T, result tokenResult, status int, code string) {
+	t.Helper()
+	if result.Status != status || result.Error != code {
+		t.Fatalf("token response = %d %q (%s); want %d %q", result.Status, result.Error, result.ErrorDescription, status, code)
+	}
+	if result.AccessToken != "" {
+		t.Fatalf("error response carries an access token")
+	}
+}
+
+func TestOAuthAuthorizationCodeFlowWithPKCE(t *testing.T) {
+	env := newOAuthTestEnv(t)
+	client := env.registerPublicClient()
+	req := env.authorizeRequest(client, testRedirectURI, "openid profile")
+
+	code := env.consent(req)
+	result := env.exchangeCode(client, code, testRedirectURI, testVerifier)
+	if result.Status != http.StatusOK {
+		t.Fatalf("code exchange = %d %q (%s)", result.Status, result.Error, result.ErrorDescription)
+	}
+	if result.TokenType != "Bearer" || result.Scope != "openid profile" || result.RefreshToken == "" || result.IDToken == "" {
+		t.Fatalf("unexpected token response: %+v", result.OAuthTokenResponse)
+	}
+
+	claims, err := env.jwt.Verify(result.AccessToken)
+	if err != nil {
+		t.Fatalf("access token does not verify: %v", err)
+	}
+	if claims.Subject != strconv.Itoa(env.user.ID) || claims.ClientID != client.ClientID || claims.Scope != "openid profile" {
+		t.Fatalf("access token claims: sub %q client_id %q scope %q", claims.Subject, claims.ClientID, claims.Scope)
+	}
+
+	var idClaims auth.IDTokenClaims
+	_, err = jwt.ParseWithClaims(result.IDToken, &idClaims, func(*jwt.Token) (interface{}, error) {
+		return env.signingKey.PublicKey, nil
+	}, jwt.WithIssuer(testIssuer), jwt.WithAudience(client.ClientID))
+	if err != nil {
+		t.Fatalf("ID token does not verify: %v", err)
+	}
+	if idClaims.Nonce != req.Nonce || idClaims.PreferredUsername != env.user.Username || idClaims.Email != "" {
+		t.Fatalf("ID token claims: nonce %q preferred_username %q email %q", idClaims.Nonce, idClaims.PreferredUsername, idClaims.Email)
+	}
+}
+
+func TestOAuthTokenRejectsBadCodeVerifier(t *testing.T) {
+	tests := []struct {
+		name     string
+		verifier string
+		status   int
+		code     string
+	}{
+		{"missing", "", http.StatusBadRequest, service.OAuthErrInvalidRequest},
+		{"wrong", "wrong-verifier-wrong-verifier-wrong-verifier-0", http.StatusBadRequest, service.OAuthErrInvalidGrant},
+		{"malformed", "short", http.StatusBadRequest, service.OAuthErrInvalidGrant},
+	}
+
+	env := newOAuthTestEnv(t)
+	client := env.registerPublicClient()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code := env.consent(env.authorizeRequest(client, testRedirectURI, ""))
+			expectTokenError(t, env.exchangeCode(client, code, testRedirectURI, tt.verifier), tt.status, tt.code)
+		})
+	}
+}
+
+func TestOAuthReusedCodeRevokesIssuedTokens(t *testing.T) {
+	env := newOAuthTestEnv(t)
+	client := env.registerPublicClient()
+	code := env.consent(env.authorizeRequest(client, testRedirectURI, ""))
+
+	first := env.exchangeCode(client, code, testRedirectURI, testVerifier)
+	if first.Status != http.StatusOK || first.RefreshToken == "" {
+		t.Fatalf("first code exchange = %d %q", first.Status, first.Error)
+	}
+
+	expectTokenError(t, env.exchangeCode(client, code, testRedirectURI, testVerifier), http.StatusBadRequest, service.OAuthErrInvalidGrant)
+
+	// Refresh token dari penukaran pertama ikut dicabut
+	if env.refreshTokens.RevokedCount("oauth-code-1") == 0 {
+		t.Fatalf("refresh token family of the reused code was not revoked")
+	}
+	expectTokenError(t, env.refresh(client, first.RefreshToken, ""), http.StatusBadRequest, service.OAuthErrInvalidGrant)
+}
+
+func TestOAuthRejectsMismatchedRedirectURI(t *testing.T) {
+	env := newOAuthTestEnv(t)
+	client := env.registerPublicClient()
+
+	t.Run("authorize endpoint", func(t *testing.T) {
+		req := env.authorizeRequest(client, "https://attacker.test/callback", "")
+		resp, err := noRedirectClient.Get(env.server.URL + "/oauth/authorize?" + authorizeQuery(req).Encode())
+		if err != nil {
+			t.Fatalf("GET /oauth/authorize: %v", err)
+		}
+		resp.Body.Close()
+		// Redirect URI yang tidak terdaftar tidak boleh di-redirect, bahkan untuk mengirim error
+		if resp.StatusCode != http.StatusBadRequest || resp.Header.Get("Location") != "" {
+			t.Fatalf("status %d, Location %q; want 400 without redirect", resp.StatusCode, resp.Header.Get("Location"))
+		}
+	})
+
+	t.Run("consent", func(t *testing.T) {
+		req := env.authorizeRequest(client, testRedirectURI+"/other", "")
+		var body map[string]any
+		status := env.doJSON(http.MethodPost, "/api/oauth/authorize", model.ConsentInput{AuthorizeRequest: req, Approve: true}, &body)
+		if status != http.StatusBadRequest || body["redirect_to"] != nil {
+			t.Fatalf("status %d, body %v; want 400 without redirect_to", status, body)
+		}
+	})
+
+	t.Run("token endpoint", func(t *testing.T) {
+		code := env.consent(env.authorizeRequest(client, testRedirectURI, ""))
+		result := env.exchangeCode(client, code, "https://client.test/other", testVerifier)
+		expectTokenError(t, result, http.StatusBadRequest, service.OAuthErrInvalidGrant)
+	})
+}
+
+func TestOAuthRejectsOpenIDWithoutAsymmetricKey(t *testing.T) {
+	env := newOAuthTestEnvWithKeys(t, auth.NewHMACKeySet([]byte("oauth-test-secret-0123456789abcdef")))
+	client := env.registerPublicClient()
+
+	t.Run("openid requested", func(t *testing.T) {
+		req := env.authorizeRequest(client, testRedirectURI, "openid profile")
+		resp, err := noRedirectClient.Get(env.server.URL + "/oauth/authorize?" + authorizeQuery(req).Encode())
+		if err != nil {
+			t.Fatalf("GET /oauth/authorize: %v", err)
+		}
+		resp.Body.Close()
+		location, err := url.Parse(resp.Header.Get("Location"))
+		if err != nil || resp.StatusCode != http.StatusFound || location.Query().Get("error") != service.OAuthErrInvalidScope {
+			t.Fatalf("status %d, Location %q; want redirect with invalid_scope", resp.StatusCode, resp.Header.Get("Location"))
+		}
+	})
+
+	t.Run("default scopes", func(t *testing.T) {
+		code := env.consent(env.authorizeRequest(client, testRedirectURI, ""))
+		result := env.exchangeCode(client, code, testRedirectURI, testVerifier)
+		if result.Status != http.StatusOK || result.Scope != "profile email" || result.IDToken != "" {
+			t.Fatalf("token response = %d, scope %q, id token %t; want profile email without ID token",
+				result.Status, result.Scope, result.IDToken != "")
+		}
+	})
+}
+
+func TestOAuthRefreshTokenRotationNarrowsScope(t *testing.T) {
+	env := newOAuthTestEnv(t)
+	client := env.registerPublicClient()
+	code := env.consent(env.authorizeRequest(client, testRedirectURI, "openid profile email"))
+	initial := env.exchangeCode(client, code, testRedirectURI, testVerifier)
+	if initial.Status != http.StatusOK {
+		t.Fatalf("code exchange = %d %q", initial.Status, initial.Error)
+	}
+
+	narrowed := env.refresh(client, initial.RefreshToken, "profile")
+	if narrowed.Status != http.StatusOK {
+		t.Fatalf("narrowing refresh = %d %q (%s)", narrowed.Status, narrowed.Error, narrowed.ErrorDescription)
+	}
+	if narrowed.Scope != "profile" || narrowed.IDToken != "" {
+		t.Fatalf("narrowed response: scope %q, id_token present %v", narrowed.Scope, narrowed.IDToken != "")
+	}
+	if narrowed.RefreshToken == "" || narrowed.RefreshToken == initial.RefreshToken {
+		t.Fatalf("refresh token was not rotated")
+	}
+	claims, err := env.jwt.Verify(narrowed.AccessToken)
+	if err != nil || claims.Scope != "profile" {
+		t.Fatalf("narrowed access token: scope %q, err %v", claims.Scope, err)
+	}
+
+	// Refresh token hasil rotasi tetap membawa scope asli
+	full := env.refresh(client, narrowed.RefreshToken, "")
+	if full.Status != http.StatusOK || full.Scope != "openid profile email" {
+		t.Fatalf("refresh without scope = %d %q, scope %q; want original scope", full.Status, full.Error, full.Scope)
+	}
+
+	// Scope tidak boleh diperluas melebihi grant asli
+	widened := env.refresh(client, full.RefreshToken, "openid admin")
+	expectTokenError(t, widened, http.StatusBadRequest, service.OAuthErrInvalidScope)
+
+	// Refresh token lama yang sudah dirotasi dianggap dicuri: seluruh family dicabut
+	expectTokenError(t, env.refresh(client, initial.RefreshToken, ""), http.StatusBadRequest, service.OAuthErrInvalidGrant)
+	expectTokenError(t, env.refresh(client, full.RefreshToken, ""), http.StatusBadRequest, service.OAuthErrInvalidGrant)
+}
+
+func TestOAuthClientCredentials(t *testing.T) {
+	env := newOAuthTestEnv(t)
+	client, secret := env.registerClient(model.OAuthClientInput{
+		Name:       "In-process worker",
+		GrantTypes: []string{model.GrantTypeClientCredentials},
+		Scopes:     []string{"reports:read", "reports:write"},
+	})
+	if secret == "" {
+		t.Fatalf("confidential client was registered without a secret")
+	}
+
+	result := env.token(client.ClientID, secret, url.Values{"grant_type": {model.GrantTypeClientCredentials}, "scope": {"reports:read"}})
+	if result.Status != http.StatusOK {
+		t.Fatalf("client_credentials = %d %q (%s)", result.Status, result.Error, result.ErrorDescription)
+	}
+	if result.Scope != "reports:read" || result.RefreshToken != "" || result.IDToken != "" {
+		t.Fatalf("unexpected client_credentials response: %+v", result.OAuthTokenResponse)
+	}
+	if result.ExpiresIn <= 0 || result.ExpiresIn > int64(time.Hour.Seconds()) {
+		t.Fatalf("expires_in = %d", result.ExpiresIn)
+	}
+	claims, err := env.jwt.Verify(result.AccessToken)
+	if err != nil {
+		t.Fatalf("access token does not verify: %v", err)
+	}
+	principal, err := claims.Principal()
+	if err != nil || principal.IsUser() || claims.Subject != client.ClientID || claims.Scope != "reports:read" {
+		t.Fatalf("client_credentials principal: sub %q user %v err %v", claims.Subject, principal != nil && principal.IsUser(), err)
+	}
+
+	t.Run("wrong secret", func(t *testing.T) {
+		result := env.token(client.ClientID, "not-the-secret", url.Values{"grant_type": {model.GrantTypeClientCredentials}})
+		expectTokenError(t, result, http.StatusUnauthorized, service.OAuthErrInvalidClient)
+		if result.WWWAuthenticate == "" {
+			t.Fatalf("401 response without WWW-Authenticate")
+		}
+	})
+
+	t.Run("scope not allowed", func(t *testing.T) {
+		result := env.token(client.ClientID, secret, url.Values{"grant_type": {model.GrantTypeClientCredentials}, "scope": {"admin"}})
+		expectTokenError(t, result, http.StatusBadRequest, service.OAuthErrInvalidScope)
+	})
+
+	t.Run("public client", func(t *testing.T) {
+		public := env.registerPublicClient()
+		result := env.token(public.ClientID, "", url.Values{"grant_type": {model.GrantTypeClientCredentials}})
+		expectTokenError(t, result, http.StatusBadRequest, service.OAuthErrUnauthorizedClient)
+	})
+}
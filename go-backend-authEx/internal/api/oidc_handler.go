@@ -0,0 +1,145 @@
+// internal/api/oidc_handler.go
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"go-auth-example/internal/auth"
+	"go-auth-example/internal/logger"
+	"go-auth-example/internal/model"
+	"go-auth-example/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// OIDCHandler melayani endpoint OpenID Connect: discovery dan userinfo
+type OIDCHandler struct {
+	userService service.UserService
+	keyStore    *auth.KeyStore
+	issuer      string // Harus sama persis dengan klaim iss (JWT_ISSUER)
+	baseURL     string // URL publik server, dipakai untuk menyusun URL endpoint
+}
+
+// NewOIDCHandler constructor untuk OIDCHandler
+func NewOIDCHandler(userService service.UserService, keyStore *auth.KeyStore, issuer, baseURL string) *OIDCHandler {
+	return &OIDCHandler{
+		userService: userService,
+		keyStore:    keyStore,
+		issuer:      issuer,
+		baseURL:     strings.TrimRight(baseURL, "/"),
+	}
+}
+
+// DiscoveryHandler mempublikasikan metadata provider (GET /.well-known/openid-configuration)
+func (h *OIDCHandler) DiscoveryHandler(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, gin.H{
+		"issuer":                                h.issuer,
+		"authorization_endpoint":                h.baseURL + "/oauth/authorize",
+		"token_endpoint":                        h.baseURL + "/oauth/token",
+		"userinfo_endpoint":                     h.baseURL + "/userinfo",
+		"jwks_uri":                              h.baseURL + "/.well-known/jwks.json",
+		"scopes_supported":                      h.scopesSupported(),
+		"response_types_supported":              []string{"code"},
+		"response_modes_supported":              []string{"query"},
+		"grant_types_supported":                 []string{model.GrantTypeAuthorizationCode, model.GrantTypeRefreshToken, model.GrantTypeClientCredentials},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": h.idTokenAlgorithms(),
+		"token_endpoint_auth_methods_supported": []string{"client_secret_basic", "client_secret_post", "none"},
+		"code_challenge_methods_supported":      []string{auth.PKCEMethodS256},
+		"claims_supported":                      []string{"sub", "iss", "aud", "exp", "iat", "auth_time", "nonce", "at_hash", "preferred_username", "email", "email_verified"},
+	})
+}
+
+// scopesSupported tidak mencantumkan openid selama key aktif adalah HMAC, karena /oauth/authorize menolaknya
+func (h *OIDCHandler) scopesSupported() []string {
+	keySet := h.keyStore.Current()
+	if keySet != nil && keySet.ActiveKey().Algorithm != auth.AlgHS256 {
+		return service.SupportedOIDCScopes
+	}
+	scopes := []string{}
+	for _, scope := range service.SupportedOIDCScopes {
+		if scope != service.ScopeOpenID {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
+
+// idTokenAlgorithms mengembalikan algoritma asimetris di key set; HMAC tidak dipakai untuk ID token
+func (h *OIDCHandler) idTokenAlgorithms() []string {
+	algs := []string{}
+	keySet := h.keyStore.Current()
+	if keySet == nil {
+		return algs
+	}
+	seen := map[string]bool{}
+	for _, key := range keySet.Keys() {
+		if key.Algorithm == auth.AlgHS256 || seen[key.Algorithm] {
+			continue
+		}
+		seen[key.Algorithm] = true
+		algs = append(algs, key.Algorithm)
+	}
+	return algs
+}
+
+// UserInfoHandler mengembalikan klaim user sesuai scope access token (GET/POST /userinfo).
+// Token OAuth wajib memiliki scope openid; token first-party mendapat semua klaim.
+func (h *OIDCHandler) UserInfoHandler(c *gin.Context) {
+	logFields := logrus.Fields{
+		"handler": "UserInfoHandler",
+	}
+
+	principal, err := getUserPrincipalFromContext(c)
+	if err != nil {
+		logger.Log.WithFields(logFields).Warnf("Rejected userinfo request: %v", err)
+		RespondWithError(c, NewAPIError(http.StatusForbidden, ErrCodeUnauthorized, "This endpoint requires a user access token."))
+		return
+	}
+	logFields["user_id"] = principal.UserID
+
+	scopes := principal.Scopes
+	if principal.IsFirstParty() {
+		scopes = service.SupportedOIDCScopes
+	} else if !containsString(scopes, service.ScopeOpenID) {
+		logFields["client_id"] = principal.ClientID
+		logger.Log.WithFields(logFields).Warn("Userinfo request without openid scope.")
+		c.Header("WWW-Authenticate", `Bearer error="insufficient_scope", scope="openid"`)
+		RespondWithError(c, NewAPIError(http.StatusForbidden, ErrCodeUnauthorized, "The access token does not have the openid scope."))
+		return
+	}
+
+	user, err := h.userService.GetUserProfile(principal.UserID)
+	if err != nil {
+		switch err.Error() {
+		case "user associated with token not found":
+			logger.Log.WithFields(logFields).Warn("User not found for userinfo request.")
+			RespondWithError(c, NewAPIError(http.StatusNotFound, ErrCodeUserNotFound, "User not found."))
+		default:
+			logger.Log.WithFields(logFields).Errorf("Unhandled userinfo error: %v", err)
+			RespondWithError(c, NewAPIError(http.StatusInternalServerError, ErrCodeInternalServer, "Failed to fetch user info. Please try again later."))
+		}
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, struct {
+		Subject string `json:"sub"`
+		auth.OIDCProfileClaims
+	}{
+		Subject:           principal.Subject,
+		OIDCProfileClaims: service.ProfileClaimsForScopes(user, scopes),
+	})
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,410 @@
+// internal/api/organization_handler.go
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"go-auth-example/internal/logger"
+	"go-auth-example/internal/model"
+	"go-auth-example/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// OrganizationHandler melayani organisasi milik user, anggota dan undangan.
+// Rute di bawah /api/organization bekerja pada organisasi aktif yang ditentukan OrganizationMiddleware.
+type OrganizationHandler struct {
+	organizationService service.OrganizationService
+}
+
+// NewOrganizationHandler constructor untuk OrganizationHandler
+func NewOrganizationHandler(organizationService service.OrganizationService) *OrganizationHandler {
+	return &OrganizationHandler{organizationService: organizationService}
+}
+
+// ListHandler menampilkan organisasi yang diikuti user beserta role-nya (GET /api/organizations)
+func (h *OrganizationHandler) ListHandler(c *gin.Context) {
+	logFields := logrus.Fields{
+		"handler": "ListOrganizationsHandler",
+	}
+
+	principal, ok := requireFirstPartyUser(c, logFields)
+	if !ok {
+		return
+	}
+	logFields["user_id"] = principal.UserID
+
+	orgs, err := h.organizationService.ListForUser(principal.UserID)
+	if err != nil {
+		respondOrganizationError(c, err, logFields)
+		return
+	}
+
+	response := gin.H{"organizations": orgs}
+	if membership, ok := GetOrganization(c); ok {
+		response["active_organization_id"] = membership.OrganizationID
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateHandler membuat organisasi baru dengan user sebagai owner (POST /api/organizations)
+func (h *OrganizationHandler) CreateHandler(c *gin.Context) {
+	var input model.CreateOrganizationInput
+	logFields := logrus.Fields{
+		"handler": "CreateOrganizationHandler",
+	}
+
+	principal, ok := requireFirstPartyUser(c, logFields)
+	if !ok {
+		return
+	}
+	logFields["user_id"] = principal.UserID
+
+	if validationErrors := ValidateAndBind(c, &input); validationErrors != nil {
+		logger.Log.WithFields(logFields).Warnf("Validation failed for organization creation: %v", validationErrors)
+		RespondWithValidationErrors(c, http.StatusBadRequest, validationErrors)
+		return
+	}
+
+	org, err := h.organizationService.Create(principal.UserID, input)
+	if err != nil {
+		respondOrganizationError(c, err, logFields)
+		return
+	}
+
+	logger.Log.WithFields(logFields).Info("Organization created")
+	c.JSON(http.StatusCreated, gin.H{"message": "Organization created successfully", "organization": org})
+}
+
+// SwitchHandler mengganti organisasi aktif session login (POST /api/organizations/:id/switch).
+// Client perlu memanggil /auth/refresh agar access token membawa klaim org_id yang baru.
+func (h *OrganizationHandler) SwitchHandler(c *gin.Context) {
+	logFields := logrus.Fields{
+		"handler": "SwitchOrganizationHandler",
+	}
+
+	principal, ok := requireFirstPartyUser(c, logFields)
+	if !ok {
+		return
+	}
+	logFields["user_id"] = principal.UserID
+
+	orgID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || orgID <= 0 {
+		RespondWithError(c, NewAPIError(http.StatusBadRequest, ErrCodeBadRequest, "Invalid organization ID."))
+		return
+	}
+	logFields["organization_id"] = orgID
+
+	org, err := h.organizationService.Switch(principal.UserID, principal.SessionID, orgID)
+	if err != nil {
+		respondOrganizationError(c, err, logFields)
+		return
+	}
+
+	logger.Log.WithFields(logFields).Info("Active organization switched")
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "Active organization switched. Refresh your access token to use it.",
+		"organization": org,
+	})
+}
+
+// CurrentHandler menampilkan organisasi aktif beserta role user (GET /api/organization)
+func (h *OrganizationHandler) CurrentHandler(c *gin.Context) {
+	membership, logFields, ok := h.actor(c, "CurrentOrganizationHandler")
+	if !ok {
+		return
+	}
+
+	org, err := h.organizationService.Get(membership)
+	if err != nil {
+		respondOrganizationError(c, err, logFields)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"organization": org})
+}
+
+// DeleteHandler menghapus organisasi aktif beserta anggota dan undangannya (DELETE /api/organization)
+func (h *OrganizationHandler) DeleteHandler(c *gin.Context) {
+	membership, logFields, ok := h.actor(c, "DeleteOrganizationHandler")
+	if !ok {
+		return
+	}
+
+	if err := h.organizationService.Delete(membership); err != nil {
+		respondOrganizationError(c, err, logFields)
+		return
+	}
+
+	logger.Log.WithFields(logFields).Info("Organization deleted")
+	c.JSON(http.StatusOK, gin.H{"message": "Organization deleted successfully"})
+}
+
+// MembersHandler menampilkan anggota organisasi aktif (GET /api/organization/members)
+func (h *OrganizationHandler) MembersHandler(c *gin.Context) {
+	membership, logFields, ok := h.actor(c, "ListMembersHandler")
+	if !ok {
+		return
+	}
+
+	members, err := h.organizationService.ListMembers(membership.OrganizationID)
+	if err != nil {
+		respondOrganizationError(c, err, logFields)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"members": members})
+}
+
+// UpdateMemberHandler mengubah role anggota organisasi aktif (PATCH /api/organization/members/:id)
+func (h *OrganizationHandler) UpdateMemberHandler(c *gin.Context) {
+	var input model.UpdateMemberRoleInput
+	membership, logFields, ok := h.actor(c, "UpdateMemberHandler")
+	if !ok {
+		return
+	}
+
+	userID, ok := parseUserIDParam(c)
+	if !ok {
+		return
+	}
+	logFields["member_id"] = userID
+
+	if validationErrors := ValidateAndBind(c, &input); validationErrors != nil {
+		logger.Log.WithFields(logFields).Warnf("Validation failed for member role update: %v", validationErrors)
+		RespondWithValidationErrors(c, http.StatusBadRequest, validationErrors)
+		return
+	}
+
+	if err := h.organizationService.UpdateMemberRole(membership, userID, input.Role); err != nil {
+		respondOrganizationError(c, err, logFields)
+		return
+	}
+
+	logger.Log.WithFields(logFields).Info("Member role updated")
+	c.JSON(http.StatusOK, gin.H{"message": "Member role updated successfully"})
+}
+
+// RemoveMemberHandler mengeluarkan anggota dari organisasi aktif, atau keluar jika :id adalah user sendiri
+// (DELETE /api/organization/members/:id)
+func (h *OrganizationHandler) RemoveMemberHandler(c *gin.Context) {
+	membership, logFields, ok := h.actor(c, "RemoveMemberHandler")
+	if !ok {
+		return
+	}
+
+	userID, ok := parseUserIDParam(c)
+	if !ok {
+		return
+	}
+	logFields["member_id"] = userID
+
+	if err := h.organizationService.RemoveMember(membership, userID); err != nil {
+		respondOrganizationError(c, err, logFields)
+		return
+	}
+
+	logger.Log.WithFields(logFields).Info("Member removed")
+	c.JSON(http.StatusOK, gin.H{"message": "Member removed successfully"})
+}
+
+// InvitationsHandler menampilkan undangan organisasi aktif yang masih berlaku (GET /api/organization/invitations)
+func (h *OrganizationHandler) InvitationsHandler(c *gin.Context) {
+	membership, logFields, ok := h.actor(c, "ListInvitationsHandler")
+	if !ok {
+		return
+	}
+
+	invitations, err := h.organizationService.ListInvitations(membership.OrganizationID)
+	if err != nil {
+		respondOrganizationError(c, err, logFields)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"invitations": invitations})
+}
+
+// InviteHandler mengundang alamat email bergabung ke organisasi aktif (POST /api/organization/invitations)
+func (h *OrganizationHandler) InviteHandler(c *gin.Context) {
+	var input model.InviteMemberInput
+	membership, logFields, ok := h.actor(c, "InviteMemberHandler")
+	if !ok {
+		return
+	}
+
+	if validationErrors := ValidateAndBind(c, &input); validationErrors != nil {
+		logger.Log.WithFields(logFields).Warnf("Validation failed for invitation: %v", validationErrors)
+		RespondWithValidationErrors(c, http.StatusBadRequest, validationErrors)
+		return
+	}
+
+	invitation, err := h.organizationService.Invite(membership, input)
+	if err != nil {
+		respondOrganizationError(c, err, logFields)
+		return
+	}
+
+	logger.Log.WithFields(logFields).Info("Invitation sent")
+	c.JSON(http.StatusCreated, gin.H{"message": "Invitation sent successfully", "invitation": invitation})
+}
+
+// RevokeInvitationHandler membatalkan undangan organisasi aktif (DELETE /api/organization/invitations/:id)
+func (h *OrganizationHandler) RevokeInvitationHandler(c *gin.Context) {
+	membership, logFields, ok := h.actor(c, "RevokeInvitationHandler")
+	if !ok {
+		return
+	}
+
+	invitationID, ok := parseInvitationIDParam(c)
+	if !ok {
+		return
+	}
+	logFields["invitation_id"] = invitationID
+
+	if err := h.organizationService.RevokeInvitation(membership.OrganizationID, invitationID); err != nil {
+		respondOrganizationError(c, err, logFields)
+		return
+	}
+
+	logger.Log.WithFields(logFields).Info("Invitation revoked")
+	c.JSON(http.StatusOK, gin.H{"message": "Invitation revoked successfully"})
+}
+
+// PendingInvitationsHandler menampilkan undangan untuk email user yang sedang login (GET /api/invitations)
+func (h *OrganizationHandler) PendingInvitationsHandler(c *gin.Context) {
+	logFields := logrus.Fields{
+		"handler": "PendingInvitationsHandler",
+	}
+
+	principal, ok := requireFirstPartyUser(c, logFields)
+	if !ok {
+		return
+	}
+	logFields["user_id"] = principal.UserID
+
+	invitations, err := h.organizationService.PendingInvitations(principal.UserID)
+	if err != nil {
+		respondOrganizationError(c, err, logFields)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"invitations": invitations})
+}
+
+// AcceptInvitationHandler menerima undangan untuk email user (POST /api/invitations/:id/accept)
+func (h *OrganizationHandler) AcceptInvitationHandler(c *gin.Context) {
+	logFields := logrus.Fields{
+		"handler": "AcceptInvitationHandler",
+	}
+
+	principal, ok := requireFirstPartyUser(c, logFields)
+	if !ok {
+		return
+	}
+	logFields["user_id"] = principal.UserID
+
+	invitationID, ok := parseInvitationIDParam(c)
+	if !ok {
+		return
+	}
+	logFields["invitation_id"] = invitationID
+
+	org, err := h.organizationService.AcceptInvitation(principal.UserID, invitationID)
+	if err != nil {
+		respondOrganizationError(c, err, logFields)
+		return
+	}
+
+	logger.Log.WithFields(logFields).Info("Invitation accepted")
+	c.JSON(http.StatusOK, gin.H{"message": "You have joined the organization", "organization": org})
+}
+
+// DeclineInvitationHandler menolak undangan untuk email user (POST /api/invitations/:id/decline)
+func (h *OrganizationHandler) DeclineInvitationHandler(c *gin.Context) {
+	logFields := logrus.Fields{
+		"handler": "DeclineInvitationHandler",
+	}
+
+	principal, ok := requireFirstPartyUser(c, logFields)
+	if !ok {
+		return
+	}
+	logFields["user_id"] = principal.UserID
+
+	invitationID, ok := parseInvitationIDParam(c)
+	if !ok {
+		return
+	}
+	logFields["invitation_id"] = invitationID
+
+	if err := h.organizationService.DeclineInvitation(principal.UserID, invitationID); err != nil {
+		respondOrganizationError(c, err, logFields)
+		return
+	}
+
+	logger.Log.WithFields(logFields).Info("Invitation declined")
+	c.JSON(http.StatusOK, gin.H{"message": "Invitation declined"})
+}
+
+// actor mengambil keanggotaan user di organisasi aktif; RequireOrgRole di router sudah memastikan keberadaannya
+func (h *OrganizationHandler) actor(c *gin.Context, handlerName string) (*model.Membership, logrus.Fields, bool) {
+	logFields := logrus.Fields{
+		"handler": handlerName,
+	}
+
+	if _, ok := requireFirstPartyUser(c, logFields); !ok {
+		return nil, nil, false
+	}
+	membership, ok := GetOrganization(c)
+	if !ok {
+		RespondWithError(c, NewAPIError(http.StatusForbidden, ErrCodeOrgRequired, "No active organization."))
+		return nil, nil, false
+	}
+	logFields["user_id"] = membership.UserID
+	logFields["organization_id"] = membership.OrganizationID
+	return membership, logFields, true
+}
+
+// parseInvitationIDParam membaca parameter :id berupa ID undangan; menulis respons error jika tidak valid
+func parseInvitationIDParam(c *gin.Context) (int64, bool) {
+	invitationID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || invitationID <= 0 {
+		RespondWithError(c, NewAPIError(http.StatusBadRequest, ErrCodeBadRequest, "Invalid invitation ID."))
+		return 0, false
+	}
+	return invitationID, true
+}
+
+// respondOrganizationError menerjemahkan error OrganizationService menjadi respons API
+func respondOrganizationError(c *gin.Context, err error, logFields logrus.Fields) {
+	switch err.Error() {
+	case "organization not found":
+		RespondWithError(c, NewAPIError(http.StatusNotFound, ErrCodeOrgNotFound, "Organization not found."))
+	case "invalid organization name":
+		RespondWithError(c, NewAPIError(http.StatusBadRequest, ErrCodeBadRequest, "The organization name must not be blank."))
+	case "invalid slug":
+		RespondWithError(c, NewAPIError(http.StatusBadRequest, ErrCodeOrgInvalidSlug,
+			"The slug may only contain lowercase letters, digits and hyphens, and must start and end with a letter or digit."))
+	case "slug already exists":
+		RespondWithError(c, NewAPIError(http.StatusConflict, ErrCodeOrgSlugTaken, "The slug is already taken."))
+	case "insufficient organization role":
+		RespondWithError(c, NewAPIError(http.StatusForbidden, ErrCodeForbidden, "Your role in this organization does not allow this action."))
+	case "member not found":
+		RespondWithError(c, NewAPIError(http.StatusNotFound, ErrCodeOrgMemberNotFound, "The user is not a member of this organization."))
+	case "cannot remove last owner":
+		RespondWithError(c, NewAPIError(http.StatusConflict, ErrCodeOrgLastOwner,
+			"The organization must keep at least one owner. Make another member an owner first."))
+	case "already a member":
+		RespondWithError(c, NewAPIError(http.StatusConflict, ErrCodeOrgAlreadyMember, "The user is already a member of this organization."))
+	case "invitation not found":
+		RespondWithError(c, NewAPIError(http.StatusNotFound, ErrCodeInvitationNotFound, "The invitation does not exist or has expired."))
+	case "email not verified":
+		RespondWithError(c, NewAPIError(http.StatusForbidden, ErrCodeEmailNotVerified, "Confirm your email address to see and answer invitations."))
+	case "session required":
+		RespondWithError(c, NewAPIError(http.StatusBadRequest, ErrCodeBadRequest, "Switching organizations requires a login session."))
+	case "user not found":
+		RespondWithError(c, NewAPIError(http.StatusNotFound, ErrCodeUserNotFound, "User profile not found."))
+	default:
+		logger.Log.WithFields(logFields).Errorf("Unhandled organization error: %v", err)
+		RespondWithError(c, NewAPIError(http.StatusInternalServerError, ErrCodeInternalServer, "The request could not be completed. Please try again later."))
+	}
+}
@@ -0,0 +1,192 @@
+// internal/api/passkey_handler.go
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"go-auth-example/internal/logger"
+	"go-auth-example/internal/model"
+	"go-auth-example/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// PasskeyHandler melayani pengelolaan passkey milik user yang sedang login di /api/passkeys
+type PasskeyHandler struct {
+	passkeyService service.PasskeyService
+}
+
+// NewPasskeyHandler constructor untuk PasskeyHandler
+func NewPasskeyHandler(passkeyService service.PasskeyService) *PasskeyHandler {
+	return &PasskeyHandler{passkeyService: passkeyService}
+}
+
+// ListHandler menampilkan passkey milik user (GET /api/passkeys)
+func (h *PasskeyHandler) ListHandler(c *gin.Context) {
+	logFields := logrus.Fields{
+		"handler": "ListPasskeysHandler",
+	}
+
+	principal, ok := requireFirstPartyUser(c, logFields)
+	if !ok {
+		return
+	}
+
+	passkeys, err := h.passkeyService.List(principal.UserID)
+	if err != nil {
+		logFields["user_id"] = principal.UserID
+		logger.Log.WithFields(logFields).Errorf("Unhandled list passkeys error: %v", err)
+		RespondWithError(c, NewAPIError(http.StatusInternalServerError, ErrCodeInternalServer, "Failed to list passkeys. Please try again later."))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"passkeys": passkeys})
+}
+
+// RegistrationOptionsHandler memulai pendaftaran passkey (POST /api/passkeys/options).
+// Options diteruskan ke navigator.credentials.create(), session_token dikirim balik ke RegisterHandler.
+func (h *PasskeyHandler) RegistrationOptionsHandler(c *gin.Context) {
+	logFields := logrus.Fields{
+		"handler": "PasskeyRegistrationOptionsHandler",
+	}
+
+	principal, ok := requireFirstPartyUser(c, logFields)
+	if !ok {
+		return
+	}
+	logFields["user_id"] = principal.UserID
+
+	ceremony, err := h.passkeyService.BeginRegistration(principal.UserID)
+	if err != nil {
+		switch err.Error() {
+		case "too many passkeys":
+			RespondWithError(c, NewAPIError(http.StatusConflict, ErrCodePasskeyLimit, "You have reached the maximum number of passkeys. Remove one before adding another."))
+		case "user not found":
+			RespondWithError(c, NewAPIError(http.StatusNotFound, ErrCodeUserNotFound, "User profile not found."))
+		default:
+			logger.Log.WithFields(logFields).Errorf("Unhandled passkey registration options error: %v", err)
+			RespondWithError(c, NewAPIError(http.StatusInternalServerError, ErrCodeInternalServer, "Failed to start passkey setup. Please try again later."))
+		}
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, ceremony)
+}
+
+// RegisterHandler menyelesaikan pendaftaran passkey dengan respons dari authenticator (POST /api/passkeys)
+func (h *PasskeyHandler) RegisterHandler(c *gin.Context) {
+	var input model.FinishPasskeyRegistrationInput
+	logFields := logrus.Fields{
+		"handler": "RegisterPasskeyHandler",
+	}
+
+	principal, ok := requireFirstPartyUser(c, logFields)
+	if !ok {
+		return
+	}
+	logFields["user_id"] = principal.UserID
+
+	if validationErrors := ValidateAndBind(c, &input); validationErrors != nil {
+		logger.Log.WithFields(logFields).Warnf("Validation failed for passkey registration: %v", validationErrors)
+		RespondWithValidationErrors(c, http.StatusBadRequest, validationErrors)
+		return
+	}
+
+	passkey, err := h.passkeyService.FinishRegistration(principal.UserID, input)
+	if err != nil {
+		switch err.Error() {
+		case "invalid passkey session":
+			RespondWithError(c, NewAPIError(http.StatusBadRequest, ErrCodePasskeySessionInvalid, "The passkey request has expired. Please try again."))
+		case "passkey verification failed":
+			RespondWithError(c, NewAPIError(http.StatusBadRequest, ErrCodePasskeyInvalid, "The passkey could not be verified."))
+		case "passkey already registered":
+			RespondWithError(c, NewAPIError(http.StatusConflict, ErrCodePasskeyExists, "This passkey is already registered."))
+		case "user not found":
+			RespondWithError(c, NewAPIError(http.StatusNotFound, ErrCodeUserNotFound, "User profile not found."))
+		default:
+			logger.Log.WithFields(logFields).Errorf("Unhandled passkey registration error: %v", err)
+			RespondWithError(c, NewAPIError(http.StatusInternalServerError, ErrCodeInternalServer, "Failed to add passkey. Please try again later."))
+		}
+		return
+	}
+
+	logger.Log.WithFields(logFields).Info("Passkey registered successfully")
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Passkey added",
+		"passkey": passkey,
+	})
+}
+
+// RenameHandler mengganti nama passkey (PATCH /api/passkeys/:id)
+func (h *PasskeyHandler) RenameHandler(c *gin.Context) {
+	var input model.RenamePasskeyInput
+	logFields := logrus.Fields{
+		"handler": "RenamePasskeyHandler",
+	}
+
+	principal, ok := requireFirstPartyUser(c, logFields)
+	if !ok {
+		return
+	}
+	logFields["user_id"] = principal.UserID
+
+	passkeyID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		RespondWithError(c, NewAPIError(http.StatusBadRequest, ErrCodeBadRequest, "Invalid passkey ID."))
+		return
+	}
+	logFields["passkey_id"] = passkeyID
+
+	if validationErrors := ValidateAndBind(c, &input); validationErrors != nil {
+		logger.Log.WithFields(logFields).Warnf("Validation failed for passkey rename: %v", validationErrors)
+		RespondWithValidationErrors(c, http.StatusBadRequest, validationErrors)
+		return
+	}
+
+	if err := h.passkeyService.Rename(principal.UserID, passkeyID, input.Name); err != nil {
+		switch err.Error() {
+		case "passkey not found":
+			RespondWithError(c, NewAPIError(http.StatusNotFound, ErrCodeNotFound, "Passkey not found."))
+		default:
+			logger.Log.WithFields(logFields).Errorf("Unhandled passkey rename error: %v", err)
+			RespondWithError(c, NewAPIError(http.StatusInternalServerError, ErrCodeInternalServer, "Failed to rename passkey. Please try again later."))
+		}
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Passkey renamed successfully"})
+}
+
+// DeleteHandler menghapus passkey (DELETE /api/passkeys/:id)
+func (h *PasskeyHandler) DeleteHandler(c *gin.Context) {
+	logFields := logrus.Fields{
+		"handler": "DeletePasskeyHandler",
+	}
+
+	principal, ok := requireFirstPartyUser(c, logFields)
+	if !ok {
+		return
+	}
+	logFields["user_id"] = principal.UserID
+
+	passkeyID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		RespondWithError(c, NewAPIError(http.StatusBadRequest, ErrCodeBadRequest, "Invalid passkey ID."))
+		return
+	}
+	logFields["passkey_id"] = passkeyID
+
+	if err := h.passkeyService.Delete(principal.UserID, passkeyID); err != nil {
+		switch err.Error() {
+		case "passkey not found":
+			RespondWithError(c, NewAPIError(http.StatusNotFound, ErrCodeNotFound, "Passkey not found."))
+		default:
+			logger.Log.WithFields(logFields).Errorf("Unhandled passkey delete error: %v", err)
+			RespondWithError(c, NewAPIError(http.StatusInternalServerError, ErrCodeInternalServer, "Failed to remove passkey. Please try again later."))
+		}
+		return
+	}
+
+	logger.Log.WithFields(logFields).Info("Passkey deleted successfully")
+	c.JSON(http.StatusOK, gin.H{"message": "Passkey removed successfully"})
+}
@@ -0,0 +1,113 @@
+// internal/api/password_handler.go
+package api
+
+import (
+	"net/http"
+
+	"go-auth-example/internal/logger"
+	"go-auth-example/internal/model"
+	"go-auth-example/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// PasswordHandler melayani lupa password dan reset password
+type PasswordHandler struct {
+	passwordService service.PasswordService
+}
+
+// NewPasswordHandler constructor untuk PasswordHandler
+func NewPasswordHandler(passwordService service.PasswordService) *PasswordHandler {
+	return &PasswordHandler{passwordService: passwordService}
+}
+
+// ForgotHandler mengirim link reset password (POST /auth/password/forgot).
+// Respons selalu sama agar endpoint ini tidak bisa dipakai untuk mengecek email terdaftar.
+func (h *PasswordHandler) ForgotHandler(c *gin.Context) {
+	var input model.ForgotPasswordInput
+	logFields := logrus.Fields{
+		"handler": "ForgotPasswordHandler",
+	}
+
+	if validationErrors := ValidateAndBind(c, &input); validationErrors != nil {
+		logger.Log.WithFields(logFields).Warnf("Validation failed for forgot password: %v", validationErrors)
+		RespondWithValidationErrors(c, http.StatusBadRequest, validationErrors)
+		return
+	}
+
+	if err := h.passwordService.ForgotPassword(input.Email); err != nil {
+		logger.Log.WithFields(logFields).Errorf("Unhandled forgot password error: %v", err)
+		RespondWithError(c, NewAPIError(http.StatusInternalServerError, ErrCodeInternalServer, "Failed to process the request. Please try again later."))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "If the address is registered, a password reset link has been sent."})
+}
+
+// ResetHandler mengganti password dengan token dari email (POST /auth/password/reset)
+func (h *PasswordHandler) ResetHandler(c *gin.Context) {
+	var input model.ResetPasswordInput
+	logFields := logrus.Fields{
+		"handler": "ResetPasswordHandler",
+	}
+
+	if validationErrors := ValidateAndBind(c, &input); validationErrors != nil {
+		logger.Log.WithFields(logFields).Warnf("Validation failed for password reset: %v", validationErrors)
+		RespondWithValidationErrors(c, http.StatusBadRequest, validationErrors)
+		return
+	}
+
+	if err := h.passwordService.ResetPassword(input); err != nil {
+		switch err.Error() {
+		case "invalid or expired reset token":
+			logger.Log.WithFields(logFields).Warn("Invalid password reset token presented.")
+			RespondWithError(c, NewAPIError(http.StatusBadRequest, ErrCodeResetTokenInvalid, "The reset link is invalid or has expired."))
+		default:
+			logger.Log.WithFields(logFields).Errorf("Unhandled password reset error: %v", err)
+			RespondWithError(c, NewAPIError(http.StatusInternalServerError, ErrCodeInternalServer, "Failed to reset password. Please try again later."))
+		}
+		return
+	}
+
+	logger.Log.WithFields(logFields).Info("Password reset successfully")
+	c.JSON(http.StatusOK, gin.H{"message": "Password has been reset. Please log in with your new password."})
+}
+
+// ChangeHandler mengganti password user yang sedang login (PUT /api/password).
+// Session yang dipakai request ini tetap aktif; session dan token lain dicabut.
+func (h *PasswordHandler) ChangeHandler(c *gin.Context) {
+	var input model.ChangePasswordInput
+	logFields := logrus.Fields{
+		"handler": "ChangePasswordHandler",
+	}
+
+	principal, ok := requireFirstPartyUser(c, logFields)
+	if !ok {
+		return
+	}
+	logFields["user_id"] = principal.UserID
+
+	if validationErrors := ValidateAndBind(c, &input); validationErrors != nil {
+		logger.Log.WithFields(logFields).Warnf("Validation failed for password change: %v", validationErrors)
+		RespondWithValidationErrors(c, http.StatusBadRequest, validationErrors)
+		return
+	}
+
+	if err := h.passwordService.ChangePassword(principal.UserID, principal.SessionID, input); err != nil {
+		switch err.Error() {
+		case "invalid current password":
+			RespondWithError(c, NewAPIError(http.StatusBadRequest, ErrCodeInvalidCredentials, "The current password is incorrect."))
+		case "new password must differ from current password":
+			RespondWithError(c, NewAPIError(http.StatusBadRequest, ErrCodePasswordReused, "The new password must be different from the current password."))
+		case "user not found":
+			RespondWithError(c, NewAPIError(http.StatusNotFound, ErrCodeUserNotFound, "User not found."))
+		default:
+			logger.Log.WithFields(logFields).Errorf("Unhandled password change error: %v", err)
+			RespondWithError(c, NewAPIError(http.StatusInternalServerError, ErrCodeInternalServer, "Failed to change password. Please try again later."))
+		}
+		return
+	}
+
+	logger.Log.WithFields(logFields).Info("Password changed successfully")
+	c.JSON(http.StatusOK, gin.H{"message": "Password changed successfully. Other devices have been signed out."})
+}
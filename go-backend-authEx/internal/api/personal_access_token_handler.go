@@ -0,0 +1,122 @@
+// internal/api/personal_access_token_handler.go
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"go-auth-example/internal/logger"
+	"go-auth-example/internal/model"
+	"go-auth-example/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// PersonalAccessTokenHandler melayani pengelolaan personal access token di /api/tokens.
+// Hanya bisa diakses dengan sesi login user, tidak dengan personal access token atau token OAuth.
+type PersonalAccessTokenHandler struct {
+	patService service.PersonalAccessTokenService
+}
+
+// NewPersonalAccessTokenHandler constructor untuk PersonalAccessTokenHandler
+func NewPersonalAccessTokenHandler(patService service.PersonalAccessTokenService) *PersonalAccessTokenHandler {
+	return &PersonalAccessTokenHandler{patService: patService}
+}
+
+// CreateHandler membuat personal access token baru (POST /api/tokens)
+func (h *PersonalAccessTokenHandler) CreateHandler(c *gin.Context) {
+	var input model.CreatePersonalAccessTokenInput
+	logFields := logrus.Fields{
+		"handler": "CreatePersonalAccessTokenHandler",
+	}
+
+	principal, ok := requireFirstPartyUser(c, logFields)
+	if !ok {
+		return
+	}
+	logFields["user_id"] = principal.UserID
+
+	validationErrors := ValidateAndBind(c, &input)
+	if validationErrors != nil {
+		logger.Log.WithFields(logFields).Warnf("Validation failed for personal access token: %v", validationErrors)
+		RespondWithValidationErrors(c, http.StatusBadRequest, validationErrors)
+		return
+	}
+
+	token, rawToken, err := h.patService.Create(principal.UserID, input)
+	if err != nil {
+		switch {
+		case strings.HasPrefix(err.Error(), "unsupported scope"), err.Error() == "expiry must be in the future":
+			logger.Log.WithFields(logFields).Warnf("Invalid personal access token request: %v", err)
+			RespondWithError(c, NewAPIError(http.StatusBadRequest, ErrCodeBadRequest, err.Error()))
+		default:
+			logger.Log.WithFields(logFields).Errorf("Unhandled personal access token error: %v", err)
+			RespondWithError(c, NewAPIError(http.StatusInternalServerError, ErrCodeInternalServer, "Failed to create token. Please try again later."))
+		}
+		return
+	}
+
+	logger.Log.WithFields(logFields).Info("Personal access token created successfully")
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Token created. Copy it now, it will not be shown again.",
+		"token":   rawToken,
+		"details": token,
+	})
+}
+
+// ListHandler menampilkan personal access token milik user (GET /api/tokens)
+func (h *PersonalAccessTokenHandler) ListHandler(c *gin.Context) {
+	logFields := logrus.Fields{
+		"handler": "ListPersonalAccessTokensHandler",
+	}
+
+	principal, ok := requireFirstPartyUser(c, logFields)
+	if !ok {
+		return
+	}
+
+	tokens, err := h.patService.List(principal.UserID)
+	if err != nil {
+		logFields["user_id"] = principal.UserID
+		logger.Log.WithFields(logFields).Errorf("Unhandled list personal access tokens error: %v", err)
+		RespondWithError(c, NewAPIError(http.StatusInternalServerError, ErrCodeInternalServer, "Failed to list tokens. Please try again later."))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"tokens": tokens, "available_scopes": service.PersonalAccessTokenScopes})
+}
+
+// RevokeHandler mencabut personal access token (DELETE /api/tokens/:id)
+func (h *PersonalAccessTokenHandler) RevokeHandler(c *gin.Context) {
+	logFields := logrus.Fields{
+		"handler": "RevokePersonalAccessTokenHandler",
+	}
+
+	principal, ok := requireFirstPartyUser(c, logFields)
+	if !ok {
+		return
+	}
+	logFields["user_id"] = principal.UserID
+
+	tokenID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		RespondWithError(c, NewAPIError(http.StatusBadRequest, ErrCodeBadRequest, "Invalid token ID."))
+		return
+	}
+	logFields["token_id"] = tokenID
+
+	if err := h.patService.Revoke(principal.UserID, tokenID); err != nil {
+		switch err.Error() {
+		case "token not found":
+			RespondWithError(c, NewAPIError(http.StatusNotFound, ErrCodeNotFound, "Token not found."))
+		default:
+			logger.Log.WithFields(logFields).Errorf("Unhandled revoke personal access token error: %v", err)
+			RespondWithError(c, NewAPIError(http.StatusInternalServerError, ErrCodeInternalServer, "Failed to revoke token. Please try again later."))
+		}
+		return
+	}
+
+	logger.Log.WithFields(logFields).Info("Personal access token revoked successfully")
+	c.JSON(http.StatusOK, gin.H{"message": "Token revoked successfully"})
+}
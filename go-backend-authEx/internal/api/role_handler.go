@@ -0,0 +1,142 @@
+// internal/api/role_handler.go
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"go-auth-example/internal/logger"
+	"go-auth-example/internal/model"
+	"go-auth-example/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// RoleHandler melayani pengelolaan role di /api/admin; akses dibatasi lewat RequirePermission di router
+type RoleHandler struct {
+	roleService service.RoleService
+}
+
+// NewRoleHandler constructor untuk RoleHandler
+func NewRoleHandler(roleService service.RoleService) *RoleHandler {
+	return &RoleHandler{roleService: roleService}
+}
+
+// ListHandler menampilkan semua role beserta permission-nya (GET /api/admin/roles)
+func (h *RoleHandler) ListHandler(c *gin.Context) {
+	roles, err := h.roleService.List()
+	if err != nil {
+		logger.Log.WithField("handler", "ListRolesHandler").Errorf("Unhandled list roles error: %v", err)
+		RespondWithError(c, NewAPIError(http.StatusInternalServerError, ErrCodeInternalServer, "Failed to list roles. Please try again later."))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"roles": roles})
+}
+
+// UserRolesHandler menampilkan role milik user (GET /api/admin/users/:id/roles)
+func (h *RoleHandler) UserRolesHandler(c *gin.Context) {
+	logFields := logrus.Fields{
+		"handler": "UserRolesHandler",
+	}
+
+	userID, ok := parseUserIDParam(c)
+	if !ok {
+		return
+	}
+	logFields["target_user_id"] = userID
+
+	roles, err := h.roleService.UserRoles(userID)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Unhandled list user roles error: %v", err)
+		RespondWithError(c, NewAPIError(http.StatusInternalServerError, ErrCodeInternalServer, "Failed to load roles. Please try again later."))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"roles": roles})
+}
+
+// AssignHandler memberikan role ke user (POST /api/admin/users/:id/roles).
+// Role baru masuk ke access token user setelah token-nya di-refresh.
+func (h *RoleHandler) AssignHandler(c *gin.Context) {
+	var input model.AssignRoleInput
+	logFields := logrus.Fields{
+		"handler": "AssignRoleHandler",
+	}
+
+	principal, _ := GetPrincipal(c)
+	logFields["user_id"] = principal.UserID
+
+	userID, ok := parseUserIDParam(c)
+	if !ok {
+		return
+	}
+	logFields["target_user_id"] = userID
+
+	if validationErrors := ValidateAndBind(c, &input); validationErrors != nil {
+		logger.Log.WithFields(logFields).Warnf("Validation failed for role assignment: %v", validationErrors)
+		RespondWithValidationErrors(c, http.StatusBadRequest, validationErrors)
+		return
+	}
+	logFields["role"] = input.Role
+
+	if err := h.roleService.Assign(userID, input.Role); err != nil {
+		switch err.Error() {
+		case "user not found":
+			RespondWithError(c, NewAPIError(http.StatusNotFound, ErrCodeUserNotFound, "User not found."))
+		case "role not found":
+			RespondWithError(c, NewAPIError(http.StatusNotFound, ErrCodeNotFound, "Role not found."))
+		default:
+			logger.Log.WithFields(logFields).Errorf("Unhandled role assignment error: %v", err)
+			RespondWithError(c, NewAPIError(http.StatusInternalServerError, ErrCodeInternalServer, "Failed to assign role. Please try again later."))
+		}
+		return
+	}
+
+	logger.Log.WithFields(logFields).Info("Role assigned by admin")
+	c.JSON(http.StatusOK, gin.H{"message": "Role assigned successfully"})
+}
+
+// RemoveHandler mencabut role dari user (DELETE /api/admin/users/:id/roles/:role)
+func (h *RoleHandler) RemoveHandler(c *gin.Context) {
+	logFields := logrus.Fields{
+		"handler": "RemoveRoleHandler",
+		"role":    c.Param("role"),
+	}
+
+	principal, _ := GetPrincipal(c)
+	logFields["user_id"] = principal.UserID
+
+	userID, ok := parseUserIDParam(c)
+	if !ok {
+		return
+	}
+	logFields["target_user_id"] = userID
+
+	if err := h.roleService.Remove(userID, c.Param("role")); err != nil {
+		switch err.Error() {
+		case "user not found":
+			RespondWithError(c, NewAPIError(http.StatusNotFound, ErrCodeUserNotFound, "User not found."))
+		case "role not found", "role not assigned":
+			RespondWithError(c, NewAPIError(http.StatusNotFound, ErrCodeNotFound, "The user does not have this role."))
+		case "cannot remove last admin":
+			RespondWithError(c, NewAPIError(http.StatusConflict, ErrCodeLastAdmin, "The last admin cannot be removed. Assign the admin role to another user first."))
+		default:
+			logger.Log.WithFields(logFields).Errorf("Unhandled role removal error: %v", err)
+			RespondWithError(c, NewAPIError(http.StatusInternalServerError, ErrCodeInternalServer, "Failed to remove role. Please try again later."))
+		}
+		return
+	}
+
+	logger.Log.WithFields(logFields).Info("Role removed by admin")
+	c.JSON(http.StatusOK, gin.H{"message": "Role removed successfully"})
+}
+
+// parseUserIDParam membaca parameter :id berupa ID user; menulis respons error jika tidak valid
+func parseUserIDParam(c *gin.Context) (int, bool) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || userID <= 0 {
+		RespondWithError(c, NewAPIError(http.StatusBadRequest, ErrCodeBadRequest, "Invalid user ID."))
+		return 0, false
+	}
+	return userID, true
+}
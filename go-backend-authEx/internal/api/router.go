@@ -4,11 +4,36 @@ package api
 import (
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"go-auth-example/internal/model"
+	"go-auth-example/internal/service"
 	"time"
 )
 
-// SetupRouter mengkonfigurasi dan mengembalikan instance Gin Engine
-func SetupRouter(authHandler *AuthHandler) *gin.Engine {
+// Handlers mengelompokkan semua handler yang didaftarkan di router
+type Handlers struct {
+	Auth      *AuthHandler
+	WellKnown *WellKnownHandler
+	OAuth     *OAuthHandler
+	OIDC      *OIDCHandler
+	Tokens    *PersonalAccessTokenHandler
+	Sessions  *SessionHandler
+	Cookies   *CookieAuth
+	Email     *EmailVerificationHandler
+	Password  *PasswordHandler
+	Account   *AccountHandler
+	MFA       *MFAHandler
+	Passkeys  *PasskeyHandler
+	Roles     *RoleHandler
+	Users     *AdminUserHandler
+	Orgs      *OrganizationHandler
+}
+
+// SetupRouter mengkonfigurasi dan mengembalikan instance Gin Engine.
+// authMiddleware dipasang pada semua rute di bawah /api, diikuti proteksi CSRF untuk request yang memakai cookie
+// dan organizationMiddleware yang menentukan organisasi aktif.
+func SetupRouter(handlers Handlers, authMiddleware gin.HandlerFunc, organizationMiddleware gin.HandlerFunc) *gin.Engine {
+	authHandler := handlers.Auth
+
 	router := gin.Default()
 
 	// --- Konfigurasi CORS ---
@@ -19,21 +44,123 @@ func SetupRouter(authHandler *AuthHandler) *gin.Engine {
 		// Menggunakan "*" akan mengizinkan semua origin (kurang aman untuk production).
 		AllowOrigins:     []string{"http://localhost:5173"}, // Alamat default Vite dev server
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", CSRFHeader, OrganizationHeader},
+		ExposeHeaders:    []string{"Content-Length", "Retry-After"},
 		AllowCredentials: true, // Jika Anda perlu mengirim cookie atau header Authorization
 		MaxAge:           12 * time.Hour,
 	}))
 
 	// Rute Publik
+	router.GET("/.well-known/jwks.json", handlers.WellKnown.JWKSHandler)
+	router.GET("/.well-known/openid-configuration", handlers.OIDC.DiscoveryHandler)
 	router.POST("/register", authHandler.RegisterHandler)
 	router.POST("/login", authHandler.LoginHandler)
+	router.POST("/auth/mfa/verify", authHandler.MFAVerifyHandler)
+	router.POST("/auth/passkey/options", authHandler.PasskeyLoginOptionsHandler)
+	router.POST("/auth/passkey/login", authHandler.PasskeyLoginHandler)
+	router.POST("/auth/magic-link", authHandler.MagicLinkRequestHandler)
+	router.POST("/auth/magic-link/consume", authHandler.MagicLinkConsumeHandler)
+	router.GET("/auth/social/providers", authHandler.SocialProvidersHandler)
+	router.POST("/auth/social/:provider/authorize", authHandler.SocialAuthorizeHandler)
+	router.POST("/auth/social/:provider/callback", authHandler.SocialCallbackHandler)
+	router.POST("/auth/refresh", authHandler.RefreshHandler)
+	router.POST("/auth/verify-email", handlers.Email.VerifyHandler)
+	router.POST("/auth/verify-email/resend", handlers.Email.ResendHandler)
+	router.POST("/auth/password/forgot", handlers.Password.ForgotHandler)
+	router.POST("/auth/password/reset", handlers.Password.ResetHandler)
+	router.POST("/auth/email/confirm", handlers.Account.ConfirmEmailChangeHandler)
+
+	// OAuth 2.1 authorization server
+	router.GET("/oauth/authorize", handlers.OAuth.AuthorizeHandler)
+	router.POST("/oauth/token", handlers.OAuth.TokenHandler)
+
+	// OIDC userinfo berada di luar /api (lokasi standar), tetapi tetap membutuhkan access token
+	router.GET("/userinfo", authMiddleware, handlers.OIDC.UserInfoHandler)
+	router.POST("/userinfo", authMiddleware, handlers.OIDC.UserInfoHandler)
 
 	// Rute Terproteksi
 	authorized := router.Group("/api")
-	authorized.Use(AuthMiddleware())
+	authorized.Use(authMiddleware, handlers.Cookies.CSRFMiddleware(), organizationMiddleware)
 	{
-		authorized.GET("/profile", authHandler.ProfileHandler)
+		authorized.GET("/csrf", handlers.Cookies.CSRFTokenHandler)
+
+		authorized.GET("/profile", RequireScope(service.ScopeProfile), authHandler.ProfileHandler)
+		authorized.PATCH("/profile", handlers.Account.UpdateProfileHandler)
+		authorized.GET("/me", RequireScope(service.ScopeProfile), authHandler.MeHandler)
+		authorized.POST("/logout", authHandler.LogoutHandler)
+		authorized.PUT("/password", handlers.Password.ChangeHandler)
+
+		// Penutupan akun dan ekspor data pribadi
+		authorized.DELETE("/account", handlers.Account.DeleteAccountHandler)
+		authorized.GET("/account/export", handlers.Account.ExportHandler)
+
+		// Pengaturan MFA (TOTP dan kode pemulihan)
+		authorized.GET("/mfa", handlers.MFA.StatusHandler)
+		authorized.DELETE("/mfa", handlers.MFA.DisableHandler)
+		authorized.POST("/mfa/totp", handlers.MFA.EnrollTOTPHandler)
+		authorized.POST("/mfa/totp/confirm", handlers.MFA.ConfirmTOTPHandler)
+		authorized.POST("/mfa/recovery-codes", handlers.MFA.RegenerateRecoveryCodesHandler)
+
+		// Passkey (WebAuthn); pendaftaran terdiri dari dua langkah: options lalu POST /passkeys
+		authorized.GET("/passkeys", handlers.Passkeys.ListHandler)
+		authorized.POST("/passkeys/options", handlers.Passkeys.RegistrationOptionsHandler)
+		authorized.POST("/passkeys", handlers.Passkeys.RegisterHandler)
+		authorized.PATCH("/passkeys/:id", handlers.Passkeys.RenameHandler)
+		authorized.DELETE("/passkeys/:id", handlers.Passkeys.DeleteHandler)
+
+		// Halaman consent di frontend memakai endpoint ini dengan token user yang sedang login
+		authorized.GET("/oauth/authorize", handlers.OAuth.ConsentDetailsHandler)
+		authorized.POST("/oauth/authorize", handlers.OAuth.ConsentHandler)
+
+		// Personal access token untuk script dan CI
+		authorized.GET("/tokens", handlers.Tokens.ListHandler)
+		authorized.POST("/tokens", handlers.Tokens.CreateHandler)
+		authorized.DELETE("/tokens/:id", handlers.Tokens.RevokeHandler)
+
+		// Session login per perangkat; DELETE /sessions mengeluarkan semua session lain
+		authorized.GET("/sessions", handlers.Sessions.ListHandler)
+		authorized.DELETE("/sessions", handlers.Sessions.RevokeOthersHandler)
+		authorized.DELETE("/sessions/:id", handlers.Sessions.RevokeHandler)
+
+		// Organisasi milik user, organisasi aktif dan undangan untuk email user
+		authorized.GET("/organizations", handlers.Orgs.ListHandler)
+		authorized.POST("/organizations", handlers.Orgs.CreateHandler)
+		authorized.POST("/organizations/:id/switch", handlers.Orgs.SwitchHandler)
+		authorized.GET("/invitations", handlers.Orgs.PendingInvitationsHandler)
+		authorized.POST("/invitations/:id/accept", handlers.Orgs.AcceptInvitationHandler)
+		authorized.POST("/invitations/:id/decline", handlers.Orgs.DeclineInvitationHandler)
+
+		// Organisasi aktif (klaim org_id atau header X-Organization-ID); dibatasi role user di organisasi tersebut
+		organization := authorized.Group("/organization")
+		{
+			organization.GET("", RequireOrgRole(model.OrgRoleMember), handlers.Orgs.CurrentHandler)
+			organization.DELETE("", RequireOrgRole(model.OrgRoleOwner), handlers.Orgs.DeleteHandler)
+			organization.GET("/members", RequireOrgRole(model.OrgRoleMember), handlers.Orgs.MembersHandler)
+			organization.PATCH("/members/:id", RequireOrgRole(model.OrgRoleAdmin), handlers.Orgs.UpdateMemberHandler)
+			// Anggota biasa boleh memakai rute ini untuk keluar dari organisasi
+			organization.DELETE("/members/:id", RequireOrgRole(model.OrgRoleMember), handlers.Orgs.RemoveMemberHandler)
+			organization.GET("/invitations", RequireOrgRole(model.OrgRoleAdmin), handlers.Orgs.InvitationsHandler)
+			organization.POST("/invitations", RequireOrgRole(model.OrgRoleAdmin), handlers.Orgs.InviteHandler)
+			organization.DELETE("/invitations/:id", RequireOrgRole(model.OrgRoleAdmin), handlers.Orgs.RevokeInvitationHandler)
+		}
+
+		// Administrasi; setiap rute dibatasi permission dari role user
+		admin := authorized.Group("/admin")
+		{
+			admin.GET("/users", RequirePermission(model.PermissionUsersRead), handlers.Users.ListHandler)
+			admin.GET("/users/:id", RequirePermission(model.PermissionUsersRead), handlers.Users.GetHandler)
+			admin.POST("/users/:id/disable", RequirePermission(model.PermissionUsersWrite), handlers.Users.DisableHandler)
+			admin.POST("/users/:id/enable", RequirePermission(model.PermissionUsersWrite), handlers.Users.EnableHandler)
+			admin.POST("/users/:id/unlock", RequirePermission(model.PermissionUsersWrite), handlers.Users.UnlockHandler)
+			admin.POST("/users/:id/password-reset", RequirePermission(model.PermissionUsersWrite), handlers.Users.ForcePasswordResetHandler)
+			admin.DELETE("/users/:id/sessions", RequirePermission(model.PermissionUsersWrite), handlers.Users.RevokeSessionsHandler)
+			admin.DELETE("/users/:id", RequirePermission(model.PermissionUsersWrite), handlers.Users.DeleteHandler)
+
+			admin.GET("/roles", RequirePermission(model.PermissionRolesRead), handlers.Roles.ListHandler)
+			admin.GET("/users/:id/roles", RequirePermission(model.PermissionRolesRead), handlers.Roles.UserRolesHandler)
+			admin.POST("/users/:id/roles", RequirePermission(model.PermissionRolesAssign), handlers.Roles.AssignHandler)
+			admin.DELETE("/users/:id/roles/:role", RequirePermission(model.PermissionRolesAssign), handlers.Roles.RemoveHandler)
+		}
 	}
 
 	return router
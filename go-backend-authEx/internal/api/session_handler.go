@@ -0,0 +1,101 @@
+// internal/api/session_handler.go
+package api
+
+import (
+	"net/http"
+
+	"go-auth-example/internal/logger"
+	"go-auth-example/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// SessionHandler melayani daftar dan pencabutan session login di /api/sessions
+type SessionHandler struct {
+	sessionService service.SessionService
+}
+
+// NewSessionHandler constructor untuk SessionHandler
+func NewSessionHandler(sessionService service.SessionService) *SessionHandler {
+	return &SessionHandler{sessionService: sessionService}
+}
+
+// ListHandler menampilkan session aktif user (GET /api/sessions)
+func (h *SessionHandler) ListHandler(c *gin.Context) {
+	logFields := logrus.Fields{
+		"handler": "ListSessionsHandler",
+	}
+
+	principal, ok := requireFirstPartyUser(c, logFields)
+	if !ok {
+		return
+	}
+
+	sessions, err := h.sessionService.List(principal.UserID, principal.SessionID)
+	if err != nil {
+		logFields["user_id"] = principal.UserID
+		logger.Log.WithFields(logFields).Errorf("Unhandled list sessions error: %v", err)
+		RespondWithError(c, NewAPIError(http.StatusInternalServerError, ErrCodeInternalServer, "Failed to list sessions. Please try again later."))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"sessions": sessions})
+}
+
+// RevokeHandler mengeluarkan satu session (DELETE /api/sessions/:id)
+func (h *SessionHandler) RevokeHandler(c *gin.Context) {
+	logFields := logrus.Fields{
+		"handler": "RevokeSessionHandler",
+	}
+
+	principal, ok := requireFirstPartyUser(c, logFields)
+	if !ok {
+		return
+	}
+	sessionID := c.Param("id")
+	logFields["user_id"] = principal.UserID
+	logFields["session_id"] = sessionID
+
+	if err := h.sessionService.Revoke(principal.UserID, sessionID); err != nil {
+		switch err.Error() {
+		case "session not found":
+			RespondWithError(c, NewAPIError(http.StatusNotFound, ErrCodeNotFound, "Session not found."))
+		default:
+			logger.Log.WithFields(logFields).Errorf("Unhandled revoke session error: %v", err)
+			RespondWithError(c, NewAPIError(http.StatusInternalServerError, ErrCodeInternalServer, "Failed to sign out session. Please try again later."))
+		}
+		return
+	}
+
+	logger.Log.WithFields(logFields).Info("Session revoked successfully")
+	c.JSON(http.StatusOK, gin.H{"message": "Session signed out successfully"})
+}
+
+// RevokeOthersHandler mengeluarkan semua session lain selain session saat ini (DELETE /api/sessions)
+func (h *SessionHandler) RevokeOthersHandler(c *gin.Context) {
+	logFields := logrus.Fields{
+		"handler": "RevokeOtherSessionsHandler",
+	}
+
+	principal, ok := requireFirstPartyUser(c, logFields)
+	if !ok {
+		return
+	}
+	logFields["user_id"] = principal.UserID
+
+	// Token lama tanpa klaim sid tidak tahu session mana yang harus dipertahankan
+	if principal.SessionID == "" {
+		RespondWithError(c, NewAPIError(http.StatusBadRequest, ErrCodeBadRequest, "Current token is not bound to a session. Please log in again."))
+		return
+	}
+
+	revoked, err := h.sessionService.RevokeOthers(principal.UserID, principal.SessionID)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Unhandled revoke other sessions error: %v", err)
+		RespondWithError(c, NewAPIError(http.StatusInternalServerError, ErrCodeInternalServer, "Failed to sign out other sessions. Please try again later."))
+		return
+	}
+
+	logger.Log.WithFields(logFields).Info("Other sessions revoked successfully")
+	c.JSON(http.StatusOK, gin.H{"message": "Signed out of all other sessions", "revoked": revoked})
+}
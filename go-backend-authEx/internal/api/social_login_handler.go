@@ -0,0 +1,106 @@
+// internal/api/social_login_handler.go
+package api
+
+import (
+	"net/http"
+
+	"go-auth-example/internal/logger"
+	"go-auth-example/internal/model"
+	"go-auth-example/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// socialLoginStateCookie menyimpan state login sosial agar callback hanya diterima dari browser yang memulainya
+	socialLoginStateCookie = "social_login_state"
+	// socialLoginCookiePath membatasi cookie state hanya terkirim ke endpoint login sosial
+	socialLoginCookiePath = "/auth/social"
+)
+
+// SocialProvidersHandler menampilkan identity provider yang aktif (GET /auth/social/providers)
+func (h *AuthHandler) SocialProvidersHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"providers": h.authService.SocialProviders()})
+}
+
+// SocialAuthorizeHandler memulai login sosial (POST /auth/social/:provider/authorize).
+// Frontend mengarahkan browser ke authorization_url; provider lalu mengembalikan user ke halaman callback frontend.
+func (h *AuthHandler) SocialAuthorizeHandler(c *gin.Context) {
+	provider := c.Param("provider")
+	logFields := logrus.Fields{
+		"handler":  "SocialAuthorizeHandler",
+		"provider": provider,
+	}
+
+	start, state, err := h.authService.BeginSocialLogin(provider)
+	if err != nil {
+		switch err.Error() {
+		case "unknown provider":
+			RespondWithError(c, NewAPIError(http.StatusNotFound, ErrCodeSocialProviderUnknown, "This login provider is not available."))
+		case "social provider unavailable":
+			logger.Log.WithFields(logFields).Warn("Social login provider unavailable.")
+			RespondWithError(c, NewAPIError(http.StatusBadGateway, ErrCodeSocialLoginFailed, "The login provider is not reachable. Please try again later."))
+		default:
+			logger.Log.WithFields(logFields).Errorf("Unhandled social login start error: %v", err)
+			RespondWithError(c, NewAPIError(http.StatusInternalServerError, ErrCodeInternalServer, "Failed to start login. Please try again later."))
+		}
+		return
+	}
+
+	h.cookieAuth.setCookie(c, socialLoginStateCookie, state, socialLoginCookiePath, int(service.SocialLoginStateTTL.Seconds()), true)
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, start)
+}
+
+// SocialCallbackHandler menukar code dan state dari callback provider dengan token login
+// (POST /auth/social/:provider/callback)
+func (h *AuthHandler) SocialCallbackHandler(c *gin.Context) {
+	var input model.SocialCallbackInput
+	provider := c.Param("provider")
+	logFields := logrus.Fields{
+		"handler":  "SocialCallbackHandler",
+		"provider": provider,
+	}
+
+	if validationErrors := ValidateAndBind(c, &input); validationErrors != nil {
+		logger.Log.WithFields(logFields).Warnf("Validation failed for social login callback: %v", validationErrors)
+		RespondWithValidationErrors(c, http.StatusBadRequest, validationErrors)
+		return
+	}
+
+	// State cookie hanya berlaku untuk satu callback, berhasil atau tidak
+	browserState, _ := c.Cookie(socialLoginStateCookie)
+	h.cookieAuth.setCookie(c, socialLoginStateCookie, "", socialLoginCookiePath, -1, true)
+
+	tokens, err := h.authService.LoginWithSocial(provider, input, browserState, clientInfo(c))
+	if err != nil {
+		switch err.Error() {
+		case "unknown provider":
+			RespondWithError(c, NewAPIError(http.StatusNotFound, ErrCodeSocialProviderUnknown, "This login provider is not available."))
+		case "invalid social login state":
+			RespondWithError(c, NewAPIError(http.StatusBadRequest, ErrCodeSocialStateInvalid, "The login request has expired or was started in another browser. Please try again."))
+		case "social login failed":
+			RespondWithError(c, NewAPIError(http.StatusBadRequest, ErrCodeSocialLoginFailed, "The login provider did not confirm your identity. Please try again."))
+		case "social email missing", "social email not verified":
+			RespondWithError(c, NewAPIError(http.StatusBadRequest, ErrCodeSocialEmailUnverified, "Your account at the login provider has no verified email address."))
+		case "email already registered":
+			RespondWithError(c, NewAPIError(http.StatusConflict, ErrCodeEmailTaken, "An account with this email already exists. Log in with your password and confirm your email address first."))
+		case "mfa required":
+			respondMFARequired(c, err)
+		case "account locked":
+			respondAccountLocked(c, err)
+		case "account disabled":
+			RespondWithError(c, NewAPIError(http.StatusForbidden, ErrCodeAccountDisabled, "This account has been disabled."))
+		case "email not verified":
+			RespondWithError(c, NewAPIError(http.StatusForbidden, ErrCodeEmailNotVerified, "Please confirm your email address before logging in."))
+		default:
+			logger.Log.WithFields(logFields).Errorf("Unhandled social login error: %v", err)
+			RespondWithError(c, NewAPIError(http.StatusInternalServerError, ErrCodeInternalServer, "An error occurred during login. Please try again later."))
+		}
+		return
+	}
+
+	logger.Log.WithFields(logFields).Info("User logged in with social provider")
+	h.respondWithTokenPair(c, tokens, logFields)
+}
@@ -3,6 +3,7 @@ package api
 
 import (
 	"fmt"
+	"reflect"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -29,9 +30,17 @@ func getErrorMsg(fe validator.FieldError) string {
 	case "email":
 		return "Invalid email format"
 	case "min":
+		if fe.Kind() == reflect.Int {
+			return fmt.Sprintf("Should be at least %s", fe.Param())
+		}
 		return fmt.Sprintf("Should be at least %s characters long", fe.Param())
 	case "max":
+		if fe.Kind() == reflect.Int {
+			return fmt.Sprintf("Should be at most %s", fe.Param())
+		}
 		return fmt.Sprintf("Should be at most %s characters long", fe.Param())
+	case "oneof":
+		return fmt.Sprintf("Should be one of: %s", strings.ReplaceAll(fe.Param(), " ", ", "))
 	case "alphanum":
 		return "Should only contain alphanumeric characters"
 	// Tambahkan case lain sesuai kebutuhan tag validasi Anda
@@ -66,3 +75,18 @@ func ValidateAndBind(c *gin.Context, input interface{}) []ErrorMsg {
 	}
 	return nil // Tidak ada error
 }
+
+// ValidateAndBindQuery sama dengan ValidateAndBind, tetapi mem-bind query string (tag `form`)
+func ValidateAndBindQuery(c *gin.Context, input interface{}) []ErrorMsg {
+	if err := c.ShouldBindQuery(input); err != nil {
+		return []ErrorMsg{{Field: "query", Message: "Invalid query parameters: " + err.Error()}}
+	}
+	if err := validate.Struct(input); err != nil {
+		var errors []ErrorMsg
+		for _, fe := range err.(validator.ValidationErrors) {
+			errors = append(errors, ErrorMsg{Field: strings.ToLower(fe.Field()), Message: getErrorMsg(fe)})
+		}
+		return errors
+	}
+	return nil
+}
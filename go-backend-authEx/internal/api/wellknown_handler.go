@@ -0,0 +1,42 @@
+// internal/api/wellknown_handler.go
+package api
+
+import (
+	"net/http"
+
+	"go-auth-example/internal/auth"
+	"go-auth-example/internal/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WellKnownHandler melayani endpoint publik di bawah /.well-known
+type WellKnownHandler struct {
+	keyStore *auth.KeyStore
+}
+
+// NewWellKnownHandler constructor untuk WellKnownHandler
+func NewWellKnownHandler(keyStore *auth.KeyStore) *WellKnownHandler {
+	return &WellKnownHandler{keyStore: keyStore}
+}
+
+// JWKSHandler mempublikasikan public key untuk verifikasi JWT (GET /.well-known/jwks.json).
+// Key yang sedang dirotasi ikut dipublikasikan agar token lama maupun baru tetap bisa diverifikasi.
+func (h *WellKnownHandler) JWKSHandler(c *gin.Context) {
+	keySet := h.keyStore.Current()
+	if keySet == nil {
+		RespondWithError(c, NewAPIError(http.StatusServiceUnavailable, ErrCodeInternalServer, "Signing keys are not configured."))
+		return
+	}
+
+	jwks, err := keySet.PublicJWKS()
+	if err != nil {
+		logger.Log.WithField("handler", "JWKSHandler").Errorf("Error building JWKS: %v", err)
+		RespondWithError(c, NewAPIError(http.StatusInternalServerError, ErrCodeInternalServer, "Failed to load signing keys."))
+		return
+	}
+
+	// Verifier boleh men-cache JWKS sebentar; jangan terlalu lama agar key baru cepat terlihat
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, jwks)
+}
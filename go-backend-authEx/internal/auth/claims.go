@@ -0,0 +1,139 @@
+package auth
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Claims adalah isi access token JWT: registered claims standar ditambah klaim milik aplikasi.
+// Subject (sub) berisi ID user dalam bentuk string, sesuai RFC 7519.
+type Claims struct {
+	jwt.RegisteredClaims
+	Username string   `json:"username,omitempty"`
+	Email    string   `json:"email,omitempty"`
+	Roles    []string `json:"roles,omitempty"`
+	// Klaim untuk token yang diterbitkan lewat OAuth (RFC 9068)
+	ClientID string `json:"client_id,omitempty"`
+	Scope    string `json:"scope,omitempty"` // Dipisah spasi
+	// AuthTime adalah waktu user benar-benar login; tetap sama walaupun token dirotasi dengan refresh token
+	AuthTime *jwt.NumericDate `json:"auth_time,omitempty"`
+	// SessionID (sid) mengikat token first-party ke session login agar bisa dicabut per perangkat
+	SessionID string `json:"sid,omitempty"`
+	// OrganizationID adalah organisasi aktif session login (multi-tenancy); 0 jika user belum punya organisasi
+	OrganizationID int `json:"org_id,omitempty"`
+}
+
+// Cara pemanggil diautentikasi
+const (
+	AuthMethodJWT                 = "jwt" // Access token JWT (login first-party atau OAuth)
+	AuthMethodPersonalAccessToken = "pat" // Personal access token
+)
+
+// Principal adalah identitas pemanggil yang sudah terverifikasi, disimpan di context request
+type Principal struct {
+	Subject  string // Nilai mentah klaim sub
+	UserID   int    // 0 jika subject bukan ID user
+	Username string
+	Email    string
+	Roles    []string
+	// Permissions adalah gabungan permission dari Roles, diisi AuthMiddleware
+	Permissions []string
+	ClientID    string   // Diisi jika token diterbitkan untuk OAuth client
+	Scopes      []string // Kosong untuk token first-party (akses penuh)
+	TokenID     string   // Klaim jti
+	IssuedAt    time.Time
+	ExpiresAt   time.Time
+	AuthTime    time.Time // Waktu login; sama dengan IssuedAt untuk token tanpa klaim auth_time
+	// AuthMethod adalah salah satu konstanta AuthMethod*
+	AuthMethod string
+	// PersonalAccessTokenID diisi jika AuthMethod adalah AuthMethodPersonalAccessToken
+	PersonalAccessTokenID int64
+	SessionID             string // Kosong untuk token OAuth, personal access token dan token lama
+	OrganizationID        int    // Klaim org_id; hanya ada di token first-party
+}
+
+// IsUser menandakan principal mewakili user aplikasi (bukan subject lain)
+func (p *Principal) IsUser() bool {
+	return p.UserID > 0
+}
+
+// IsFirstParty menandakan token diterbitkan langsung oleh aplikasi ini saat user login,
+// bukan untuk OAuth client dan bukan personal access token
+func (p *Principal) IsFirstParty() bool {
+	return p.ClientID == "" && p.AuthMethod == AuthMethodJWT
+}
+
+// HasScope mengecek apakah principal boleh mengakses resource dengan scope tertentu.
+// Token first-party memiliki akses penuh; token lain dibatasi oleh scope-nya.
+func (p *Principal) HasScope(scope string) bool {
+	if p.IsFirstParty() {
+		return true
+	}
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// HasRole mengecek apakah principal memiliki role tertentu
+func (p *Principal) HasRole(role string) bool {
+	for _, r := range p.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// HasPermission mengecek apakah salah satu role principal memberikan permission tertentu
+func (p *Principal) HasPermission(permission string) bool {
+	for _, perm := range p.Permissions {
+		if perm == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// Principal membangun Principal dari claims yang sudah diverifikasi
+func (c *Claims) Principal() (*Principal, error) {
+	if c.Subject == "" {
+		return nil, errors.New("token has no subject")
+	}
+	if c.ExpiresAt == nil {
+		return nil, errors.New("token has no expiration time")
+	}
+
+	p := &Principal{
+		Subject:    c.Subject,
+		Username:   c.Username,
+		Email:      c.Email,
+		Roles:      c.Roles,
+		ClientID:   c.ClientID,
+		Scopes:     strings.Fields(c.Scope),
+		TokenID:    c.ID,
+		ExpiresAt:  c.ExpiresAt.Time,
+		AuthMethod: AuthMethodJWT,
+		SessionID:  c.SessionID,
+		// Klaim org_id hanya usulan; OrganizationMiddleware tetap memeriksa keanggotaan di setiap request
+		OrganizationID: c.OrganizationID,
+	}
+	if c.IssuedAt != nil {
+		p.IssuedAt = c.IssuedAt.Time
+	}
+	p.AuthTime = p.IssuedAt
+	if c.AuthTime != nil {
+		p.AuthTime = c.AuthTime.Time
+	}
+	// Subject numerik adalah ID user; subject lain (misal client_id pada client_credentials) dibiarkan apa adanya
+	if id, err := strconv.Atoi(c.Subject); err == nil && id > 0 && c.Subject != c.ClientID {
+		p.UserID = id
+	}
+	return p, nil
+}
@@ -0,0 +1,96 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// OIDCProfileClaims adalah klaim standar OpenID Connect yang isinya bergantung pada scope (profile, email)
+type OIDCProfileClaims struct {
+	PreferredUsername string `json:"preferred_username,omitempty"` // Scope profile
+	Email             string `json:"email,omitempty"`              // Scope email
+	EmailVerified     *bool  `json:"email_verified,omitempty"`     // Scope email
+}
+
+// IDTokenClaims adalah isi ID token OpenID Connect (OIDC Core section 2)
+type IDTokenClaims struct {
+	jwt.RegisteredClaims
+	OIDCProfileClaims
+	AuthTime *jwt.NumericDate `json:"auth_time,omitempty"`
+	Nonce    string           `json:"nonce,omitempty"`
+	AtHash   string           `json:"at_hash,omitempty"` // Mengikat ID token ke access token yang diterbitkan bersamanya
+}
+
+// IDTokenIssuer menerbitkan ID token OpenID Connect
+type IDTokenIssuer interface {
+	// IssueIDToken melengkapi iss, iat, exp dan at_hash (jika accessToken tidak kosong) lalu menandatangani ID token.
+	// Subject, audience (client_id), auth_time dan nonce diisi pemanggil.
+	IssueIDToken(claims IDTokenClaims, accessToken string) (string, error)
+	// CanIssueIDToken menandakan key aktif bisa menandatangani ID token
+	CanIssueIDToken() bool
+}
+
+// CanIssueIDToken bernilai false jika key aktif adalah HMAC, yang ditolak IssueIDToken
+func (s *JWTService) CanIssueIDToken() bool {
+	signingKey, err := s.signingKey()
+	return err == nil && signingKey.Algorithm != AlgHS256
+}
+
+// IssueIDToken menandatangani ID token dengan key aktif.
+// Key HMAC ditolak karena verifier pihak ketiga tidak bisa memverifikasinya lewat JWKS.
+func (s *JWTService) IssueIDToken(claims IDTokenClaims, accessToken string) (string, error) {
+	signingKey, err := s.signingKey()
+	if err != nil {
+		return "", err
+	}
+	if signingKey.Algorithm == AlgHS256 {
+		return "", errors.New("ID tokens require an asymmetric signing key (configure JWT_KEYS_FILE)")
+	}
+	if claims.Subject == "" || len(claims.Audience) == 0 {
+		return "", errors.New("ID token requires subject and audience")
+	}
+
+	now := time.Now()
+	claims.Issuer = s.config.Issuer
+	claims.IssuedAt = jwt.NewNumericDate(now)
+	claims.ExpiresAt = jwt.NewNumericDate(now.Add(s.config.TTL))
+	if accessToken != "" {
+		claims.AtHash, err = AccessTokenHash(accessToken, signingKey.Algorithm)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	token := jwt.NewWithClaims(signingKey.SigningMethod(), claims)
+	token.Header["kid"] = signingKey.ID
+
+	tokenString, err := token.SignedString(signingKey.PrivateKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign ID token: %w", err)
+	}
+	return tokenString, nil
+}
+
+// AccessTokenHash menghitung klaim at_hash: base64url dari separuh kiri hash access token,
+// dengan fungsi hash yang sesuai algoritma ID token (OIDC Core section 3.1.3.6)
+func AccessTokenHash(accessToken, alg string) (string, error) {
+	var sum []byte
+	switch alg {
+	case AlgHS256, AlgRS256, AlgES256:
+		h := sha256.Sum256([]byte(accessToken))
+		sum = h[:]
+	case AlgEdDSA:
+		// Ed25519 memakai SHA-512
+		h := sha512.Sum512([]byte(accessToken))
+		sum = h[:]
+	default:
+		return "", fmt.Errorf("unsupported algorithm for at_hash: %s", alg)
+	}
+	return base64.RawURLEncoding.EncodeToString(sum[:len(sum)/2]), nil
+}
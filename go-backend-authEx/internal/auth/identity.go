@@ -0,0 +1,21 @@
+package auth
+
+import (
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// NormalizeIdentity merapikan username atau email dari input user: spasi di awal/akhir dibuang dan
+// karakter Unicode diubah ke bentuk NFKC (misal huruf fullwidth menjadi huruf biasa).
+// Huruf besar/kecil dipertahankan; hasilnya yang disimpan dan ditampilkan.
+func NormalizeIdentity(value string) string {
+	return norm.NFKC.String(strings.TrimSpace(value))
+}
+
+// CanonicalIdentity menghasilkan kunci pembanding username atau email yang tidak peka huruf besar/kecil.
+// Dua nilai dengan CanonicalIdentity yang sama dianggap identitas yang sama.
+func CanonicalIdentity(value string) string {
+	// NFKC diulang karena lowercase bisa menghasilkan urutan karakter yang belum ternormalisasi
+	return norm.NFKC.String(strings.ToLower(NormalizeIdentity(value)))
+}
@@ -5,49 +5,175 @@ import (
 	"fmt"
 	"github.com/golang-jwt/jwt/v5"
 	"go-auth-example/internal/model"
-	"os"
+	"strconv"
 	"time"
 )
 
-var jwtSecretKey = []byte(os.Getenv("JWT_SECRET_KEY"))
-
-// GenerateJWT membuat token JWT baru untuk user
-func GenerateJWT(user model.User) (string, error) {
-	// Set standard claims
-	claims := jwt.MapClaims{
-		"sub": user.ID,         // Subject (biasanya ID user)
-		"iss": "your-app-name", // Issuer (nama aplikasi Anda)
-		// "aud": "your-audience", // Audience (siapa yang boleh menggunakan token ini) - Opsional
-		"exp": time.Now().Add(time.Hour * 1).Unix(), // Expiration time (1 jam dari sekarang)
-		"iat": time.Now().Unix(),                    // Issued at
-		"nbf": time.Now().Unix(),                    // Not before
+// DefaultAccessTokenTTL adalah masa berlaku access token JWT jika tidak dikonfigurasi
+const DefaultAccessTokenTTL = time.Hour * 1
+
+// TokenConfig berisi konfigurasi penerbitan dan verifikasi JWT per environment
+type TokenConfig struct {
+	Issuer   string        // Nilai klaim iss; juga diwajibkan saat verifikasi
+	Audience []string      // Nilai klaim aud; saat verifikasi token harus memuat salah satunya
+	TTL      time.Duration // Masa berlaku access token
+	Leeway   time.Duration // Toleransi perbedaan jam untuk exp/nbf/iat
+	// Algorithm adalah algoritma penandatanganan yang diharapkan untuk key aktif (kosong = ikuti key aktif)
+	Algorithm string
+	// AcceptedAlgorithms membatasi algoritma yang diterima saat verifikasi (kosong = semua algoritma di key set)
+	AcceptedAlgorithms []string
+}
+
+// IssuedToken adalah access token yang baru diterbitkan beserta metadata-nya
+type IssuedToken struct {
+	Token     string
+	ID        string // Klaim jti
+	ExpiresAt time.Time
+}
+
+// TokenIssuer menerbitkan access token
+type TokenIssuer interface {
+	// Issue menerbitkan access token first-party untuk user
+	Issue(user model.User) (*IssuedToken, error)
+	// IssueClaims menandatangani claims yang sudah disiapkan pemanggil (misal token OAuth).
+	// jti, iss, iat, nbf dan exp selalu diisi oleh issuer; aud diisi default jika kosong.
+	IssueClaims(claims Claims) (*IssuedToken, error)
+}
+
+// TokenVerifier memverifikasi access token dan mengembalikan claims-nya
+type TokenVerifier interface {
+	Verify(encodedToken string) (*Claims, error)
+}
+
+// JWTService mengimplementasikan TokenIssuer dan TokenVerifier menggunakan key dari KeyStore
+type JWTService struct {
+	config TokenConfig
+	keys   *KeyStore
+}
+
+// NewJWTService membuat JWTService dan memvalidasi konfigurasinya terhadap key aktif
+func NewJWTService(config TokenConfig, keys *KeyStore) (*JWTService, error) {
+	if config.Issuer == "" {
+		return nil, errors.New("token issuer must be configured")
+	}
+	if config.TTL <= 0 {
+		config.TTL = DefaultAccessTokenTTL
+	}
+	if config.Leeway < 0 {
+		return nil, errors.New("token leeway must not be negative")
+	}
+
+	s := &JWTService{config: config, keys: keys}
+	if _, err := s.signingKey(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// TTL mengembalikan masa berlaku access token yang diterbitkan
+func (s *JWTService) TTL() time.Duration {
+	return s.config.TTL
+}
+
+// signingKey mengambil key aktif dan memastikan algoritmanya sesuai konfigurasi
+func (s *JWTService) signingKey() (*SigningKey, error) {
+	keySet := s.keys.Current()
+	if keySet == nil {
+		return nil, errors.New("no signing key configured")
+	}
+	key := keySet.ActiveKey()
+	if s.config.Algorithm != "" && key.Algorithm != s.config.Algorithm {
+		return nil, fmt.Errorf("active signing key %q uses %s but %s is configured", key.ID, key.Algorithm, s.config.Algorithm)
+	}
+	return key, nil
+}
+
+// UserClaims menyiapkan claims access token first-party untuk user
+func UserClaims(user model.User) Claims {
+	return Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject: strconv.Itoa(user.ID), // Subject (ID user)
+		},
 		// Custom claims
-		"user_id":  user.ID,
-		"username": user.Username,
-		"email":    user.Email,
+		Username: user.Username,
+		Email:    user.Email,
+		Roles:    user.Roles,
+	}
+}
+
+// Issue membuat token JWT baru untuk user
+func (s *JWTService) Issue(user model.User) (*IssuedToken, error) {
+	return s.IssueClaims(UserClaims(user))
+}
+
+// IssueClaims melengkapi registered claims lalu menandatangani token dengan key aktif
+func (s *JWTService) IssueClaims(claims Claims) (*IssuedToken, error) {
+	signingKey, err := s.signingKey()
+	if err != nil {
+		return nil, err
+	}
+
+	// jti unik per token, dipakai untuk mencabut token sebelum kedaluwarsa (logout)
+	jti, err := GenerateOpaqueToken(16)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(s.config.TTL)
+
+	claims.ID = jti                                  // JWT ID
+	claims.Issuer = s.config.Issuer                  // Issuer (nama aplikasi / environment)
+	claims.ExpiresAt = jwt.NewNumericDate(expiresAt) // Expiration time
+	claims.IssuedAt = jwt.NewNumericDate(now)        // Issued at
+	claims.NotBefore = jwt.NewNumericDate(now)       // Not before
+	if len(claims.Audience) == 0 {
+		claims.Audience = s.config.Audience // Audience (siapa yang boleh menggunakan token ini)
 	}
 
-	// Buat token dengan claims
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	// Buat token dengan claims; kid memberi tahu verifier key mana yang dipakai
+	token := jwt.NewWithClaims(signingKey.SigningMethod(), claims)
+	token.Header["kid"] = signingKey.ID
 
-	// Tandatangani token dengan secret key
-	tokenString, err := token.SignedString(jwtSecretKey)
+	// Tandatangani token dengan key aktif
+	tokenString, err := token.SignedString(signingKey.PrivateKey)
 	if err != nil {
-		return "", fmt.Errorf("failed to sign token: %w", err)
+		return nil, fmt.Errorf("failed to sign token: %w", err)
 	}
 
-	return tokenString, nil
+	return &IssuedToken{Token: tokenString, ID: jti, ExpiresAt: expiresAt}, nil
 }
 
-// validateToken memvalidasi token JWT dari header Authorization
-func ValidateToken(encodedToken string) (*jwt.Token, error) {
-	token, err := jwt.Parse(encodedToken, func(token *jwt.Token) (interface{}, error) {
-		// Validasi signing method
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+// Verify memvalidasi token JWT dari header Authorization, termasuk iss dan aud
+func (s *JWTService) Verify(encodedToken string) (*Claims, error) {
+	keySet := s.keys.Current()
+	if keySet == nil {
+		return nil, errors.New("no verification key configured")
+	}
+
+	options := []jwt.ParserOption{
+		jwt.WithIssuer(s.config.Issuer),
+		jwt.WithLeeway(s.config.Leeway),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	}
+	if len(s.config.AcceptedAlgorithms) > 0 {
+		options = append(options, jwt.WithValidMethods(s.config.AcceptedAlgorithms))
+	}
+
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(encodedToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, ok := keySet.Key(kid)
+		if !ok {
+			return nil, fmt.Errorf("unknown signing key: %q", kid)
+		}
+		// Validasi signing method: alg di header harus sama dengan algoritma key (mencegah alg confusion)
+		if token.Method.Alg() != key.Algorithm {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
-		return jwtSecretKey, nil
-	})
+		return key.PublicKey, nil
+	}, options...)
 
 	if err != nil {
 		return nil, fmt.Errorf("invalid token: %w", err)
@@ -57,5 +183,21 @@ func ValidateToken(encodedToken string) (*jwt.Token, error) {
 		return nil, errors.New("invalid token")
 	}
 
-	return token, nil
+	// jwt.WithAudience hanya menerima satu nilai, jadi aud dicek manual: cukup satu yang cocok
+	if len(s.config.Audience) > 0 && !containsAny(claims.Audience, s.config.Audience) {
+		return nil, errors.New("invalid token: token has invalid audience")
+	}
+
+	return claims, nil
+}
+
+func containsAny(values []string, wanted []string) bool {
+	for _, v := range values {
+		for _, w := range wanted {
+			if v == w {
+				return true
+			}
+		}
+	}
+	return false
 }
@@ -0,0 +1,288 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync/atomic"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Algoritma penandatanganan yang didukung
+const (
+	AlgHS256 = "HS256" // Legacy, shared secret (tidak dipublikasikan di JWKS)
+	AlgRS256 = "RS256"
+	AlgES256 = "ES256"
+	AlgEdDSA = "EdDSA"
+)
+
+// legacyHMACKeyID dipakai untuk key HS256 dari JWT_SECRET_KEY; token lama tidak memiliki header kid
+const legacyHMACKeyID = "hs256-legacy"
+
+// SigningKey adalah satu key dalam key set, diidentifikasi oleh kid
+type SigningKey struct {
+	ID         string
+	Algorithm  string
+	PrivateKey crypto.PrivateKey // *rsa.PrivateKey, *ecdsa.PrivateKey, ed25519.PrivateKey atau []byte (HMAC)
+	PublicKey  crypto.PublicKey  // Untuk HMAC sama dengan secret-nya
+	CreatedAt  time.Time
+	RetiredAt  *time.Time // Diisi saat key tidak lagi aktif; tetap dipakai untuk verifikasi sampai di-prune
+}
+
+// SigningMethod mengembalikan jwt.SigningMethod yang sesuai dengan algoritma key
+func (k *SigningKey) SigningMethod() jwt.SigningMethod {
+	switch k.Algorithm {
+	case AlgRS256:
+		return jwt.SigningMethodRS256
+	case AlgES256:
+		return jwt.SigningMethodES256
+	case AlgEdDSA:
+		return jwt.SigningMethodEdDSA
+	default:
+		return jwt.SigningMethodHS256
+	}
+}
+
+// KeySet berisi satu key aktif untuk menandatangani dan beberapa key untuk verifikasi selama rotasi
+type KeySet struct {
+	activeID string
+	keys     map[string]*SigningKey
+}
+
+// NewKeySet membuat key set dari daftar key; activeID harus ada di dalam daftar
+func NewKeySet(activeID string, keys ...*SigningKey) (*KeySet, error) {
+	ks := &KeySet{activeID: activeID, keys: make(map[string]*SigningKey, len(keys))}
+	for _, k := range keys {
+		if _, exists := ks.keys[k.ID]; exists {
+			return nil, fmt.Errorf("duplicate key id %q", k.ID)
+		}
+		ks.keys[k.ID] = k
+	}
+	if _, ok := ks.keys[activeID]; !ok {
+		return nil, fmt.Errorf("active key %q not found in key set", activeID)
+	}
+	return ks, nil
+}
+
+// NewHMACKeySet membuat key set HS256 dari shared secret (mode lama)
+func NewHMACKeySet(secret []byte) *KeySet {
+	ks, _ := NewKeySet(legacyHMACKeyID, NewHMACKey(secret))
+	return ks
+}
+
+// NewHMACKey membungkus shared secret JWT_SECRET_KEY sebagai SigningKey HS256
+func NewHMACKey(secret []byte) *SigningKey {
+	return &SigningKey{ID: legacyHMACKeyID, Algorithm: AlgHS256, PrivateKey: secret, PublicKey: secret}
+}
+
+// WithVerificationKey mengembalikan salinan key set dengan tambahan key yang hanya dipakai untuk verifikasi.
+// Berguna saat migrasi dari HS256 agar token lama tetap valid sampai kedaluwarsa.
+func (ks *KeySet) WithVerificationKey(key *SigningKey) *KeySet {
+	clone := &KeySet{activeID: ks.activeID, keys: make(map[string]*SigningKey, len(ks.keys)+1)}
+	for id, k := range ks.keys {
+		clone.keys[id] = k
+	}
+	if _, exists := clone.keys[key.ID]; !exists {
+		clone.keys[key.ID] = key
+	}
+	return clone
+}
+
+// ActiveKey mengembalikan key yang dipakai untuk menandatangani token baru
+func (ks *KeySet) ActiveKey() *SigningKey {
+	return ks.keys[ks.activeID]
+}
+
+// Key mencari key verifikasi berdasarkan kid. Token tanpa kid dianggap token HS256 lama.
+func (ks *KeySet) Key(kid string) (*SigningKey, bool) {
+	if kid == "" {
+		kid = legacyHMACKeyID
+	}
+	k, ok := ks.keys[kid]
+	return k, ok
+}
+
+// Keys mengembalikan semua key di dalam key set
+func (ks *KeySet) Keys() []*SigningKey {
+	keys := make([]*SigningKey, 0, len(ks.keys))
+	for _, k := range ks.keys {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// KeyStore menyimpan key set yang sedang dipakai dan memungkinkan penggantian saat runtime (reload setelah rotasi)
+type KeyStore struct {
+	current atomic.Pointer[KeySet]
+}
+
+// NewKeyStore membuat KeyStore dengan key set awal
+func NewKeyStore(ks *KeySet) *KeyStore {
+	store := &KeyStore{}
+	store.Set(ks)
+	return store
+}
+
+// Set mengganti key set yang dipakai untuk menandatangani dan memverifikasi token
+func (s *KeyStore) Set(ks *KeySet) {
+	s.current.Store(ks)
+}
+
+// Current mengembalikan key set yang sedang dipakai
+func (s *KeyStore) Current() *KeySet {
+	return s.current.Load()
+}
+
+// GenerateSigningKey membuat key pair baru untuk algoritma asimetris yang diberikan
+func GenerateSigningKey(alg string) (*SigningKey, error) {
+	kid, err := GenerateOpaqueToken(12)
+	if err != nil {
+		return nil, err
+	}
+	key := &SigningKey{ID: kid, Algorithm: alg, CreatedAt: time.Now().UTC()}
+
+	switch alg {
+	case AlgRS256:
+		priv, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate RSA key: %w", err)
+		}
+		key.PrivateKey, key.PublicKey = priv, &priv.PublicKey
+	case AlgES256:
+		priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate ECDSA key: %w", err)
+		}
+		key.PrivateKey, key.PublicKey = priv, &priv.PublicKey
+	case AlgEdDSA:
+		pub, priv, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate Ed25519 key: %w", err)
+		}
+		key.PrivateKey, key.PublicKey = priv, pub
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm %q", alg)
+	}
+	return key, nil
+}
+
+// EncodePrivateKeyPEM meng-encode private key asimetris ke PEM PKCS#8
+func EncodePrivateKeyPEM(key *SigningKey) (string, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key.PrivateKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal private key: %w", err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})), nil
+}
+
+// ParsePrivateKeyPEM membaca private key PEM PKCS#8 dan memastikan tipenya cocok dengan algoritma
+func ParsePrivateKeyPEM(kid, alg, pemData string) (*SigningKey, error) {
+	block, _ := pem.Decode([]byte(pemData))
+	if block == nil {
+		return nil, fmt.Errorf("key %q: invalid PEM data", kid)
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("key %q: failed to parse private key: %w", kid, err)
+	}
+
+	key := &SigningKey{ID: kid, Algorithm: alg, PrivateKey: parsed}
+	switch priv := parsed.(type) {
+	case *rsa.PrivateKey:
+		if alg != AlgRS256 {
+			return nil, fmt.Errorf("key %q: RSA key cannot be used with %s", kid, alg)
+		}
+		key.PublicKey = &priv.PublicKey
+	case *ecdsa.PrivateKey:
+		if alg != AlgES256 || priv.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("key %q: ECDSA key must use P-256 with ES256", kid)
+		}
+		key.PublicKey = &priv.PublicKey
+	case ed25519.PrivateKey:
+		if alg != AlgEdDSA {
+			return nil, fmt.Errorf("key %q: Ed25519 key cannot be used with %s", kid, alg)
+		}
+		key.PublicKey = priv.Public()
+	default:
+		return nil, fmt.Errorf("key %q: unsupported private key type %T", kid, parsed)
+	}
+	return key, nil
+}
+
+// JWK adalah representasi public key dalam format JSON Web Key (RFC 7517)
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// EC dan OKP
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+	Y     string `json:"y,omitempty"`
+}
+
+// JWKS adalah kumpulan JWK yang dipublikasikan di /.well-known/jwks.json
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// PublicJWKS mengembalikan semua public key asimetris di key set. Key HMAC tidak pernah dipublikasikan.
+func (ks *KeySet) PublicJWKS() (JWKS, error) {
+	jwks := JWKS{Keys: []JWK{}}
+	for _, k := range ks.keys {
+		if k.Algorithm == AlgHS256 {
+			continue
+		}
+		jwk, err := publicJWK(k)
+		if err != nil {
+			return JWKS{}, err
+		}
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+	return jwks, nil
+}
+
+func publicJWK(k *SigningKey) (JWK, error) {
+	b64 := base64.RawURLEncoding.EncodeToString
+	jwk := JWK{KeyID: k.ID, Use: "sig", Algorithm: k.Algorithm}
+
+	switch pub := k.PublicKey.(type) {
+	case *rsa.PublicKey:
+		jwk.KeyType = "RSA"
+		jwk.N = b64(pub.N.Bytes())
+		jwk.E = b64(big.NewInt(int64(pub.E)).Bytes())
+	case *ecdsa.PublicKey:
+		ecdhKey, err := pub.ECDH()
+		if err != nil {
+			return JWK{}, fmt.Errorf("key %q: %w", k.ID, err)
+		}
+		// Format uncompressed: 0x04 || X || Y
+		raw := ecdhKey.Bytes()
+		size := (len(raw) - 1) / 2
+		jwk.KeyType = "EC"
+		jwk.Curve = "P-256"
+		jwk.X = b64(raw[1 : 1+size])
+		jwk.Y = b64(raw[1+size:])
+	case ed25519.PublicKey:
+		jwk.KeyType = "OKP"
+		jwk.Curve = "Ed25519"
+		jwk.X = b64(pub)
+	default:
+		return JWK{}, errors.New("unsupported public key type")
+	}
+	return jwk, nil
+}
@@ -0,0 +1,198 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// KeySetFile adalah format file key set di disk (JWT_KEYS_FILE).
+// File ini berisi private key, jadi harus disimpan dengan permission 0600.
+//
+// Alur rotasi tanpa downtime:
+//  1. "keygen add" menambah key baru (belum aktif) -> dipublikasikan di JWKS agar verifier sempat mengambilnya
+//  2. "keygen promote" menjadikan key baru aktif -> token baru ditandatangani key ini, key lama masih dipakai verifikasi
+//  3. "keygen prune" menghapus key lama setelah semua token yang ditandatanganinya kedaluwarsa
+//
+// Server membaca ulang file ini saat menerima SIGHUP.
+type KeySetFile struct {
+	ActiveKeyID string         `json:"active_kid"`
+	Keys        []KeyFileEntry `json:"keys"`
+}
+
+// KeyFileEntry adalah satu key di dalam KeySetFile
+type KeyFileEntry struct {
+	KeyID      string     `json:"kid"`
+	Algorithm  string     `json:"alg"`
+	PrivateKey string     `json:"private_key"` // PEM PKCS#8
+	CreatedAt  time.Time  `json:"created_at"`
+	RetiredAt  *time.Time `json:"retired_at,omitempty"`
+}
+
+// ReadKeySetFile membaca file key set dari disk
+func ReadKeySetFile(path string) (*KeySetFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key set file: %w", err)
+	}
+	var f KeySetFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse key set file: %w", err)
+	}
+	return &f, nil
+}
+
+// Save menulis file key set secara atomik (tulis ke file sementara lalu rename)
+func (f *KeySetFile) Save(path string) error {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode key set file: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".keyset-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary key set file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to set key set file permissions: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write key set file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write key set file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace key set file: %w", err)
+	}
+	return nil
+}
+
+// AddKey membuat key baru dengan algoritma yang diberikan. Key baru belum aktif kecuali file masih kosong.
+func (f *KeySetFile) AddKey(alg string) (*KeyFileEntry, error) {
+	key, err := GenerateSigningKey(alg)
+	if err != nil {
+		return nil, err
+	}
+	pemData, err := EncodePrivateKeyPEM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	f.Keys = append(f.Keys, KeyFileEntry{
+		KeyID:      key.ID,
+		Algorithm:  key.Algorithm,
+		PrivateKey: pemData,
+		CreatedAt:  key.CreatedAt,
+	})
+	if f.ActiveKeyID == "" {
+		f.ActiveKeyID = key.ID
+	}
+	return &f.Keys[len(f.Keys)-1], nil
+}
+
+// Promote menjadikan key dengan kid tertentu sebagai key aktif dan menandai key aktif sebelumnya sebagai retired
+func (f *KeySetFile) Promote(kid string) error {
+	entry := f.find(kid)
+	if entry == nil {
+		return fmt.Errorf("key %q not found", kid)
+	}
+	if entry.RetiredAt != nil {
+		return fmt.Errorf("key %q has been retired and cannot be promoted", kid)
+	}
+	if f.ActiveKeyID == kid {
+		return nil
+	}
+
+	if previous := f.find(f.ActiveKeyID); previous != nil {
+		now := time.Now().UTC()
+		previous.RetiredAt = &now
+	}
+	f.ActiveKeyID = kid
+	return nil
+}
+
+// NewestInactiveKey mengembalikan key non-aktif terbaru yang belum retired (kandidat untuk promote)
+func (f *KeySetFile) NewestInactiveKey() (*KeyFileEntry, error) {
+	var newest *KeyFileEntry
+	for i := range f.Keys {
+		k := &f.Keys[i]
+		if k.KeyID == f.ActiveKeyID || k.RetiredAt != nil {
+			continue
+		}
+		if newest == nil || k.CreatedAt.After(newest.CreatedAt) {
+			newest = k
+		}
+	}
+	if newest == nil {
+		return nil, errors.New("no pending key to promote; run 'add' first")
+	}
+	return newest, nil
+}
+
+// Prune menghapus key yang sudah retired lebih lama dari olderThan. Mengembalikan kid yang dihapus.
+func (f *KeySetFile) Prune(olderThan time.Duration) []string {
+	cutoff := time.Now().Add(-olderThan)
+	var removed []string
+	kept := f.Keys[:0]
+	for _, k := range f.Keys {
+		if k.KeyID != f.ActiveKeyID && k.RetiredAt != nil && k.RetiredAt.Before(cutoff) {
+			removed = append(removed, k.KeyID)
+			continue
+		}
+		kept = append(kept, k)
+	}
+	f.Keys = kept
+	return removed
+}
+
+// SortedKeys mengembalikan key diurutkan dari yang terlama
+func (f *KeySetFile) SortedKeys() []KeyFileEntry {
+	keys := append([]KeyFileEntry(nil), f.Keys...)
+	sort.Slice(keys, func(i, j int) bool { return keys[i].CreatedAt.Before(keys[j].CreatedAt) })
+	return keys
+}
+
+// KeySet mengubah isi file menjadi KeySet yang siap dipakai
+func (f *KeySetFile) KeySet() (*KeySet, error) {
+	if f.ActiveKeyID == "" {
+		return nil, errors.New("key set file has no active key")
+	}
+	keys := make([]*SigningKey, 0, len(f.Keys))
+	for _, entry := range f.Keys {
+		key, err := ParsePrivateKeyPEM(entry.KeyID, entry.Algorithm, entry.PrivateKey)
+		if err != nil {
+			return nil, err
+		}
+		key.CreatedAt = entry.CreatedAt
+		key.RetiredAt = entry.RetiredAt
+		keys = append(keys, key)
+	}
+	return NewKeySet(f.ActiveKeyID, keys...)
+}
+
+func (f *KeySetFile) find(kid string) *KeyFileEntry {
+	for i := range f.Keys {
+		if f.Keys[i].KeyID == kid {
+			return &f.Keys[i]
+		}
+	}
+	return nil
+}
+
+// LoadKeySetFile membaca file key set dan mengubahnya menjadi KeySet
+func LoadKeySetFile(path string) (*KeySet, error) {
+	f, err := ReadKeySetFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return f.KeySet()
+}
@@ -0,0 +1,34 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"regexp"
+)
+
+// PKCEMethodS256 adalah satu-satunya code_challenge_method yang diterima (metode "plain" tidak didukung)
+const PKCEMethodS256 = "S256"
+
+// pkceValuePattern: 43-128 karakter unreserved sesuai RFC 7636 section 4.1
+var pkceValuePattern = regexp.MustCompile(`^[A-Za-z0-9\-._~]{43,128}$`)
+
+// ValidPKCEValue mengecek format code_verifier atau code_challenge
+func ValidPKCEValue(value string) bool {
+	return pkceValuePattern.MatchString(value)
+}
+
+// PKCEChallengeS256 menghitung code_challenge dari code_verifier: BASE64URL(SHA256(verifier))
+func PKCEChallengeS256(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// VerifyPKCE mengecek code_verifier terhadap code_challenge yang disimpan saat authorization request
+func VerifyPKCE(verifier, challenge, method string) bool {
+	if method != PKCEMethodS256 || !ValidPKCEValue(verifier) {
+		return false
+	}
+	computed := PKCEChallengeS256(verifier)
+	return subtle.ConstantTimeCompare([]byte(computed), []byte(challenge)) == 1
+}
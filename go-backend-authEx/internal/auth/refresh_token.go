@@ -0,0 +1,57 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// GenerateOpaqueToken membuat token acak (base64url) dengan panjang byteLen byte
+func GenerateOpaqueToken(byteLen int) (string, error) {
+	b := make([]byte, byteLen)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate random token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// GenerateRefreshToken membuat refresh token baru beserta hash-nya untuk disimpan di database
+func GenerateRefreshToken() (token string, tokenHash string, err error) {
+	token, err = GenerateOpaqueToken(32)
+	if err != nil {
+		return "", "", err
+	}
+	return token, HashToken(token), nil
+}
+
+// HashToken menghitung hash SHA-256 (hex) dari token opaque.
+// Token opaque memiliki entropi tinggi, jadi hash cepat sudah cukup (tidak perlu bcrypt).
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// PersonalAccessTokenPrefix menandai personal access token sehingga middleware bisa membedakannya dari JWT
+// dan secret scanner bisa mengenalinya
+const PersonalAccessTokenPrefix = "pat_"
+
+// personalAccessTokenDisplayLen adalah panjang awal token yang disimpan apa adanya untuk ditampilkan di daftar token
+const personalAccessTokenDisplayLen = len(PersonalAccessTokenPrefix) + 8
+
+// GeneratePersonalAccessToken membuat personal access token baru, prefix yang boleh ditampilkan, dan hash-nya
+func GeneratePersonalAccessToken() (token string, displayPrefix string, tokenHash string, err error) {
+	random, err := GenerateOpaqueToken(32)
+	if err != nil {
+		return "", "", "", err
+	}
+	token = PersonalAccessTokenPrefix + random
+	return token, token[:personalAccessTokenDisplayLen], HashToken(token), nil
+}
+
+// IsPersonalAccessToken mengecek apakah bearer token berformat personal access token
+func IsPersonalAccessToken(token string) bool {
+	return strings.HasPrefix(token, PersonalAccessTokenPrefix)
+}
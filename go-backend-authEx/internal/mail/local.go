@@ -0,0 +1,97 @@
+// internal/mail/local.go
+package mail
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"go-auth-example/internal/logger"
+
+	"github.com/sirupsen/logrus"
+)
+
+// FileMailer menyimpan setiap email sebagai file .eml di sebuah direktori (untuk development)
+type FileMailer struct {
+	dir  string
+	from string
+}
+
+// NewFileMailer constructor untuk FileMailer; direktori dibuat jika belum ada
+func NewFileMailer(dir, from string) (*FileMailer, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("could not create mail directory: %w", err)
+	}
+	return &FileMailer{dir: dir, from: from}, nil
+}
+
+// Send menulis pesan ke file baru
+func (m *FileMailer) Send(msg Message) error {
+	if err := msg.validate(); err != nil {
+		return err
+	}
+
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return fmt.Errorf("could not name mail file: %w", err)
+	}
+	now := time.Now()
+	name := fmt.Sprintf("%s-%s.eml", now.UTC().Format("20060102T150405.000"), hex.EncodeToString(suffix))
+	path := filepath.Join(m.dir, name)
+	if err := os.WriteFile(path, msg.render(m.from, now), 0o600); err != nil {
+		return fmt.Errorf("could not write mail file: %w", err)
+	}
+	logger.Log.WithFields(logrus.Fields{"to": msg.To, "file": path}).Info("Mail written to file.")
+	return nil
+}
+
+// LogMailer hanya mencatat isi email ke log aplikasi (untuk development).
+// Jangan dipakai di production: link di dalam email ikut tercatat di log.
+type LogMailer struct{}
+
+// NewLogMailer constructor untuk LogMailer
+func NewLogMailer() *LogMailer {
+	return &LogMailer{}
+}
+
+// Send mencatat pesan ke log
+func (m *LogMailer) Send(msg Message) error {
+	if err := msg.validate(); err != nil {
+		return err
+	}
+	logger.Log.WithFields(logrus.Fields{"to": msg.To, "subject": msg.Subject}).Infof("Mail not sent (log mailer):\n%s", msg.Body)
+	return nil
+}
+
+// MemoryMailer menyimpan email di memori, berguna untuk pengujian dan demo
+type MemoryMailer struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+// NewMemoryMailer constructor untuk MemoryMailer
+func NewMemoryMailer() *MemoryMailer {
+	return &MemoryMailer{}
+}
+
+// Send menyimpan pesan
+func (m *MemoryMailer) Send(msg Message) error {
+	if err := msg.validate(); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = append(m.messages, msg)
+	return nil
+}
+
+// Messages mengembalikan salinan semua pesan yang sudah dikirim
+func (m *MemoryMailer) Messages() []Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Message(nil), m.messages...)
+}
@@ -0,0 +1,51 @@
+// internal/mail/smtp.go
+package mail
+
+import (
+	"fmt"
+	"net"
+	"net/smtp"
+	"time"
+)
+
+// SMTPConfig berisi alamat server SMTP dan kredensialnya
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string // Kosong = tanpa autentikasi
+	Password string
+	From     string
+}
+
+// SMTPMailer mengirim email lewat server SMTP. STARTTLS dipakai otomatis jika didukung server.
+type SMTPMailer struct {
+	config SMTPConfig
+}
+
+// NewSMTPMailer constructor untuk SMTPMailer
+func NewSMTPMailer(config SMTPConfig) (*SMTPMailer, error) {
+	if config.Host == "" || config.From == "" {
+		return nil, fmt.Errorf("SMTP host and sender address are required")
+	}
+	if config.Port == "" {
+		config.Port = "587"
+	}
+	return &SMTPMailer{config: config}, nil
+}
+
+// Send mengirim pesan ke server SMTP
+func (m *SMTPMailer) Send(msg Message) error {
+	if err := msg.validate(); err != nil {
+		return err
+	}
+
+	var smtpAuth smtp.Auth
+	if m.config.Username != "" {
+		smtpAuth = smtp.PlainAuth("", m.config.Username, m.config.Password, m.config.Host)
+	}
+	addr := net.JoinHostPort(m.config.Host, m.config.Port)
+	if err := smtp.SendMail(addr, smtpAuth, m.config.From, []string{msg.To}, msg.render(m.config.From, time.Now())); err != nil {
+		return fmt.Errorf("could not send mail via SMTP: %w", err)
+	}
+	return nil
+}
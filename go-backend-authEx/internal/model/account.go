@@ -0,0 +1,21 @@
+// internal/model/account.go
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Input untuk menghapus akun; password diminta ulang sebagai konfirmasi.
+// Akun tanpa password mengosongkan field ini dan harus baru saja login.
+type DeleteAccountInput struct {
+	Password string `json:"password"`
+}
+
+// AccountExport adalah arsip semua data yang disimpan tentang user (hak akses data pribadi).
+// Hash token, secret dan password tidak pernah ikut diekspor.
+type AccountExport struct {
+	ExportedAt time.Time                  `json:"exported_at"`
+	User       *User                      `json:"user"`
+	Records    map[string]json.RawMessage `json:"records"` // Data terkait per tabel, misal "sessions"
+}
@@ -0,0 +1,43 @@
+// internal/model/admin.go
+package model
+
+import "time"
+
+// Kolom yang bisa dipakai untuk mengurutkan daftar user
+const (
+	UserSortCreatedAt = "created_at"
+	UserSortEmail     = "email"
+	UserSortUsername  = "username"
+	UserSortStatus    = "status"
+)
+
+// UserListQuery adalah filter, urutan dan halaman daftar user (query string GET /api/admin/users).
+// Filter email dan username mencocokkan sebagian teks tanpa peka huruf besar/kecil.
+type UserListQuery struct {
+	Email         string     `form:"email" validate:"max=255"`
+	Username      string     `form:"username" validate:"max=30"`
+	Status        string     `form:"status" validate:"omitempty,oneof=active disabled pending_deletion"`
+	CreatedAfter  *time.Time `form:"created_after"` // RFC 3339
+	CreatedBefore *time.Time `form:"created_before"`
+	Sort          string     `form:"sort" validate:"omitempty,oneof=created_at email username status"` // Default created_at
+	Order         string     `form:"order" validate:"omitempty,oneof=asc desc"`                        // Default desc
+	Page          int        `form:"page" validate:"omitempty,min=1"`                                  // Mulai dari 1
+	PerPage       int        `form:"per_page" validate:"omitempty,min=1,max=100"`
+}
+
+// UserList adalah satu halaman hasil UserListQuery
+type UserList struct {
+	Users      []User `json:"users"`
+	Page       int    `json:"page"`
+	PerPage    int    `json:"per_page"`
+	Total      int    `json:"total"` // Jumlah user yang cocok dengan filter di semua halaman
+	TotalPages int    `json:"total_pages"`
+}
+
+// UserDetail adalah data satu user beserta keamanan akunnya, untuk halaman detail admin
+type UserDetail struct {
+	User       *User      `json:"user"` // Roles ikut diisi
+	MFA        *MFAStatus `json:"mfa"`
+	Sessions   []Session  `json:"sessions"`   // Session login yang masih aktif
+	Identities []Identity `json:"identities"` // Akun login sosial yang terhubung
+}
@@ -0,0 +1,47 @@
+// internal/model/email_token.go
+package model
+
+import "time"
+
+// Tujuan token email; token hanya bisa dipakai untuk tujuan yang sama saat dibuat
+const (
+	EmailTokenPurposeVerifyEmail   = "verify_email"
+	EmailTokenPurposeResetPassword = "reset_password"
+	EmailTokenPurposeChangeEmail   = "change_email" // Email pada token berisi alamat baru
+	EmailTokenPurposeMagicLogin    = "magic_login"
+)
+
+// EmailToken adalah token sekali pakai yang dikirim ke alamat email user.
+// Token asli hanya ada di email; yang disimpan hanya hash SHA-256-nya.
+type EmailToken struct {
+	ID        int64  `json:"id"`
+	UserID    int    `json:"user_id"`
+	Purpose   string `json:"purpose"`
+	Email     string `json:"email"` // Alamat tujuan token; token tidak berlaku lagi jika email user sudah berubah
+	TokenHash string `json:"-"`
+	// BindingHash adalah hash nilai acak di cookie browser yang meminta token; kosong jika token tidak terikat browser
+	BindingHash string     `json:"-"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UsedAt      *time.Time `json:"used_at,omitempty"`
+}
+
+// Input untuk verifikasi email
+type VerifyEmailInput struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// Input untuk meminta link login lewat email
+type MagicLinkRequestInput struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// Input untuk login dengan token dari link email
+type ConsumeMagicLinkInput struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// Input untuk mengirim ulang email verifikasi
+type ResendVerificationInput struct {
+	Email string `json:"email" validate:"required,email"`
+}
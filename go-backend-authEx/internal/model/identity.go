@@ -0,0 +1,45 @@
+// internal/model/identity.go
+package model
+
+import "time"
+
+// Identity menghubungkan akun di identity provider eksternal (Google, GitHub, OIDC) dengan user lokal.
+// Satu user bisa punya beberapa identity, tapi satu akun provider hanya terhubung ke satu user.
+type Identity struct {
+	ID         int64      `json:"id"`
+	UserID     int        `json:"-"`
+	Provider   string     `json:"provider"`
+	Subject    string     `json:"-"`     // ID user di provider; tidak berubah walaupun email/username di provider berubah
+	Email      string     `json:"email"` // Email dari provider saat identity dihubungkan, hanya informasi
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
+
+// SocialLoginState menyimpan state, PKCE code_verifier dan nonce satu login sosial
+// di antara redirect ke provider dan callback. Yang disimpan hanya hash dari state.
+type SocialLoginState struct {
+	ID           int64
+	StateHash    string
+	Provider     string
+	CodeVerifier string
+	Nonce        string
+	ExpiresAt    time.Time
+	CreatedAt    time.Time
+}
+
+// SocialProvider adalah provider login sosial yang aktif, untuk ditampilkan sebagai tombol di halaman login
+type SocialProvider struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+}
+
+// SocialLoginStart dikembalikan saat memulai login sosial; browser diarahkan ke AuthorizationURL
+type SocialLoginStart struct {
+	AuthorizationURL string `json:"authorization_url"`
+}
+
+// Input untuk menyelesaikan login sosial dengan parameter yang dikirim provider ke halaman callback frontend
+type SocialCallbackInput struct {
+	Code  string `json:"code" validate:"required,max=2048"`
+	State string `json:"state" validate:"required,max=256"`
+}
@@ -0,0 +1,67 @@
+// internal/model/mfa.go
+package model
+
+import "time"
+
+// Metode MFA yang bisa dipakai untuk menyelesaikan login
+const (
+	MFAMethodTOTP         = "totp"
+	MFAMethodRecoveryCode = "recovery_code"
+)
+
+// TOTPFactor adalah secret TOTP milik user. Secret belum aktif sebelum ConfirmedAt terisi.
+type TOTPFactor struct {
+	UserID       int
+	Secret       string // Base32; dibutuhkan dalam bentuk asli untuk menghitung kode, jadi tidak di-hash
+	ConfirmedAt  *time.Time
+	LastUsedStep int64 // Periode TOTP terakhir yang dipakai; kode dari periode yang sama atau lebih lama ditolak
+	CreatedAt    time.Time
+}
+
+// MFAChallenge adalah tahap kedua login: dibuat setelah password benar untuk user yang mengaktifkan MFA.
+// Token asli hanya dikirim ke client; yang disimpan hanya hash SHA-256-nya.
+type MFAChallenge struct {
+	ID        int64
+	UserID    int
+	TokenHash string
+	AuthTime  time.Time // Waktu password diverifikasi
+	Attempts  int
+	ExpiresAt time.Time
+	CreatedAt time.Time
+}
+
+// MFAStatus adalah ringkasan MFA user untuk halaman pengaturan
+type MFAStatus struct {
+	Enabled                bool       `json:"enabled"`
+	EnabledAt              *time.Time `json:"enabled_at,omitempty"`
+	RecoveryCodesRemaining int        `json:"recovery_codes_remaining"`
+}
+
+// TOTPEnrollment dikembalikan saat pendaftaran TOTP dimulai; secret hanya ditampilkan sekali
+type TOTPEnrollment struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+// Input untuk menyelesaikan login dengan kode TOTP atau kode pemulihan
+type MFAVerifyInput struct {
+	MFAToken string `json:"mfa_token" validate:"required"`
+	Code     string `json:"code" validate:"required,max=32"`
+}
+
+// Input untuk mengaktifkan TOTP dengan kode pertama dari aplikasi authenticator
+type ConfirmTOTPInput struct {
+	Code string `json:"code" validate:"required,len=6,numeric"`
+}
+
+// Input untuk menonaktifkan MFA. Code boleh berupa kode TOTP atau kode pemulihan.
+// Password kosong hanya diterima untuk akun tanpa password yang baru saja login.
+type DisableMFAInput struct {
+	Password string `json:"password"`
+	Code     string `json:"code" validate:"required,max=32"`
+}
+
+// Input untuk membuat ulang kode pemulihan; aturan password sama dengan DisableMFAInput
+type RegenerateRecoveryCodesInput struct {
+	Password string `json:"password"`
+}
@@ -0,0 +1,104 @@
+// internal/model/oauth.go
+package model
+
+import "time"
+
+// Grant type OAuth 2.1 yang didukung
+const (
+	GrantTypeAuthorizationCode = "authorization_code"
+	GrantTypeRefreshToken      = "refresh_token"
+	GrantTypeClientCredentials = "client_credentials"
+)
+
+// OAuthClient adalah aplikasi yang terdaftar untuk memakai server ini sebagai authorization server
+type OAuthClient struct {
+	ID           int       `json:"-"`
+	ClientID     string    `json:"client_id"`
+	SecretHash   string    `json:"-"` // Kosong untuk public client (SPA, aplikasi native)
+	Name         string    `json:"name"`
+	RedirectURIs []string  `json:"redirect_uris"`
+	GrantTypes   []string  `json:"grant_types"`
+	Scopes       []string  `json:"scopes"` // Scope yang boleh diminta client ini
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// IsConfidential menandakan client memiliki secret dan wajib melakukan autentikasi di token endpoint
+func (c *OAuthClient) IsConfidential() bool {
+	return c.SecretHash != ""
+}
+
+// AllowsGrant mengecek apakah client boleh memakai grant type tertentu
+func (c *OAuthClient) AllowsGrant(grantType string) bool {
+	for _, g := range c.GrantTypes {
+		if g == grantType {
+			return true
+		}
+	}
+	return false
+}
+
+// AuthorizationCode adalah kode sekali pakai hasil persetujuan user di /oauth/authorize
+type AuthorizationCode struct {
+	ID                  int64      `json:"id"`
+	CodeHash            string     `json:"-"`
+	ClientID            string     `json:"client_id"`
+	UserID              int        `json:"user_id"`
+	RedirectURI         string     `json:"redirect_uri"` // Kosong jika tidak dikirim di authorization request
+	Scope               string     `json:"scope"`
+	CodeChallenge       string     `json:"-"`
+	CodeChallengeMethod string     `json:"-"`
+	Nonce               string     `json:"-"`         // Nonce OIDC, disalin ke ID token
+	AuthTime            time.Time  `json:"auth_time"` // Waktu user login sebelum menyetujui consent
+	ExpiresAt           time.Time  `json:"expires_at"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UsedAt              *time.Time `json:"used_at,omitempty"`
+}
+
+// Input untuk mendaftarkan OAuth client
+type OAuthClientInput struct {
+	Name         string   `json:"name" validate:"required,max=100"`
+	RedirectURIs []string `json:"redirect_uris"`
+	GrantTypes   []string `json:"grant_types" validate:"required,min=1"`
+	Scopes       []string `json:"scopes"`
+	Public       bool     `json:"public"` // true = tanpa client secret (wajib PKCE)
+}
+
+// AuthorizeRequest adalah parameter authorization request (RFC 6749 section 4.1.1 + PKCE)
+type AuthorizeRequest struct {
+	ResponseType        string `form:"response_type" json:"response_type"`
+	ClientID            string `form:"client_id" json:"client_id"`
+	RedirectURI         string `form:"redirect_uri" json:"redirect_uri"`
+	Scope               string `form:"scope" json:"scope"`
+	State               string `form:"state" json:"state"`
+	CodeChallenge       string `form:"code_challenge" json:"code_challenge"`
+	CodeChallengeMethod string `form:"code_challenge_method" json:"code_challenge_method"`
+	Nonce               string `form:"nonce" json:"nonce"` // OIDC: dikembalikan apa adanya di ID token
+}
+
+// ConsentInput adalah keputusan user atas authorization request
+type ConsentInput struct {
+	AuthorizeRequest
+	Approve bool `json:"approve"`
+}
+
+// OAuthTokenRequest adalah parameter token request (application/x-www-form-urlencoded)
+type OAuthTokenRequest struct {
+	GrantType    string `form:"grant_type"`
+	Code         string `form:"code"`
+	RedirectURI  string `form:"redirect_uri"`
+	CodeVerifier string `form:"code_verifier"`
+	RefreshToken string `form:"refresh_token"`
+	Scope        string `form:"scope"`
+	ClientID     string `form:"client_id"`
+	ClientSecret string `form:"client_secret"`
+}
+
+// OAuthTokenResponse adalah response sukses token endpoint (RFC 6749 section 5.1)
+type OAuthTokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+	IDToken      string `json:"id_token,omitempty"` // Hanya jika scope openid diberikan
+}
@@ -0,0 +1,73 @@
+// internal/model/organization.go
+package model
+
+import "time"
+
+// Role anggota di dalam satu organisasi, dari yang paling tinggi
+const (
+	OrgRoleOwner  = "owner"  // Semua hak admin, ditambah mengelola owner dan menghapus organisasi
+	OrgRoleAdmin  = "admin"  // Mengundang, mengubah role dan mengeluarkan anggota
+	OrgRoleMember = "member" // Anggota biasa
+)
+
+// orgRoleRanks menentukan urutan role organisasi untuk OrgRoleAtLeast
+var orgRoleRanks = map[string]int{
+	OrgRoleMember: 1,
+	OrgRoleAdmin:  2,
+	OrgRoleOwner:  3,
+}
+
+// OrgRoleAtLeast mengecek apakah role organisasi setara atau lebih tinggi dari minimum
+func OrgRoleAtLeast(role, minimum string) bool {
+	return orgRoleRanks[role] > 0 && orgRoleRanks[role] >= orgRoleRanks[minimum]
+}
+
+// Organization adalah tim/tenant yang beranggotakan beberapa user
+type Organization struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Slug      string    `json:"slug"` // Unik, huruf kecil, angka dan tanda hubung
+	CreatedAt time.Time `json:"created_at"`
+	Role      string    `json:"role,omitempty"` // Role user yang meminta; diisi saat menampilkan organisasi milik user
+}
+
+// Membership adalah keanggotaan satu user di satu organisasi
+type Membership struct {
+	OrganizationID int       `json:"organization_id"`
+	UserID         int       `json:"user_id"`
+	Username       string    `json:"username"`
+	Email          string    `json:"email"`
+	Role           string    `json:"role"`
+	CreatedAt      time.Time `json:"created_at"` // Waktu user bergabung
+}
+
+// Invitation adalah undangan bergabung ke organisasi untuk satu alamat email.
+// Undangan diterima oleh user yang login dengan email tersebut (sudah dikonfirmasi).
+type Invitation struct {
+	ID               int64     `json:"id"`
+	OrganizationID   int       `json:"organization_id"`
+	OrganizationName string    `json:"organization_name"`
+	Email            string    `json:"email"`
+	Role             string    `json:"role"`
+	InvitedBy        *int      `json:"-"`
+	InvitedByName    string    `json:"invited_by,omitempty"` // Username pengundang, kosong jika akunnya sudah dihapus
+	ExpiresAt        time.Time `json:"expires_at"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// Input untuk membuat organisasi. Slug dibuat dari nama jika tidak dikirim.
+type CreateOrganizationInput struct {
+	Name string `json:"name" validate:"required,max=100"`
+	Slug string `json:"slug" validate:"omitempty,min=3,max=50"`
+}
+
+// Input untuk mengundang anggota baru; owner baru ditunjuk dengan mengubah role anggota
+type InviteMemberInput struct {
+	Email string `json:"email" validate:"required,email,max=255"`
+	Role  string `json:"role" validate:"required,oneof=admin member"`
+}
+
+// Input untuk mengubah role anggota organisasi
+type UpdateMemberRoleInput struct {
+	Role string `json:"role" validate:"required,oneof=owner admin member"`
+}
@@ -0,0 +1,69 @@
+// internal/model/passkey.go
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Jenis ceremony WebAuthn yang disimpan di tabel webauthn_sessions
+const (
+	WebAuthnCeremonyRegistration = "registration"
+	WebAuthnCeremonyLogin        = "login"
+)
+
+// Passkey adalah credential WebAuthn milik user.
+// Hanya public key yang disimpan; private key tidak pernah meninggalkan authenticator.
+type Passkey struct {
+	ID              int64      `json:"id"`
+	UserID          int        `json:"-"`
+	CredentialID    []byte     `json:"-"`
+	PublicKey       []byte     `json:"-"` // Public key dalam format COSE
+	AttestationType string     `json:"-"`
+	AAGUID          []byte     `json:"-"`
+	Transports      []string   `json:"transports"`
+	SignCount       uint32     `json:"-"`
+	CloneWarning    bool       `json:"-"` // Sign counter pernah mundur: kemungkinan authenticator digandakan
+	BackupEligible  bool       `json:"backup_eligible"`
+	BackupState     bool       `json:"backup_state"` // Credential disinkronkan (misal iCloud Keychain / Google Password Manager)
+	Name            string     `json:"name"`
+	CreatedAt       time.Time  `json:"created_at"`
+	LastUsedAt      *time.Time `json:"last_used_at,omitempty"`
+}
+
+// WebAuthnSession menyimpan challenge satu ceremony WebAuthn di antara langkah begin dan finish.
+// Token asli hanya dikirim ke client; yang disimpan hanya hash SHA-256-nya.
+type WebAuthnSession struct {
+	ID        int64
+	UserID    *int // Nil untuk login passkey: user baru diketahui dari credential yang dipakai
+	Ceremony  string
+	TokenHash string
+	Data      []byte // webauthn.SessionData dalam bentuk JSON
+	ExpiresAt time.Time
+	CreatedAt time.Time
+}
+
+// PasskeyCeremony dikembalikan oleh langkah begin: Options diteruskan apa adanya ke
+// navigator.credentials.create()/get() di browser, SessionToken dikirim balik di langkah finish
+type PasskeyCeremony struct {
+	SessionToken string      `json:"session_token"`
+	Options      interface{} `json:"options"`
+}
+
+// Input untuk menyelesaikan pendaftaran passkey. Credential adalah hasil navigator.credentials.create() dalam bentuk JSON.
+type FinishPasskeyRegistrationInput struct {
+	SessionToken string          `json:"session_token" validate:"required"`
+	Name         string          `json:"name" validate:"omitempty,max=64"`
+	Credential   json.RawMessage `json:"credential" validate:"required"`
+}
+
+// Input untuk login dengan passkey. Credential adalah hasil navigator.credentials.get() dalam bentuk JSON.
+type PasskeyLoginInput struct {
+	SessionToken string          `json:"session_token" validate:"required"`
+	Credential   json.RawMessage `json:"credential" validate:"required"`
+}
+
+// Input untuk mengganti nama passkey
+type RenamePasskeyInput struct {
+	Name string `json:"name" validate:"required,max=64"`
+}
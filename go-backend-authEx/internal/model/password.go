@@ -0,0 +1,19 @@
+// internal/model/password.go
+package model
+
+// Input untuk meminta link reset password
+type ForgotPasswordInput struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// Input untuk mengganti password dengan token dari email reset. Aturan password sama dengan RegisterInput.
+type ResetPasswordInput struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,min=8,max=72"`
+}
+
+// Input untuk mengganti password oleh user yang sedang login. Aturan password baru sama dengan RegisterInput.
+type ChangePasswordInput struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required,min=8,max=72"`
+}
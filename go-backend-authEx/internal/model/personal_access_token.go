@@ -0,0 +1,32 @@
+// internal/model/personal_access_token.go
+package model
+
+import "time"
+
+// PersonalAccessToken adalah token jangka panjang milik user untuk script dan CI.
+// Token asli hanya ditampilkan sekali saat dibuat; yang disimpan hanya hash dan prefix-nya.
+type PersonalAccessToken struct {
+	ID         int64      `json:"id"`
+	UserID     int        `json:"-"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"` // Awal token (misal "pat_AbCd1234") agar user bisa mengenali tokennya
+	TokenHash  string     `json:"-"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"` // nil = tidak kedaluwarsa
+	LastUsedAt *time.Time `json:"last_used_at"`
+	LastUsedIP string     `json:"last_used_ip,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	RevokedAt  *time.Time `json:"-"`
+}
+
+// IsExpired mengecek apakah token sudah melewati masa berlakunya
+func (t *PersonalAccessToken) IsExpired(now time.Time) bool {
+	return t.ExpiresAt != nil && !now.Before(*t.ExpiresAt)
+}
+
+// Input untuk membuat personal access token
+type CreatePersonalAccessTokenInput struct {
+	Name      string     `json:"name" validate:"required,max=100"`
+	Scopes    []string   `json:"scopes" validate:"required,min=1"`
+	ExpiresAt *time.Time `json:"expires_at"` // Opsional; harus di masa depan
+}
@@ -0,0 +1,31 @@
+// internal/model/role.go
+package model
+
+import "time"
+
+// Role bawaan yang dibuat saat startup
+const (
+	RoleAdmin = "admin" // Mendapat semua permission bawaan
+)
+
+// Permission bawaan yang dibuat saat startup, dengan format <resource>:<aksi>
+const (
+	PermissionUsersRead   = "users:read"   // Melihat data user lain
+	PermissionUsersWrite  = "users:write"  // Mengubah status, mereset password dan menghapus user lain
+	PermissionRolesRead   = "roles:read"   // Melihat role dan permission
+	PermissionRolesAssign = "roles:assign" // Memberi dan mencabut role user
+)
+
+// Role adalah kumpulan permission yang bisa diberikan ke user
+type Role struct {
+	ID          int       `json:"-"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Permissions []string  `json:"permissions"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Input untuk memberikan role ke user
+type AssignRoleInput struct {
+	Role string `json:"role" validate:"required,max=50"`
+}
@@ -0,0 +1,24 @@
+// internal/model/session.go
+package model
+
+import "time"
+
+// Session adalah satu login user di satu perangkat/browser.
+// ID session sama dengan family_id refresh token yang diterbitkan saat login tersebut.
+type Session struct {
+	ID         string     `json:"id"`
+	UserID     int        `json:"-"`
+	UserAgent  string     `json:"user_agent"`
+	IP         string     `json:"ip"` // IP terakhir yang memakai session ini
+	CreatedAt  time.Time  `json:"created_at"`
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	RevokedAt  *time.Time `json:"-"`
+	CSRFToken  string     `json:"-"`       // Token CSRF synchronizer untuk mode cookie
+	Current    bool       `json:"current"` // Diisi saat ditampilkan: session yang sedang dipakai request ini
+}
+
+// ClientInfo adalah informasi perangkat pemanggil yang dicatat pada session
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+}
@@ -0,0 +1,47 @@
+// internal/model/token.go
+package model
+
+import "time"
+
+// RefreshToken merepresentasikan refresh token yang tersimpan di database.
+// Token asli tidak pernah disimpan, hanya hash SHA-256-nya.
+type RefreshToken struct {
+	ID        int64      `json:"id"`
+	UserID    int        `json:"user_id"`
+	FamilyID  string     `json:"family_id"`           // Semua token hasil rotasi dari satu login berbagi family yang sama
+	ClientID  string     `json:"client_id,omitempty"` // Diisi jika token diterbitkan untuk OAuth client
+	Scope     string     `json:"scope,omitempty"`     // Scope OAuth yang diberikan (hanya untuk token OAuth)
+	AuthTime  time.Time  `json:"auth_time"`           // Waktu login awal family ini, diteruskan ke setiap token hasil rotasi
+	TokenHash string     `json:"-"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	CreatedAt time.Time  `json:"created_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`    // Diisi saat token dirotasi
+	RevokedAt *time.Time `json:"revoked_at,omitempty"` // Diisi saat family dicabut
+}
+
+// TokenPair adalah pasangan access token dan refresh token yang dikirim ke client
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"` // Masa berlaku access token dalam detik
+	SessionID    string `json:"-"`          // Session (family) tempat token ini diterbitkan
+}
+
+// Input untuk refresh token
+type RefreshInput struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// RevokedToken adalah entri denylist untuk access token (berdasarkan klaim jti) yang dicabut sebelum kedaluwarsa
+type RevokedToken struct {
+	JTI       string    `json:"jti"`
+	UserID    int       `json:"user_id"`
+	ExpiresAt time.Time `json:"expires_at"` // Sama dengan exp token; setelah lewat, entri boleh dihapus
+	RevokedAt time.Time `json:"revoked_at"`
+}
+
+// Input untuk logout. Refresh token opsional; jika dikirim, family-nya ikut dicabut.
+type LogoutInput struct {
+	RefreshToken string `json:"refresh_token"`
+}
@@ -3,13 +3,48 @@ package model // <- Pastikan package tetap model
 
 import "time"
 
+// Status akun user
+const (
+	UserStatusActive          = "active"
+	UserStatusDisabled        = "disabled"         // Dinonaktifkan; tidak bisa login
+	UserStatusPendingDeletion = "pending_deletion" // Menunggu dihapus permanen setelah masa tenggang
+)
+
 // User struct merepresentasikan data pengguna
 type User struct {
-	ID           int       `json:"id"`
-	Username     string    `json:"username"`
-	Email        string    `json:"email"`
-	PasswordHash string    `json:"-"` // Jangan kirim hash password ke client
-	CreatedAt    time.Time `json:"created_at"`
+	ID              int        `json:"id"`
+	Username        string     `json:"username"`
+	Email           string     `json:"email"`
+	PasswordHash    string     `json:"-"`                 // Jangan kirim hash password ke client
+	EmailVerifiedAt *time.Time `json:"email_verified_at"` // nil = email belum dikonfirmasi
+	Status          string     `json:"status"`
+	DeletionAt      *time.Time `json:"deletion_scheduled_at,omitempty"` // Waktu penghapusan permanen jika status pending_deletion
+	CreatedAt       time.Time  `json:"created_at"`
+
+	FailedLoginCount  int        `json:"-"`                      // Jumlah login gagal berturut-turut
+	LastFailedLoginAt *time.Time `json:"-"`                      // Waktu login gagal terakhir, dasar perhitungan backoff
+	LockedUntil       *time.Time `json:"locked_until,omitempty"` // Login ditolak sampai waktu ini
+
+	Roles []string `json:"roles,omitempty"` // Nama role RBAC; hanya diisi jika dimuat lewat RoleService
+}
+
+// LoginFailure adalah hitungan login gagal untuk identifier yang tidak cocok dengan user mana pun,
+// dicatat seperti kolom login gagal pada User agar respons login tidak membedakan akun yang ada dan yang tidak
+type LoginFailure struct {
+	Identifier   string
+	FailedCount  int
+	LastFailedAt time.Time
+	LockedUntil  *time.Time
+}
+
+// IsActive mengecek apakah user boleh login dan memakai token
+func (u *User) IsActive() bool {
+	return u.Status == "" || u.Status == UserStatusActive
+}
+
+// IsEmailVerified mengecek apakah user sudah mengkonfirmasi alamat email-nya
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
 }
 
 // Input untuk registrasi
@@ -21,8 +56,20 @@ type RegisterInput struct {
 	// seperti `containsany=!@#$%^&*()`, atau membuat custom validator.
 }
 
-// Input untuk login
+// Input untuk login. Identifier berisi username atau email; keduanya unik.
 type LoginInput struct {
-	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required"` // Untuk login, biasanya hanya 'required' sudah cukup
+	Identifier string `json:"identifier" validate:"required,max=255"`
+	Password   string `json:"password" validate:"required"` // Untuk login, biasanya hanya 'required' sudah cukup
+}
+
+// Input untuk mengubah profil. Field yang tidak dikirim tidak diubah; aturan sama dengan RegisterInput.
+// Perubahan email baru berlaku setelah alamat baru dikonfirmasi lewat link di email.
+type UpdateProfileInput struct {
+	Username *string `json:"username" validate:"omitempty,alphanum,min=3,max=30"`
+	Email    *string `json:"email" validate:"omitempty,email"`
+}
+
+// Input untuk mengkonfirmasi alamat email baru
+type ConfirmEmailChangeInput struct {
+	Token string `json:"token" validate:"required"`
 }
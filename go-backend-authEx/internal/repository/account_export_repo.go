@@ -0,0 +1,70 @@
+// internal/repository/account_export_repo.go
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+)
+
+// AccountExportRepository mengumpulkan data milik user dari semua tabel untuk ekspor data pribadi
+type AccountExportRepository interface {
+	// ExportRecords mengembalikan data user per bagian sebagai array JSON
+	ExportRecords(userID int) (map[string]json.RawMessage, error)
+}
+
+// Implementasi AccountExportRepository untuk PostgreSQL
+type postgresAccountExportRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresAccountExportRepository adalah constructor untuk account export repository
+func NewPostgresAccountExportRepository(db *sql.DB) AccountExportRepository {
+	return &postgresAccountExportRepository{db: db}
+}
+
+// accountExportSections berisi query per bagian ekspor. Setiap query memilih kolom secara eksplisit
+// agar hash token dan secret tidak ikut diekspor; tabel baru yang menyimpan data user harus ditambahkan di sini.
+var accountExportSections = []struct {
+	name  string
+	query string
+}{
+	{"sessions", `SELECT id, user_agent, ip, created_at, last_seen_at, revoked_at
+	              FROM sessions WHERE user_id = $1 ORDER BY created_at`},
+	{"refresh_tokens", `SELECT family_id, client_id, scope, auth_time, created_at, expires_at, used_at, revoked_at
+	                    FROM refresh_tokens WHERE user_id = $1 ORDER BY created_at`},
+	{"revoked_access_tokens", `SELECT jti, expires_at, revoked_at
+	                           FROM revoked_tokens WHERE user_id = $1 ORDER BY revoked_at`},
+	{"oauth_authorization_codes", `SELECT client_id, redirect_uri, scope, created_at, expires_at, used_at
+	                               FROM oauth_authorization_codes WHERE user_id = $1 ORDER BY created_at`},
+	{"personal_access_tokens", `SELECT id, name, prefix, scopes, expires_at, last_used_at, last_used_ip, created_at, revoked_at
+	                            FROM personal_access_tokens WHERE user_id = $1 ORDER BY created_at`},
+	{"email_tokens", `SELECT purpose, email, created_at, expires_at, used_at
+	                  FROM email_tokens WHERE user_id = $1 ORDER BY created_at`},
+	{"mfa_totp", `SELECT confirmed_at, created_at FROM mfa_totp WHERE user_id = $1`},
+	{"mfa_recovery_codes", `SELECT created_at, used_at FROM mfa_recovery_codes WHERE user_id = $1 ORDER BY id`},
+	{"passkeys", `SELECT name, transports, backup_eligible, backup_state, created_at, last_used_at
+	              FROM webauthn_credentials WHERE user_id = $1 ORDER BY created_at`},
+	{"identities", `SELECT provider, subject, email, created_at, last_used_at
+	                FROM identities WHERE user_id = $1 ORDER BY created_at`},
+	{"roles", `SELECT r.name, ur.created_at
+                FROM user_roles ur JOIN roles r ON r.id = ur.role_id WHERE ur.user_id = $1 ORDER BY ur.created_at`},
+	{"organizations", `SELECT o.name, o.slug, m.role, m.created_at
+                FROM organization_members m JOIN organizations o ON o.id = m.organization_id WHERE m.user_id = $1 ORDER BY m.created_at`},
+}
+
+func (p *postgresAccountExportRepository) ExportRecords(userID int) (map[string]json.RawMessage, error) {
+	records := make(map[string]json.RawMessage, len(accountExportSections))
+	for _, section := range accountExportSections {
+		query := `SELECT COALESCE(json_agg(t), '[]'::json) FROM (` + section.query + `) t`
+
+		var data []byte
+		if err := p.db.QueryRow(query, userID).Scan(&data); err != nil {
+			log.Printf("Error exporting %s of user %d: %v", section.name, userID, err)
+			return nil, fmt.Errorf("could not export %s: %w", section.name, err)
+		}
+		records[section.name] = data
+	}
+	return records, nil
+}
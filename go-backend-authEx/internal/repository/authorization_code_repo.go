@@ -0,0 +1,86 @@
+// internal/repository/authorization_code_repo.go
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+
+	"go-auth-example/internal/model"
+)
+
+// AuthorizationCodeRepository mendefinisikan operasi penyimpanan authorization code OAuth
+type AuthorizationCodeRepository interface {
+	Create(code *model.AuthorizationCode) error
+	GetByHash(codeHash string) (*model.AuthorizationCode, error)
+	// Consume menandai kode sebagai terpakai secara atomik.
+	// Mengembalikan nil jika kode tidak ada atau sudah pernah dipakai.
+	Consume(codeHash string) (*model.AuthorizationCode, error)
+}
+
+// Implementasi AuthorizationCodeRepository untuk PostgreSQL
+type postgresAuthorizationCodeRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresAuthorizationCodeRepository adalah constructor untuk authorization code repository
+func NewPostgresAuthorizationCodeRepository(db *sql.DB) AuthorizationCodeRepository {
+	return &postgresAuthorizationCodeRepository{db: db}
+}
+
+const authorizationCodeColumns = `id, code_hash, client_id, user_id, redirect_uri, scope,
+	code_challenge, code_challenge_method, nonce, auth_time, expires_at, created_at, used_at`
+
+func scanAuthorizationCode(row *sql.Row) (*model.AuthorizationCode, error) {
+	code := &model.AuthorizationCode{}
+	err := row.Scan(&code.ID, &code.CodeHash, &code.ClientID, &code.UserID, &code.RedirectURI, &code.Scope,
+		&code.CodeChallenge, &code.CodeChallengeMethod, &code.Nonce, &code.AuthTime, &code.ExpiresAt, &code.CreatedAt, &code.UsedAt)
+	if err != nil {
+		return nil, err
+	}
+	return code, nil
+}
+
+func (p *postgresAuthorizationCodeRepository) Create(code *model.AuthorizationCode) error {
+	query := `INSERT INTO oauth_authorization_codes
+	          (code_hash, client_id, user_id, redirect_uri, scope, code_challenge, code_challenge_method, nonce, auth_time, expires_at)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id, created_at`
+
+	err := p.db.QueryRow(query, code.CodeHash, code.ClientID, code.UserID, code.RedirectURI, code.Scope,
+		code.CodeChallenge, code.CodeChallengeMethod, code.Nonce, code.AuthTime, code.ExpiresAt).Scan(&code.ID, &code.CreatedAt)
+	if err != nil {
+		log.Printf("Error creating authorization code for client %s: %v", code.ClientID, err)
+		return fmt.Errorf("could not create authorization code: %w", err)
+	}
+	return nil
+}
+
+func (p *postgresAuthorizationCodeRepository) GetByHash(codeHash string) (*model.AuthorizationCode, error) {
+	query := `SELECT ` + authorizationCodeColumns + ` FROM oauth_authorization_codes WHERE code_hash = $1`
+
+	code, err := scanAuthorizationCode(p.db.QueryRow(query, codeHash))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		log.Printf("Error getting authorization code: %v", err)
+		return nil, fmt.Errorf("could not get authorization code: %w", err)
+	}
+	return code, nil
+}
+
+func (p *postgresAuthorizationCodeRepository) Consume(codeHash string) (*model.AuthorizationCode, error) {
+	query := `UPDATE oauth_authorization_codes SET used_at = NOW()
+	          WHERE code_hash = $1 AND used_at IS NULL
+	          RETURNING ` + authorizationCodeColumns
+
+	code, err := scanAuthorizationCode(p.db.QueryRow(query, codeHash))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		log.Printf("Error consuming authorization code: %v", err)
+		return nil, fmt.Errorf("could not consume authorization code: %w", err)
+	}
+	return code, nil
+}
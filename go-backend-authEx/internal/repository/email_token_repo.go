@@ -0,0 +1,87 @@
+// internal/repository/email_token_repo.go
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"go-auth-example/internal/model"
+)
+
+// EmailTokenRepository mendefinisikan operasi penyimpanan token yang dikirim lewat email
+type EmailTokenRepository interface {
+	Create(token *model.EmailToken) error
+	// Consume menandai token sebagai terpakai secara atomik dan mengembalikannya.
+	// Mengembalikan nil jika token tidak ada, beda tujuan, sudah dipakai atau kedaluwarsa.
+	Consume(tokenHash string, purpose string) (*model.EmailToken, error)
+	// InvalidateForUser menandai semua token user dengan tujuan tertentu yang belum dipakai sebagai terpakai
+	InvalidateForUser(userID int, purpose string) error
+	// LatestCreatedAt mengembalikan waktu pembuatan token terakhir user untuk tujuan tertentu (nil jika belum ada)
+	LatestCreatedAt(userID int, purpose string) (*time.Time, error)
+}
+
+// Implementasi EmailTokenRepository untuk PostgreSQL
+type postgresEmailTokenRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresEmailTokenRepository adalah constructor untuk email token repository
+func NewPostgresEmailTokenRepository(db *sql.DB) EmailTokenRepository {
+	return &postgresEmailTokenRepository{db: db}
+}
+
+func (p *postgresEmailTokenRepository) Create(token *model.EmailToken) error {
+	query := `INSERT INTO email_tokens (user_id, purpose, email, token_hash, binding_hash, expires_at)
+	          VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at`
+
+	err := p.db.QueryRow(query, token.UserID, token.Purpose, token.Email, token.TokenHash, token.BindingHash, token.ExpiresAt).
+		Scan(&token.ID, &token.CreatedAt)
+	if err != nil {
+		log.Printf("Error creating %s email token for user %d: %v", token.Purpose, token.UserID, err)
+		return fmt.Errorf("could not create email token: %w", err)
+	}
+	return nil
+}
+
+func (p *postgresEmailTokenRepository) Consume(tokenHash string, purpose string) (*model.EmailToken, error) {
+	query := `UPDATE email_tokens SET used_at = NOW()
+	          WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > NOW()
+	          RETURNING id, user_id, purpose, email, token_hash, binding_hash, expires_at, created_at, used_at`
+
+	token := &model.EmailToken{}
+	err := p.db.QueryRow(query, tokenHash, purpose).Scan(&token.ID, &token.UserID, &token.Purpose, &token.Email,
+		&token.TokenHash, &token.BindingHash, &token.ExpiresAt, &token.CreatedAt, &token.UsedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		log.Printf("Error consuming %s email token: %v", purpose, err)
+		return nil, fmt.Errorf("could not consume email token: %w", err)
+	}
+	return token, nil
+}
+
+func (p *postgresEmailTokenRepository) InvalidateForUser(userID int, purpose string) error {
+	query := `UPDATE email_tokens SET used_at = NOW() WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL`
+	if _, err := p.db.Exec(query, userID, purpose); err != nil {
+		log.Printf("Error invalidating %s email tokens for user %d: %v", purpose, userID, err)
+		return fmt.Errorf("could not invalidate email tokens: %w", err)
+	}
+	return nil
+}
+
+func (p *postgresEmailTokenRepository) LatestCreatedAt(userID int, purpose string) (*time.Time, error) {
+	query := `SELECT MAX(created_at) FROM email_tokens WHERE user_id = $1 AND purpose = $2`
+
+	var createdAt sql.NullTime
+	if err := p.db.QueryRow(query, userID, purpose).Scan(&createdAt); err != nil {
+		log.Printf("Error getting latest %s email token for user %d: %v", purpose, userID, err)
+		return nil, fmt.Errorf("could not get latest email token: %w", err)
+	}
+	if !createdAt.Valid {
+		return nil, nil
+	}
+	return &createdAt.Time, nil
+}
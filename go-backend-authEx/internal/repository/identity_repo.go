@@ -0,0 +1,148 @@
+// internal/repository/identity_repo.go
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+
+	"go-auth-example/internal/model"
+)
+
+// IdentityRepository mendefinisikan operasi penyimpanan identity login sosial dan state login-nya
+type IdentityRepository interface {
+	// GetByProviderSubject mengembalikan identity berdasarkan provider dan subject, nil jika tidak ada
+	GetByProviderSubject(provider, subject string) (*model.Identity, error)
+	// ListByUser mengembalikan semua identity milik user
+	ListByUser(userID int) ([]model.Identity, error)
+	// Create menyimpan identity baru; mengembalikan "identity already linked" jika akun provider sudah terhubung
+	Create(identity *model.Identity) error
+	// TouchLastUsed mencatat waktu login terakhir dengan identity
+	TouchLastUsed(id int64) error
+
+	// CreateState menyimpan state login sosial baru sekaligus membersihkan state yang sudah kedaluwarsa
+	CreateState(state *model.SocialLoginState) error
+	// TakeState mengambil dan menghapus state secara atomik agar callback hanya bisa dipakai sekali.
+	// Mengembalikan nil jika tidak ada atau sudah kedaluwarsa.
+	TakeState(stateHash string) (*model.SocialLoginState, error)
+}
+
+// Implementasi IdentityRepository untuk PostgreSQL
+type postgresIdentityRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresIdentityRepository adalah constructor untuk identity repository
+func NewPostgresIdentityRepository(db *sql.DB) IdentityRepository {
+	return &postgresIdentityRepository{db: db}
+}
+
+const identityColumns = `id, user_id, provider, subject, email, created_at, last_used_at`
+
+func scanIdentity(row rowScanner) (*model.Identity, error) {
+	identity := &model.Identity{}
+	err := row.Scan(&identity.ID, &identity.UserID, &identity.Provider, &identity.Subject, &identity.Email,
+		&identity.CreatedAt, &identity.LastUsedAt)
+	if err != nil {
+		return nil, err
+	}
+	return identity, nil
+}
+
+func (p *postgresIdentityRepository) GetByProviderSubject(provider, subject string) (*model.Identity, error) {
+	query := `SELECT ` + identityColumns + ` FROM identities WHERE provider = $1 AND subject = $2`
+
+	identity, err := scanIdentity(p.db.QueryRow(query, provider, subject))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		log.Printf("Error getting %s identity: %v", provider, err)
+		return nil, fmt.Errorf("could not get identity: %w", err)
+	}
+	return identity, nil
+}
+
+func (p *postgresIdentityRepository) ListByUser(userID int) ([]model.Identity, error) {
+	query := `SELECT ` + identityColumns + ` FROM identities WHERE user_id = $1 ORDER BY created_at`
+
+	rows, err := p.db.Query(query, userID)
+	if err != nil {
+		log.Printf("Error listing identities for user %d: %v", userID, err)
+		return nil, fmt.Errorf("could not list identities: %w", err)
+	}
+	defer rows.Close()
+
+	identities := []model.Identity{}
+	for rows.Next() {
+		identity, err := scanIdentity(rows)
+		if err != nil {
+			log.Printf("Error scanning identity for user %d: %v", userID, err)
+			return nil, fmt.Errorf("could not list identities: %w", err)
+		}
+		identities = append(identities, *identity)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not list identities: %w", err)
+	}
+	return identities, nil
+}
+
+func (p *postgresIdentityRepository) Create(identity *model.Identity) error {
+	query := `INSERT INTO identities (user_id, provider, subject, email)
+	          VALUES ($1, $2, $3, $4) RETURNING id, created_at`
+
+	err := p.db.QueryRow(query, identity.UserID, identity.Provider, identity.Subject, identity.Email).
+		Scan(&identity.ID, &identity.CreatedAt)
+	if err != nil {
+		log.Printf("Error creating %s identity for user %d: %v", identity.Provider, identity.UserID, err)
+		if strings.Contains(err.Error(), "duplicate key value violates unique constraint") {
+			return fmt.Errorf("identity already linked")
+		}
+		return fmt.Errorf("could not create identity: %w", err)
+	}
+	return nil
+}
+
+func (p *postgresIdentityRepository) TouchLastUsed(id int64) error {
+	if _, err := p.db.Exec(`UPDATE identities SET last_used_at = NOW() WHERE id = $1`, id); err != nil {
+		log.Printf("Error updating identity %d last use: %v", id, err)
+		return fmt.Errorf("could not update identity: %w", err)
+	}
+	return nil
+}
+
+func (p *postgresIdentityRepository) CreateState(state *model.SocialLoginState) error {
+	if _, err := p.db.Exec(`DELETE FROM social_login_states WHERE expires_at <= NOW()`); err != nil {
+		log.Printf("Error deleting expired social login states: %v", err)
+	}
+
+	query := `INSERT INTO social_login_states (state_hash, provider, code_verifier, nonce, expires_at)
+	          VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at`
+
+	err := p.db.QueryRow(query, state.StateHash, state.Provider, state.CodeVerifier, state.Nonce, state.ExpiresAt).
+		Scan(&state.ID, &state.CreatedAt)
+	if err != nil {
+		log.Printf("Error creating %s login state: %v", state.Provider, err)
+		return fmt.Errorf("could not create social login state: %w", err)
+	}
+	return nil
+}
+
+func (p *postgresIdentityRepository) TakeState(stateHash string) (*model.SocialLoginState, error) {
+	query := `DELETE FROM social_login_states WHERE state_hash = $1 AND expires_at > NOW()
+	          RETURNING id, state_hash, provider, code_verifier, nonce, expires_at, created_at`
+
+	state := &model.SocialLoginState{}
+	err := p.db.QueryRow(query, stateHash).Scan(&state.ID, &state.StateHash, &state.Provider, &state.CodeVerifier,
+		&state.Nonce, &state.ExpiresAt, &state.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		log.Printf("Error taking social login state: %v", err)
+		return nil, fmt.Errorf("could not get social login state: %w", err)
+	}
+	return state, nil
+}
@@ -0,0 +1,82 @@
+// internal/repository/login_failure_repo.go
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"go-auth-example/internal/model"
+)
+
+// LoginFailureRepository mendefinisikan operasi penyimpanan login gagal untuk identifier tanpa akun
+type LoginFailureRepository interface {
+	// Get mengembalikan hitungan login gagal identifier, atau nil jika belum pernah gagal
+	Get(identifier string) (*model.LoginFailure, error)
+	// RecordFailure menambah hitungan login gagal secara atomik dan mengembalikan jumlah terbarunya
+	RecordFailure(identifier string) (int, error)
+	// LockUntil menolak login identifier sampai waktu yang diberikan
+	LockUntil(identifier string, until time.Time) error
+	// DeleteBefore menghapus hitungan yang login gagal terakhirnya sebelum waktu tersebut
+	DeleteBefore(before time.Time) (int64, error)
+}
+
+// Implementasi LoginFailureRepository untuk PostgreSQL
+type postgresLoginFailureRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresLoginFailureRepository adalah constructor untuk login failure repository
+func NewPostgresLoginFailureRepository(db *sql.DB) LoginFailureRepository {
+	return &postgresLoginFailureRepository{db: db}
+}
+
+func (p *postgresLoginFailureRepository) Get(identifier string) (*model.LoginFailure, error) {
+	failure := &model.LoginFailure{Identifier: identifier}
+	query := `SELECT failed_count, last_failed_at, locked_until FROM login_failures WHERE identifier = $1`
+
+	err := p.db.QueryRow(query, identifier).Scan(&failure.FailedCount, &failure.LastFailedAt, &failure.LockedUntil)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		log.Printf("Error getting login failures of %s: %v", identifier, err)
+		return nil, fmt.Errorf("could not get login failures: %w", err)
+	}
+	return failure, nil
+}
+
+func (p *postgresLoginFailureRepository) RecordFailure(identifier string) (int, error) {
+	query := `INSERT INTO login_failures (identifier, failed_count, last_failed_at) VALUES ($1, 1, NOW())
+	          ON CONFLICT (identifier) DO UPDATE
+	          SET failed_count = login_failures.failed_count + 1, last_failed_at = NOW()
+	          RETURNING failed_count`
+
+	var count int
+	if err := p.db.QueryRow(query, identifier).Scan(&count); err != nil {
+		log.Printf("Error recording login failure of %s: %v", identifier, err)
+		return 0, fmt.Errorf("could not record login failure: %w", err)
+	}
+	return count, nil
+}
+
+func (p *postgresLoginFailureRepository) LockUntil(identifier string, until time.Time) error {
+	query := `UPDATE login_failures SET locked_until = $2 WHERE identifier = $1`
+	if _, err := p.db.Exec(query, identifier, until); err != nil {
+		log.Printf("Error locking identifier %s: %v", identifier, err)
+		return fmt.Errorf("could not lock identifier: %w", err)
+	}
+	return nil
+}
+
+func (p *postgresLoginFailureRepository) DeleteBefore(before time.Time) (int64, error) {
+	query := `DELETE FROM login_failures WHERE last_failed_at < $1 AND (locked_until IS NULL OR locked_until < $1)`
+
+	result, err := p.db.Exec(query, before)
+	if err != nil {
+		log.Printf("Error deleting stale login failures: %v", err)
+		return 0, fmt.Errorf("could not delete stale login failures: %w", err)
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,55 @@
+package memrepo
+
+import (
+	"sync"
+	"time"
+
+	"go-auth-example/internal/model"
+)
+
+// AuthorizationCodes adalah repository.AuthorizationCodeRepository in-memory
+type AuthorizationCodes struct {
+	mu     sync.Mutex
+	nextID int64
+	codes  map[string]*model.AuthorizationCode
+}
+
+// NewAuthorizationCodes membuat repository authorization code kosong
+func NewAuthorizationCodes() *AuthorizationCodes {
+	return &AuthorizationCodes{codes: map[string]*model.AuthorizationCode{}}
+}
+
+func (r *AuthorizationCodes) Create(code *model.AuthorizationCode) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	code.ID = r.nextID
+	code.CreatedAt = time.Now()
+	copied := *code
+	r.codes[code.CodeHash] = &copied
+	return nil
+}
+
+func (r *AuthorizationCodes) GetByHash(codeHash string) (*model.AuthorizationCode, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	code, ok := r.codes[codeHash]
+	if !ok {
+		return nil, nil
+	}
+	copied := *code
+	return &copied, nil
+}
+
+func (r *AuthorizationCodes) Consume(codeHash string) (*model.AuthorizationCode, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	code, ok := r.codes[codeHash]
+	if !ok || code.UsedAt != nil {
+		return nil, nil
+	}
+	now := time.Now()
+	code.UsedAt = &now
+	copied := *code
+	return &copied, nil
+}
@@ -0,0 +1,7 @@
+// Package memrepo berisi implementasi repository in-memory untuk pengujian service dan handler tanpa PostgreSQL.
+// Sebagian repository hanya meng-embed interface-nya: method yang tidak dipakai test tidak diimplementasikan
+// dan akan panic jika dipanggil.
+//
+// Setiap repository aman dipakai dari banyak goroutine dan selalu mengembalikan salinan data yang tersimpan.
+// Seperti implementasi PostgreSQL, data yang tidak ditemukan dikembalikan sebagai (nil, nil).
+package memrepo
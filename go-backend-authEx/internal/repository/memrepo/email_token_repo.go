@@ -0,0 +1,83 @@
+package memrepo
+
+import (
+	"sync"
+	"time"
+
+	"go-auth-example/internal/model"
+)
+
+// EmailTokens adalah repository.EmailTokenRepository in-memory
+type EmailTokens struct {
+	mu     sync.Mutex
+	nextID int64
+	tokens map[int64]*model.EmailToken
+}
+
+// NewEmailTokens membuat repository email token kosong
+func NewEmailTokens() *EmailTokens {
+	return &EmailTokens{tokens: map[int64]*model.EmailToken{}}
+}
+
+func (r *EmailTokens) Create(token *model.EmailToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	token.ID = r.nextID
+	token.CreatedAt = time.Now()
+	copied := *token
+	r.tokens[token.ID] = &copied
+	return nil
+}
+
+func (r *EmailTokens) Consume(tokenHash string, purpose string) (*model.EmailToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, token := range r.tokens {
+		if token.TokenHash != tokenHash || token.Purpose != purpose || token.UsedAt != nil {
+			continue
+		}
+		now := time.Now()
+		if now.After(token.ExpiresAt) {
+			return nil, nil
+		}
+		token.UsedAt = &now
+		copied := *token
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (r *EmailTokens) InvalidateForUser(userID int, purpose string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	for _, token := range r.tokens {
+		if token.UserID == userID && token.Purpose == purpose && token.UsedAt == nil {
+			token.UsedAt = &now
+		}
+	}
+	return nil
+}
+
+func (r *EmailTokens) LatestCreatedAt(userID int, purpose string) (*time.Time, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var latest *time.Time
+	for _, token := range r.tokens {
+		if token.UserID == userID && token.Purpose == purpose && (latest == nil || token.CreatedAt.After(*latest)) {
+			createdAt := token.CreatedAt
+			latest = &createdAt
+		}
+	}
+	return latest, nil
+}
+
+// ExpireAll memundurkan masa berlaku semua token agar dianggap kedaluwarsa
+func (r *EmailTokens) ExpireAll() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, token := range r.tokens {
+		token.ExpiresAt = time.Now().Add(-time.Second)
+	}
+}
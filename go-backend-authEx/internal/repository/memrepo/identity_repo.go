@@ -0,0 +1,99 @@
+package memrepo
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"go-auth-example/internal/model"
+)
+
+// Identities adalah repository.IdentityRepository in-memory
+type Identities struct {
+	mu         sync.Mutex
+	nextID     int64
+	identities map[int64]*model.Identity
+	states     map[string]*model.SocialLoginState
+}
+
+// NewIdentities membuat repository identity dan state login sosial kosong
+func NewIdentities() *Identities {
+	return &Identities{identities: map[int64]*model.Identity{}, states: map[string]*model.SocialLoginState{}}
+}
+
+func (r *Identities) GetByProviderSubject(provider, subject string) (*model.Identity, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, identity := range r.identities {
+		if identity.Provider == provider && identity.Subject == subject {
+			copied := *identity
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *Identities) ListByUser(userID int) ([]model.Identity, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	identities := []model.Identity{}
+	for _, identity := range r.identities {
+		if identity.UserID == userID {
+			identities = append(identities, *identity)
+		}
+	}
+	sort.Slice(identities, func(i, j int) bool { return identities[i].ID < identities[j].ID })
+	return identities, nil
+}
+
+func (r *Identities) Create(identity *model.Identity) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.identities {
+		if existing.Provider == identity.Provider && existing.Subject == identity.Subject {
+			return errors.New("identity already linked")
+		}
+	}
+	r.nextID++
+	identity.ID = r.nextID
+	identity.CreatedAt = time.Now()
+	copied := *identity
+	r.identities[identity.ID] = &copied
+	return nil
+}
+
+func (r *Identities) TouchLastUsed(id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if identity, ok := r.identities[id]; ok {
+		now := time.Now()
+		identity.LastUsedAt = &now
+	}
+	return nil
+}
+
+func (r *Identities) CreateState(state *model.SocialLoginState) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	state.ID = r.nextID
+	state.CreatedAt = time.Now()
+	copied := *state
+	r.states[state.StateHash] = &copied
+	return nil
+}
+
+func (r *Identities) TakeState(stateHash string) (*model.SocialLoginState, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	state, ok := r.states[stateHash]
+	if !ok {
+		return nil, nil
+	}
+	delete(r.states, stateHash)
+	if time.Now().After(state.ExpiresAt) {
+		return nil, nil
+	}
+	return state, nil
+}
@@ -0,0 +1,65 @@
+package memrepo
+
+import (
+	"sync"
+	"time"
+
+	"go-auth-example/internal/model"
+)
+
+// LoginFailures adalah repository.LoginFailureRepository in-memory
+type LoginFailures struct {
+	mu       sync.Mutex
+	failures map[string]*model.LoginFailure
+}
+
+// NewLoginFailures membuat repository login gagal kosong
+func NewLoginFailures() *LoginFailures {
+	return &LoginFailures{failures: map[string]*model.LoginFailure{}}
+}
+
+func (r *LoginFailures) Get(identifier string) (*model.LoginFailure, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	failure, ok := r.failures[identifier]
+	if !ok {
+		return nil, nil
+	}
+	copied := *failure
+	return &copied, nil
+}
+
+func (r *LoginFailures) RecordFailure(identifier string) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	failure, ok := r.failures[identifier]
+	if !ok {
+		failure = &model.LoginFailure{Identifier: identifier}
+		r.failures[identifier] = failure
+	}
+	failure.FailedCount++
+	failure.LastFailedAt = time.Now()
+	return failure.FailedCount, nil
+}
+
+func (r *LoginFailures) LockUntil(identifier string, until time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if failure, ok := r.failures[identifier]; ok {
+		failure.LockedUntil = &until
+	}
+	return nil
+}
+
+func (r *LoginFailures) DeleteBefore(before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var deleted int64
+	for identifier, failure := range r.failures {
+		if failure.LastFailedAt.Before(before) && (failure.LockedUntil == nil || failure.LockedUntil.Before(before)) {
+			delete(r.failures, identifier)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
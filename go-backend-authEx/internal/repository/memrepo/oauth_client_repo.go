@@ -0,0 +1,42 @@
+package memrepo
+
+import (
+	"sync"
+	"time"
+
+	"go-auth-example/internal/model"
+)
+
+// OAuthClients adalah repository.OAuthClientRepository in-memory
+type OAuthClients struct {
+	mu      sync.Mutex
+	nextID  int
+	clients map[string]*model.OAuthClient
+}
+
+// NewOAuthClients membuat repository OAuth client kosong
+func NewOAuthClients() *OAuthClients {
+	return &OAuthClients{clients: map[string]*model.OAuthClient{}}
+}
+
+func (r *OAuthClients) Create(client *model.OAuthClient) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	client.ID = r.nextID
+	client.CreatedAt = time.Now()
+	copied := *client
+	r.clients[client.ClientID] = &copied
+	return nil
+}
+
+func (r *OAuthClients) GetByClientID(clientID string) (*model.OAuthClient, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	client, ok := r.clients[clientID]
+	if !ok {
+		return nil, nil
+	}
+	copied := *client
+	return &copied, nil
+}
@@ -0,0 +1,132 @@
+package memrepo
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+	"time"
+
+	"go-auth-example/internal/model"
+)
+
+// Passkeys adalah repository.PasskeyRepository in-memory
+type Passkeys struct {
+	mu       sync.Mutex
+	nextID   int64
+	passkeys map[int64]*model.Passkey
+	sessions map[string]*model.WebAuthnSession
+}
+
+// NewPasskeys membuat repository passkey dan session WebAuthn kosong
+func NewPasskeys() *Passkeys {
+	return &Passkeys{passkeys: map[int64]*model.Passkey{}, sessions: map[string]*model.WebAuthnSession{}}
+}
+
+func (r *Passkeys) ListByUser(userID int) ([]model.Passkey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	passkeys := []model.Passkey{}
+	for _, passkey := range r.passkeys {
+		if passkey.UserID == userID {
+			passkeys = append(passkeys, *passkey)
+		}
+	}
+	sort.Slice(passkeys, func(i, j int) bool { return passkeys[i].ID > passkeys[j].ID })
+	return passkeys, nil
+}
+
+func (r *Passkeys) GetByCredentialID(credentialID []byte) (*model.Passkey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, passkey := range r.passkeys {
+		if bytes.Equal(passkey.CredentialID, credentialID) {
+			copied := *passkey
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *Passkeys) Create(passkey *model.Passkey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	passkey.ID = r.nextID
+	passkey.CreatedAt = time.Now()
+	copied := *passkey
+	r.passkeys[passkey.ID] = &copied
+	return nil
+}
+
+func (r *Passkeys) UpdateAfterLogin(passkey *model.Passkey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.passkeys[passkey.ID]
+	if !ok {
+		return nil
+	}
+	now := time.Now()
+	stored.SignCount = passkey.SignCount
+	stored.CloneWarning = passkey.CloneWarning
+	stored.BackupState = passkey.BackupState
+	stored.LastUsedAt = &now
+	return nil
+}
+
+func (r *Passkeys) Rename(userID int, id int64, name string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	passkey, ok := r.passkeys[id]
+	if !ok || passkey.UserID != userID {
+		return false, nil
+	}
+	passkey.Name = name
+	return true, nil
+}
+
+func (r *Passkeys) Delete(userID int, id int64) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	passkey, ok := r.passkeys[id]
+	if !ok || passkey.UserID != userID {
+		return false, nil
+	}
+	delete(r.passkeys, id)
+	return true, nil
+}
+
+func (r *Passkeys) CreateSession(session *model.WebAuthnSession) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	session.ID = r.nextID
+	session.CreatedAt = time.Now()
+	copied := *session
+	r.sessions[session.TokenHash] = &copied
+	return nil
+}
+
+func (r *Passkeys) TakeSession(tokenHash, ceremony string) (*model.WebAuthnSession, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	session, ok := r.sessions[tokenHash]
+	if !ok || session.Ceremony != ceremony {
+		return nil, nil
+	}
+	delete(r.sessions, tokenHash)
+	if time.Now().After(session.ExpiresAt) {
+		return nil, nil
+	}
+	return session, nil
+}
+
+// Get mengembalikan salinan passkey tersimpan tanpa memeriksa pemiliknya
+func (r *Passkeys) Get(id int64) (model.Passkey, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	passkey, ok := r.passkeys[id]
+	if !ok {
+		return model.Passkey{}, false
+	}
+	return *passkey, true
+}
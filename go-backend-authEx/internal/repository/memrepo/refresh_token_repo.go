@@ -0,0 +1,92 @@
+package memrepo
+
+import (
+	"sync"
+	"time"
+
+	"go-auth-example/internal/model"
+)
+
+// RefreshTokens adalah repository.RefreshTokenRepository in-memory
+type RefreshTokens struct {
+	mu     sync.Mutex
+	nextID int64
+	tokens map[int64]*model.RefreshToken
+}
+
+// NewRefreshTokens membuat repository refresh token kosong
+func NewRefreshTokens() *RefreshTokens {
+	return &RefreshTokens{tokens: map[int64]*model.RefreshToken{}}
+}
+
+func (r *RefreshTokens) Create(token *model.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	token.ID = r.nextID
+	token.CreatedAt = time.Now()
+	copied := *token
+	r.tokens[token.ID] = &copied
+	return nil
+}
+
+func (r *RefreshTokens) GetByHash(tokenHash string) (*model.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, token := range r.tokens {
+		if token.TokenHash == tokenHash {
+			copied := *token
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *RefreshTokens) MarkUsed(id int64) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	token, ok := r.tokens[id]
+	if !ok || token.UsedAt != nil || token.RevokedAt != nil {
+		return false, nil
+	}
+	now := time.Now()
+	token.UsedAt = &now
+	return true, nil
+}
+
+func (r *RefreshTokens) RevokeFamily(familyID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	for _, token := range r.tokens {
+		if token.FamilyID == familyID && token.RevokedAt == nil {
+			token.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+func (r *RefreshTokens) RevokeAllForUser(userID int, exceptFamilyID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	for _, token := range r.tokens {
+		if token.UserID == userID && token.FamilyID != exceptFamilyID && token.RevokedAt == nil {
+			token.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+// RevokedCount menghitung refresh token yang sudah dicabut di family tertentu
+func (r *RefreshTokens) RevokedCount(familyID string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	count := 0
+	for _, token := range r.tokens {
+		if token.FamilyID == familyID && token.RevokedAt != nil {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,39 @@
+package memrepo
+
+import (
+	"sync"
+	"time"
+
+	"go-auth-example/internal/repository"
+)
+
+// RevokedTokens adalah repository.RevokedTokenRepository in-memory untuk batas waktu terbit token per user
+type RevokedTokens struct {
+	repository.RevokedTokenRepository
+	mu      sync.Mutex
+	cutoffs map[int]time.Time
+}
+
+// NewRevokedTokens membuat repository revoked token kosong
+func NewRevokedTokens() *RevokedTokens {
+	return &RevokedTokens{cutoffs: map[int]time.Time{}}
+}
+
+func (r *RevokedTokens) SetUserCutoff(userID int, before time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if before.After(r.cutoffs[userID]) {
+		r.cutoffs[userID] = before
+	}
+	return nil
+}
+
+func (r *RevokedTokens) GetUserCutoff(userID int) (*time.Time, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	before, ok := r.cutoffs[userID]
+	if !ok {
+		return nil, nil
+	}
+	return &before, nil
+}
@@ -0,0 +1,43 @@
+package memrepo
+
+import (
+	"sync"
+	"time"
+
+	"go-auth-example/internal/model"
+	"go-auth-example/internal/repository"
+)
+
+// Sessions adalah repository.SessionRepository in-memory
+type Sessions struct {
+	repository.SessionRepository
+	mu       sync.Mutex
+	sessions map[string]*model.Session
+}
+
+// NewSessions membuat repository session kosong
+func NewSessions() *Sessions {
+	return &Sessions{sessions: map[string]*model.Session{}}
+}
+
+func (r *Sessions) Create(session *model.Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	session.CreatedAt = now
+	session.LastSeenAt = now
+	copied := *session
+	r.sessions[session.ID] = &copied
+	return nil
+}
+
+func (r *Sessions) GetByID(id string) (*model.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	session, ok := r.sessions[id]
+	if !ok {
+		return nil, nil
+	}
+	copied := *session
+	return &copied, nil
+}
@@ -0,0 +1,136 @@
+package memrepo
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"go-auth-example/internal/auth"
+	"go-auth-example/internal/model"
+	"go-auth-example/internal/repository"
+)
+
+// Users adalah repository.UserRepository in-memory
+type Users struct {
+	repository.UserRepository
+	mu     sync.Mutex
+	nextID int
+	users  map[int]*model.User
+}
+
+// NewUsers membuat repository berisi user yang diberikan; ID user baru melanjutkan ID terbesar
+func NewUsers(users ...model.User) *Users {
+	r := &Users{users: map[int]*model.User{}}
+	for i := range users {
+		user := users[i]
+		r.users[user.ID] = &user
+		if user.ID > r.nextID {
+			r.nextID = user.ID
+		}
+	}
+	return r
+}
+
+func (r *Users) Create(user *model.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.users {
+		if auth.CanonicalIdentity(existing.Username) == auth.CanonicalIdentity(user.Username) {
+			return errors.New("username already exists")
+		}
+		if auth.CanonicalIdentity(existing.Email) == auth.CanonicalIdentity(user.Email) {
+			return errors.New("email already exists")
+		}
+	}
+	r.nextID++
+	user.ID = r.nextID
+	user.Status = model.UserStatusActive
+	user.CreatedAt = time.Now()
+	copied := *user
+	r.users[user.ID] = &copied
+	return nil
+}
+
+func (r *Users) GetByID(id int) (*model.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user, ok := r.users[id]
+	if !ok {
+		return nil, nil
+	}
+	copied := *user
+	return &copied, nil
+}
+
+// findBy mencari user pertama yang cocok; dipanggil dengan mutex terkunci
+func (r *Users) findBy(match func(user *model.User) bool) *model.User {
+	for _, user := range r.users {
+		if match(user) {
+			copied := *user
+			return &copied
+		}
+	}
+	return nil
+}
+
+func (r *Users) GetByEmail(email string) (*model.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.findBy(func(user *model.User) bool {
+		return auth.CanonicalIdentity(user.Email) == auth.CanonicalIdentity(email)
+	}), nil
+}
+
+func (r *Users) GetByUsername(username string) (*model.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.findBy(func(user *model.User) bool {
+		return auth.CanonicalIdentity(user.Username) == auth.CanonicalIdentity(username)
+	}), nil
+}
+
+func (r *Users) MarkEmailVerified(id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if user, ok := r.users[id]; ok && user.EmailVerifiedAt == nil {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+	}
+	return nil
+}
+
+func (r *Users) ResetFailedLogins(id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if user, ok := r.users[id]; ok {
+		user.FailedLoginCount = 0
+		user.LastFailedLoginAt = nil
+		user.LockedUntil = nil
+	}
+	return nil
+}
+
+func (r *Users) RecordFailedLogin(id int, since time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user, ok := r.users[id]
+	if !ok {
+		return 0, nil
+	}
+	if user.LastFailedLoginAt != nil && user.LastFailedLoginAt.Before(since) {
+		user.FailedLoginCount = 0
+	}
+	now := time.Now()
+	user.FailedLoginCount++
+	user.LastFailedLoginAt = &now
+	return user.FailedLoginCount, nil
+}
+
+func (r *Users) LockUntil(id int, until time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if user, ok := r.users[id]; ok {
+		user.LockedUntil = &until
+	}
+	return nil
+}
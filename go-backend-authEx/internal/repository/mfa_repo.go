@@ -0,0 +1,229 @@
+// internal/repository/mfa_repo.go
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+
+	"go-auth-example/internal/model"
+)
+
+// MFARepository mendefinisikan operasi penyimpanan faktor MFA, kode pemulihan dan challenge login
+type MFARepository interface {
+	// GetTOTP mengembalikan faktor TOTP user (terkonfirmasi atau belum), nil jika tidak ada
+	GetTOTP(userID int) (*model.TOTPFactor, error)
+	// SaveTOTP menyimpan secret baru yang belum terkonfirmasi, menggantikan secret lama user
+	SaveTOTP(userID int, secret string) error
+	// ConfirmTOTP mengaktifkan faktor TOTP dan mencatat periode kode yang dipakai untuk konfirmasi
+	ConfirmTOTP(userID int, step int64) error
+	// UseTOTPStep mencatat periode kode TOTP yang dipakai secara atomik.
+	// Mengembalikan false jika periode tersebut (atau yang lebih baru) sudah pernah dipakai.
+	UseTOTPStep(userID int, step int64) (bool, error)
+	// DeleteFactors menghapus faktor TOTP, kode pemulihan dan challenge user (MFA nonaktif)
+	DeleteFactors(userID int) error
+
+	// ReplaceRecoveryCodes mengganti semua kode pemulihan user dengan hash yang baru
+	ReplaceRecoveryCodes(userID int, codeHashes []string) error
+	// UseRecoveryCode menandai kode pemulihan sebagai terpakai secara atomik; false jika tidak ada atau sudah dipakai
+	UseRecoveryCode(userID int, codeHash string) (bool, error)
+	// CountRecoveryCodes menghitung kode pemulihan yang belum dipakai
+	CountRecoveryCodes(userID int) (int, error)
+
+	// CreateChallenge menyimpan challenge baru sekaligus membersihkan challenge yang sudah kedaluwarsa
+	CreateChallenge(challenge *model.MFAChallenge) error
+	// GetChallenge mengembalikan challenge yang belum kedaluwarsa, nil jika tidak ada
+	GetChallenge(tokenHash string) (*model.MFAChallenge, error)
+	// IncrementChallengeAttempts menambah hitungan kode salah dan mengembalikan jumlah terbarunya
+	IncrementChallengeAttempts(id int64) (int, error)
+	// DeleteChallenge menghapus challenge; false jika sudah dihapus sebelumnya (dipakai request lain)
+	DeleteChallenge(id int64) (bool, error)
+}
+
+// Implementasi MFARepository untuk PostgreSQL
+type postgresMFARepository struct {
+	db *sql.DB
+}
+
+// NewPostgresMFARepository adalah constructor untuk MFA repository
+func NewPostgresMFARepository(db *sql.DB) MFARepository {
+	return &postgresMFARepository{db: db}
+}
+
+func (p *postgresMFARepository) GetTOTP(userID int) (*model.TOTPFactor, error) {
+	query := `SELECT user_id, secret, confirmed_at, last_used_step, created_at FROM mfa_totp WHERE user_id = $1`
+
+	factor := &model.TOTPFactor{}
+	err := p.db.QueryRow(query, userID).Scan(&factor.UserID, &factor.Secret, &factor.ConfirmedAt, &factor.LastUsedStep, &factor.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		log.Printf("Error getting TOTP factor of user %d: %v", userID, err)
+		return nil, fmt.Errorf("could not get totp factor: %w", err)
+	}
+	return factor, nil
+}
+
+func (p *postgresMFARepository) SaveTOTP(userID int, secret string) error {
+	query := `INSERT INTO mfa_totp (user_id, secret) VALUES ($1, $2)
+	          ON CONFLICT (user_id) DO UPDATE SET secret = EXCLUDED.secret, confirmed_at = NULL, last_used_step = 0, created_at = NOW()`
+	if _, err := p.db.Exec(query, userID, secret); err != nil {
+		log.Printf("Error saving TOTP factor of user %d: %v", userID, err)
+		return fmt.Errorf("could not save totp factor: %w", err)
+	}
+	return nil
+}
+
+func (p *postgresMFARepository) ConfirmTOTP(userID int, step int64) error {
+	query := `UPDATE mfa_totp SET confirmed_at = NOW(), last_used_step = $2 WHERE user_id = $1`
+	if _, err := p.db.Exec(query, userID, step); err != nil {
+		log.Printf("Error confirming TOTP factor of user %d: %v", userID, err)
+		return fmt.Errorf("could not confirm totp factor: %w", err)
+	}
+	return nil
+}
+
+func (p *postgresMFARepository) UseTOTPStep(userID int, step int64) (bool, error) {
+	query := `UPDATE mfa_totp SET last_used_step = $2 WHERE user_id = $1 AND last_used_step < $2`
+	result, err := p.db.Exec(query, userID, step)
+	if err != nil {
+		log.Printf("Error recording TOTP step of user %d: %v", userID, err)
+		return false, fmt.Errorf("could not record totp step: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("could not record totp step: %w", err)
+	}
+	return n == 1, nil
+}
+
+func (p *postgresMFARepository) DeleteFactors(userID int) error {
+	tx, err := p.db.Begin()
+	if err != nil {
+		return fmt.Errorf("could not delete mfa factors: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, query := range []string{
+		`DELETE FROM mfa_totp WHERE user_id = $1`,
+		`DELETE FROM mfa_recovery_codes WHERE user_id = $1`,
+		`DELETE FROM mfa_challenges WHERE user_id = $1`,
+	} {
+		if _, err := tx.Exec(query, userID); err != nil {
+			log.Printf("Error deleting MFA factors of user %d: %v", userID, err)
+			return fmt.Errorf("could not delete mfa factors: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("could not delete mfa factors: %w", err)
+	}
+	return nil
+}
+
+func (p *postgresMFARepository) ReplaceRecoveryCodes(userID int, codeHashes []string) error {
+	tx, err := p.db.Begin()
+	if err != nil {
+		return fmt.Errorf("could not replace recovery codes: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		log.Printf("Error deleting recovery codes of user %d: %v", userID, err)
+		return fmt.Errorf("could not replace recovery codes: %w", err)
+	}
+	for _, hash := range codeHashes {
+		if _, err := tx.Exec(`INSERT INTO mfa_recovery_codes (user_id, code_hash) VALUES ($1, $2)`, userID, hash); err != nil {
+			log.Printf("Error inserting recovery code of user %d: %v", userID, err)
+			return fmt.Errorf("could not replace recovery codes: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("could not replace recovery codes: %w", err)
+	}
+	return nil
+}
+
+func (p *postgresMFARepository) UseRecoveryCode(userID int, codeHash string) (bool, error) {
+	query := `UPDATE mfa_recovery_codes SET used_at = NOW() WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL`
+	result, err := p.db.Exec(query, userID, codeHash)
+	if err != nil {
+		log.Printf("Error using recovery code of user %d: %v", userID, err)
+		return false, fmt.Errorf("could not use recovery code: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("could not use recovery code: %w", err)
+	}
+	return n == 1, nil
+}
+
+func (p *postgresMFARepository) CountRecoveryCodes(userID int) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM mfa_recovery_codes WHERE user_id = $1 AND used_at IS NULL`
+	if err := p.db.QueryRow(query, userID).Scan(&count); err != nil {
+		log.Printf("Error counting recovery codes of user %d: %v", userID, err)
+		return 0, fmt.Errorf("could not count recovery codes: %w", err)
+	}
+	return count, nil
+}
+
+func (p *postgresMFARepository) CreateChallenge(challenge *model.MFAChallenge) error {
+	if _, err := p.db.Exec(`DELETE FROM mfa_challenges WHERE expires_at <= NOW()`); err != nil {
+		log.Printf("Error deleting expired MFA challenges: %v", err)
+	}
+
+	query := `INSERT INTO mfa_challenges (user_id, token_hash, auth_time, expires_at)
+	          VALUES ($1, $2, $3, $4) RETURNING id, created_at`
+
+	err := p.db.QueryRow(query, challenge.UserID, challenge.TokenHash, challenge.AuthTime, challenge.ExpiresAt).
+		Scan(&challenge.ID, &challenge.CreatedAt)
+	if err != nil {
+		log.Printf("Error creating MFA challenge for user %d: %v", challenge.UserID, err)
+		return fmt.Errorf("could not create mfa challenge: %w", err)
+	}
+	return nil
+}
+
+func (p *postgresMFARepository) GetChallenge(tokenHash string) (*model.MFAChallenge, error) {
+	query := `SELECT id, user_id, token_hash, auth_time, attempts, expires_at, created_at
+	          FROM mfa_challenges WHERE token_hash = $1 AND expires_at > NOW()`
+
+	challenge := &model.MFAChallenge{}
+	err := p.db.QueryRow(query, tokenHash).Scan(&challenge.ID, &challenge.UserID, &challenge.TokenHash, &challenge.AuthTime,
+		&challenge.Attempts, &challenge.ExpiresAt, &challenge.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		log.Printf("Error getting MFA challenge: %v", err)
+		return nil, fmt.Errorf("could not get mfa challenge: %w", err)
+	}
+	return challenge, nil
+}
+
+func (p *postgresMFARepository) IncrementChallengeAttempts(id int64) (int, error) {
+	var attempts int
+	query := `UPDATE mfa_challenges SET attempts = attempts + 1 WHERE id = $1 RETURNING attempts`
+	if err := p.db.QueryRow(query, id).Scan(&attempts); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, nil
+		}
+		log.Printf("Error incrementing attempts of MFA challenge %d: %v", id, err)
+		return 0, fmt.Errorf("could not update mfa challenge: %w", err)
+	}
+	return attempts, nil
+}
+
+func (p *postgresMFARepository) DeleteChallenge(id int64) (bool, error) {
+	result, err := p.db.Exec(`DELETE FROM mfa_challenges WHERE id = $1`, id)
+	if err != nil {
+		log.Printf("Error deleting MFA challenge %d: %v", id, err)
+		return false, fmt.Errorf("could not delete mfa challenge: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("could not delete mfa challenge: %w", err)
+	}
+	return n == 1, nil
+}
@@ -0,0 +1,63 @@
+// internal/repository/oauth_client_repo.go
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+
+	"go-auth-example/internal/model"
+)
+
+// OAuthClientRepository mendefinisikan operasi registry OAuth client
+type OAuthClientRepository interface {
+	Create(client *model.OAuthClient) error
+	GetByClientID(clientID string) (*model.OAuthClient, error)
+}
+
+// Implementasi OAuthClientRepository untuk PostgreSQL.
+// Daftar (redirect URI, grant type, scope) disimpan sebagai teks dipisah spasi, sama seperti format parameter scope OAuth.
+type postgresOAuthClientRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresOAuthClientRepository adalah constructor untuk OAuth client repository
+func NewPostgresOAuthClientRepository(db *sql.DB) OAuthClientRepository {
+	return &postgresOAuthClientRepository{db: db}
+}
+
+func (p *postgresOAuthClientRepository) Create(client *model.OAuthClient) error {
+	query := `INSERT INTO oauth_clients (client_id, secret_hash, name, redirect_uris, grant_types, scopes)
+	          VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at`
+
+	err := p.db.QueryRow(query, client.ClientID, client.SecretHash, client.Name,
+		strings.Join(client.RedirectURIs, " "), strings.Join(client.GrantTypes, " "), strings.Join(client.Scopes, " ")).
+		Scan(&client.ID, &client.CreatedAt)
+	if err != nil {
+		log.Printf("Error creating OAuth client %s: %v", client.Name, err)
+		return fmt.Errorf("could not create oauth client: %w", err)
+	}
+	return nil
+}
+
+func (p *postgresOAuthClientRepository) GetByClientID(clientID string) (*model.OAuthClient, error) {
+	client := &model.OAuthClient{}
+	var redirectURIs, grantTypes, scopes string
+	query := `SELECT id, client_id, secret_hash, name, redirect_uris, grant_types, scopes, created_at
+	          FROM oauth_clients WHERE client_id = $1`
+
+	err := p.db.QueryRow(query, clientID).Scan(&client.ID, &client.ClientID, &client.SecretHash, &client.Name,
+		&redirectURIs, &grantTypes, &scopes, &client.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		log.Printf("Error getting OAuth client %s: %v", clientID, err)
+		return nil, fmt.Errorf("could not get oauth client: %w", err)
+	}
+	client.RedirectURIs = strings.Fields(redirectURIs)
+	client.GrantTypes = strings.Fields(grantTypes)
+	client.Scopes = strings.Fields(scopes)
+	return client, nil
+}
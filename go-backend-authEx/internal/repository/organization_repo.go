@@ -0,0 +1,360 @@
+// internal/repository/organization_repo.go
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+
+	"go-auth-example/internal/auth"
+	"go-auth-example/internal/model"
+)
+
+// OrganizationRepository mendefinisikan operasi penyimpanan organisasi, anggota, undangan
+// dan organisasi aktif per session login
+type OrganizationRepository interface {
+	// Create menyimpan organisasi baru dengan ownerID sebagai owner pertama;
+	// mengembalikan "slug already exists" jika slug sudah dipakai
+	Create(org *model.Organization, ownerID int) error
+	// GetByID mengembalikan organisasi, nil jika tidak ada
+	GetByID(id int) (*model.Organization, error)
+	// ListForUser mengembalikan organisasi tempat user menjadi anggota beserta role-nya, urut waktu bergabung
+	ListForUser(userID int) ([]model.Organization, error)
+	// Delete menghapus organisasi beserta anggota dan undangannya
+	Delete(id int) error
+
+	// GetMembership mengembalikan keanggotaan user di organisasi, nil jika bukan anggota
+	GetMembership(orgID, userID int) (*model.Membership, error)
+	// ListMembers mengembalikan semua anggota organisasi, urut waktu bergabung
+	ListMembers(orgID int) ([]model.Membership, error)
+	// UpdateMemberRole mengganti role anggota; false jika user bukan anggota
+	UpdateMemberRole(orgID, userID int, role string) (bool, error)
+	// RemoveMember mengeluarkan anggota; false jika user bukan anggota
+	RemoveMember(orgID, userID int) (bool, error)
+	// CountMembersWithRole menghitung anggota organisasi dengan role tertentu
+	CountMembersWithRole(orgID int, role string) (int, error)
+
+	// CreateInvitation menyimpan undangan; undangan lama untuk email yang sama di organisasi yang sama diganti
+	CreateInvitation(invitation *model.Invitation) error
+	// GetInvitation mengembalikan undangan yang belum kedaluwarsa, nil jika tidak ada
+	GetInvitation(id int64) (*model.Invitation, error)
+	// ListInvitations mengembalikan undangan organisasi yang belum kedaluwarsa
+	ListInvitations(orgID int) ([]model.Invitation, error)
+	// ListInvitationsForEmail mengembalikan undangan yang belum kedaluwarsa untuk alamat email (tidak peka huruf besar/kecil)
+	ListInvitationsForEmail(email string) ([]model.Invitation, error)
+	// DeleteInvitation menghapus undangan (ditolak atau dibatalkan); false jika tidak ada
+	DeleteInvitation(id int64) (bool, error)
+	// AcceptInvitation menjadikan user anggota dengan role dari undangan lalu menghapus undangannya dalam satu transaksi.
+	// Jika user sudah menjadi anggota, role-nya tidak diubah.
+	AcceptInvitation(invitation *model.Invitation, userID int) error
+
+	// GetActiveOrganization mengembalikan ID organisasi aktif session login, 0 jika belum dipilih
+	GetActiveOrganization(sessionID string) (int, error)
+	// SetActiveOrganization menyimpan organisasi aktif session login milik user
+	SetActiveOrganization(sessionID string, userID, orgID int) error
+}
+
+// Implementasi OrganizationRepository untuk PostgreSQL
+type postgresOrganizationRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresOrganizationRepository adalah constructor untuk organization repository
+func NewPostgresOrganizationRepository(db *sql.DB) OrganizationRepository {
+	return &postgresOrganizationRepository{db: db}
+}
+
+func (p *postgresOrganizationRepository) Create(org *model.Organization, ownerID int) error {
+	tx, err := p.db.Begin()
+	if err != nil {
+		return fmt.Errorf("could not create organization: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO organizations (name, slug) VALUES ($1, $2) RETURNING id, created_at`
+	if err := tx.QueryRow(query, org.Name, org.Slug).Scan(&org.ID, &org.CreatedAt); err != nil {
+		log.Printf("Error creating organization %q: %v", org.Slug, err)
+		if strings.Contains(err.Error(), "duplicate key value violates unique constraint") {
+			return fmt.Errorf("slug already exists")
+		}
+		return fmt.Errorf("could not create organization: %w", err)
+	}
+	query = `INSERT INTO organization_members (organization_id, user_id, role) VALUES ($1, $2, $3)`
+	if _, err := tx.Exec(query, org.ID, ownerID, model.OrgRoleOwner); err != nil {
+		log.Printf("Error adding owner %d to organization %d: %v", ownerID, org.ID, err)
+		return fmt.Errorf("could not create organization: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("could not create organization: %w", err)
+	}
+	org.Role = model.OrgRoleOwner
+	return nil
+}
+
+func (p *postgresOrganizationRepository) GetByID(id int) (*model.Organization, error) {
+	org := &model.Organization{}
+	query := `SELECT id, name, slug, created_at FROM organizations WHERE id = $1`
+	if err := p.db.QueryRow(query, id).Scan(&org.ID, &org.Name, &org.Slug, &org.CreatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		log.Printf("Error getting organization %d: %v", id, err)
+		return nil, fmt.Errorf("could not get organization: %w", err)
+	}
+	return org, nil
+}
+
+func (p *postgresOrganizationRepository) ListForUser(userID int) ([]model.Organization, error) {
+	query := `SELECT o.id, o.name, o.slug, o.created_at, m.role
+	          FROM organization_members m JOIN organizations o ON o.id = m.organization_id
+	          WHERE m.user_id = $1 ORDER BY m.created_at, o.id`
+
+	rows, err := p.db.Query(query, userID)
+	if err != nil {
+		log.Printf("Error listing organizations of user %d: %v", userID, err)
+		return nil, fmt.Errorf("could not list organizations: %w", err)
+	}
+	defer rows.Close()
+
+	orgs := []model.Organization{}
+	for rows.Next() {
+		var org model.Organization
+		if err := rows.Scan(&org.ID, &org.Name, &org.Slug, &org.CreatedAt, &org.Role); err != nil {
+			log.Printf("Error scanning organization of user %d: %v", userID, err)
+			return nil, fmt.Errorf("could not list organizations: %w", err)
+		}
+		orgs = append(orgs, org)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not list organizations: %w", err)
+	}
+	return orgs, nil
+}
+
+func (p *postgresOrganizationRepository) Delete(id int) error {
+	if _, err := p.db.Exec(`DELETE FROM organizations WHERE id = $1`, id); err != nil {
+		log.Printf("Error deleting organization %d: %v", id, err)
+		return fmt.Errorf("could not delete organization: %w", err)
+	}
+	return nil
+}
+
+// membershipQuery memilih keanggotaan beserta username dan email anggotanya
+const membershipQuery = `SELECT m.organization_id, m.user_id, u.username, u.email, m.role, m.created_at
+	FROM organization_members m JOIN users u ON u.id = m.user_id`
+
+func scanMembership(row rowScanner) (*model.Membership, error) {
+	membership := &model.Membership{}
+	if err := row.Scan(&membership.OrganizationID, &membership.UserID, &membership.Username, &membership.Email,
+		&membership.Role, &membership.CreatedAt); err != nil {
+		return nil, err
+	}
+	return membership, nil
+}
+
+func (p *postgresOrganizationRepository) GetMembership(orgID, userID int) (*model.Membership, error) {
+	membership, err := scanMembership(p.db.QueryRow(membershipQuery+` WHERE m.organization_id = $1 AND m.user_id = $2`, orgID, userID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		log.Printf("Error getting membership of user %d in organization %d: %v", userID, orgID, err)
+		return nil, fmt.Errorf("could not get membership: %w", err)
+	}
+	return membership, nil
+}
+
+func (p *postgresOrganizationRepository) ListMembers(orgID int) ([]model.Membership, error) {
+	rows, err := p.db.Query(membershipQuery+` WHERE m.organization_id = $1 ORDER BY m.created_at, m.user_id`, orgID)
+	if err != nil {
+		log.Printf("Error listing members of organization %d: %v", orgID, err)
+		return nil, fmt.Errorf("could not list members: %w", err)
+	}
+	defer rows.Close()
+
+	members := []model.Membership{}
+	for rows.Next() {
+		membership, err := scanMembership(rows)
+		if err != nil {
+			log.Printf("Error scanning member of organization %d: %v", orgID, err)
+			return nil, fmt.Errorf("could not list members: %w", err)
+		}
+		members = append(members, *membership)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not list members: %w", err)
+	}
+	return members, nil
+}
+
+func (p *postgresOrganizationRepository) UpdateMemberRole(orgID, userID int, role string) (bool, error) {
+	query := `UPDATE organization_members SET role = $3 WHERE organization_id = $1 AND user_id = $2`
+	result, err := p.db.Exec(query, orgID, userID, role)
+	if err != nil {
+		log.Printf("Error updating role of user %d in organization %d: %v", userID, orgID, err)
+		return false, fmt.Errorf("could not update member role: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("could not update member role: %w", err)
+	}
+	return n == 1, nil
+}
+
+func (p *postgresOrganizationRepository) RemoveMember(orgID, userID int) (bool, error) {
+	query := `DELETE FROM organization_members WHERE organization_id = $1 AND user_id = $2`
+	result, err := p.db.Exec(query, orgID, userID)
+	if err != nil {
+		log.Printf("Error removing user %d from organization %d: %v", userID, orgID, err)
+		return false, fmt.Errorf("could not remove member: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("could not remove member: %w", err)
+	}
+	return n == 1, nil
+}
+
+func (p *postgresOrganizationRepository) CountMembersWithRole(orgID int, role string) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM organization_members WHERE organization_id = $1 AND role = $2`
+	if err := p.db.QueryRow(query, orgID, role).Scan(&count); err != nil {
+		log.Printf("Error counting %s members of organization %d: %v", role, orgID, err)
+		return 0, fmt.Errorf("could not count members: %w", err)
+	}
+	return count, nil
+}
+
+func (p *postgresOrganizationRepository) CreateInvitation(invitation *model.Invitation) error {
+	query := `INSERT INTO organization_invitations (organization_id, email, email_canonical, role, invited_by, expires_at)
+	          VALUES ($1, $2, $3, $4, $5, $6)
+	          ON CONFLICT (organization_id, email_canonical) DO UPDATE
+	          SET email = EXCLUDED.email, role = EXCLUDED.role, invited_by = EXCLUDED.invited_by,
+	              expires_at = EXCLUDED.expires_at, created_at = NOW()
+	          RETURNING id, created_at`
+
+	err := p.db.QueryRow(query, invitation.OrganizationID, invitation.Email, auth.CanonicalIdentity(invitation.Email),
+		invitation.Role, invitation.InvitedBy, invitation.ExpiresAt).Scan(&invitation.ID, &invitation.CreatedAt)
+	if err != nil {
+		log.Printf("Error creating invitation to organization %d: %v", invitation.OrganizationID, err)
+		return fmt.Errorf("could not create invitation: %w", err)
+	}
+	return nil
+}
+
+// invitationQuery memilih undangan beserta nama organisasi dan username pengundangnya
+const invitationQuery = `SELECT i.id, i.organization_id, o.name, i.email, i.role, i.invited_by, COALESCE(u.username, ''),
+	i.expires_at, i.created_at
+	FROM organization_invitations i
+	JOIN organizations o ON o.id = i.organization_id
+	LEFT JOIN users u ON u.id = i.invited_by`
+
+func scanInvitation(row rowScanner) (*model.Invitation, error) {
+	invitation := &model.Invitation{}
+	if err := row.Scan(&invitation.ID, &invitation.OrganizationID, &invitation.OrganizationName, &invitation.Email,
+		&invitation.Role, &invitation.InvitedBy, &invitation.InvitedByName, &invitation.ExpiresAt, &invitation.CreatedAt); err != nil {
+		return nil, err
+	}
+	return invitation, nil
+}
+
+func (p *postgresOrganizationRepository) GetInvitation(id int64) (*model.Invitation, error) {
+	invitation, err := scanInvitation(p.db.QueryRow(invitationQuery+` WHERE i.id = $1 AND i.expires_at > NOW()`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		log.Printf("Error getting invitation %d: %v", id, err)
+		return nil, fmt.Errorf("could not get invitation: %w", err)
+	}
+	return invitation, nil
+}
+
+func (p *postgresOrganizationRepository) ListInvitations(orgID int) ([]model.Invitation, error) {
+	return p.listInvitations(`i.organization_id = $1`, orgID)
+}
+
+func (p *postgresOrganizationRepository) ListInvitationsForEmail(email string) ([]model.Invitation, error) {
+	return p.listInvitations(`i.email_canonical = $1`, auth.CanonicalIdentity(email))
+}
+
+func (p *postgresOrganizationRepository) listInvitations(condition string, arg interface{}) ([]model.Invitation, error) {
+	rows, err := p.db.Query(invitationQuery+` WHERE `+condition+` AND i.expires_at > NOW() ORDER BY i.created_at DESC`, arg)
+	if err != nil {
+		log.Printf("Error listing invitations: %v", err)
+		return nil, fmt.Errorf("could not list invitations: %w", err)
+	}
+	defer rows.Close()
+
+	invitations := []model.Invitation{}
+	for rows.Next() {
+		invitation, err := scanInvitation(rows)
+		if err != nil {
+			log.Printf("Error scanning invitation: %v", err)
+			return nil, fmt.Errorf("could not list invitations: %w", err)
+		}
+		invitations = append(invitations, *invitation)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not list invitations: %w", err)
+	}
+	return invitations, nil
+}
+
+func (p *postgresOrganizationRepository) DeleteInvitation(id int64) (bool, error) {
+	result, err := p.db.Exec(`DELETE FROM organization_invitations WHERE id = $1`, id)
+	if err != nil {
+		log.Printf("Error deleting invitation %d: %v", id, err)
+		return false, fmt.Errorf("could not delete invitation: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("could not delete invitation: %w", err)
+	}
+	return n == 1, nil
+}
+
+func (p *postgresOrganizationRepository) AcceptInvitation(invitation *model.Invitation, userID int) error {
+	tx, err := p.db.Begin()
+	if err != nil {
+		return fmt.Errorf("could not accept invitation: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO organization_members (organization_id, user_id, role) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING`
+	if _, err := tx.Exec(query, invitation.OrganizationID, userID, invitation.Role); err != nil {
+		log.Printf("Error adding user %d to organization %d: %v", userID, invitation.OrganizationID, err)
+		return fmt.Errorf("could not accept invitation: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM organization_invitations WHERE id = $1`, invitation.ID); err != nil {
+		log.Printf("Error deleting accepted invitation %d: %v", invitation.ID, err)
+		return fmt.Errorf("could not accept invitation: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("could not accept invitation: %w", err)
+	}
+	return nil
+}
+
+func (p *postgresOrganizationRepository) GetActiveOrganization(sessionID string) (int, error) {
+	var orgID sql.NullInt64
+	err := p.db.QueryRow(`SELECT active_organization_id FROM sessions WHERE id = $1`, sessionID).Scan(&orgID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, nil
+		}
+		log.Printf("Error getting active organization of session: %v", err)
+		return 0, fmt.Errorf("could not get active organization: %w", err)
+	}
+	return int(orgID.Int64), nil
+}
+
+func (p *postgresOrganizationRepository) SetActiveOrganization(sessionID string, userID, orgID int) error {
+	query := `UPDATE sessions SET active_organization_id = $3 WHERE id = $1 AND user_id = $2`
+	if _, err := p.db.Exec(query, sessionID, userID, orgID); err != nil {
+		log.Printf("Error setting active organization of user %d: %v", userID, err)
+		return fmt.Errorf("could not set active organization: %w", err)
+	}
+	return nil
+}
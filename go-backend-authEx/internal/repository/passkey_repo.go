@@ -0,0 +1,184 @@
+// internal/repository/passkey_repo.go
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+
+	"go-auth-example/internal/model"
+)
+
+// PasskeyRepository mendefinisikan operasi penyimpanan credential WebAuthn dan session ceremony-nya
+type PasskeyRepository interface {
+	// ListByUser mengembalikan semua passkey milik user, yang terbaru lebih dulu
+	ListByUser(userID int) ([]model.Passkey, error)
+	// GetByCredentialID mengembalikan passkey berdasarkan credential ID dari authenticator, nil jika tidak ada
+	GetByCredentialID(credentialID []byte) (*model.Passkey, error)
+	Create(passkey *model.Passkey) error
+	// UpdateAfterLogin menyimpan sign counter dan flag terbaru setelah login berhasil
+	UpdateAfterLogin(passkey *model.Passkey) error
+	// Rename mengganti nama passkey milik user; false jika tidak ditemukan
+	Rename(userID int, id int64, name string) (bool, error)
+	// Delete menghapus passkey milik user; false jika tidak ditemukan
+	Delete(userID int, id int64) (bool, error)
+
+	// CreateSession menyimpan session ceremony baru sekaligus membersihkan session yang sudah kedaluwarsa
+	CreateSession(session *model.WebAuthnSession) error
+	// TakeSession mengambil dan menghapus session ceremony secara atomik agar challenge hanya bisa dipakai sekali.
+	// Mengembalikan nil jika tidak ada, sudah kedaluwarsa atau ceremony-nya berbeda.
+	TakeSession(tokenHash, ceremony string) (*model.WebAuthnSession, error)
+}
+
+// Implementasi PasskeyRepository untuk PostgreSQL. Transport disimpan dipisah spasi.
+type postgresPasskeyRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresPasskeyRepository adalah constructor untuk passkey repository
+func NewPostgresPasskeyRepository(db *sql.DB) PasskeyRepository {
+	return &postgresPasskeyRepository{db: db}
+}
+
+const passkeyColumns = `id, user_id, credential_id, public_key, attestation_type, aaguid, transports, sign_count,
+	clone_warning, backup_eligible, backup_state, name, created_at, last_used_at`
+
+func scanPasskey(row rowScanner) (*model.Passkey, error) {
+	passkey := &model.Passkey{}
+	var transports string
+	var signCount int64
+	err := row.Scan(&passkey.ID, &passkey.UserID, &passkey.CredentialID, &passkey.PublicKey, &passkey.AttestationType,
+		&passkey.AAGUID, &transports, &signCount, &passkey.CloneWarning, &passkey.BackupEligible, &passkey.BackupState,
+		&passkey.Name, &passkey.CreatedAt, &passkey.LastUsedAt)
+	if err != nil {
+		return nil, err
+	}
+	passkey.Transports = strings.Fields(transports)
+	passkey.SignCount = uint32(signCount)
+	return passkey, nil
+}
+
+func (p *postgresPasskeyRepository) ListByUser(userID int) ([]model.Passkey, error) {
+	query := `SELECT ` + passkeyColumns + ` FROM webauthn_credentials WHERE user_id = $1 ORDER BY created_at DESC`
+
+	rows, err := p.db.Query(query, userID)
+	if err != nil {
+		log.Printf("Error listing passkeys of user %d: %v", userID, err)
+		return nil, fmt.Errorf("could not list passkeys: %w", err)
+	}
+	defer rows.Close()
+
+	passkeys := []model.Passkey{}
+	for rows.Next() {
+		passkey, err := scanPasskey(rows)
+		if err != nil {
+			log.Printf("Error scanning passkey row: %v", err)
+			return nil, fmt.Errorf("could not list passkeys: %w", err)
+		}
+		passkeys = append(passkeys, *passkey)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not list passkeys: %w", err)
+	}
+	return passkeys, nil
+}
+
+func (p *postgresPasskeyRepository) GetByCredentialID(credentialID []byte) (*model.Passkey, error) {
+	query := `SELECT ` + passkeyColumns + ` FROM webauthn_credentials WHERE credential_id = $1`
+
+	passkey, err := scanPasskey(p.db.QueryRow(query, credentialID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		log.Printf("Error getting passkey by credential ID: %v", err)
+		return nil, fmt.Errorf("could not get passkey: %w", err)
+	}
+	return passkey, nil
+}
+
+func (p *postgresPasskeyRepository) Create(passkey *model.Passkey) error {
+	query := `INSERT INTO webauthn_credentials (user_id, credential_id, public_key, attestation_type, aaguid, transports,
+	              sign_count, backup_eligible, backup_state, name)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id, created_at`
+
+	err := p.db.QueryRow(query, passkey.UserID, passkey.CredentialID, passkey.PublicKey, passkey.AttestationType, passkey.AAGUID,
+		strings.Join(passkey.Transports, " "), int64(passkey.SignCount), passkey.BackupEligible, passkey.BackupState, passkey.Name).
+		Scan(&passkey.ID, &passkey.CreatedAt)
+	if err != nil {
+		log.Printf("Error creating passkey for user %d: %v", passkey.UserID, err)
+		return fmt.Errorf("could not create passkey: %w", err)
+	}
+	return nil
+}
+
+func (p *postgresPasskeyRepository) UpdateAfterLogin(passkey *model.Passkey) error {
+	query := `UPDATE webauthn_credentials SET sign_count = $2, clone_warning = $3, backup_state = $4, last_used_at = NOW()
+	          WHERE id = $1`
+	if _, err := p.db.Exec(query, passkey.ID, int64(passkey.SignCount), passkey.CloneWarning, passkey.BackupState); err != nil {
+		log.Printf("Error updating passkey %d after login: %v", passkey.ID, err)
+		return fmt.Errorf("could not update passkey: %w", err)
+	}
+	return nil
+}
+
+func (p *postgresPasskeyRepository) Rename(userID int, id int64, name string) (bool, error) {
+	result, err := p.db.Exec(`UPDATE webauthn_credentials SET name = $3 WHERE id = $1 AND user_id = $2`, id, userID, name)
+	if err != nil {
+		log.Printf("Error renaming passkey %d of user %d: %v", id, userID, err)
+		return false, fmt.Errorf("could not rename passkey: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("could not rename passkey: %w", err)
+	}
+	return n == 1, nil
+}
+
+func (p *postgresPasskeyRepository) Delete(userID int, id int64) (bool, error) {
+	result, err := p.db.Exec(`DELETE FROM webauthn_credentials WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		log.Printf("Error deleting passkey %d of user %d: %v", id, userID, err)
+		return false, fmt.Errorf("could not delete passkey: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("could not delete passkey: %w", err)
+	}
+	return n == 1, nil
+}
+
+func (p *postgresPasskeyRepository) CreateSession(session *model.WebAuthnSession) error {
+	if _, err := p.db.Exec(`DELETE FROM webauthn_sessions WHERE expires_at <= NOW()`); err != nil {
+		log.Printf("Error deleting expired WebAuthn sessions: %v", err)
+	}
+
+	query := `INSERT INTO webauthn_sessions (user_id, ceremony, token_hash, data, expires_at)
+	          VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at`
+
+	err := p.db.QueryRow(query, session.UserID, session.Ceremony, session.TokenHash, session.Data, session.ExpiresAt).
+		Scan(&session.ID, &session.CreatedAt)
+	if err != nil {
+		log.Printf("Error creating WebAuthn %s session: %v", session.Ceremony, err)
+		return fmt.Errorf("could not create webauthn session: %w", err)
+	}
+	return nil
+}
+
+func (p *postgresPasskeyRepository) TakeSession(tokenHash, ceremony string) (*model.WebAuthnSession, error) {
+	query := `DELETE FROM webauthn_sessions WHERE token_hash = $1 AND ceremony = $2 AND expires_at > NOW()
+	          RETURNING id, user_id, ceremony, token_hash, data, expires_at, created_at`
+
+	session := &model.WebAuthnSession{}
+	err := p.db.QueryRow(query, tokenHash, ceremony).Scan(&session.ID, &session.UserID, &session.Ceremony, &session.TokenHash,
+		&session.Data, &session.ExpiresAt, &session.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		log.Printf("Error taking WebAuthn %s session: %v", ceremony, err)
+		return nil, fmt.Errorf("could not get webauthn session: %w", err)
+	}
+	return session, nil
+}
@@ -0,0 +1,146 @@
+// internal/repository/personal_access_token_repo.go
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+
+	"go-auth-example/internal/model"
+)
+
+// PersonalAccessTokenRepository mendefinisikan operasi penyimpanan personal access token
+type PersonalAccessTokenRepository interface {
+	Create(token *model.PersonalAccessToken) error
+	// GetByHash mengembalikan token yang belum dicabut
+	GetByHash(tokenHash string) (*model.PersonalAccessToken, error)
+	// ListByUser mengembalikan token user yang belum dicabut, terbaru lebih dulu
+	ListByUser(userID int) ([]model.PersonalAccessToken, error)
+	// Revoke mencabut token milik user. Mengembalikan false jika token tidak ditemukan.
+	Revoke(id int64, userID int) (bool, error)
+	// RevokeAllForUser mencabut semua token milik user
+	RevokeAllForUser(userID int) error
+	// Touch mencatat waktu dan IP pemakaian terakhir
+	Touch(id int64, ip string) error
+}
+
+// Implementasi PersonalAccessTokenRepository untuk PostgreSQL. Scope disimpan dipisah spasi.
+type postgresPersonalAccessTokenRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresPersonalAccessTokenRepository adalah constructor untuk personal access token repository
+func NewPostgresPersonalAccessTokenRepository(db *sql.DB) PersonalAccessTokenRepository {
+	return &postgresPersonalAccessTokenRepository{db: db}
+}
+
+const personalAccessTokenColumns = `id, user_id, name, prefix, token_hash, scopes, expires_at,
+	last_used_at, last_used_ip, created_at, revoked_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanPersonalAccessToken(row rowScanner) (*model.PersonalAccessToken, error) {
+	token := &model.PersonalAccessToken{}
+	var scopes string
+	err := row.Scan(&token.ID, &token.UserID, &token.Name, &token.Prefix, &token.TokenHash, &scopes, &token.ExpiresAt,
+		&token.LastUsedAt, &token.LastUsedIP, &token.CreatedAt, &token.RevokedAt)
+	if err != nil {
+		return nil, err
+	}
+	token.Scopes = strings.Fields(scopes)
+	return token, nil
+}
+
+func (p *postgresPersonalAccessTokenRepository) Create(token *model.PersonalAccessToken) error {
+	query := `INSERT INTO personal_access_tokens (user_id, name, prefix, token_hash, scopes, expires_at)
+	          VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at`
+
+	err := p.db.QueryRow(query, token.UserID, token.Name, token.Prefix, token.TokenHash,
+		strings.Join(token.Scopes, " "), token.ExpiresAt).Scan(&token.ID, &token.CreatedAt)
+	if err != nil {
+		log.Printf("Error creating personal access token for user %d: %v", token.UserID, err)
+		return fmt.Errorf("could not create personal access token: %w", err)
+	}
+	return nil
+}
+
+func (p *postgresPersonalAccessTokenRepository) GetByHash(tokenHash string) (*model.PersonalAccessToken, error) {
+	query := `SELECT ` + personalAccessTokenColumns + ` FROM personal_access_tokens
+	          WHERE token_hash = $1 AND revoked_at IS NULL`
+
+	token, err := scanPersonalAccessToken(p.db.QueryRow(query, tokenHash))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		log.Printf("Error getting personal access token by hash: %v", err)
+		return nil, fmt.Errorf("could not get personal access token: %w", err)
+	}
+	return token, nil
+}
+
+func (p *postgresPersonalAccessTokenRepository) ListByUser(userID int) ([]model.PersonalAccessToken, error) {
+	query := `SELECT ` + personalAccessTokenColumns + ` FROM personal_access_tokens
+	          WHERE user_id = $1 AND revoked_at IS NULL ORDER BY created_at DESC`
+
+	rows, err := p.db.Query(query, userID)
+	if err != nil {
+		log.Printf("Error listing personal access tokens for user %d: %v", userID, err)
+		return nil, fmt.Errorf("could not list personal access tokens: %w", err)
+	}
+	defer rows.Close()
+
+	tokens := []model.PersonalAccessToken{}
+	for rows.Next() {
+		token, err := scanPersonalAccessToken(rows)
+		if err != nil {
+			return nil, fmt.Errorf("could not scan personal access token: %w", err)
+		}
+		tokens = append(tokens, *token)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not list personal access tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+func (p *postgresPersonalAccessTokenRepository) Revoke(id int64, userID int) (bool, error) {
+	query := `UPDATE personal_access_tokens SET revoked_at = NOW()
+	          WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`
+
+	result, err := p.db.Exec(query, id, userID)
+	if err != nil {
+		log.Printf("Error revoking personal access token %d: %v", id, err)
+		return false, fmt.Errorf("could not revoke personal access token: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("could not revoke personal access token: %w", err)
+	}
+	return affected == 1, nil
+}
+
+func (p *postgresPersonalAccessTokenRepository) Touch(id int64, ip string) error {
+	// Dibatasi sekali per menit per IP agar setiap request tidak selalu menulis ke database
+	query := `UPDATE personal_access_tokens SET last_used_at = NOW(), last_used_ip = $2
+	          WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute' OR last_used_ip <> $2)`
+
+	if _, err := p.db.Exec(query, id, ip); err != nil {
+		log.Printf("Error recording usage of personal access token %d: %v", id, err)
+		return fmt.Errorf("could not record personal access token usage: %w", err)
+	}
+	return nil
+}
+
+func (p *postgresPersonalAccessTokenRepository) RevokeAllForUser(userID int) error {
+	query := `UPDATE personal_access_tokens SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL`
+
+	if _, err := p.db.Exec(query, userID); err != nil {
+		log.Printf("Error revoking personal access tokens of user %d: %v", userID, err)
+		return fmt.Errorf("could not revoke personal access tokens: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+// internal/repository/refresh_token_repo.go
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+
+	"go-auth-example/internal/model"
+)
+
+// RefreshTokenRepository mendefinisikan operasi penyimpanan refresh token
+type RefreshTokenRepository interface {
+	Create(token *model.RefreshToken) error
+	GetByHash(tokenHash string) (*model.RefreshToken, error)
+	// MarkUsed menandai token sebagai sudah dipakai secara atomik.
+	// Mengembalikan false jika token sudah dipakai atau dicabut sebelumnya.
+	MarkUsed(id int64) (bool, error)
+	RevokeFamily(familyID string) error
+	// RevokeAllForUser mencabut semua refresh token user (termasuk milik OAuth client), kecuali family exceptFamilyID
+	RevokeAllForUser(userID int, exceptFamilyID string) error
+}
+
+// Implementasi RefreshTokenRepository untuk PostgreSQL
+type postgresRefreshTokenRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresRefreshTokenRepository adalah constructor untuk refresh token repository
+func NewPostgresRefreshTokenRepository(db *sql.DB) RefreshTokenRepository {
+	return &postgresRefreshTokenRepository{db: db}
+}
+
+func (p *postgresRefreshTokenRepository) Create(token *model.RefreshToken) error {
+	query := `INSERT INTO refresh_tokens (user_id, family_id, client_id, scope, auth_time, token_hash, expires_at)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, created_at`
+
+	err := p.db.QueryRow(query, token.UserID, token.FamilyID, token.ClientID, token.Scope, token.AuthTime, token.TokenHash, token.ExpiresAt).
+		Scan(&token.ID, &token.CreatedAt)
+	if err != nil {
+		log.Printf("Error creating refresh token for user %d: %v", token.UserID, err)
+		return fmt.Errorf("could not create refresh token: %w", err)
+	}
+	return nil
+}
+
+func (p *postgresRefreshTokenRepository) GetByHash(tokenHash string) (*model.RefreshToken, error) {
+	token := &model.RefreshToken{}
+	query := `SELECT id, user_id, family_id, client_id, scope, auth_time, token_hash, expires_at, created_at, used_at, revoked_at
+	          FROM refresh_tokens WHERE token_hash = $1`
+
+	err := p.db.QueryRow(query, tokenHash).Scan(&token.ID, &token.UserID, &token.FamilyID, &token.ClientID, &token.Scope, &token.AuthTime,
+		&token.TokenHash, &token.ExpiresAt, &token.CreatedAt, &token.UsedAt, &token.RevokedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		log.Printf("Error getting refresh token by hash: %v", err)
+		return nil, fmt.Errorf("could not get refresh token: %w", err)
+	}
+	return token, nil
+}
+
+func (p *postgresRefreshTokenRepository) MarkUsed(id int64) (bool, error) {
+	query := `UPDATE refresh_tokens SET used_at = NOW()
+	          WHERE id = $1 AND used_at IS NULL AND revoked_at IS NULL`
+
+	result, err := p.db.Exec(query, id)
+	if err != nil {
+		log.Printf("Error marking refresh token %d as used: %v", id, err)
+		return false, fmt.Errorf("could not mark refresh token as used: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("could not mark refresh token as used: %w", err)
+	}
+	return affected == 1, nil
+}
+
+func (p *postgresRefreshTokenRepository) RevokeFamily(familyID string) error {
+	query := `UPDATE refresh_tokens SET revoked_at = NOW() WHERE family_id = $1 AND revoked_at IS NULL`
+
+	if _, err := p.db.Exec(query, familyID); err != nil {
+		log.Printf("Error revoking refresh token family %s: %v", familyID, err)
+		return fmt.Errorf("could not revoke refresh token family: %w", err)
+	}
+	return nil
+}
+
+func (p *postgresRefreshTokenRepository) RevokeAllForUser(userID int, exceptFamilyID string) error {
+	query := `UPDATE refresh_tokens SET revoked_at = NOW()
+	          WHERE user_id = $1 AND family_id <> $2 AND revoked_at IS NULL`
+
+	if _, err := p.db.Exec(query, userID, exceptFamilyID); err != nil {
+		log.Printf("Error revoking refresh tokens of user %d: %v", userID, err)
+		return fmt.Errorf("could not revoke refresh tokens: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+// internal/repository/revoked_token_repo.go
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"go-auth-example/internal/model"
+)
+
+// RevokedTokenRepository mendefinisikan operasi penyimpanan denylist access token
+type RevokedTokenRepository interface {
+	Add(token *model.RevokedToken) error
+	Exists(jti string) (bool, error)
+	ListActive() ([]model.RevokedToken, error)
+	DeleteExpired(before time.Time) (int64, error)
+	// SetUserCutoff mencatat bahwa access token user yang diterbitkan sebelum waktu tersebut tidak berlaku lagi.
+	// Batas yang sudah tercatat tidak pernah dimundurkan.
+	SetUserCutoff(userID int, before time.Time) error
+	// GetUserCutoff mengembalikan batas tersebut, atau nil jika token user belum pernah dicabut
+	GetUserCutoff(userID int) (*time.Time, error)
+}
+
+// Implementasi RevokedTokenRepository untuk PostgreSQL
+type postgresRevokedTokenRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresRevokedTokenRepository adalah constructor untuk revoked token repository
+func NewPostgresRevokedTokenRepository(db *sql.DB) RevokedTokenRepository {
+	return &postgresRevokedTokenRepository{db: db}
+}
+
+func (p *postgresRevokedTokenRepository) Add(token *model.RevokedToken) error {
+	// Mencabut token yang sama dua kali bukan error
+	query := `INSERT INTO revoked_tokens (jti, user_id, expires_at)
+	          VALUES ($1, $2, $3) ON CONFLICT (jti) DO NOTHING RETURNING revoked_at`
+
+	err := p.db.QueryRow(query, token.JTI, token.UserID, token.ExpiresAt).Scan(&token.RevokedAt)
+	if err != nil && err != sql.ErrNoRows {
+		log.Printf("Error revoking token %s: %v", token.JTI, err)
+		return fmt.Errorf("could not revoke token: %w", err)
+	}
+	return nil
+}
+
+func (p *postgresRevokedTokenRepository) Exists(jti string) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE jti = $1)`
+
+	if err := p.db.QueryRow(query, jti).Scan(&exists); err != nil {
+		log.Printf("Error checking revoked token %s: %v", jti, err)
+		return false, fmt.Errorf("could not check revoked token: %w", err)
+	}
+	return exists, nil
+}
+
+func (p *postgresRevokedTokenRepository) ListActive() ([]model.RevokedToken, error) {
+	query := `SELECT jti, user_id, expires_at, revoked_at FROM revoked_tokens WHERE expires_at > NOW()`
+
+	rows, err := p.db.Query(query)
+	if err != nil {
+		log.Printf("Error listing revoked tokens: %v", err)
+		return nil, fmt.Errorf("could not list revoked tokens: %w", err)
+	}
+	defer rows.Close()
+
+	var tokens []model.RevokedToken
+	for rows.Next() {
+		var t model.RevokedToken
+		if err := rows.Scan(&t.JTI, &t.UserID, &t.ExpiresAt, &t.RevokedAt); err != nil {
+			return nil, fmt.Errorf("could not scan revoked token: %w", err)
+		}
+		tokens = append(tokens, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not list revoked tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+func (p *postgresRevokedTokenRepository) DeleteExpired(before time.Time) (int64, error) {
+	query := `DELETE FROM revoked_tokens WHERE expires_at <= $1`
+
+	result, err := p.db.Exec(query, before)
+	if err != nil {
+		log.Printf("Error deleting expired revoked tokens: %v", err)
+		return 0, fmt.Errorf("could not delete expired revoked tokens: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+func (p *postgresRevokedTokenRepository) SetUserCutoff(userID int, before time.Time) error {
+	query := `INSERT INTO revoked_user_tokens (user_id, revoked_before) VALUES ($1, $2)
+	          ON CONFLICT (user_id) DO UPDATE
+	          SET revoked_before = GREATEST(revoked_user_tokens.revoked_before, EXCLUDED.revoked_before)`
+
+	if _, err := p.db.Exec(query, userID, before); err != nil {
+		log.Printf("Error revoking tokens of user %d: %v", userID, err)
+		return fmt.Errorf("could not revoke user tokens: %w", err)
+	}
+	return nil
+}
+
+func (p *postgresRevokedTokenRepository) GetUserCutoff(userID int) (*time.Time, error) {
+	var before time.Time
+	query := `SELECT revoked_before FROM revoked_user_tokens WHERE user_id = $1`
+
+	err := p.db.QueryRow(query, userID).Scan(&before)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		log.Printf("Error getting token cutoff of user %d: %v", userID, err)
+		return nil, fmt.Errorf("could not get user token cutoff: %w", err)
+	}
+	return &before, nil
+}
@@ -0,0 +1,144 @@
+// internal/repository/role_repo.go
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+
+	"go-auth-example/internal/model"
+)
+
+// RoleRepository mendefinisikan operasi penyimpanan role, permission-nya dan role milik user
+type RoleRepository interface {
+	// List mengembalikan semua role beserta permission-nya, urut nama
+	List() ([]model.Role, error)
+	// GetByName mengembalikan role beserta permission-nya, nil jika tidak ada
+	GetByName(name string) (*model.Role, error)
+	// ListUserRoles mengembalikan nama role milik user, urut nama
+	ListUserRoles(userID int) ([]string, error)
+	// Assign memberikan role ke user; tidak error jika user sudah memilikinya
+	Assign(userID, roleID int) error
+	// Remove mencabut role dari user; false jika user tidak memilikinya
+	Remove(userID, roleID int) (bool, error)
+	// CountUsers menghitung user yang memiliki role
+	CountUsers(roleID int) (int, error)
+}
+
+// Implementasi RoleRepository untuk PostgreSQL
+type postgresRoleRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresRoleRepository adalah constructor untuk role repository
+func NewPostgresRoleRepository(db *sql.DB) RoleRepository {
+	return &postgresRoleRepository{db: db}
+}
+
+// roleQuery memilih role beserta nama permission-nya (dipisah spasi)
+const roleQuery = `SELECT r.id, r.name, r.description, r.created_at, COALESCE(string_agg(p.name, ' ' ORDER BY p.name), '')
+	FROM roles r
+	LEFT JOIN role_permissions rp ON rp.role_id = r.id
+	LEFT JOIN permissions p ON p.id = rp.permission_id`
+
+func scanRole(row rowScanner) (*model.Role, error) {
+	role := &model.Role{}
+	var permissions string
+	if err := row.Scan(&role.ID, &role.Name, &role.Description, &role.CreatedAt, &permissions); err != nil {
+		return nil, err
+	}
+	role.Permissions = strings.Fields(permissions)
+	return role, nil
+}
+
+func (p *postgresRoleRepository) List() ([]model.Role, error) {
+	rows, err := p.db.Query(roleQuery + ` GROUP BY r.id ORDER BY r.name`)
+	if err != nil {
+		log.Printf("Error listing roles: %v", err)
+		return nil, fmt.Errorf("could not list roles: %w", err)
+	}
+	defer rows.Close()
+
+	roles := []model.Role{}
+	for rows.Next() {
+		role, err := scanRole(rows)
+		if err != nil {
+			log.Printf("Error scanning role: %v", err)
+			return nil, fmt.Errorf("could not list roles: %w", err)
+		}
+		roles = append(roles, *role)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not list roles: %w", err)
+	}
+	return roles, nil
+}
+
+func (p *postgresRoleRepository) GetByName(name string) (*model.Role, error) {
+	role, err := scanRole(p.db.QueryRow(roleQuery+` WHERE r.name = $1 GROUP BY r.id`, name))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		log.Printf("Error getting role %q: %v", name, err)
+		return nil, fmt.Errorf("could not get role: %w", err)
+	}
+	return role, nil
+}
+
+func (p *postgresRoleRepository) ListUserRoles(userID int) ([]string, error) {
+	query := `SELECT r.name FROM user_roles ur JOIN roles r ON r.id = ur.role_id WHERE ur.user_id = $1 ORDER BY r.name`
+
+	rows, err := p.db.Query(query, userID)
+	if err != nil {
+		log.Printf("Error listing roles of user %d: %v", userID, err)
+		return nil, fmt.Errorf("could not list user roles: %w", err)
+	}
+	defer rows.Close()
+
+	roles := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			log.Printf("Error scanning role of user %d: %v", userID, err)
+			return nil, fmt.Errorf("could not list user roles: %w", err)
+		}
+		roles = append(roles, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not list user roles: %w", err)
+	}
+	return roles, nil
+}
+
+func (p *postgresRoleRepository) Assign(userID, roleID int) error {
+	query := `INSERT INTO user_roles (user_id, role_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`
+	if _, err := p.db.Exec(query, userID, roleID); err != nil {
+		log.Printf("Error assigning role %d to user %d: %v", roleID, userID, err)
+		return fmt.Errorf("could not assign role: %w", err)
+	}
+	return nil
+}
+
+func (p *postgresRoleRepository) Remove(userID, roleID int) (bool, error) {
+	result, err := p.db.Exec(`DELETE FROM user_roles WHERE user_id = $1 AND role_id = $2`, userID, roleID)
+	if err != nil {
+		log.Printf("Error removing role %d from user %d: %v", roleID, userID, err)
+		return false, fmt.Errorf("could not remove role: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("could not remove role: %w", err)
+	}
+	return n == 1, nil
+}
+
+func (p *postgresRoleRepository) CountUsers(roleID int) (int, error) {
+	var count int
+	if err := p.db.QueryRow(`SELECT COUNT(*) FROM user_roles WHERE role_id = $1`, roleID).Scan(&count); err != nil {
+		log.Printf("Error counting users with role %d: %v", roleID, err)
+		return 0, fmt.Errorf("could not count role users: %w", err)
+	}
+	return count, nil
+}
@@ -0,0 +1,162 @@
+// internal/repository/session_repo.go
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"go-auth-example/internal/model"
+)
+
+// SessionRepository mendefinisikan operasi penyimpanan session login
+type SessionRepository interface {
+	Create(session *model.Session) error
+	GetByID(id string) (*model.Session, error)
+	// ListActive mengembalikan session user yang belum dicabut dan masih terlihat aktif sejak waktu tertentu
+	ListActive(userID int, seenSince time.Time) ([]model.Session, error)
+	// Touch mencatat waktu, IP dan user agent terakhir; paling sering sekali per menit, kecuali IP berubah
+	Touch(id string, client model.ClientInfo) error
+	// Revoke mencabut session milik user. Mengembalikan false jika session tidak ditemukan atau sudah dicabut.
+	Revoke(id string, userID int) (bool, error)
+	// RevokeAllExcept mencabut semua session user kecuali keepID dan mengembalikan ID yang dicabut
+	RevokeAllExcept(userID int, keepID string) ([]string, error)
+	// SetCSRFToken menyimpan token CSRF synchronizer untuk session
+	SetCSRFToken(id string, token string) error
+}
+
+// Implementasi SessionRepository untuk PostgreSQL
+type postgresSessionRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresSessionRepository adalah constructor untuk session repository
+func NewPostgresSessionRepository(db *sql.DB) SessionRepository {
+	return &postgresSessionRepository{db: db}
+}
+
+const sessionColumns = `id, user_id, user_agent, ip, created_at, last_seen_at, revoked_at, csrf_token`
+
+func scanSession(row rowScanner) (*model.Session, error) {
+	session := &model.Session{}
+	err := row.Scan(&session.ID, &session.UserID, &session.UserAgent, &session.IP,
+		&session.CreatedAt, &session.LastSeenAt, &session.RevokedAt, &session.CSRFToken)
+	if err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+func (p *postgresSessionRepository) Create(session *model.Session) error {
+	query := `INSERT INTO sessions (id, user_id, user_agent, ip, csrf_token)
+	          VALUES ($1, $2, $3, $4, $5) RETURNING created_at, last_seen_at`
+
+	err := p.db.QueryRow(query, session.ID, session.UserID, session.UserAgent, session.IP, session.CSRFToken).
+		Scan(&session.CreatedAt, &session.LastSeenAt)
+	if err != nil {
+		log.Printf("Error creating session for user %d: %v", session.UserID, err)
+		return fmt.Errorf("could not create session: %w", err)
+	}
+	return nil
+}
+
+func (p *postgresSessionRepository) GetByID(id string) (*model.Session, error) {
+	query := `SELECT ` + sessionColumns + ` FROM sessions WHERE id = $1`
+
+	session, err := scanSession(p.db.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		log.Printf("Error getting session %s: %v", id, err)
+		return nil, fmt.Errorf("could not get session: %w", err)
+	}
+	return session, nil
+}
+
+func (p *postgresSessionRepository) ListActive(userID int, seenSince time.Time) ([]model.Session, error) {
+	query := `SELECT ` + sessionColumns + ` FROM sessions
+	          WHERE user_id = $1 AND revoked_at IS NULL AND last_seen_at > $2
+	          ORDER BY last_seen_at DESC`
+
+	rows, err := p.db.Query(query, userID, seenSince)
+	if err != nil {
+		log.Printf("Error listing sessions for user %d: %v", userID, err)
+		return nil, fmt.Errorf("could not list sessions: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := []model.Session{}
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, fmt.Errorf("could not scan session: %w", err)
+		}
+		sessions = append(sessions, *session)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not list sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+func (p *postgresSessionRepository) Touch(id string, client model.ClientInfo) error {
+	// Dibatasi sekali per menit kecuali IP berubah, agar setiap request tidak selalu menulis ke database
+	query := `UPDATE sessions SET last_seen_at = NOW(), ip = $2, user_agent = $3
+	          WHERE id = $1 AND (last_seen_at < NOW() - INTERVAL '1 minute' OR ip <> $2)`
+
+	if _, err := p.db.Exec(query, id, client.IP, client.UserAgent); err != nil {
+		log.Printf("Error touching session %s: %v", id, err)
+		return fmt.Errorf("could not update session: %w", err)
+	}
+	return nil
+}
+
+func (p *postgresSessionRepository) Revoke(id string, userID int) (bool, error) {
+	query := `UPDATE sessions SET revoked_at = NOW() WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`
+
+	result, err := p.db.Exec(query, id, userID)
+	if err != nil {
+		log.Printf("Error revoking session %s: %v", id, err)
+		return false, fmt.Errorf("could not revoke session: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("could not revoke session: %w", err)
+	}
+	return affected == 1, nil
+}
+
+func (p *postgresSessionRepository) RevokeAllExcept(userID int, keepID string) ([]string, error) {
+	query := `UPDATE sessions SET revoked_at = NOW()
+	          WHERE user_id = $1 AND id <> $2 AND revoked_at IS NULL RETURNING id`
+
+	rows, err := p.db.Query(query, userID, keepID)
+	if err != nil {
+		log.Printf("Error revoking sessions for user %d: %v", userID, err)
+		return nil, fmt.Errorf("could not revoke sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("could not scan revoked session: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not revoke sessions: %w", err)
+	}
+	return ids, nil
+}
+
+func (p *postgresSessionRepository) SetCSRFToken(id string, token string) error {
+	if _, err := p.db.Exec(`UPDATE sessions SET csrf_token = $2 WHERE id = $1`, id, token); err != nil {
+		log.Printf("Error setting CSRF token of session %s: %v", id, err)
+		return fmt.Errorf("could not set session csrf token: %w", err)
+	}
+	return nil
+}
@@ -8,14 +8,44 @@ import (
 	"strings"
 	"time"
 
+	"go-auth-example/internal/auth"
 	"go-auth-example/internal/model" // <- Import model baru
 )
 
 // Definisikan interface untuk UserRepository
 type UserRepository interface {
 	Create(user *model.User) error
+	// GetByEmail dan GetByUsername tidak peka huruf besar/kecil (dicocokkan lewat auth.CanonicalIdentity)
 	GetByEmail(email string) (*model.User, error)
+	GetByUsername(username string) (*model.User, error)
 	GetByID(id int) (*model.User, error)
+	// MarkEmailVerified mengisi email_verified_at jika belum terisi
+	MarkEmailVerified(id int) error
+	// UpdatePassword mengganti password_hash user
+	UpdatePassword(id int, passwordHash string) error
+	// UpdateUsername mengganti username; mengembalikan "username already exists" jika sudah dipakai
+	UpdateUsername(id int, username string) error
+	// UpdateEmail mengganti email yang sudah dikonfirmasi dan mencatatnya sebagai terverifikasi;
+	// mengembalikan "email already exists" jika sudah dipakai
+	UpdateEmail(id int, email string) error
+	// SetStatus mengganti status user beserta jadwal penghapusannya (nil untuk membatalkan)
+	SetStatus(id int, status string, deletionAt *time.Time) error
+	// DeleteScheduled menghapus permanen user pending_deletion yang jadwalnya sudah lewat dan mengembalikan ID-nya.
+	// Data terkait ikut terhapus lewat ON DELETE CASCADE.
+	DeleteScheduled(now time.Time) ([]int, error)
+	// RecordFailedLogin menambah hitungan login gagal secara atomik dan mengembalikan jumlah terbarunya.
+	// Jika login gagal terakhir terjadi sebelum since, hitungan dimulai lagi dari satu.
+	RecordFailedLogin(id int, since time.Time) (int, error)
+	// LockUntil menolak login user sampai waktu yang diberikan
+	LockUntil(id int, until time.Time) error
+	// ResetFailedLogins mengosongkan hitungan login gagal dan membuka kunci akun
+	ResetFailedLogins(id int) error
+	// List mengembalikan satu halaman user sesuai filter dan urutan query, beserta jumlah total user yang cocok.
+	// Page dan PerPage harus sudah diisi.
+	List(query model.UserListQuery) ([]model.User, int, error)
+	// Delete menghapus user secara permanen; false jika user tidak ada.
+	// Data terkait ikut terhapus lewat ON DELETE CASCADE.
+	Delete(id int) (bool, error)
 }
 
 // Implementasi UserRepository untuk PostgreSQL
@@ -32,31 +62,52 @@ func NewPostgresUserRepository(db *sql.DB) UserRepository {
 // Gunakan p.db, bukan variabel global DB
 
 func (p *postgresUserRepository) Create(user *model.User) error {
-	query := `INSERT INTO users (username, email, password_hash, created_at)
-	          VALUES ($1, $2, $3, $4) RETURNING id`
+	query := `INSERT INTO users (username, email, username_canonical, email_canonical, password_hash, created_at)
+	          VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`
 
 	// Gunakan p.db
-	err := p.db.QueryRow(query, user.Username, user.Email, user.PasswordHash, time.Now()).Scan(&user.ID)
+	err := p.db.QueryRow(query, user.Username, user.Email, auth.CanonicalIdentity(user.Username), auth.CanonicalIdentity(user.Email),
+		user.PasswordHash, time.Now()).Scan(&user.ID)
 	if err != nil {
 		log.Printf("Error creating user: %v", err)
-		if strings.Contains(err.Error(), "duplicate key value violates unique constraint") {
-			if strings.Contains(err.Error(), "users_username_key") {
-				return fmt.Errorf("username already exists")
-			}
-			if strings.Contains(err.Error(), "users_email_key") {
-				return fmt.Errorf("email already exists")
-			}
+		if uniqueErr := uniqueUserViolation(err); uniqueErr != nil {
+			return uniqueErr
 		}
 		return fmt.Errorf("could not create user: %w", err)
 	}
 	return nil
 }
 
-func (p *postgresUserRepository) GetByEmail(email string) (*model.User, error) {
+// uniqueUserViolation menerjemahkan pelanggaran unique constraint tabel users menjadi error bisnis
+func uniqueUserViolation(err error) error {
+	if strings.Contains(err.Error(), "duplicate key value violates unique constraint") {
+		if strings.Contains(err.Error(), "users_username_key") || strings.Contains(err.Error(), "users_username_canonical_key") {
+			return fmt.Errorf("username already exists")
+		}
+		if strings.Contains(err.Error(), "users_email_key") || strings.Contains(err.Error(), "users_email_canonical_key") {
+			return fmt.Errorf("email already exists")
+		}
+	}
+	return nil
+}
+
+const userColumns = `id, username, email, password_hash, email_verified_at, status, deletion_scheduled_at, created_at,
+	failed_login_count, last_failed_login_at, locked_until`
+
+func scanUser(row rowScanner) (*model.User, error) {
 	user := &model.User{}
-	query := `SELECT id, username, email, password_hash, created_at FROM users WHERE email = $1`
+	err := row.Scan(&user.ID, &user.Username, &user.Email, &user.PasswordHash, &user.EmailVerifiedAt,
+		&user.Status, &user.DeletionAt, &user.CreatedAt, &user.FailedLoginCount, &user.LastFailedLoginAt, &user.LockedUntil)
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (p *postgresUserRepository) GetByEmail(email string) (*model.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE email_canonical = $1`
 	// Gunakan p.db
-	err := p.db.QueryRow(query, email).Scan(&user.ID, &user.Username, &user.Email, &user.PasswordHash, &user.CreatedAt)
+	user, err := scanUser(p.db.QueryRow(query, auth.CanonicalIdentity(email)))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -67,11 +118,23 @@ func (p *postgresUserRepository) GetByEmail(email string) (*model.User, error) {
 	return user, nil
 }
 
+func (p *postgresUserRepository) GetByUsername(username string) (*model.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE username_canonical = $1`
+	user, err := scanUser(p.db.QueryRow(query, auth.CanonicalIdentity(username)))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		log.Printf("Error getting user by username %s: %v", username, err)
+		return nil, fmt.Errorf("could not get user by username: %w", err)
+	}
+	return user, nil
+}
+
 func (p *postgresUserRepository) GetByID(id int) (*model.User, error) {
-	user := &model.User{}
-	query := `SELECT id, username, email, password_hash, created_at FROM users WHERE id = $1`
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = $1`
 	// Gunakan p.db
-	err := p.db.QueryRow(query, id).Scan(&user.ID, &user.Username, &user.Email, &user.PasswordHash, &user.CreatedAt)
+	user, err := scanUser(p.db.QueryRow(query, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -81,3 +144,203 @@ func (p *postgresUserRepository) GetByID(id int) (*model.User, error) {
 	}
 	return user, nil
 }
+
+func (p *postgresUserRepository) MarkEmailVerified(id int) error {
+	query := `UPDATE users SET email_verified_at = NOW() WHERE id = $1 AND email_verified_at IS NULL`
+	if _, err := p.db.Exec(query, id); err != nil {
+		log.Printf("Error marking email of user %d as verified: %v", id, err)
+		return fmt.Errorf("could not mark email as verified: %w", err)
+	}
+	return nil
+}
+
+func (p *postgresUserRepository) UpdatePassword(id int, passwordHash string) error {
+	query := `UPDATE users SET password_hash = $2 WHERE id = $1`
+	if _, err := p.db.Exec(query, id, passwordHash); err != nil {
+		log.Printf("Error updating password of user %d: %v", id, err)
+		return fmt.Errorf("could not update password: %w", err)
+	}
+	return nil
+}
+
+func (p *postgresUserRepository) UpdateUsername(id int, username string) error {
+	query := `UPDATE users SET username = $2, username_canonical = $3 WHERE id = $1`
+	if _, err := p.db.Exec(query, id, username, auth.CanonicalIdentity(username)); err != nil {
+		log.Printf("Error updating username of user %d: %v", id, err)
+		if uniqueErr := uniqueUserViolation(err); uniqueErr != nil {
+			return uniqueErr
+		}
+		return fmt.Errorf("could not update username: %w", err)
+	}
+	return nil
+}
+
+func (p *postgresUserRepository) UpdateEmail(id int, email string) error {
+	query := `UPDATE users SET email = $2, email_canonical = $3, email_verified_at = NOW() WHERE id = $1`
+	if _, err := p.db.Exec(query, id, email, auth.CanonicalIdentity(email)); err != nil {
+		log.Printf("Error updating email of user %d: %v", id, err)
+		if uniqueErr := uniqueUserViolation(err); uniqueErr != nil {
+			return uniqueErr
+		}
+		return fmt.Errorf("could not update email: %w", err)
+	}
+	return nil
+}
+
+func (p *postgresUserRepository) SetStatus(id int, status string, deletionAt *time.Time) error {
+	query := `UPDATE users SET status = $2, deletion_scheduled_at = $3 WHERE id = $1`
+	if _, err := p.db.Exec(query, id, status, deletionAt); err != nil {
+		log.Printf("Error setting status of user %d: %v", id, err)
+		return fmt.Errorf("could not set user status: %w", err)
+	}
+	return nil
+}
+
+func (p *postgresUserRepository) DeleteScheduled(now time.Time) ([]int, error) {
+	query := `DELETE FROM users WHERE status = $1 AND deletion_scheduled_at <= $2 RETURNING id`
+
+	rows, err := p.db.Query(query, model.UserStatusPendingDeletion, now)
+	if err != nil {
+		log.Printf("Error deleting scheduled users: %v", err)
+		return nil, fmt.Errorf("could not delete scheduled users: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("could not scan deleted user: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not delete scheduled users: %w", err)
+	}
+	return ids, nil
+}
+
+func (p *postgresUserRepository) RecordFailedLogin(id int, since time.Time) (int, error) {
+	query := `UPDATE users
+	          SET failed_login_count = CASE WHEN last_failed_login_at < $2 THEN 1 ELSE failed_login_count + 1 END,
+	              last_failed_login_at = NOW()
+	          WHERE id = $1 RETURNING failed_login_count`
+
+	var count int
+	if err := p.db.QueryRow(query, id, since).Scan(&count); err != nil {
+		log.Printf("Error recording failed login of user %d: %v", id, err)
+		return 0, fmt.Errorf("could not record failed login: %w", err)
+	}
+	return count, nil
+}
+
+func (p *postgresUserRepository) LockUntil(id int, until time.Time) error {
+	query := `UPDATE users SET locked_until = $2 WHERE id = $1`
+	if _, err := p.db.Exec(query, id, until); err != nil {
+		log.Printf("Error locking user %d: %v", id, err)
+		return fmt.Errorf("could not lock user: %w", err)
+	}
+	return nil
+}
+
+func (p *postgresUserRepository) ResetFailedLogins(id int) error {
+	query := `UPDATE users SET failed_login_count = 0, last_failed_login_at = NULL, locked_until = NULL
+	          WHERE id = $1 AND (failed_login_count > 0 OR locked_until IS NOT NULL)`
+	if _, err := p.db.Exec(query, id); err != nil {
+		log.Printf("Error resetting failed logins of user %d: %v", id, err)
+		return fmt.Errorf("could not reset failed logins: %w", err)
+	}
+	return nil
+}
+
+// userSortColumns memetakan nilai sort yang diizinkan ke kolom tabel users
+var userSortColumns = map[string]string{
+	model.UserSortCreatedAt: "created_at",
+	model.UserSortEmail:     "email_canonical",
+	model.UserSortUsername:  "username_canonical",
+	model.UserSortStatus:    "status",
+}
+
+func (p *postgresUserRepository) List(query model.UserListQuery) ([]model.User, int, error) {
+	var conditions []string
+	var args []interface{}
+	where := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+	if query.Email != "" {
+		where("email_canonical LIKE $%d", "%"+escapeLike(auth.CanonicalIdentity(query.Email))+"%")
+	}
+	if query.Username != "" {
+		where("username_canonical LIKE $%d", "%"+escapeLike(auth.CanonicalIdentity(query.Username))+"%")
+	}
+	if query.Status != "" {
+		where("status = $%d", query.Status)
+	}
+	if query.CreatedAfter != nil {
+		where("created_at >= $%d", *query.CreatedAfter)
+	}
+	if query.CreatedBefore != nil {
+		where("created_at < $%d", *query.CreatedBefore)
+	}
+	filter := ""
+	if len(conditions) > 0 {
+		filter = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	if err := p.db.QueryRow(`SELECT COUNT(*) FROM users`+filter, args...).Scan(&total); err != nil {
+		log.Printf("Error counting users: %v", err)
+		return nil, 0, fmt.Errorf("could not list users: %w", err)
+	}
+
+	column, ok := userSortColumns[query.Sort]
+	if !ok {
+		column = "created_at"
+	}
+	direction := "DESC"
+	if query.Order == "asc" {
+		direction = "ASC"
+	}
+	// id sebagai urutan kedua agar halaman tetap stabil untuk nilai kolom yang sama
+	listQuery := fmt.Sprintf(`SELECT %s FROM users%s ORDER BY %s %s, id %s LIMIT $%d OFFSET $%d`,
+		userColumns, filter, column, direction, direction, len(args)+1, len(args)+2)
+	rows, err := p.db.Query(listQuery, append(args, query.PerPage, (query.Page-1)*query.PerPage)...)
+	if err != nil {
+		log.Printf("Error listing users: %v", err)
+		return nil, 0, fmt.Errorf("could not list users: %w", err)
+	}
+	defer rows.Close()
+
+	users := []model.User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			log.Printf("Error scanning user: %v", err)
+			return nil, 0, fmt.Errorf("could not list users: %w", err)
+		}
+		users = append(users, *user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("could not list users: %w", err)
+	}
+	return users, total, nil
+}
+
+// escapeLike meng-escape karakter wildcard LIKE agar input dicocokkan apa adanya
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
+
+func (p *postgresUserRepository) Delete(id int) (bool, error) {
+	result, err := p.db.Exec(`DELETE FROM users WHERE id = $1`, id)
+	if err != nil {
+		log.Printf("Error deleting user %d: %v", id, err)
+		return false, fmt.Errorf("could not delete user: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("could not delete user: %w", err)
+	}
+	return n == 1, nil
+}
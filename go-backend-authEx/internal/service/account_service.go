@@ -0,0 +1,202 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go-auth-example/internal/logger"
+	"go-auth-example/internal/mail"
+	"go-auth-example/internal/model"
+	"go-auth-example/internal/repository"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultAccountDeletionGracePeriod adalah jeda sebelum akun yang diminta dihapus benar-benar dihapus
+const DefaultAccountDeletionGracePeriod = 30 * 24 * time.Hour
+
+// AccountService mengelola penutupan akun dan ekspor data pribadi
+type AccountService interface {
+	// RequestDeletion memeriksa password, menjadwalkan penghapusan permanen setelah masa tenggang
+	// dan mencabut semua session dan token user. Login selama masa tenggang membatalkan penghapusan.
+	RequestDeletion(userID int, password string, authTime time.Time) (*time.Time, error)
+	// Export mengumpulkan semua data yang disimpan tentang user
+	Export(userID int) (*model.AccountExport, error)
+	// PurgeScheduled menghapus permanen akun yang masa tenggangnya sudah lewat
+	PurgeScheduled() error
+	// Unlock membuka kunci login akun dan mengosongkan hitungan login gagal (operasi admin)
+	Unlock(userID int) error
+	// StartDeletionWorker menjalankan PurgeScheduled secara periodik di background; panggil fungsi yang dikembalikan untuk berhenti
+	StartDeletionWorker(interval time.Duration) (stop func())
+}
+
+// accountService struct mengimplementasikan AccountService
+type accountService struct {
+	userRepo    repository.UserRepository
+	exportRepo  repository.AccountExportRepository
+	credentials credentialRevoker
+	mailer      mail.Mailer
+	gracePeriod time.Duration
+}
+
+// NewAccountService adalah constructor untuk accountService
+func NewAccountService(userRepo repository.UserRepository, exportRepo repository.AccountExportRepository,
+	refreshTokenRepo repository.RefreshTokenRepository, patRepo repository.PersonalAccessTokenRepository,
+	revocationService TokenRevocationService, sessionService SessionService, mailer mail.Mailer, gracePeriod time.Duration) AccountService {
+	if gracePeriod <= 0 {
+		gracePeriod = DefaultAccountDeletionGracePeriod
+	}
+	return &accountService{
+		userRepo:    userRepo,
+		exportRepo:  exportRepo,
+		credentials: credentialRevoker{sessionService: sessionService, revocationService: revocationService, refreshTokenRepo: refreshTokenRepo, patRepo: patRepo},
+		mailer:      mailer,
+		gracePeriod: gracePeriod,
+	}
+}
+
+// Implementasi RequestDeletion
+func (s *accountService) RequestDeletion(userID int, password string, authTime time.Time) (*time.Time, error) {
+	logFields := logrus.Fields{
+		"service": "AccountService",
+		"method":  "RequestDeletion",
+		"user_id": userID,
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error loading user for deletion: %v", err)
+		return nil, errors.New("failed to delete account")
+	}
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+	if err := confirmIdentity(user, password, authTime); err != nil {
+		logger.Log.WithFields(logFields).Warnf("Account deletion not confirmed: %v", err)
+		return nil, err
+	}
+
+	deletionAt := time.Now().Add(s.gracePeriod)
+	if err := s.userRepo.SetStatus(user.ID, model.UserStatusPendingDeletion, &deletionAt); err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error scheduling account deletion: %v", err)
+		return nil, errors.New("failed to delete account")
+	}
+	if err := s.credentials.revokeAll(user.ID, ""); err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error revoking credentials of deleted account: %v", err)
+		return nil, errors.New("failed to delete account")
+	}
+
+	msg := mail.Message{
+		To:      user.Email,
+		Subject: "Your account is scheduled for deletion",
+		Body: fmt.Sprintf("Hi %s,\n\nYour account and all of its data will be permanently deleted on %s.\n\n"+
+			"Changed your mind? Log in before then and the deletion will be cancelled.\n",
+			user.Username, deletionAt.UTC().Format("2 January 2006 15:04 MST")),
+	}
+	go func() {
+		if err := s.mailer.Send(msg); err != nil {
+			logger.Log.WithFields(logFields).Warnf("Could not send account deletion notice: %v", err)
+		}
+	}()
+
+	logFields["deletion_scheduled_at"] = deletionAt
+	logger.Log.WithFields(logFields).Info("Account deletion scheduled.")
+	return &deletionAt, nil
+}
+
+// Implementasi Export
+func (s *accountService) Export(userID int) (*model.AccountExport, error) {
+	logFields := logrus.Fields{
+		"service": "AccountService",
+		"method":  "Export",
+		"user_id": userID,
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error loading user for export: %v", err)
+		return nil, errors.New("failed to export account data")
+	}
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+	user.PasswordHash = ""
+
+	records, err := s.exportRepo.ExportRecords(userID)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error exporting account records: %v", err)
+		return nil, errors.New("failed to export account data")
+	}
+
+	logger.Log.WithFields(logFields).Info("Account data exported.")
+	return &model.AccountExport{ExportedAt: time.Now().UTC(), User: user, Records: records}, nil
+}
+
+// Implementasi PurgeScheduled
+func (s *accountService) PurgeScheduled() error {
+	ids, err := s.userRepo.DeleteScheduled(time.Now())
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		logger.Log.WithFields(logrus.Fields{
+			"service": "AccountService",
+			"method":  "PurgeScheduled",
+			"user_id": id,
+		}).Info("Account permanently deleted.")
+	}
+	return nil
+}
+
+// Implementasi Unlock
+func (s *accountService) Unlock(userID int) error {
+	logFields := logrus.Fields{
+		"service": "AccountService",
+		"method":  "Unlock",
+		"user_id": userID,
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error loading user for unlock: %v", err)
+		return errors.New("failed to unlock account")
+	}
+	if user == nil {
+		return errors.New("user not found")
+	}
+	if err := s.userRepo.ResetFailedLogins(user.ID); err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error unlocking account: %v", err)
+		return errors.New("failed to unlock account")
+	}
+
+	logger.Log.WithFields(logFields).Info("Account unlocked.")
+	return nil
+}
+
+// Implementasi StartDeletionWorker
+func (s *accountService) StartDeletionWorker(interval time.Duration) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				if err := s.PurgeScheduled(); err != nil {
+					logger.Log.WithFields(logrus.Fields{
+						"service": "AccountService",
+						"method":  "StartDeletionWorker",
+					}).Errorf("Error deleting scheduled accounts: %v", err)
+				}
+			case <-done:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }
+}
@@ -4,6 +4,8 @@ package service
 import (
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 	// "log" // Dihapus, diganti dengan logger kustom
 
 	"go-auth-example/internal/auth"
@@ -11,27 +13,95 @@ import (
 	"go-auth-example/internal/model"
 	"go-auth-example/internal/repository"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/sirupsen/logrus" // <-- Impor logrus untuk Fields
 )
 
+// RefreshTokenTTL adalah masa berlaku refresh token
+const RefreshTokenTTL = 30 * 24 * time.Hour
+
+// AuthOptions berisi kebijakan login yang bisa dikonfigurasi
+type AuthOptions struct {
+	// RequireVerifiedEmail menolak login user yang belum mengkonfirmasi email-nya
+	RequireVerifiedEmail bool
+	// Throttle membatasi percobaan login gagal per akun
+	Throttle LoginThrottleOptions
+}
+
 // AuthService interface mendefinisikan operasi otentikasi
 type AuthService interface {
 	Register(input model.RegisterInput) (*model.User, error)
-	Login(input model.LoginInput) (string, error) // Return JWT string
+	Login(input model.LoginInput, client model.ClientInfo) (*model.TokenPair, error) // Return access + refresh token, memulai session baru
+	Refresh(refreshToken string, client model.ClientInfo) (*model.TokenPair, error)  // Rotasi refresh token
+	// VerifyMFA menyelesaikan login tahap kedua dengan token dari *MFARequiredError dan kode MFA
+	VerifyMFA(input model.MFAVerifyInput, client model.ClientInfo) (*model.TokenPair, error)
+	// BeginPasskeyLogin membuat challenge untuk login dengan passkey
+	BeginPasskeyLogin() (*model.PasskeyCeremony, error)
+	// LoginWithPasskey memverifikasi assertion passkey dan menerbitkan token yang sama seperti Login
+	LoginWithPasskey(input model.PasskeyLoginInput, client model.ClientInfo) (*model.TokenPair, error)
+	// RequestMagicLink mengirim link login sekali pakai ke email; binding adalah nilai cookie browser yang meminta
+	RequestMagicLink(email string, binding string) error
+	// LoginWithMagicLink menukar token dari link email dengan token yang sama seperti Login (termasuk challenge MFA)
+	LoginWithMagicLink(input model.ConsumeMagicLinkInput, binding string, client model.ClientInfo) (*model.TokenPair, error)
+	// SocialProviders mengembalikan identity provider eksternal yang aktif
+	SocialProviders() []model.SocialProvider
+	// BeginSocialLogin memulai login sosial; state mentah yang dikembalikan harus disimpan di cookie browser
+	BeginSocialLogin(provider string) (*model.SocialLoginStart, string, error)
+	// LoginWithSocial menyelesaikan callback login sosial dan menerbitkan token yang sama seperti Login (termasuk challenge MFA)
+	LoginWithSocial(provider string, input model.SocialCallbackInput, browserState string, client model.ClientInfo) (*model.TokenPair, error)
+	// Logout mencabut access token (berdasarkan jti), session-nya, dan jika diberikan, family refresh token milik user
+	Logout(userID int, jti string, expiresAt time.Time, sessionID string, refreshToken string) error
 }
 
 // authService struct mengimplementasikan AuthService
 type authService struct {
-	userRepo repository.UserRepository // Dependensi ke interface repo
+	userRepo          repository.UserRepository         // Dependensi ke interface repo
+	refreshTokenRepo  repository.RefreshTokenRepository // Penyimpanan refresh token
+	revocationService TokenRevocationService            // Denylist access token
+	sessionService    SessionService                    // Session login per perangkat
+	emailVerification EmailVerificationService          // Pengiriman link verifikasi email
+	mfa               MFAService                        // Challenge login tahap kedua
+	passkeys          PasskeyService                    // Login tanpa password dengan WebAuthn
+	magicLinks        MagicLinkService                  // Login tanpa password lewat link email
+	socialLogins      SocialLoginService                // Login lewat identity provider eksternal
+	roles             RoleService                       // Role RBAC yang ditanam di access token
+	organizations     OrganizationService               // Organisasi aktif yang ditanam di access token
+	tokenIssuer       auth.TokenIssuer                  // Penerbit access token (JWT)
+	refreshTokens     refreshTokenRotator
+	throttle          loginThrottle
+	options           AuthOptions
 }
 
 // NewAuthService adalah constructor untuk authService
-func NewAuthService(userRepo repository.UserRepository) AuthService {
-	return &authService{userRepo: userRepo}
+func NewAuthService(userRepo repository.UserRepository, refreshTokenRepo repository.RefreshTokenRepository,
+	loginFailureRepo repository.LoginFailureRepository, revocationService TokenRevocationService, sessionService SessionService, emailVerification EmailVerificationService,
+	mfa MFAService, passkeys PasskeyService, magicLinks MagicLinkService, socialLogins SocialLoginService, roles RoleService,
+	organizations OrganizationService, tokenIssuer auth.TokenIssuer, options AuthOptions) AuthService {
+	return &authService{
+		userRepo:          userRepo,
+		refreshTokenRepo:  refreshTokenRepo,
+		revocationService: revocationService,
+		sessionService:    sessionService,
+		emailVerification: emailVerification,
+		mfa:               mfa,
+		passkeys:          passkeys,
+		magicLinks:        magicLinks,
+		socialLogins:      socialLogins,
+		roles:             roles,
+		organizations:     organizations,
+		tokenIssuer:       tokenIssuer,
+		refreshTokens:     refreshTokenRotator{repo: refreshTokenRepo},
+		throttle:          newLoginThrottle(userRepo, loginFailureRepo, options.Throttle),
+		options:           options,
+	}
 }
 
 // Implementasi Register
 func (s *authService) Register(input model.RegisterInput) (*model.User, error) {
+	// Huruf besar/kecil yang diketik user disimpan untuk tampilan; keunikan dicek tanpa membedakannya
+	input.Username = auth.NormalizeIdentity(input.Username)
+	input.Email = auth.NormalizeIdentity(input.Email)
+
 	// Definisikan field log yang umum untuk method ini
 	logFields := logrus.Fields{
 		"service":  "AuthService",
@@ -93,29 +163,63 @@ func (s *authService) Register(input model.RegisterInput) (*model.User, error) {
 	logFields["user_id"] = newUser.ID
 	logger.Log.WithFields(logFields).Info("User successfully registered by service.")
 
+	// Gagal mengirim email tidak membatalkan registrasi; user bisa meminta kirim ulang
+	if err := s.emailVerification.SendVerification(newUser); err != nil {
+		logger.Log.WithFields(logFields).Warnf("Could not send verification email after registration: %v", err)
+	}
+
 	// Penting: Hapus hash password sebelum dikembalikan
 	newUser.PasswordHash = ""
 	return newUser, nil
 }
 
+// findByIdentifier mencari user berdasarkan username atau email.
+// Username hanya boleh alfanumerik, jadi identifier yang mengandung "@" pasti sebuah email.
+func (s *authService) findByIdentifier(identifier string) (*model.User, error) {
+	if strings.Contains(identifier, "@") {
+		return s.userRepo.GetByEmail(identifier)
+	}
+	return s.userRepo.GetByUsername(identifier)
+}
+
 // Implementasi Login
-func (s *authService) Login(input model.LoginInput) (string, error) {
+func (s *authService) Login(input model.LoginInput, client model.ClientInfo) (*model.TokenPair, error) {
+	input.Identifier = auth.NormalizeIdentity(input.Identifier)
 	logFields := logrus.Fields{
-		"service": "AuthService",
-		"method":  "Login",
-		"email":   input.Email,
+		"service":    "AuthService",
+		"method":     "Login",
+		"identifier": input.Identifier,
 	}
 
-	// Cari user berdasarkan email via repository
-	user, err := s.userRepo.GetByEmail(input.Email)
+	// Cari user berdasarkan username atau email via repository
+	user, err := s.findByIdentifier(input.Identifier)
 	if err != nil {
-		logger.Log.WithFields(logFields).Errorf("Database error during login for email %s: %v", input.Email, err)
+		logger.Log.WithFields(logFields).Errorf("Database error during login for %s: %v", input.Identifier, err)
 		// Kembalikan error generik, handler akan memetakannya
-		return "", errors.New("an error occurred during login")
+		return nil, errors.New("an error occurred during login")
 	}
 	if user == nil {
-		logger.Log.WithFields(logFields).Warn("Login attempt for non-existent email.")
-		return "", errors.New("invalid email or password") // Pesan error generik
+		// Identifier tanpa akun juga kena backoff dan lockout, agar respons 429 tidak membocorkan akun mana yang ada
+		if err := s.throttle.checkUnknown(input.Identifier, time.Now()); err != nil {
+			var lockedErr *AccountLockedError
+			if errors.As(err, &lockedErr) {
+				logger.Log.WithFields(logFields).Warn("Login attempt for non-existent user rejected by lockout/backoff.")
+				return nil, err
+			}
+			logger.Log.WithFields(logFields).Errorf("Error checking login failures: %v", err)
+		}
+		logger.Log.WithFields(logFields).Warn("Login attempt for non-existent user.")
+		if err := s.throttle.recordUnknownFailure(input.Identifier, logFields); err != nil {
+			logger.Log.WithFields(logFields).Errorf("Error recording failed login: %v", err)
+		}
+		return nil, errors.New("invalid credentials") // Pesan error generik
+	}
+
+	// Backoff dan lockout dicek sebelum password agar password tidak bisa terus ditebak selama akun terkunci
+	if err := s.throttle.check(user, time.Now()); err != nil {
+		logFields["user_id_attempted"] = user.ID
+		logger.Log.WithFields(logFields).Warn("Login attempt rejected by lockout/backoff.")
+		return nil, err
 	}
 
 	// Cek password
@@ -123,19 +227,404 @@ func (s *authService) Login(input model.LoginInput) (string, error) {
 		// Tambahkan user_id ke log jika user ditemukan tapi password salah
 		logFields["user_id_attempted"] = user.ID
 		logger.Log.WithFields(logFields).Warn("Invalid password attempt for existing user.")
-		return "", errors.New("invalid email or password") // Pesan error generik
+		if err := s.throttle.recordFailure(user, logFields); err != nil {
+			logger.Log.WithFields(logFields).Errorf("Error recording failed login: %v", err)
+		}
+		return nil, errors.New("invalid credentials") // Pesan error generik
+	}
+	logFields["user_id"] = user.ID
+
+	// Status akun juga dicek setelah password agar tidak terbaca oleh yang tidak tahu password-nya
+	if user.Status == model.UserStatusDisabled {
+		logger.Log.WithFields(logFields).Warn("Login attempt for disabled account.")
+		return nil, errors.New("account disabled")
+	}
+
+	// Dicek setelah password agar tidak membocorkan status email kepada yang tidak tahu password-nya
+	if s.options.RequireVerifiedEmail && !user.IsEmailVerified() {
+		logger.Log.WithFields(logFields).Info("Login attempt with unverified email.")
+		return nil, errors.New("email not verified")
+	}
+
+	if err := s.requireMFA(user, logFields); err != nil {
+		return nil, err
+	}
+
+	tokens, err := s.completeLogin(user, client, time.Now(), logFields)
+	if err != nil {
+		return nil, err
+	}
+	logger.Log.WithFields(logFields).Info("User successfully logged in by service.")
+	return tokens, nil
+}
+
+// requireMFA mengembalikan *MFARequiredError jika user mengaktifkan MFA; token baru diterbitkan setelah POST /auth/mfa/verify.
+// Hitungan login gagal tidak di-reset di sini agar kode MFA tidak bisa ditebak tanpa batas dengan login ulang.
+func (s *authService) requireMFA(user *model.User, logFields logrus.Fields) error {
+	mfaEnabled, err := s.mfa.IsEnabled(user.ID)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error checking MFA status: %v", err)
+		return errors.New("an error occurred during login")
+	}
+	if !mfaEnabled {
+		return nil
+	}
+	challenge, err := s.mfa.StartChallenge(user.ID, time.Now())
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error starting MFA challenge: %v", err)
+		return errors.New("an error occurred during login")
+	}
+	logger.Log.WithFields(logFields).Info("First factor accepted, MFA challenge issued.")
+	return challenge
+}
+
+// Implementasi VerifyMFA
+func (s *authService) VerifyMFA(input model.MFAVerifyInput, client model.ClientInfo) (*model.TokenPair, error) {
+	logFields := logrus.Fields{
+		"service": "AuthService",
+		"method":  "VerifyMFA",
+	}
+
+	challenge, err := s.mfa.GetChallenge(input.MFAToken)
+	if err != nil {
+		return nil, err
+	}
+	logFields["user_id"] = challenge.UserID
+
+	user, err := s.userRepo.GetByID(challenge.UserID)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error loading user for MFA verification: %v", err)
+		return nil, errors.New("failed to verify mfa code")
+	}
+	if user == nil || user.Status == model.UserStatusDisabled {
+		return nil, errors.New("invalid or expired mfa token")
+	}
+	if err := s.throttle.check(user, time.Now()); err != nil {
+		logger.Log.WithFields(logFields).Warn("MFA attempt rejected by lockout/backoff.")
+		return nil, err
 	}
 
-	// Buat token JWT
-	// Kita akan mengirimkan seluruh user model ke GenerateJWT, jadi pastikan tidak ada info sensitif selain yang dibutuhkan claims
-	token, err := auth.GenerateJWT(*user) // GenerateJWT ada di internal/auth/jwt.go
+	method, err := s.mfa.CompleteChallenge(challenge, input.Code)
 	if err != nil {
-		logFields["user_id"] = user.ID
-		logger.Log.WithFields(logFields).Errorf("Error generating JWT for user %d: %v", user.ID, err)
-		return "", errors.New("failed to generate token")
+		if err.Error() == "invalid mfa code" {
+			logger.Log.WithFields(logFields).Warn("Invalid MFA code.")
+			// Kode MFA salah dihitung sama seperti password salah
+			if recErr := s.throttle.recordFailure(user, logFields); recErr != nil {
+				logger.Log.WithFields(logFields).Errorf("Error recording failed login: %v", recErr)
+			}
+		}
+		return nil, err
 	}
 
+	tokens, err := s.completeLogin(user, client, challenge.AuthTime, logFields)
+	if err != nil {
+		return nil, err
+	}
+	logFields["mfa_method"] = method
+	logger.Log.WithFields(logFields).Info("User successfully logged in with MFA.")
+	return tokens, nil
+}
+
+// Implementasi BeginPasskeyLogin
+func (s *authService) BeginPasskeyLogin() (*model.PasskeyCeremony, error) {
+	return s.passkeys.BeginLogin()
+}
+
+// Implementasi LoginWithPasskey.
+// Passkey selalu meminta verifikasi user (PIN/biometrik) di authenticator, sehingga sudah memenuhi dua faktor
+// dan tidak dilanjutkan dengan challenge MFA.
+func (s *authService) LoginWithPasskey(input model.PasskeyLoginInput, client model.ClientInfo) (*model.TokenPair, error) {
+	logFields := logrus.Fields{
+		"service": "AuthService",
+		"method":  "LoginWithPasskey",
+	}
+
+	user, err := s.passkeys.FinishLogin(input)
+	if err != nil {
+		return nil, err
+	}
 	logFields["user_id"] = user.ID
-	logger.Log.WithFields(logFields).Info("User successfully logged in by service.")
-	return token, nil
+
+	if user.Status == model.UserStatusDisabled {
+		logger.Log.WithFields(logFields).Warn("Passkey login attempt for disabled account.")
+		return nil, errors.New("account disabled")
+	}
+	// Lockout karena tebakan password tetap berlaku agar penyerang tidak bisa membukanya lewat jalur lain
+	if err := s.throttle.check(user, time.Now()); err != nil {
+		logger.Log.WithFields(logFields).Warn("Passkey login rejected by lockout/backoff.")
+		return nil, err
+	}
+	if s.options.RequireVerifiedEmail && !user.IsEmailVerified() {
+		logger.Log.WithFields(logFields).Info("Passkey login attempt with unverified email.")
+		return nil, errors.New("email not verified")
+	}
+
+	tokens, err := s.completeLogin(user, client, time.Now(), logFields)
+	if err != nil {
+		return nil, err
+	}
+	logger.Log.WithFields(logFields).Info("User successfully logged in with passkey.")
+	return tokens, nil
+}
+
+// Implementasi RequestMagicLink
+func (s *authService) RequestMagicLink(email string, binding string) error {
+	return s.magicLinks.RequestLink(email, binding)
+}
+
+// Implementasi LoginWithMagicLink.
+// Link email hanya membuktikan akses ke inbox, jadi user dengan MFA tetap harus memasukkan kode.
+func (s *authService) LoginWithMagicLink(input model.ConsumeMagicLinkInput, binding string, client model.ClientInfo) (*model.TokenPair, error) {
+	logFields := logrus.Fields{
+		"service": "AuthService",
+		"method":  "LoginWithMagicLink",
+	}
+
+	user, err := s.magicLinks.Consume(input.Token, binding)
+	if err != nil {
+		return nil, err
+	}
+	logFields["user_id"] = user.ID
+
+	if user.Status == model.UserStatusDisabled {
+		logger.Log.WithFields(logFields).Warn("Magic link login attempt for disabled account.")
+		return nil, errors.New("account disabled")
+	}
+	if err := s.throttle.check(user, time.Now()); err != nil {
+		logger.Log.WithFields(logFields).Warn("Magic link login rejected by lockout/backoff.")
+		return nil, err
+	}
+	// Link yang dikirim ke alamat email sekaligus membuktikan kepemilikan email tersebut
+	if !user.IsEmailVerified() {
+		if err := s.userRepo.MarkEmailVerified(user.ID); err != nil {
+			logger.Log.WithFields(logFields).Errorf("Error marking email verified: %v", err)
+			return nil, errors.New("an error occurred during login")
+		}
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+	}
+
+	if err := s.requireMFA(user, logFields); err != nil {
+		return nil, err
+	}
+
+	tokens, err := s.completeLogin(user, client, time.Now(), logFields)
+	if err != nil {
+		return nil, err
+	}
+	logger.Log.WithFields(logFields).Info("User successfully logged in with magic link.")
+	return tokens, nil
+}
+
+// Implementasi SocialProviders
+func (s *authService) SocialProviders() []model.SocialProvider {
+	return s.socialLogins.Providers()
+}
+
+// Implementasi BeginSocialLogin
+func (s *authService) BeginSocialLogin(provider string) (*model.SocialLoginStart, string, error) {
+	return s.socialLogins.Begin(provider)
+}
+
+// Implementasi LoginWithSocial.
+// Provider eksternal dianggap satu faktor, jadi user dengan MFA tetap harus memasukkan kode.
+func (s *authService) LoginWithSocial(provider string, input model.SocialCallbackInput, browserState string,
+	client model.ClientInfo) (*model.TokenPair, error) {
+	logFields := logrus.Fields{
+		"service":  "AuthService",
+		"method":   "LoginWithSocial",
+		"provider": provider,
+	}
+
+	user, err := s.socialLogins.Complete(provider, input, browserState)
+	if err != nil {
+		return nil, err
+	}
+	logFields["user_id"] = user.ID
+
+	if user.Status == model.UserStatusDisabled {
+		logger.Log.WithFields(logFields).Warn("Social login attempt for disabled account.")
+		return nil, errors.New("account disabled")
+	}
+	if err := s.throttle.check(user, time.Now()); err != nil {
+		logger.Log.WithFields(logFields).Warn("Social login rejected by lockout/backoff.")
+		return nil, err
+	}
+	if s.options.RequireVerifiedEmail && !user.IsEmailVerified() {
+		logger.Log.WithFields(logFields).Info("Social login attempt with unverified email.")
+		return nil, errors.New("email not verified")
+	}
+
+	if err := s.requireMFA(user, logFields); err != nil {
+		return nil, err
+	}
+
+	tokens, err := s.completeLogin(user, client, time.Now(), logFields)
+	if err != nil {
+		return nil, err
+	}
+	logger.Log.WithFields(logFields).Info("User successfully logged in with social provider.")
+	return tokens, nil
+}
+
+// completeLogin dijalankan setelah semua faktor login terpenuhi: hitungan login gagal di-reset,
+// penghapusan akun yang terjadwal dibatalkan, lalu session baru dimulai beserta token-nya
+func (s *authService) completeLogin(user *model.User, client model.ClientInfo, authTime time.Time, logFields logrus.Fields) (*model.TokenPair, error) {
+	if err := s.throttle.reset(user); err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error resetting failed logins: %v", err)
+	}
+
+	// Login selama masa tenggang membatalkan penghapusan akun
+	if user.Status == model.UserStatusPendingDeletion {
+		if err := s.userRepo.SetStatus(user.ID, model.UserStatusActive, nil); err != nil {
+			logger.Log.WithFields(logFields).Errorf("Error cancelling account deletion: %v", err)
+			return nil, errors.New("an error occurred during login")
+		}
+		user.Status = model.UserStatusActive
+		user.DeletionAt = nil
+		logger.Log.WithFields(logFields).Info("Account deletion cancelled by login.")
+	}
+
+	// Setiap login memulai family refresh token baru, yang sekaligus menjadi ID session
+	familyID, err := newTokenFamilyID()
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error generating refresh token family: %v", err)
+		return nil, errors.New("failed to generate token")
+	}
+	if err := s.sessionService.Start(user.ID, familyID, client); err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error starting session: %v", err)
+		return nil, errors.New("failed to generate token")
+	}
+
+	tokens, err := s.issueTokenPair(user, familyID, authTime)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error issuing tokens for user %d: %v", user.ID, err)
+		return nil, errors.New("failed to generate token")
+	}
+	return tokens, nil
+}
+
+// Implementasi Refresh: setiap refresh token hanya boleh dipakai sekali.
+// Jika token yang sudah dirotasi dipakai lagi, seluruh family dicabut karena kemungkinan besar token telah dicuri.
+func (s *authService) Refresh(refreshToken string, client model.ClientInfo) (*model.TokenPair, error) {
+	logFields := logrus.Fields{
+		"service": "AuthService",
+		"method":  "Refresh",
+	}
+
+	// Refresh token milik OAuth client hanya boleh dipakai lewat /oauth/token
+	stored, err := s.refreshTokens.consume(refreshToken, "", logFields)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(stored.UserID)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Database error while loading user for refresh: %v", err)
+		return nil, errors.New("an error occurred during token refresh")
+	}
+	if user == nil {
+		logger.Log.WithFields(logFields).Warn("Refresh token belongs to a non-existent user.")
+		return nil, errors.New("invalid refresh token")
+	}
+	if !user.IsActive() {
+		logger.Log.WithFields(logFields).Warn("Refresh token belongs to an inactive account.")
+		return nil, errors.New("invalid refresh token")
+	}
+
+	if err := s.sessionService.Resume(user.ID, stored.FamilyID, client); err != nil {
+		if err.Error() == "session revoked" {
+			logger.Log.WithFields(logFields).Warn("Refresh attempt for a revoked session.")
+			return nil, errors.New("invalid refresh token")
+		}
+		return nil, errors.New("an error occurred during token refresh")
+	}
+
+	// auth_time tetap waktu login awal, bukan waktu refresh
+	tokens, err := s.issueTokenPair(user, stored.FamilyID, stored.AuthTime)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error issuing rotated tokens: %v", err)
+		return nil, errors.New("failed to generate token")
+	}
+
+	logger.Log.WithFields(logFields).Info("Refresh token rotated successfully.")
+	return tokens, nil
+}
+
+// Implementasi Logout
+func (s *authService) Logout(userID int, jti string, expiresAt time.Time, sessionID string, refreshToken string) error {
+	logFields := logrus.Fields{
+		"service": "AuthService",
+		"method":  "Logout",
+		"user_id": userID,
+	}
+
+	if err := s.revocationService.Revoke(jti, userID, expiresAt); err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error revoking access token: %v", err)
+		return errors.New("failed to logout")
+	}
+
+	// Mencabut session juga mencabut family refresh token-nya
+	if sessionID != "" {
+		if err := s.sessionService.Revoke(userID, sessionID); err != nil && err.Error() != "session not found" {
+			logger.Log.WithFields(logFields).Errorf("Error revoking session: %v", err)
+			return errors.New("failed to logout")
+		}
+	}
+
+	if refreshToken != "" {
+		stored, err := s.refreshTokenRepo.GetByHash(auth.HashToken(refreshToken))
+		if err != nil {
+			logger.Log.WithFields(logFields).Errorf("Database error while looking up refresh token: %v", err)
+			return errors.New("failed to logout")
+		}
+		// Refresh token milik user lain diabaikan, jangan sampai logout bisa mencabut sesi orang lain
+		if stored != nil && stored.UserID == userID {
+			if err := s.refreshTokenRepo.RevokeFamily(stored.FamilyID); err != nil {
+				logger.Log.WithFields(logFields).Errorf("Error revoking refresh token family: %v", err)
+				return errors.New("failed to logout")
+			}
+		}
+	}
+
+	logger.Log.WithFields(logFields).Info("User successfully logged out by service.")
+	return nil
+}
+
+// issueTokenPair membuat access token JWT dan refresh token baru dalam family yang diberikan
+func (s *authService) issueTokenPair(user *model.User, familyID string, authTime time.Time) (*model.TokenPair, error) {
+	// Role dibaca ulang setiap token diterbitkan (termasuk saat refresh) agar perubahan role ikut terbawa
+	roles, err := s.roles.UserRoles(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	user.Roles = roles
+	// Organisasi aktif disimpan di session, jadi refresh setelah berganti organisasi membawa klaim yang baru
+	orgID, err := s.organizations.ActiveOrganization(user.ID, familyID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Kita akan mengirimkan seluruh user model ke token issuer, jadi pastikan tidak ada info sensitif selain yang dibutuhkan claims
+	claims := auth.UserClaims(*user)
+	claims.AuthTime = jwt.NewNumericDate(authTime)
+	claims.SessionID = familyID
+	claims.OrganizationID = orgID
+	accessToken, err := s.tokenIssuer.IssueClaims(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := s.refreshTokens.issue(model.RefreshToken{UserID: user.ID, FamilyID: familyID, AuthTime: authTime})
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.TokenPair{
+		AccessToken:  accessToken.Token,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(time.Until(accessToken.ExpiresAt).Round(time.Second).Seconds()),
+		SessionID:    familyID,
+	}, nil
 }
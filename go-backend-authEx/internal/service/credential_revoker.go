@@ -0,0 +1,33 @@
+package service
+
+import (
+	"time"
+
+	"go-auth-example/internal/repository"
+)
+
+// credentialRevoker mencabut semua kredensial milik user: session login (beserta access token-nya),
+// access token yang tidak terikat session seperti token OAuth, refresh token termasuk milik OAuth client,
+// dan personal access token.
+type credentialRevoker struct {
+	sessionService    SessionService
+	revocationService TokenRevocationService
+	refreshTokenRepo  repository.RefreshTokenRepository
+	patRepo           repository.PersonalAccessTokenRepository
+}
+
+// revokeAll mencabut semua kredensial user kecuali session keepSessionID (kosong = tanpa pengecualian)
+func (r credentialRevoker) revokeAll(userID int, keepSessionID string) error {
+	if _, err := r.sessionService.RevokeOthers(userID, keepSessionID); err != nil {
+		return err
+	}
+	// Access token dari session yang dipertahankan tetap berlaku karena AuthMiddleware hanya
+	// menerapkan batas ini pada token tanpa session
+	if err := r.revocationService.RevokeUserTokens(userID, time.Now()); err != nil {
+		return err
+	}
+	if err := r.refreshTokenRepo.RevokeAllForUser(userID, keepSessionID); err != nil {
+		return err
+	}
+	return r.patRepo.RevokeAllForUser(userID)
+}
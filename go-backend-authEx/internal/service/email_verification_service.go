@@ -0,0 +1,203 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"go-auth-example/internal/auth"
+	"go-auth-example/internal/logger"
+	"go-auth-example/internal/mail"
+	"go-auth-example/internal/model"
+	"go-auth-example/internal/repository"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// EmailVerificationTTL adalah masa berlaku link verifikasi email
+	EmailVerificationTTL = 24 * time.Hour
+	// emailResendCooldown membatasi seberapa sering email verifikasi boleh dikirim ulang ke user yang sama
+	emailResendCooldown = time.Minute
+)
+
+// EmailVerificationService mengirim dan memverifikasi link konfirmasi alamat email
+type EmailVerificationService interface {
+	// SendVerification membuat token baru (token lama dibatalkan) dan mengirim link verifikasi ke email user
+	SendVerification(user *model.User) error
+	// Verify memakai token dari link verifikasi dan menandai email user sebagai terverifikasi
+	Verify(token string) (*model.User, error)
+	// Resend mengirim ulang link verifikasi. Tidak mengembalikan error untuk email yang tidak terdaftar
+	// atau sudah terverifikasi agar tidak membocorkan daftar user.
+	Resend(email string) error
+}
+
+// emailVerificationService struct mengimplementasikan EmailVerificationService
+type emailVerificationService struct {
+	userRepo       repository.UserRepository
+	emailTokenRepo repository.EmailTokenRepository
+	mailer         mail.Mailer
+	verifyURL      string // Halaman frontend yang menerima ?token=... lalu memanggil POST /auth/verify-email
+}
+
+// NewEmailVerificationService adalah constructor untuk emailVerificationService
+func NewEmailVerificationService(userRepo repository.UserRepository, emailTokenRepo repository.EmailTokenRepository,
+	mailer mail.Mailer, verifyURL string) EmailVerificationService {
+	return &emailVerificationService{
+		userRepo:       userRepo,
+		emailTokenRepo: emailTokenRepo,
+		mailer:         mailer,
+		verifyURL:      verifyURL,
+	}
+}
+
+// Implementasi SendVerification
+func (s *emailVerificationService) SendVerification(user *model.User) error {
+	logFields := logrus.Fields{
+		"service": "EmailVerificationService",
+		"method":  "SendVerification",
+		"user_id": user.ID,
+	}
+
+	rawToken, err := issueEmailToken(s.emailTokenRepo, user, user.Email, model.EmailTokenPurposeVerifyEmail, EmailVerificationTTL)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error issuing verification token: %v", err)
+		return errors.New("failed to send verification email")
+	}
+
+	link, err := linkWithToken(s.verifyURL, rawToken)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error building verification link: %v", err)
+		return errors.New("failed to send verification email")
+	}
+
+	msg := mail.Message{
+		To:      user.Email,
+		Subject: "Confirm your email address",
+		Body: fmt.Sprintf("Hi %s,\n\nPlease confirm your email address by opening the link below:\n\n%s\n\n"+
+			"The link expires in %d hours. If you did not create an account, you can ignore this email.\n",
+			user.Username, link, int(EmailVerificationTTL.Hours())),
+	}
+	if err := s.mailer.Send(msg); err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error sending verification email: %v", err)
+		return errors.New("failed to send verification email")
+	}
+
+	logger.Log.WithFields(logFields).Info("Verification email sent.")
+	return nil
+}
+
+// Implementasi Verify
+func (s *emailVerificationService) Verify(token string) (*model.User, error) {
+	logFields := logrus.Fields{
+		"service": "EmailVerificationService",
+		"method":  "Verify",
+	}
+
+	emailToken, err := s.emailTokenRepo.Consume(auth.HashToken(token), model.EmailTokenPurposeVerifyEmail)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error consuming verification token: %v", err)
+		return nil, errors.New("failed to verify email")
+	}
+	if emailToken == nil {
+		return nil, errors.New("invalid or expired verification token")
+	}
+	logFields["user_id"] = emailToken.UserID
+
+	user, err := s.userRepo.GetByID(emailToken.UserID)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error loading user for verification: %v", err)
+		return nil, errors.New("failed to verify email")
+	}
+	// Token hanya berlaku untuk alamat email tempat token tersebut dikirim
+	if user == nil || user.Email != emailToken.Email {
+		return nil, errors.New("invalid or expired verification token")
+	}
+
+	if !user.IsEmailVerified() {
+		if err := s.userRepo.MarkEmailVerified(user.ID); err != nil {
+			logger.Log.WithFields(logFields).Errorf("Error marking email as verified: %v", err)
+			return nil, errors.New("failed to verify email")
+		}
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+	}
+
+	logger.Log.WithFields(logFields).Info("Email verified.")
+	user.PasswordHash = ""
+	return user, nil
+}
+
+// Implementasi Resend
+func (s *emailVerificationService) Resend(email string) error {
+	email = auth.NormalizeIdentity(email)
+	logFields := logrus.Fields{
+		"service": "EmailVerificationService",
+		"method":  "Resend",
+	}
+
+	user, err := s.userRepo.GetByEmail(email)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error loading user for resend: %v", err)
+		return errors.New("failed to send verification email")
+	}
+	if user == nil || user.IsEmailVerified() {
+		return nil
+	}
+	logFields["user_id"] = user.ID
+
+	latest, err := s.emailTokenRepo.LatestCreatedAt(user.ID, model.EmailTokenPurposeVerifyEmail)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error checking previous verification email: %v", err)
+		return errors.New("failed to send verification email")
+	}
+	if latest != nil && time.Since(*latest) < emailResendCooldown {
+		logger.Log.WithFields(logFields).Info("Verification email resend throttled.")
+		return nil
+	}
+
+	return s.SendVerification(user)
+}
+
+// issueEmailToken membuat token untuk dikirim ke alamat email dan membatalkan token lama user dengan tujuan yang sama.
+// Token acak 256-bit dan hanya hash-nya yang disimpan, jadi token tidak bisa ditebak maupun dipalsukan.
+func issueEmailToken(repo repository.EmailTokenRepository, user *model.User, email string, purpose string, ttl time.Duration) (string, error) {
+	return issueBoundEmailToken(repo, user, email, purpose, ttl, "")
+}
+
+// issueBoundEmailToken sama dengan issueEmailToken, tetapi token hanya berlaku bersama nilai cookie yang hash-nya bindingHash
+func issueBoundEmailToken(repo repository.EmailTokenRepository, user *model.User, email string, purpose string, ttl time.Duration,
+	bindingHash string) (string, error) {
+	rawToken, err := auth.GenerateOpaqueToken(32)
+	if err != nil {
+		return "", err
+	}
+	if err := repo.InvalidateForUser(user.ID, purpose); err != nil {
+		return "", err
+	}
+	token := &model.EmailToken{
+		UserID:      user.ID,
+		Purpose:     purpose,
+		Email:       email,
+		TokenHash:   auth.HashToken(rawToken),
+		BindingHash: bindingHash,
+		ExpiresAt:   time.Now().Add(ttl),
+	}
+	if err := repo.Create(token); err != nil {
+		return "", err
+	}
+	return rawToken, nil
+}
+
+// linkWithToken menambahkan parameter token ke URL halaman frontend
+func linkWithToken(baseURL, token string) (string, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return "", err
+	}
+	query := u.Query()
+	query.Set("token", token)
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
@@ -0,0 +1,28 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"go-auth-example/internal/auth"
+	"go-auth-example/internal/model"
+)
+
+// RecentLoginMaxAge adalah umur login maksimum untuk mengonfirmasi operasi sensitif pada akun tanpa password
+const RecentLoginMaxAge = 5 * time.Minute
+
+// confirmIdentity memastikan pemanggil operasi sensitif adalah pemilik akun lewat password-nya.
+// Akun tanpa password (daftar lewat social login, atau password dikosongkan admin) dikonfirmasi lewat
+// login yang baru saja dilakukan; authTime adalah klaim auth_time dari access token pemanggil.
+func confirmIdentity(user *model.User, password string, authTime time.Time) error {
+	if user.PasswordHash == "" {
+		if time.Since(authTime) > RecentLoginMaxAge {
+			return errors.New("recent login required")
+		}
+		return nil
+	}
+	if !auth.CheckPasswordHash(password, user.PasswordHash) {
+		return errors.New("invalid password")
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"go-auth-example/internal/auth"
+	"go-auth-example/internal/model"
+)
+
+func TestConfirmIdentity(t *testing.T) {
+	hash, err := auth.HashPassword("correct horse battery")
+	if err != nil {
+		t.Fatalf("HashPassword: %v", err)
+	}
+	withPassword := &model.User{ID: 1, PasswordHash: hash}
+	// Akun hasil social login tidak punya password
+	passwordless := &model.User{ID: 2}
+	recent, stale := time.Now().Add(-time.Minute), time.Now().Add(-RecentLoginMaxAge-time.Minute)
+
+	tests := []struct {
+		name     string
+		user     *model.User
+		password string
+		authTime time.Time
+		want     string
+	}{
+		{"correct password", withPassword, "correct horse battery", stale, ""},
+		{"wrong password", withPassword, "wrong", recent, "invalid password"},
+		{"recent login does not replace password", withPassword, "", recent, "invalid password"},
+		{"passwordless after recent login", passwordless, "", recent, ""},
+		{"passwordless after stale login", passwordless, "", stale, "recent login required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := confirmIdentity(tt.user, tt.password, tt.authTime)
+			if tt.want == "" {
+				if err != nil {
+					t.Fatalf("confirmIdentity: %v", err)
+				}
+				return
+			}
+			expectError(t, err, tt.want)
+		})
+	}
+}
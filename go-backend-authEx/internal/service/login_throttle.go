@@ -0,0 +1,162 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"go-auth-example/internal/logger"
+	"go-auth-example/internal/model"
+	"go-auth-example/internal/repository"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Nilai default LoginThrottleOptions
+const (
+	DefaultLoginMaxAttempts     = 5
+	DefaultLoginLockoutDuration = 15 * time.Minute
+	DefaultLoginBackoffBase     = time.Second
+	loginBackoffMax             = time.Minute
+	// loginFailureWindow: login gagal yang lebih lama dari ini tidak dihitung lagi
+	loginFailureWindow = 24 * time.Hour
+)
+
+// LoginThrottleOptions mengatur pembatasan login gagal per akun
+type LoginThrottleOptions struct {
+	// MaxAttempts adalah jumlah login gagal berturut-turut sebelum akun dikunci sementara (0 = default)
+	MaxAttempts int
+	// LockoutDuration adalah lama akun dikunci setelah MaxAttempts tercapai (0 = default)
+	LockoutDuration time.Duration
+	// BackoffBase adalah jeda setelah login gagal pertama; jeda berlipat dua untuk setiap kegagalan berikutnya (0 = default)
+	BackoffBase time.Duration
+}
+
+// AccountLockedError dikembalikan saat login ditolak karena backoff atau akun terkunci
+type AccountLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *AccountLockedError) Error() string {
+	return "account locked"
+}
+
+// loginThrottle menerapkan backoff eksponensial dan lockout sementara berdasarkan login gagal per akun.
+// Hitungan disimpan di tabel users sehingga berlaku di semua instance server. Identifier yang tidak cocok
+// dengan akun mana pun dihitung dengan aturan yang sama di tabel login_failures, sehingga respons 429
+// tidak membocorkan akun mana yang ada.
+type loginThrottle struct {
+	userRepo    repository.UserRepository
+	failureRepo repository.LoginFailureRepository
+	options     LoginThrottleOptions
+}
+
+// newLoginThrottle mengisi opsi yang kosong dengan nilai default
+func newLoginThrottle(userRepo repository.UserRepository, failureRepo repository.LoginFailureRepository,
+	options LoginThrottleOptions) loginThrottle {
+	if options.MaxAttempts <= 0 {
+		options.MaxAttempts = DefaultLoginMaxAttempts
+	}
+	if options.LockoutDuration <= 0 {
+		options.LockoutDuration = DefaultLoginLockoutDuration
+	}
+	if options.BackoffBase <= 0 {
+		options.BackoffBase = DefaultLoginBackoffBase
+	}
+	return loginThrottle{userRepo: userRepo, failureRepo: failureRepo, options: options}
+}
+
+// check mengembalikan *AccountLockedError jika user belum boleh mencoba login lagi
+func (t loginThrottle) check(user *model.User, now time.Time) error {
+	return t.checkFailures(user.FailedLoginCount, user.LastFailedLoginAt, user.LockedUntil, now)
+}
+
+// checkUnknown sama dengan check untuk identifier yang tidak cocok dengan akun mana pun
+func (t loginThrottle) checkUnknown(identifier string, now time.Time) error {
+	failure, err := t.failureRepo.Get(identifier)
+	if err != nil {
+		return err
+	}
+	if failure == nil {
+		return nil
+	}
+	return t.checkFailures(failure.FailedCount, &failure.LastFailedAt, failure.LockedUntil, now)
+}
+
+func (t loginThrottle) checkFailures(failures int, lastFailedAt, lockedUntil *time.Time, now time.Time) error {
+	if lockedUntil != nil && now.Before(*lockedUntil) {
+		return &AccountLockedError{RetryAfter: lockedUntil.Sub(now)}
+	}
+	if failures > 0 && lastFailedAt != nil {
+		next := lastFailedAt.Add(t.backoff(failures))
+		if now.Before(next) {
+			return &AccountLockedError{RetryAfter: next.Sub(now)}
+		}
+	}
+	return nil
+}
+
+// backoff menghitung jeda minimum setelah failures kali login gagal: base, 2*base, 4*base, ... dibatasi loginBackoffMax
+func (t loginThrottle) backoff(failures int) time.Duration {
+	delay := t.options.BackoffBase
+	for i := 1; i < failures && delay < loginBackoffMax; i++ {
+		delay *= 2
+	}
+	if delay > loginBackoffMax {
+		delay = loginBackoffMax
+	}
+	return delay
+}
+
+// recordFailure mencatat login gagal dan mengunci akun jika batas tercapai
+func (t loginThrottle) recordFailure(user *model.User, logFields logrus.Fields) error {
+	count, err := t.userRepo.RecordFailedLogin(user.ID, time.Now().Add(-loginFailureWindow))
+	if err != nil {
+		return err
+	}
+	if count < t.options.MaxAttempts {
+		return nil
+	}
+
+	until := time.Now().Add(t.options.LockoutDuration)
+	if err := t.userRepo.LockUntil(user.ID, until); err != nil {
+		return fmt.Errorf("could not lock account: %w", err)
+	}
+	logger.Log.WithFields(logFields).WithFields(logrus.Fields{
+		"failed_attempts": count,
+		"locked_until":    until,
+	}).Warn("Account temporarily locked after repeated failed logins.")
+	return nil
+}
+
+// recordUnknownFailure sama dengan recordFailure untuk identifier yang tidak cocok dengan akun mana pun
+func (t loginThrottle) recordUnknownFailure(identifier string, logFields logrus.Fields) error {
+	// Tabel ini bisa diisi identifier sembarang, jadi hitungan yang sudah tidak berlaku dibersihkan di sini
+	if _, err := t.failureRepo.DeleteBefore(time.Now().Add(-loginFailureWindow)); err != nil {
+		return err
+	}
+	count, err := t.failureRepo.RecordFailure(identifier)
+	if err != nil {
+		return err
+	}
+	if count < t.options.MaxAttempts {
+		return nil
+	}
+
+	until := time.Now().Add(t.options.LockoutDuration)
+	if err := t.failureRepo.LockUntil(identifier, until); err != nil {
+		return fmt.Errorf("could not lock identifier: %w", err)
+	}
+	logger.Log.WithFields(logFields).WithFields(logrus.Fields{
+		"failed_attempts": count,
+		"locked_until":    until,
+	}).Warn("Unknown identifier temporarily locked after repeated failed logins.")
+	return nil
+}
+
+// reset mengosongkan hitungan setelah login berhasil
+func (t loginThrottle) reset(user *model.User) error {
+	if user.FailedLoginCount == 0 && user.LockedUntil == nil {
+		return nil
+	}
+	return t.userRepo.ResetFailedLogins(user.ID)
+}
@@ -0,0 +1,35 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"go-auth-example/internal/model"
+)
+
+// loginOutcome mengembalikan pesan error login beserta apakah error-nya *AccountLockedError
+func loginOutcome(auth AuthService, identifier string) (string, bool) {
+	_, err := auth.Login(model.LoginInput{Identifier: identifier, Password: "wrong password"}, model.ClientInfo{})
+	if err == nil {
+		return "", false
+	}
+	var lockedErr *AccountLockedError
+	return err.Error(), errors.As(err, &lockedErr)
+}
+
+func TestLoginThrottleDoesNotRevealWhichAccountsExist(t *testing.T) {
+	env := newMagicLinkTestEnv(t, false, newMagicLinkUser(t, true))
+
+	for attempt := 1; attempt <= 2; attempt++ {
+		existing, existingLocked := loginOutcome(env.auth, "alice")
+		unknown, unknownLocked := loginOutcome(env.auth, "mallory")
+		if existing != unknown || existingLocked != unknownLocked {
+			t.Fatalf("attempt %d: existing account got %q (locked %t), unknown identifier got %q (locked %t)",
+				attempt, existing, existingLocked, unknown, unknownLocked)
+		}
+	}
+	// Percobaan kedua jatuh di dalam jeda backoff untuk keduanya
+	if _, locked := loginOutcome(env.auth, "mallory"); !locked {
+		t.Fatal("unknown identifier is not throttled after a failed login")
+	}
+}
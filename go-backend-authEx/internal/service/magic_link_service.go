@@ -0,0 +1,155 @@
+package service
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"time"
+
+	"go-auth-example/internal/auth"
+	"go-auth-example/internal/logger"
+	"go-auth-example/internal/mail"
+	"go-auth-example/internal/model"
+	"go-auth-example/internal/repository"
+
+	"github.com/sirupsen/logrus"
+)
+
+// MagicLinkTTL adalah masa berlaku link login yang dikirim lewat email
+const MagicLinkTTL = 15 * time.Minute
+
+// MagicLinkService mengirim link login sekali pakai lewat email dan memverifikasi token dari link tersebut
+type MagicLinkService interface {
+	// RequestLink mengirim link login ke email user. Tidak mengembalikan error untuk email yang tidak terdaftar
+	// agar tidak membocorkan daftar user. binding adalah nilai acak dari cookie browser yang meminta;
+	// jika pengikatan browser aktif, link hanya berlaku bersama nilai yang sama.
+	RequestLink(email string, binding string) error
+	// Consume memakai token dari link dan mengembalikan pemiliknya.
+	// binding adalah nilai cookie browser yang membuka link; diabaikan jika token tidak diikat ke browser.
+	Consume(token string, binding string) (*model.User, error)
+}
+
+// magicLinkService struct mengimplementasikan MagicLinkService
+type magicLinkService struct {
+	userRepo       repository.UserRepository
+	emailTokenRepo repository.EmailTokenRepository
+	mailer         mail.Mailer
+	loginURL       string // Halaman frontend yang menerima ?token=... lalu memanggil POST /auth/magic-link/consume
+	bindBrowser    bool   // Link hanya bisa dipakai di browser yang memintanya
+}
+
+// NewMagicLinkService adalah constructor untuk magicLinkService
+func NewMagicLinkService(userRepo repository.UserRepository, emailTokenRepo repository.EmailTokenRepository, mailer mail.Mailer,
+	loginURL string, bindBrowser bool) MagicLinkService {
+	return &magicLinkService{
+		userRepo:       userRepo,
+		emailTokenRepo: emailTokenRepo,
+		mailer:         mailer,
+		loginURL:       loginURL,
+		bindBrowser:    bindBrowser,
+	}
+}
+
+// Implementasi RequestLink
+func (s *magicLinkService) RequestLink(email string, binding string) error {
+	email = auth.NormalizeIdentity(email)
+	logFields := logrus.Fields{
+		"service": "MagicLinkService",
+		"method":  "RequestLink",
+	}
+
+	user, err := s.userRepo.GetByEmail(email)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error loading user for magic link: %v", err)
+		return errors.New("failed to send magic link")
+	}
+	if user == nil || user.Status == model.UserStatusDisabled {
+		logger.Log.WithFields(logFields).Info("Magic link requested for unknown or disabled account.")
+		return nil
+	}
+	logFields["user_id"] = user.ID
+
+	latest, err := s.emailTokenRepo.LatestCreatedAt(user.ID, model.EmailTokenPurposeMagicLogin)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error checking previous magic link: %v", err)
+		return errors.New("failed to send magic link")
+	}
+	if latest != nil && time.Since(*latest) < emailResendCooldown {
+		logger.Log.WithFields(logFields).Info("Magic link email throttled.")
+		return nil
+	}
+
+	var bindingHash string
+	if s.bindBrowser {
+		if binding == "" {
+			return errors.New("missing browser binding")
+		}
+		bindingHash = auth.HashToken(binding)
+	}
+	rawToken, err := issueBoundEmailToken(s.emailTokenRepo, user, user.Email, model.EmailTokenPurposeMagicLogin, MagicLinkTTL, bindingHash)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error issuing magic link token: %v", err)
+		return errors.New("failed to send magic link")
+	}
+	link, err := linkWithToken(s.loginURL, rawToken)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error building magic link: %v", err)
+		return errors.New("failed to send magic link")
+	}
+
+	body := fmt.Sprintf("Hi %s,\n\nOpen the link below to log in to your account:\n\n%s\n\n"+
+		"The link expires in %d minutes and can only be used once.", user.Username, link, int(MagicLinkTTL.Minutes()))
+	if s.bindBrowser {
+		body += " It only works in the browser where you requested it."
+	}
+	msg := mail.Message{
+		To:      user.Email,
+		Subject: "Your login link",
+		Body:    body + " If you did not ask for this, you can ignore this email.\n",
+	}
+	// Dikirim di background: waktu respons dan kegagalan pengiriman tidak boleh membedakan email terdaftar
+	go func() {
+		if err := s.mailer.Send(msg); err != nil {
+			logger.Log.WithFields(logFields).Errorf("Error sending magic link email: %v", err)
+			return
+		}
+		logger.Log.WithFields(logFields).Info("Magic link email sent.")
+	}()
+	return nil
+}
+
+// Implementasi Consume
+func (s *magicLinkService) Consume(token string, binding string) (*model.User, error) {
+	logFields := logrus.Fields{
+		"service": "MagicLinkService",
+		"method":  "Consume",
+	}
+
+	// Token langsung ditandai terpakai, juga jika dibuka di browser lain: link yang bocor tidak bisa dicoba ulang
+	emailToken, err := s.emailTokenRepo.Consume(auth.HashToken(token), model.EmailTokenPurposeMagicLogin)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error consuming magic link token: %v", err)
+		return nil, errors.New("failed to verify magic link")
+	}
+	if emailToken == nil {
+		return nil, errors.New("invalid or expired magic link")
+	}
+	logFields["user_id"] = emailToken.UserID
+
+	if emailToken.BindingHash != "" &&
+		subtle.ConstantTimeCompare([]byte(emailToken.BindingHash), []byte(auth.HashToken(binding))) != 1 {
+		logger.Log.WithFields(logFields).Warn("Magic link opened in a different browser.")
+		return nil, errors.New("magic link browser mismatch")
+	}
+
+	user, err := s.userRepo.GetByID(emailToken.UserID)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error loading user for magic link: %v", err)
+		return nil, errors.New("failed to verify magic link")
+	}
+	// Link tidak berlaku lagi jika email user sudah berubah sejak link dikirim
+	if user == nil || user.Email != emailToken.Email {
+		return nil, errors.New("invalid or expired magic link")
+	}
+	return user, nil
+}
@@ -0,0 +1,225 @@
+package service
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go-auth-example/internal/auth"
+	"go-auth-example/internal/mail"
+	"go-auth-example/internal/model"
+	"go-auth-example/internal/repository/memrepo"
+)
+
+const testMagicLinkURL = "http://localhost:5173/magic-link"
+
+// Stub service pendukung AuthService yang tidak diuji di sini
+
+type noMFAService struct{ MFAService }
+
+func (noMFAService) IsEnabled(userID int) (bool, error) { return false, nil }
+
+type fixedRoleService struct {
+	RoleService
+	roles []string
+}
+
+func (s fixedRoleService) UserRoles(userID int) ([]string, error) { return s.roles, nil }
+
+type fixedOrganizationService struct {
+	OrganizationService
+	orgID int
+}
+
+func (s fixedOrganizationService) ActiveOrganization(userID int, sessionID string) (int, error) {
+	return s.orgID, nil
+}
+
+type magicLinkTestEnv struct {
+	t             *testing.T
+	auth          AuthService
+	jwt           *auth.JWTService
+	mailer        *mail.MemoryMailer
+	users         *memrepo.Users
+	emailTokens   *memrepo.EmailTokens
+	sessions      *memrepo.Sessions
+	refreshTokens *memrepo.RefreshTokens
+}
+
+func newMagicLinkTestEnv(t *testing.T, bindBrowser bool, users ...model.User) *magicLinkTestEnv {
+	t.Helper()
+	jwtService, err := auth.NewJWTService(auth.TokenConfig{Issuer: "go-auth-example", Audience: []string{"go-auth-example"}},
+		auth.NewKeyStore(auth.NewHMACKeySet([]byte("magic-link-test-secret-0123456789"))))
+	if err != nil {
+		t.Fatalf("NewJWTService: %v", err)
+	}
+	env := &magicLinkTestEnv{
+		t:             t,
+		jwt:           jwtService,
+		mailer:        mail.NewMemoryMailer(),
+		users:         memrepo.NewUsers(users...),
+		emailTokens:   memrepo.NewEmailTokens(),
+		sessions:      memrepo.NewSessions(),
+		refreshTokens: memrepo.NewRefreshTokens(),
+	}
+	magicLinks := NewMagicLinkService(env.users, env.emailTokens, env.mailer, testMagicLinkURL, bindBrowser)
+	env.auth = NewAuthService(env.users, env.refreshTokens, memrepo.NewLoginFailures(), nil, NewSessionService(env.sessions, env.refreshTokens),
+		nil, noMFAService{}, nil, magicLinks, nil, fixedRoleService{roles: []string{"user"}},
+		fixedOrganizationService{orgID: 7}, jwtService, AuthOptions{})
+	return env
+}
+
+// requestLink meminta magic link dan mengambil token dari email yang terkirim di background
+func (e *magicLinkTestEnv) requestLink(email, binding string) string {
+	e.t.Helper()
+	sent := len(e.mailer.Messages())
+	if err := e.auth.RequestMagicLink(email, binding); err != nil {
+		e.t.Fatalf("RequestMagicLink: %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(e.mailer.Messages()) == sent {
+		if time.Now().After(deadline) {
+			e.t.Fatal("magic link email was not sent")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	msg := e.mailer.Messages()[sent]
+	if msg.To != email {
+		e.t.Fatalf("magic link sent to %q; want %q", msg.To, email)
+	}
+	for _, line := range strings.Split(msg.Body, "\n") {
+		if !strings.HasPrefix(line, testMagicLinkURL+"?") {
+			continue
+		}
+		link, err := url.Parse(strings.TrimSpace(line))
+		if err != nil {
+			e.t.Fatalf("parse magic link %q: %v", line, err)
+		}
+		return link.Query().Get("token")
+	}
+	e.t.Fatalf("no magic link in email body:\n%s", msg.Body)
+	return ""
+}
+
+func (e *magicLinkTestEnv) consume(token, binding string) (*model.TokenPair, error) {
+	return e.auth.LoginWithMagicLink(model.ConsumeMagicLinkInput{Token: token}, binding, model.ClientInfo{IP: "127.0.0.1", UserAgent: "test"})
+}
+
+// assertIssuedPair mengecek bahwa token pair tercatat seperti hasil login: session dan refresh token pada family yang sama
+func (e *magicLinkTestEnv) assertIssuedPair(tokens *model.TokenPair) *auth.Claims {
+	e.t.Helper()
+	claims, err := e.jwt.Verify(tokens.AccessToken)
+	if err != nil {
+		e.t.Fatalf("Verify access token: %v", err)
+	}
+	if claims.SessionID != tokens.SessionID {
+		e.t.Fatalf("sid claim %q; want session %q", claims.SessionID, tokens.SessionID)
+	}
+	if session, _ := e.sessions.GetByID(tokens.SessionID); session == nil {
+		e.t.Fatalf("no session stored for %q", tokens.SessionID)
+	}
+	stored, _ := e.refreshTokens.GetByHash(auth.HashToken(tokens.RefreshToken))
+	if stored == nil || stored.FamilyID != tokens.SessionID {
+		e.t.Fatalf("refresh token not stored in family %q: %+v", tokens.SessionID, stored)
+	}
+	return claims
+}
+
+func newMagicLinkUser(t *testing.T, verified bool) model.User {
+	t.Helper()
+	hash, err := auth.HashPassword("correct horse battery")
+	if err != nil {
+		t.Fatalf("HashPassword: %v", err)
+	}
+	user := model.User{ID: 1, Username: "alice", Email: "alice@example.com", PasswordHash: hash, Status: model.UserStatusActive}
+	if verified {
+		verifiedAt := time.Now().Add(-time.Hour)
+		user.EmailVerifiedAt = &verifiedAt
+	}
+	return user
+}
+
+func TestMagicLinkLoginIssuesSameTokensAsLogin(t *testing.T) {
+	env := newMagicLinkTestEnv(t, false, newMagicLinkUser(t, false))
+
+	passwordTokens, err := env.auth.Login(model.LoginInput{Identifier: "alice", Password: "correct horse battery"}, model.ClientInfo{})
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	magicTokens, err := env.consume(env.requestLink("alice@example.com", ""), "")
+	if err != nil {
+		t.Fatalf("LoginWithMagicLink: %v", err)
+	}
+
+	passwordClaims, magicClaims := env.assertIssuedPair(passwordTokens), env.assertIssuedPair(magicTokens)
+	if magicTokens.TokenType != passwordTokens.TokenType || magicTokens.ExpiresIn != passwordTokens.ExpiresIn {
+		t.Fatalf("magic link pair %s/%d; login pair %s/%d",
+			magicTokens.TokenType, magicTokens.ExpiresIn, passwordTokens.TokenType, passwordTokens.ExpiresIn)
+	}
+	if magicClaims.Subject != passwordClaims.Subject || magicClaims.Username != passwordClaims.Username ||
+		magicClaims.Email != passwordClaims.Email || !reflect.DeepEqual(magicClaims.Roles, passwordClaims.Roles) ||
+		magicClaims.OrganizationID != passwordClaims.OrganizationID ||
+		!reflect.DeepEqual(magicClaims.Audience, passwordClaims.Audience) || magicClaims.Issuer != passwordClaims.Issuer {
+		t.Fatalf("magic link claims %+v differ from login claims %+v", magicClaims, passwordClaims)
+	}
+	if magicTokens.SessionID == passwordTokens.SessionID {
+		t.Fatal("magic link login reused the session of the password login")
+	}
+
+	// Link membuktikan kepemilikan email
+	if user, _ := env.users.GetByID(1); !user.IsEmailVerified() {
+		t.Fatal("email not marked verified after magic link login")
+	}
+}
+
+func TestMagicLinkCannotBeConsumedTwice(t *testing.T) {
+	env := newMagicLinkTestEnv(t, false, newMagicLinkUser(t, true))
+	token := env.requestLink("alice@example.com", "")
+
+	if _, err := env.consume(token, ""); err != nil {
+		t.Fatalf("first consume: %v", err)
+	}
+	_, err := env.consume(token, "")
+	expectError(t, err, "invalid or expired magic link")
+}
+
+func TestMagicLinkExpired(t *testing.T) {
+	env := newMagicLinkTestEnv(t, false, newMagicLinkUser(t, true))
+	token := env.requestLink("alice@example.com", "")
+	env.emailTokens.ExpireAll()
+
+	_, err := env.consume(token, "")
+	expectError(t, err, "invalid or expired magic link")
+}
+
+func TestMagicLinkBoundToRequestingBrowser(t *testing.T) {
+	t.Run("same browser", func(t *testing.T) {
+		env := newMagicLinkTestEnv(t, true, newMagicLinkUser(t, true))
+		tokens, err := env.consume(env.requestLink("alice@example.com", "browser-a"), "browser-a")
+		if err != nil {
+			t.Fatalf("LoginWithMagicLink: %v", err)
+		}
+		env.assertIssuedPair(tokens)
+	})
+
+	t.Run("different browser", func(t *testing.T) {
+		env := newMagicLinkTestEnv(t, true, newMagicLinkUser(t, true))
+		token := env.requestLink("alice@example.com", "browser-a")
+
+		_, err := env.consume(token, "browser-b")
+		expectError(t, err, "magic link browser mismatch")
+		_, err = env.consume(token, "")
+		expectError(t, err, "invalid or expired magic link")
+		// Link yang sudah dibuka di browser lain tidak bisa dipakai lagi, juga oleh browser yang memintanya
+		_, err = env.consume(token, "browser-a")
+		expectError(t, err, "invalid or expired magic link")
+	})
+
+	t.Run("binding required", func(t *testing.T) {
+		env := newMagicLinkTestEnv(t, true, newMagicLinkUser(t, true))
+		expectError(t, env.auth.RequestMagicLink("alice@example.com", ""), "missing browser binding")
+	})
+}
@@ -0,0 +1,14 @@
+package service
+
+import (
+	"io"
+	"os"
+	"testing"
+
+	"go-auth-example/internal/logger"
+)
+
+func TestMain(m *testing.M) {
+	logger.Log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
@@ -0,0 +1,441 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go-auth-example/internal/auth"
+	"go-auth-example/internal/logger"
+	"go-auth-example/internal/mail"
+	"go-auth-example/internal/model"
+	"go-auth-example/internal/repository"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// MFAChallengeTTL adalah batas waktu untuk memasukkan kode MFA setelah password benar
+	MFAChallengeTTL = 5 * time.Minute
+	// mfaChallengeMaxAttempts adalah jumlah kode salah sebelum challenge dibatalkan dan user harus login ulang
+	mfaChallengeMaxAttempts = 5
+	// recoveryCodeCount adalah jumlah kode pemulihan yang dibuat sekaligus
+	recoveryCodeCount = 10
+)
+
+// MFARequiredError dikembalikan Login saat password benar tetapi user harus menyelesaikan tahap MFA.
+// Token dipakai di POST /auth/mfa/verify bersama kode dari aplikasi authenticator atau kode pemulihan.
+type MFARequiredError struct {
+	Token     string
+	ExpiresIn time.Duration
+	Methods   []string
+}
+
+func (e *MFARequiredError) Error() string {
+	return "mfa required"
+}
+
+// MFAService mengelola pendaftaran TOTP, kode pemulihan dan challenge login tahap kedua
+type MFAService interface {
+	// Status mengembalikan ringkasan MFA user
+	Status(userID int) (*model.MFAStatus, error)
+	// IsEnabled mengecek apakah user sudah mengaktifkan MFA
+	IsEnabled(userID int) (bool, error)
+	// BeginTOTPEnrollment membuat secret baru yang belum aktif sampai dikonfirmasi dengan kode pertama
+	BeginTOTPEnrollment(userID int) (*model.TOTPEnrollment, error)
+	// ConfirmTOTPEnrollment mengaktifkan MFA dan mengembalikan kode pemulihan (hanya ditampilkan sekali)
+	ConfirmTOTPEnrollment(userID int, code string) ([]string, error)
+	// RegenerateRecoveryCodes memeriksa password lalu mengganti semua kode pemulihan
+	RegenerateRecoveryCodes(userID int, password string, authTime time.Time) ([]string, error)
+	// Disable memeriksa password dan kode MFA lalu menonaktifkan MFA
+	Disable(userID int, password string, code string, authTime time.Time) error
+	// Reset menonaktifkan MFA tanpa pemeriksaan, untuk admin saat user kehilangan authenticator dan kode pemulihan
+	Reset(userID int) error
+
+	// StartChallenge membuat challenge login tahap kedua untuk user yang passwordnya sudah benar
+	StartChallenge(userID int, authTime time.Time) (*MFARequiredError, error)
+	// GetChallenge mengembalikan challenge dari token yang diberikan ke client
+	GetChallenge(token string) (*model.MFAChallenge, error)
+	// CompleteChallenge memeriksa kode untuk challenge; challenge dihapus jika kode benar atau salah terlalu sering.
+	// Mengembalikan metode yang dipakai (model.MFAMethod*).
+	CompleteChallenge(challenge *model.MFAChallenge, code string) (string, error)
+}
+
+// mfaService struct mengimplementasikan MFAService
+type mfaService struct {
+	userRepo repository.UserRepository
+	mfaRepo  repository.MFARepository
+	mailer   mail.Mailer
+	issuer   string // Nama aplikasi yang tampil di authenticator
+}
+
+// NewMFAService adalah constructor untuk mfaService
+func NewMFAService(userRepo repository.UserRepository, mfaRepo repository.MFARepository, mailer mail.Mailer, issuer string) MFAService {
+	return &mfaService{
+		userRepo: userRepo,
+		mfaRepo:  mfaRepo,
+		mailer:   mailer,
+		issuer:   issuer,
+	}
+}
+
+// Implementasi Status
+func (s *mfaService) Status(userID int) (*model.MFAStatus, error) {
+	factor, err := s.mfaRepo.GetTOTP(userID)
+	if err != nil {
+		return nil, errors.New("failed to load mfa status")
+	}
+	status := &model.MFAStatus{}
+	if factor == nil || factor.ConfirmedAt == nil {
+		return status, nil
+	}
+
+	remaining, err := s.mfaRepo.CountRecoveryCodes(userID)
+	if err != nil {
+		return nil, errors.New("failed to load mfa status")
+	}
+	status.Enabled = true
+	status.EnabledAt = factor.ConfirmedAt
+	status.RecoveryCodesRemaining = remaining
+	return status, nil
+}
+
+// Implementasi IsEnabled
+func (s *mfaService) IsEnabled(userID int) (bool, error) {
+	factor, err := s.mfaRepo.GetTOTP(userID)
+	if err != nil {
+		return false, err
+	}
+	return factor != nil && factor.ConfirmedAt != nil, nil
+}
+
+// Implementasi BeginTOTPEnrollment
+func (s *mfaService) BeginTOTPEnrollment(userID int) (*model.TOTPEnrollment, error) {
+	logFields := logrus.Fields{
+		"service": "MFAService",
+		"method":  "BeginTOTPEnrollment",
+		"user_id": userID,
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error loading user for MFA enrollment: %v", err)
+		return nil, errors.New("failed to start mfa enrollment")
+	}
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+	enabled, err := s.IsEnabled(userID)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error checking MFA status: %v", err)
+		return nil, errors.New("failed to start mfa enrollment")
+	}
+	// Secret yang aktif hanya boleh diganti lewat Disable agar tidak bisa diganti hanya dengan access token curian
+	if enabled {
+		return nil, errors.New("mfa already enabled")
+	}
+
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error generating TOTP secret: %v", err)
+		return nil, errors.New("failed to start mfa enrollment")
+	}
+	if err := s.mfaRepo.SaveTOTP(userID, secret); err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error saving TOTP secret: %v", err)
+		return nil, errors.New("failed to start mfa enrollment")
+	}
+
+	logger.Log.WithFields(logFields).Info("TOTP enrollment started.")
+	return &model.TOTPEnrollment{Secret: secret, OTPAuthURI: auth.TOTPURI(s.issuer, user.Email, secret)}, nil
+}
+
+// Implementasi ConfirmTOTPEnrollment
+func (s *mfaService) ConfirmTOTPEnrollment(userID int, code string) ([]string, error) {
+	logFields := logrus.Fields{
+		"service": "MFAService",
+		"method":  "ConfirmTOTPEnrollment",
+		"user_id": userID,
+	}
+
+	factor, err := s.mfaRepo.GetTOTP(userID)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error loading TOTP factor: %v", err)
+		return nil, errors.New("failed to confirm mfa enrollment")
+	}
+	if factor == nil {
+		return nil, errors.New("mfa enrollment not started")
+	}
+	if factor.ConfirmedAt != nil {
+		return nil, errors.New("mfa already enabled")
+	}
+
+	step, ok := auth.ValidateTOTP(factor.Secret, code, time.Now())
+	if !ok {
+		logger.Log.WithFields(logFields).Warn("Invalid TOTP code during enrollment.")
+		return nil, errors.New("invalid mfa code")
+	}
+	if err := s.mfaRepo.ConfirmTOTP(userID, step); err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error confirming TOTP factor: %v", err)
+		return nil, errors.New("failed to confirm mfa enrollment")
+	}
+	codes, err := s.issueRecoveryCodes(userID)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error issuing recovery codes: %v", err)
+		return nil, errors.New("failed to confirm mfa enrollment")
+	}
+
+	s.notify(userID, "Two-factor authentication enabled",
+		"Two-factor authentication was just enabled for your account. You will be asked for a code from your authenticator app when you log in.",
+		logFields)
+	logger.Log.WithFields(logFields).Info("MFA enabled.")
+	return codes, nil
+}
+
+// Implementasi RegenerateRecoveryCodes
+func (s *mfaService) RegenerateRecoveryCodes(userID int, password string, authTime time.Time) ([]string, error) {
+	logFields := logrus.Fields{
+		"service": "MFAService",
+		"method":  "RegenerateRecoveryCodes",
+		"user_id": userID,
+	}
+
+	if err := s.checkPassword(userID, password, authTime, logFields); err != nil {
+		return nil, err
+	}
+	enabled, err := s.IsEnabled(userID)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error checking MFA status: %v", err)
+		return nil, errors.New("failed to regenerate recovery codes")
+	}
+	if !enabled {
+		return nil, errors.New("mfa not enabled")
+	}
+
+	codes, err := s.issueRecoveryCodes(userID)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error issuing recovery codes: %v", err)
+		return nil, errors.New("failed to regenerate recovery codes")
+	}
+	logger.Log.WithFields(logFields).Info("Recovery codes regenerated.")
+	return codes, nil
+}
+
+// Implementasi Disable
+func (s *mfaService) Disable(userID int, password string, code string, authTime time.Time) error {
+	logFields := logrus.Fields{
+		"service": "MFAService",
+		"method":  "Disable",
+		"user_id": userID,
+	}
+
+	if err := s.checkPassword(userID, password, authTime, logFields); err != nil {
+		return err
+	}
+	enabled, err := s.IsEnabled(userID)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error checking MFA status: %v", err)
+		return errors.New("failed to disable mfa")
+	}
+	if !enabled {
+		return errors.New("mfa not enabled")
+	}
+	if _, err := s.verifyCode(userID, code); err != nil {
+		logger.Log.WithFields(logFields).Warn("Invalid MFA code while disabling MFA.")
+		return err
+	}
+
+	if err := s.mfaRepo.DeleteFactors(userID); err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error deleting MFA factors: %v", err)
+		return errors.New("failed to disable mfa")
+	}
+	s.notify(userID, "Two-factor authentication disabled",
+		"Two-factor authentication was just disabled for your account. If you did not do this, reset your password immediately.",
+		logFields)
+	logger.Log.WithFields(logFields).Info("MFA disabled.")
+	return nil
+}
+
+// Implementasi Reset
+func (s *mfaService) Reset(userID int) error {
+	logFields := logrus.Fields{
+		"service": "MFAService",
+		"method":  "Reset",
+		"user_id": userID,
+	}
+
+	if err := s.mfaRepo.DeleteFactors(userID); err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error deleting MFA factors: %v", err)
+		return errors.New("failed to reset mfa")
+	}
+	s.notify(userID, "Two-factor authentication reset",
+		"An administrator reset two-factor authentication for your account. Set it up again from your profile page.",
+		logFields)
+	logger.Log.WithFields(logFields).Info("MFA reset by administrator.")
+	return nil
+}
+
+// Implementasi StartChallenge
+func (s *mfaService) StartChallenge(userID int, authTime time.Time) (*MFARequiredError, error) {
+	token, err := auth.GenerateOpaqueToken(32)
+	if err != nil {
+		return nil, err
+	}
+	challenge := &model.MFAChallenge{
+		UserID:    userID,
+		TokenHash: auth.HashToken(token),
+		AuthTime:  authTime,
+		ExpiresAt: time.Now().Add(MFAChallengeTTL),
+	}
+	if err := s.mfaRepo.CreateChallenge(challenge); err != nil {
+		return nil, err
+	}
+	return &MFARequiredError{
+		Token:     token,
+		ExpiresIn: MFAChallengeTTL,
+		Methods:   []string{model.MFAMethodTOTP, model.MFAMethodRecoveryCode},
+	}, nil
+}
+
+// Implementasi GetChallenge
+func (s *mfaService) GetChallenge(token string) (*model.MFAChallenge, error) {
+	challenge, err := s.mfaRepo.GetChallenge(auth.HashToken(token))
+	if err != nil {
+		return nil, errors.New("failed to verify mfa code")
+	}
+	if challenge == nil || challenge.Attempts >= mfaChallengeMaxAttempts {
+		return nil, errors.New("invalid or expired mfa token")
+	}
+	return challenge, nil
+}
+
+// Implementasi CompleteChallenge
+func (s *mfaService) CompleteChallenge(challenge *model.MFAChallenge, code string) (string, error) {
+	logFields := logrus.Fields{
+		"service": "MFAService",
+		"method":  "CompleteChallenge",
+		"user_id": challenge.UserID,
+	}
+
+	method, err := s.verifyCode(challenge.UserID, code)
+	if err != nil {
+		if err.Error() != "invalid mfa code" {
+			return "", err
+		}
+		attempts, incErr := s.mfaRepo.IncrementChallengeAttempts(challenge.ID)
+		if incErr != nil {
+			logger.Log.WithFields(logFields).Errorf("Error counting MFA attempts: %v", incErr)
+		}
+		if attempts >= mfaChallengeMaxAttempts {
+			if _, delErr := s.mfaRepo.DeleteChallenge(challenge.ID); delErr != nil {
+				logger.Log.WithFields(logFields).Errorf("Error deleting MFA challenge: %v", delErr)
+			}
+			logger.Log.WithFields(logFields).Warn("MFA challenge cancelled after too many invalid codes.")
+		}
+		return "", err
+	}
+
+	// Challenge hanya bisa diselesaikan sekali walaupun dua request membawa kode yang benar bersamaan
+	deleted, err := s.mfaRepo.DeleteChallenge(challenge.ID)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error deleting MFA challenge: %v", err)
+		return "", errors.New("failed to verify mfa code")
+	}
+	if !deleted {
+		return "", errors.New("invalid or expired mfa token")
+	}
+
+	if method == model.MFAMethodRecoveryCode {
+		logger.Log.WithFields(logFields).Info("Login completed with a recovery code.")
+	}
+	return method, nil
+}
+
+// verifyCode menerima kode TOTP 6 digit atau kode pemulihan. Kode TOTP yang sudah dipakai
+// dan kode pemulihan yang sudah terpakai ditolak.
+func (s *mfaService) verifyCode(userID int, code string) (string, error) {
+	code = strings.TrimSpace(code)
+	if len(code) == auth.TOTPDigits {
+		factor, err := s.mfaRepo.GetTOTP(userID)
+		if err != nil {
+			return "", errors.New("failed to verify mfa code")
+		}
+		if factor == nil || factor.ConfirmedAt == nil {
+			return "", errors.New("invalid mfa code")
+		}
+		step, ok := auth.ValidateTOTP(factor.Secret, code, time.Now())
+		if !ok {
+			return "", errors.New("invalid mfa code")
+		}
+		fresh, err := s.mfaRepo.UseTOTPStep(userID, step)
+		if err != nil {
+			return "", errors.New("failed to verify mfa code")
+		}
+		if !fresh {
+			return "", errors.New("invalid mfa code")
+		}
+		return model.MFAMethodTOTP, nil
+	}
+
+	used, err := s.mfaRepo.UseRecoveryCode(userID, auth.HashToken(auth.NormalizeRecoveryCode(code)))
+	if err != nil {
+		return "", errors.New("failed to verify mfa code")
+	}
+	if !used {
+		return "", errors.New("invalid mfa code")
+	}
+	return model.MFAMethodRecoveryCode, nil
+}
+
+// issueRecoveryCodes membuat kode pemulihan baru dan hanya menyimpan hash-nya
+func (s *mfaService) issueRecoveryCodes(userID int) ([]string, error) {
+	codes, err := auth.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i] = auth.HashToken(code)
+	}
+	if err := s.mfaRepo.ReplaceRecoveryCodes(userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// checkPassword memastikan pemanggil masih mengetahui password untuk operasi MFA yang sensitif,
+// atau baru saja login jika akunnya tidak punya password
+func (s *mfaService) checkPassword(userID int, password string, authTime time.Time, logFields logrus.Fields) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error loading user: %v", err)
+		return errors.New("failed to update mfa")
+	}
+	if user == nil {
+		return errors.New("user not found")
+	}
+	if err := confirmIdentity(user, password, authTime); err != nil {
+		logger.Log.WithFields(logFields).Warnf("MFA change not confirmed: %v", err)
+		return err
+	}
+	return nil
+}
+
+// notify mengirim pemberitahuan keamanan ke email user di background
+func (s *mfaService) notify(userID int, subject, text string, logFields logrus.Fields) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil || user == nil {
+		logger.Log.WithFields(logFields).Warnf("Could not load user for MFA notice: %v", err)
+		return
+	}
+	msg := mail.Message{
+		To:      user.Email,
+		Subject: subject,
+		Body:    fmt.Sprintf("Hi %s,\n\n%s\n", user.Username, text),
+	}
+	go func() {
+		if err := s.mailer.Send(msg); err != nil {
+			logger.Log.WithFields(logFields).Warnf("Could not send MFA notice: %v", err)
+		}
+	}()
+}
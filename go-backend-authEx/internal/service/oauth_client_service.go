@@ -0,0 +1,166 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"go-auth-example/internal/auth"
+	"go-auth-example/internal/logger"
+	"go-auth-example/internal/model"
+	"go-auth-example/internal/repository"
+
+	"github.com/sirupsen/logrus"
+)
+
+// OAuthClientService mengelola registry OAuth client
+type OAuthClientService interface {
+	// RegisterClient mendaftarkan client baru. Secret hanya dikembalikan sekali (kosong untuk public client).
+	RegisterClient(input model.OAuthClientInput) (*model.OAuthClient, string, error)
+}
+
+// oauthClientService struct mengimplementasikan OAuthClientService
+type oauthClientService struct {
+	clientRepo repository.OAuthClientRepository
+}
+
+// NewOAuthClientService adalah constructor untuk oauthClientService
+func NewOAuthClientService(clientRepo repository.OAuthClientRepository) OAuthClientService {
+	return &oauthClientService{clientRepo: clientRepo}
+}
+
+var supportedGrantTypes = map[string]bool{
+	model.GrantTypeAuthorizationCode: true,
+	model.GrantTypeRefreshToken:      true,
+	model.GrantTypeClientCredentials: true,
+}
+
+// Implementasi RegisterClient
+func (s *oauthClientService) RegisterClient(input model.OAuthClientInput) (*model.OAuthClient, string, error) {
+	logFields := logrus.Fields{
+		"service": "OAuthClientService",
+		"method":  "RegisterClient",
+		"name":    input.Name,
+	}
+
+	client := &model.OAuthClient{Name: input.Name}
+	for _, grantType := range input.GrantTypes {
+		if !supportedGrantTypes[grantType] {
+			return nil, "", fmt.Errorf("unsupported grant type %q", grantType)
+		}
+		client.GrantTypes = appendUnique(client.GrantTypes, grantType)
+	}
+	if client.AllowsGrant(model.GrantTypeClientCredentials) && input.Public {
+		return nil, "", errors.New("client_credentials grant requires a confidential client")
+	}
+	if client.AllowsGrant(model.GrantTypeAuthorizationCode) && len(input.RedirectURIs) == 0 {
+		return nil, "", errors.New("authorization_code grant requires at least one redirect URI")
+	}
+	for _, redirectURI := range input.RedirectURIs {
+		if err := validateRedirectURI(redirectURI); err != nil {
+			return nil, "", err
+		}
+		client.RedirectURIs = appendUnique(client.RedirectURIs, redirectURI)
+	}
+	for _, scope := range input.Scopes {
+		for _, s := range strings.Fields(scope) {
+			client.Scopes = appendUnique(client.Scopes, s)
+		}
+	}
+
+	clientID, err := auth.GenerateOpaqueToken(16)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error generating client ID: %v", err)
+		return nil, "", errors.New("failed to register client")
+	}
+	client.ClientID = clientID
+
+	var secret string
+	if !input.Public {
+		secret, err = auth.GenerateOpaqueToken(32)
+		if err != nil {
+			logger.Log.WithFields(logFields).Errorf("Error generating client secret: %v", err)
+			return nil, "", errors.New("failed to register client")
+		}
+		client.SecretHash = auth.HashToken(secret)
+	}
+
+	if err := s.clientRepo.Create(client); err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error creating client in repository: %v", err)
+		return nil, "", errors.New("failed to register client")
+	}
+
+	logFields["client_id"] = client.ClientID
+	logger.Log.WithFields(logFields).Info("OAuth client registered.")
+	return client, secret, nil
+}
+
+// validateRedirectURI memastikan redirect URI aman untuk didaftarkan:
+// URI absolut tanpa fragment, https, http hanya untuk loopback/localhost,
+// atau private-use scheme bertitik untuk aplikasi native (RFC 8252), misal com.example.app:/callback.
+func validateRedirectURI(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || !u.IsAbs() {
+		return fmt.Errorf("redirect URI %q must be an absolute URI", raw)
+	}
+	if u.Fragment != "" || strings.Contains(raw, "#") {
+		return fmt.Errorf("redirect URI %q must not contain a fragment", raw)
+	}
+
+	switch u.Scheme {
+	case "https":
+		return nil
+	case "http":
+		if isLoopbackHost(u.Hostname()) || u.Hostname() == "localhost" {
+			return nil
+		}
+		return fmt.Errorf("redirect URI %q must use https", raw)
+	default:
+		if strings.Contains(u.Scheme, ".") {
+			return nil
+		}
+		return fmt.Errorf("redirect URI %q uses an unsupported scheme", raw)
+	}
+}
+
+// matchRedirectURI membandingkan redirect URI secara exact match (OAuth 2.1).
+// Pengecualian: untuk IP loopback, port boleh berbeda (RFC 8252 section 7.3).
+func matchRedirectURI(registered []string, requested string) bool {
+	for _, r := range registered {
+		if r == requested {
+			return true
+		}
+	}
+
+	req, err := url.Parse(requested)
+	if err != nil || req.Scheme != "http" || !isLoopbackHost(req.Hostname()) {
+		return false
+	}
+	for _, r := range registered {
+		reg, err := url.Parse(r)
+		if err != nil {
+			continue
+		}
+		if reg.Scheme == req.Scheme && reg.Hostname() == req.Hostname() &&
+			reg.Path == req.Path && reg.RawQuery == req.RawQuery {
+			return true
+		}
+	}
+	return false
+}
+
+func isLoopbackHost(host string) bool {
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func appendUnique(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
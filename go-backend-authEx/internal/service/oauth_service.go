@@ -0,0 +1,578 @@
+package service
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-auth-example/internal/auth"
+	"go-auth-example/internal/logger"
+	"go-auth-example/internal/model"
+	"go-auth-example/internal/repository"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// Kode error OAuth standar (RFC 6749 section 4.1.2.1 dan 5.2)
+const (
+	OAuthErrInvalidRequest          = "invalid_request"
+	OAuthErrInvalidClient           = "invalid_client"
+	OAuthErrInvalidGrant            = "invalid_grant"
+	OAuthErrUnauthorizedClient      = "unauthorized_client"
+	OAuthErrUnsupportedGrantType    = "unsupported_grant_type"
+	OAuthErrUnsupportedResponseType = "unsupported_response_type"
+	OAuthErrInvalidScope            = "invalid_scope"
+	OAuthErrAccessDenied            = "access_denied"
+	OAuthErrServerError             = "server_error"
+)
+
+// DefaultAuthorizationCodeTTL adalah masa berlaku authorization code
+const DefaultAuthorizationCodeTTL = 5 * time.Minute
+
+// OAuthError adalah error OAuth yang dikirim apa adanya ke client dalam format {"error", "error_description"}
+type OAuthError struct {
+	Code        string
+	Description string
+}
+
+func (e *OAuthError) Error() string {
+	return e.Code + ": " + e.Description
+}
+
+func newOAuthError(code, description string) *OAuthError {
+	return &OAuthError{Code: code, Description: description}
+}
+
+// AuthorizationContext adalah authorization request yang sudah divalidasi
+type AuthorizationContext struct {
+	Client      *model.OAuthClient
+	RedirectURI string   // Redirect URI yang sudah dicocokkan dengan registry
+	Scopes      []string // Scope yang akan diberikan
+	State       string
+}
+
+// OAuthService mengimplementasikan authorization server OAuth 2.1 (authorization code + PKCE,
+// refresh token dan client credentials)
+type OAuthService interface {
+	// ValidateAuthorizeRequest memvalidasi authorization request.
+	// Jika client atau redirect URI tidak valid, context yang dikembalikan nil dan error TIDAK boleh di-redirect.
+	// Jika keduanya valid tetapi parameter lain salah, context dikembalikan bersama error agar bisa di-redirect ke client.
+	ValidateAuthorizeRequest(req model.AuthorizeRequest) (*AuthorizationContext, error)
+	// Approve membuat authorization code untuk user dan mengembalikan URL redirect ke client.
+	// authTime adalah waktu user login, dipakai untuk klaim auth_time di ID token.
+	Approve(userID int, authTime time.Time, req model.AuthorizeRequest) (string, error)
+	// Deny mengembalikan URL redirect ke client dengan error access_denied
+	Deny(req model.AuthorizeRequest) (string, error)
+	// Token memproses token request; clientID/clientSecret berasal dari HTTP Basic atau body
+	Token(req model.OAuthTokenRequest, clientID, clientSecret string) (*model.OAuthTokenResponse, error)
+}
+
+// oauthService struct mengimplementasikan OAuthService
+type oauthService struct {
+	clientRepo    repository.OAuthClientRepository
+	codeRepo      repository.AuthorizationCodeRepository
+	userRepo      repository.UserRepository
+	tokenIssuer   auth.TokenIssuer
+	idTokens      auth.IDTokenIssuer
+	refreshTokens refreshTokenRotator
+	codeTTL       time.Duration
+}
+
+// NewOAuthService adalah constructor untuk oauthService
+func NewOAuthService(clientRepo repository.OAuthClientRepository, codeRepo repository.AuthorizationCodeRepository,
+	userRepo repository.UserRepository, refreshTokenRepo repository.RefreshTokenRepository,
+	tokenIssuer auth.TokenIssuer, idTokenIssuer auth.IDTokenIssuer) OAuthService {
+	return &oauthService{
+		clientRepo:    clientRepo,
+		codeRepo:      codeRepo,
+		userRepo:      userRepo,
+		tokenIssuer:   tokenIssuer,
+		idTokens:      idTokenIssuer,
+		refreshTokens: refreshTokenRotator{repo: refreshTokenRepo},
+		codeTTL:       DefaultAuthorizationCodeTTL,
+	}
+}
+
+// Implementasi ValidateAuthorizeRequest
+func (s *oauthService) ValidateAuthorizeRequest(req model.AuthorizeRequest) (*AuthorizationContext, error) {
+	logFields := logrus.Fields{
+		"service":   "OAuthService",
+		"method":    "ValidateAuthorizeRequest",
+		"client_id": req.ClientID,
+	}
+
+	if req.ClientID == "" {
+		return nil, newOAuthError(OAuthErrInvalidRequest, "client_id is required")
+	}
+	client, err := s.clientRepo.GetByClientID(req.ClientID)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error loading OAuth client: %v", err)
+		return nil, newOAuthError(OAuthErrServerError, "an internal error occurred")
+	}
+	if client == nil {
+		logger.Log.WithFields(logFields).Warn("Authorization request for unknown client.")
+		return nil, newOAuthError(OAuthErrInvalidRequest, "unknown client_id")
+	}
+
+	// redirect_uri boleh dihilangkan hanya jika client mendaftarkan tepat satu redirect URI
+	redirectURI := req.RedirectURI
+	if redirectURI == "" {
+		if len(client.RedirectURIs) != 1 {
+			return nil, newOAuthError(OAuthErrInvalidRequest, "redirect_uri is required")
+		}
+		redirectURI = client.RedirectURIs[0]
+	} else if !matchRedirectURI(client.RedirectURIs, redirectURI) {
+		logger.Log.WithFields(logFields).Warnf("Authorization request with unregistered redirect URI %q.", redirectURI)
+		return nil, newOAuthError(OAuthErrInvalidRequest, "redirect_uri is not registered for this client")
+	}
+
+	authCtx := &AuthorizationContext{Client: client, RedirectURI: redirectURI, State: req.State}
+
+	if req.ResponseType != "code" {
+		return authCtx, newOAuthError(OAuthErrUnsupportedResponseType, "only response_type=code is supported")
+	}
+	if !client.AllowsGrant(model.GrantTypeAuthorizationCode) {
+		return authCtx, newOAuthError(OAuthErrUnauthorizedClient, "client is not allowed to use the authorization code grant")
+	}
+	// PKCE wajib untuk semua client (OAuth 2.1)
+	if req.CodeChallenge == "" {
+		return authCtx, newOAuthError(OAuthErrInvalidRequest, "code_challenge is required")
+	}
+	if req.CodeChallengeMethod != auth.PKCEMethodS256 {
+		return authCtx, newOAuthError(OAuthErrInvalidRequest, "code_challenge_method must be S256")
+	}
+	if !auth.ValidPKCEValue(req.CodeChallenge) {
+		return authCtx, newOAuthError(OAuthErrInvalidRequest, "code_challenge is malformed")
+	}
+
+	scopes, oauthErr := resolveScopes(req.Scope, client.Scopes)
+	if oauthErr != nil {
+		return authCtx, oauthErr
+	}
+	// Tanpa key asimetris ID token tidak bisa diterbitkan; tolak sekarang daripada gagal setelah kode ditukar
+	if hasScope(scopes, ScopeOpenID) && !s.idTokens.CanIssueIDToken() {
+		if strings.TrimSpace(req.Scope) != "" {
+			logger.Log.WithFields(logFields).Warn("Authorization request for openid scope without an asymmetric signing key.")
+			return authCtx, newOAuthError(OAuthErrInvalidScope, "scope \"openid\" is not supported by this server")
+		}
+		scopes = removeScope(scopes, ScopeOpenID)
+	}
+	authCtx.Scopes = scopes
+	return authCtx, nil
+}
+
+// Implementasi Approve
+func (s *oauthService) Approve(userID int, authTime time.Time, req model.AuthorizeRequest) (string, error) {
+	logFields := logrus.Fields{
+		"service":   "OAuthService",
+		"method":    "Approve",
+		"client_id": req.ClientID,
+		"user_id":   userID,
+	}
+
+	authCtx, err := s.ValidateAuthorizeRequest(req)
+	if err != nil {
+		return s.errorRedirect(authCtx, err)
+	}
+
+	code, err := auth.GenerateOpaqueToken(32)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error generating authorization code: %v", err)
+		return s.errorRedirect(authCtx, newOAuthError(OAuthErrServerError, "an internal error occurred"))
+	}
+
+	err = s.codeRepo.Create(&model.AuthorizationCode{
+		CodeHash:            auth.HashToken(code),
+		ClientID:            authCtx.Client.ClientID,
+		UserID:              userID,
+		RedirectURI:         req.RedirectURI, // Disimpan apa adanya; jika dikirim, token request wajib mengirim nilai yang sama
+		Scope:               strings.Join(authCtx.Scopes, " "),
+		CodeChallenge:       req.CodeChallenge,
+		CodeChallengeMethod: req.CodeChallengeMethod,
+		Nonce:               req.Nonce,
+		AuthTime:            authTime,
+		ExpiresAt:           time.Now().Add(s.codeTTL),
+	})
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error storing authorization code: %v", err)
+		return s.errorRedirect(authCtx, newOAuthError(OAuthErrServerError, "an internal error occurred"))
+	}
+
+	logger.Log.WithFields(logFields).Info("Authorization code issued.")
+	return buildRedirectURL(authCtx.RedirectURI, url.Values{"code": {code}}, authCtx.State), nil
+}
+
+// Implementasi Deny
+func (s *oauthService) Deny(req model.AuthorizeRequest) (string, error) {
+	authCtx, err := s.ValidateAuthorizeRequest(req)
+	if err != nil {
+		return s.errorRedirect(authCtx, err)
+	}
+	return ErrorRedirectURL(authCtx, newOAuthError(OAuthErrAccessDenied, "the user denied the request")), nil
+}
+
+// errorRedirect mengubah error validasi menjadi URL redirect bila aman, atau meneruskan error jika tidak
+func (s *oauthService) errorRedirect(authCtx *AuthorizationContext, err error) (string, error) {
+	oauthErr, ok := err.(*OAuthError)
+	if authCtx == nil || !ok {
+		return "", err
+	}
+	return ErrorRedirectURL(authCtx, oauthErr), nil
+}
+
+// ErrorRedirectURL membangun URL redirect ke client yang membawa error OAuth
+func ErrorRedirectURL(authCtx *AuthorizationContext, oauthErr *OAuthError) string {
+	params := url.Values{"error": {oauthErr.Code}}
+	if oauthErr.Description != "" {
+		params.Set("error_description", oauthErr.Description)
+	}
+	return buildRedirectURL(authCtx.RedirectURI, params, authCtx.State)
+}
+
+func buildRedirectURL(redirectURI string, params url.Values, state string) string {
+	if state != "" {
+		params.Set("state", state)
+	}
+	u, err := url.Parse(redirectURI)
+	if err != nil {
+		return redirectURI
+	}
+	query := u.Query()
+	for key, values := range params {
+		query[key] = values
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// Implementasi Token
+func (s *oauthService) Token(req model.OAuthTokenRequest, clientID, clientSecret string) (*model.OAuthTokenResponse, error) {
+	logFields := logrus.Fields{
+		"service":    "OAuthService",
+		"method":     "Token",
+		"client_id":  clientID,
+		"grant_type": req.GrantType,
+	}
+
+	client, oauthErr := s.authenticateClient(clientID, clientSecret, logFields)
+	if oauthErr != nil {
+		return nil, oauthErr
+	}
+
+	switch req.GrantType {
+	case model.GrantTypeAuthorizationCode:
+		return s.authorizationCodeGrant(client, req, logFields)
+	case model.GrantTypeRefreshToken:
+		return s.refreshTokenGrant(client, req, logFields)
+	case model.GrantTypeClientCredentials:
+		return s.clientCredentialsGrant(client, req, logFields)
+	case "":
+		return nil, newOAuthError(OAuthErrInvalidRequest, "grant_type is required")
+	default:
+		return nil, newOAuthError(OAuthErrUnsupportedGrantType, "unsupported grant_type")
+	}
+}
+
+// authenticateClient memverifikasi client di token endpoint. Public client tidak boleh mengirim secret.
+func (s *oauthService) authenticateClient(clientID, clientSecret string, logFields logrus.Fields) (*model.OAuthClient, *OAuthError) {
+	if clientID == "" {
+		return nil, newOAuthError(OAuthErrInvalidClient, "client authentication failed")
+	}
+	client, err := s.clientRepo.GetByClientID(clientID)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error loading OAuth client: %v", err)
+		return nil, newOAuthError(OAuthErrServerError, "an internal error occurred")
+	}
+	if client == nil {
+		logger.Log.WithFields(logFields).Warn("Token request from unknown client.")
+		return nil, newOAuthError(OAuthErrInvalidClient, "client authentication failed")
+	}
+
+	if client.IsConfidential() {
+		if clientSecret == "" || subtle.ConstantTimeCompare([]byte(auth.HashToken(clientSecret)), []byte(client.SecretHash)) != 1 {
+			logger.Log.WithFields(logFields).Warn("Token request with invalid client secret.")
+			return nil, newOAuthError(OAuthErrInvalidClient, "client authentication failed")
+		}
+	} else if clientSecret != "" {
+		return nil, newOAuthError(OAuthErrInvalidClient, "public clients must not send a client secret")
+	}
+	return client, nil
+}
+
+func (s *oauthService) authorizationCodeGrant(client *model.OAuthClient, req model.OAuthTokenRequest, logFields logrus.Fields) (*model.OAuthTokenResponse, error) {
+	if !client.AllowsGrant(model.GrantTypeAuthorizationCode) {
+		return nil, newOAuthError(OAuthErrUnauthorizedClient, "client is not allowed to use this grant type")
+	}
+	if req.Code == "" || req.CodeVerifier == "" {
+		return nil, newOAuthError(OAuthErrInvalidRequest, "code and code_verifier are required")
+	}
+
+	codeHash := auth.HashToken(req.Code)
+	code, err := s.codeRepo.Consume(codeHash)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error consuming authorization code: %v", err)
+		return nil, newOAuthError(OAuthErrServerError, "an internal error occurred")
+	}
+	if code == nil {
+		s.handleCodeReuse(codeHash, logFields)
+		return nil, newOAuthError(OAuthErrInvalidGrant, "invalid authorization code")
+	}
+	logFields["user_id"] = code.UserID
+
+	if code.ClientID != client.ClientID {
+		logger.Log.WithFields(logFields).Warn("Authorization code presented by a different client.")
+		return nil, newOAuthError(OAuthErrInvalidGrant, "invalid authorization code")
+	}
+	if time.Now().After(code.ExpiresAt) {
+		return nil, newOAuthError(OAuthErrInvalidGrant, "authorization code has expired")
+	}
+	if code.RedirectURI != "" && code.RedirectURI != req.RedirectURI {
+		return nil, newOAuthError(OAuthErrInvalidGrant, "redirect_uri does not match the authorization request")
+	}
+	if !auth.VerifyPKCE(req.CodeVerifier, code.CodeChallenge, code.CodeChallengeMethod) {
+		logger.Log.WithFields(logFields).Warn("PKCE verification failed.")
+		return nil, newOAuthError(OAuthErrInvalidGrant, "code_verifier does not match code_challenge")
+	}
+
+	user, oauthErr := s.loadUser(code.UserID, logFields)
+	if oauthErr != nil {
+		return nil, oauthErr
+	}
+
+	// Family refresh token diturunkan dari ID kode, supaya bisa dicabut jika kode dipakai ulang
+	response, err := s.issueUserTokens(userTokenGrant{
+		client:   client,
+		user:     user,
+		scope:    code.Scope,
+		familyID: codeTokenFamilyID(code),
+		authTime: code.AuthTime,
+		nonce:    code.Nonce,
+	})
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error issuing tokens: %v", err)
+		return nil, newOAuthError(OAuthErrServerError, "an internal error occurred")
+	}
+
+	logger.Log.WithFields(logFields).Info("Authorization code exchanged for tokens.")
+	return response, nil
+}
+
+// handleCodeReuse mencabut refresh token yang diterbitkan dari kode yang dipakai ulang (OAuth 2.1 section 4.1.3)
+func (s *oauthService) handleCodeReuse(codeHash string, logFields logrus.Fields) {
+	code, err := s.codeRepo.GetByHash(codeHash)
+	if err != nil || code == nil || code.UsedAt == nil {
+		return
+	}
+	logFields["user_id"] = code.UserID
+	logger.Log.WithFields(logFields).Warn("Authorization code reuse detected, revoking issued tokens.")
+	if err := s.refreshTokens.repo.RevokeFamily(codeTokenFamilyID(code)); err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error revoking tokens for reused code: %v", err)
+	}
+}
+
+func (s *oauthService) refreshTokenGrant(client *model.OAuthClient, req model.OAuthTokenRequest, logFields logrus.Fields) (*model.OAuthTokenResponse, error) {
+	if !client.AllowsGrant(model.GrantTypeRefreshToken) {
+		return nil, newOAuthError(OAuthErrUnauthorizedClient, "client is not allowed to use this grant type")
+	}
+	if req.RefreshToken == "" {
+		return nil, newOAuthError(OAuthErrInvalidRequest, "refresh_token is required")
+	}
+
+	stored, err := s.refreshTokens.consume(req.RefreshToken, client.ClientID, logFields)
+	if err != nil {
+		if err.Error() == "an error occurred during token refresh" {
+			return nil, newOAuthError(OAuthErrServerError, "an internal error occurred")
+		}
+		return nil, newOAuthError(OAuthErrInvalidGrant, "invalid refresh token")
+	}
+
+	// Scope hanya boleh dipersempit, tidak diperluas
+	scope := stored.Scope
+	if req.Scope != "" {
+		scopes, oauthErr := resolveScopes(req.Scope, strings.Fields(stored.Scope))
+		if oauthErr != nil {
+			return nil, oauthErr
+		}
+		scope = strings.Join(scopes, " ")
+	}
+
+	user, oauthErr := s.loadUser(stored.UserID, logFields)
+	if oauthErr != nil {
+		return nil, oauthErr
+	}
+
+	// Refresh token baru mempertahankan scope asli walaupun access token dipersempit
+	response, err := s.issueUserTokens(userTokenGrant{
+		client:       client,
+		user:         user,
+		scope:        scope,
+		refreshScope: stored.Scope,
+		familyID:     stored.FamilyID,
+		authTime:     stored.AuthTime,
+	})
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error issuing rotated tokens: %v", err)
+		return nil, newOAuthError(OAuthErrServerError, "an internal error occurred")
+	}
+
+	logger.Log.WithFields(logFields).Info("OAuth refresh token rotated.")
+	return response, nil
+}
+
+func (s *oauthService) clientCredentialsGrant(client *model.OAuthClient, req model.OAuthTokenRequest, logFields logrus.Fields) (*model.OAuthTokenResponse, error) {
+	if !client.IsConfidential() || !client.AllowsGrant(model.GrantTypeClientCredentials) {
+		return nil, newOAuthError(OAuthErrUnauthorizedClient, "client is not allowed to use this grant type")
+	}
+
+	scopes, oauthErr := resolveScopes(req.Scope, client.Scopes)
+	if oauthErr != nil {
+		return nil, oauthErr
+	}
+	scope := strings.Join(scopes, " ")
+
+	// Token tanpa user: subject adalah client itu sendiri (RFC 9068 section 2.2)
+	accessToken, err := s.tokenIssuer.IssueClaims(auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{Subject: client.ClientID},
+		ClientID:         client.ClientID,
+		Scope:            scope,
+	})
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error issuing client credentials token: %v", err)
+		return nil, newOAuthError(OAuthErrServerError, "an internal error occurred")
+	}
+
+	logger.Log.WithFields(logFields).Info("Client credentials token issued.")
+	return &model.OAuthTokenResponse{
+		AccessToken: accessToken.Token,
+		TokenType:   "Bearer",
+		ExpiresIn:   secondsUntil(accessToken.ExpiresAt),
+		Scope:       scope,
+	}, nil
+}
+
+func (s *oauthService) loadUser(userID int, logFields logrus.Fields) (*model.User, *OAuthError) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error loading user: %v", err)
+		return nil, newOAuthError(OAuthErrServerError, "an internal error occurred")
+	}
+	if user == nil {
+		return nil, newOAuthError(OAuthErrInvalidGrant, "the resource owner no longer exists")
+	}
+	if !user.IsActive() {
+		return nil, newOAuthError(OAuthErrInvalidGrant, "the resource owner account is not active")
+	}
+	return user, nil
+}
+
+// userTokenGrant berisi data untuk menerbitkan token user atas nama client
+type userTokenGrant struct {
+	client       *model.OAuthClient
+	user         *model.User
+	scope        string // Scope access token
+	refreshScope string // Scope refresh token; kosong berarti sama dengan scope
+	familyID     string
+	authTime     time.Time
+	nonce        string // Hanya dari authorization code; tidak diulang saat refresh
+}
+
+// issueUserTokens menerbitkan access token, refresh token (jika client diizinkan)
+// dan ID token (jika scope openid diberikan) untuk user atas nama client
+func (s *oauthService) issueUserTokens(grant userTokenGrant) (*model.OAuthTokenResponse, error) {
+	subject := strconv.Itoa(grant.user.ID)
+	accessToken, err := s.tokenIssuer.IssueClaims(auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{Subject: subject},
+		ClientID:         grant.client.ClientID,
+		Scope:            grant.scope,
+		AuthTime:         jwt.NewNumericDate(grant.authTime),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := &model.OAuthTokenResponse{
+		AccessToken: accessToken.Token,
+		TokenType:   "Bearer",
+		ExpiresIn:   secondsUntil(accessToken.ExpiresAt),
+		Scope:       grant.scope,
+	}
+
+	scopes := strings.Fields(grant.scope)
+	if hasScope(scopes, ScopeOpenID) {
+		response.IDToken, err = s.idTokens.IssueIDToken(auth.IDTokenClaims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				Subject:  subject,
+				Audience: jwt.ClaimStrings{grant.client.ClientID},
+			},
+			OIDCProfileClaims: ProfileClaimsForScopes(grant.user, scopes),
+			AuthTime:          jwt.NewNumericDate(grant.authTime),
+			Nonce:             grant.nonce,
+		}, accessToken.Token)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if grant.client.AllowsGrant(model.GrantTypeRefreshToken) {
+		refreshScope := grant.refreshScope
+		if refreshScope == "" {
+			refreshScope = grant.scope
+		}
+		refreshToken, err := s.refreshTokens.issue(model.RefreshToken{
+			UserID:   grant.user.ID,
+			FamilyID: grant.familyID,
+			ClientID: grant.client.ClientID,
+			Scope:    refreshScope,
+			AuthTime: grant.authTime,
+		})
+		if err != nil {
+			return nil, err
+		}
+		response.RefreshToken = refreshToken
+	}
+	return response, nil
+}
+
+// resolveScopes mem-parsing parameter scope dan memastikan semuanya diizinkan.
+// Scope kosong berarti semua scope yang diizinkan.
+func resolveScopes(requested string, allowed []string) ([]string, *OAuthError) {
+	if strings.TrimSpace(requested) == "" {
+		return append([]string(nil), allowed...), nil
+	}
+
+	allowedSet := make(map[string]bool, len(allowed))
+	for _, a := range allowed {
+		allowedSet[a] = true
+	}
+	var scopes []string
+	for _, scope := range strings.Fields(requested) {
+		if !allowedSet[scope] {
+			return nil, newOAuthError(OAuthErrInvalidScope, fmt.Sprintf("scope %q is not allowed for this client", scope))
+		}
+		scopes = appendUnique(scopes, scope)
+	}
+	return scopes, nil
+}
+
+// removeScope mengembalikan scopes tanpa scope tertentu
+func removeScope(scopes []string, scope string) []string {
+	var kept []string
+	for _, s := range scopes {
+		if s != scope {
+			kept = append(kept, s)
+		}
+	}
+	return kept
+}
+
+func codeTokenFamilyID(code *model.AuthorizationCode) string {
+	return fmt.Sprintf("oauth-code-%d", code.ID)
+}
+
+func secondsUntil(t time.Time) int64 {
+	return int64(time.Until(t).Round(time.Second).Seconds())
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"go-auth-example/internal/auth"
+	"go-auth-example/internal/model"
+)
+
+// Scope standar OpenID Connect
+const (
+	ScopeOpenID  = "openid"
+	ScopeProfile = "profile"
+	ScopeEmail   = "email"
+)
+
+// SupportedOIDCScopes dipublikasikan di discovery document
+var SupportedOIDCScopes = []string{ScopeOpenID, ScopeProfile, ScopeEmail}
+
+// ProfileClaimsForScopes memilih klaim user yang boleh dilihat client berdasarkan scope yang diberikan
+// (OIDC Core section 5.4). Dipakai bersama oleh ID token dan endpoint /userinfo.
+func ProfileClaimsForScopes(user *model.User, scopes []string) auth.OIDCProfileClaims {
+	var claims auth.OIDCProfileClaims
+	if hasScope(scopes, ScopeProfile) {
+		claims.PreferredUsername = user.Username
+	}
+	if hasScope(scopes, ScopeEmail) {
+		verified := user.IsEmailVerified()
+		claims.Email = user.Email
+		claims.EmailVerified = &verified
+	}
+	return claims
+}
+
+func hasScope(scopes []string, scope string) bool {
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
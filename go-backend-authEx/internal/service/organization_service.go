@@ -0,0 +1,616 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"regexp"
+	"strings"
+	"time"
+
+	"go-auth-example/internal/auth"
+	"go-auth-example/internal/logger"
+	"go-auth-example/internal/mail"
+	"go-auth-example/internal/model"
+	"go-auth-example/internal/repository"
+
+	"github.com/sirupsen/logrus"
+)
+
+// OrganizationInvitationTTL adalah masa berlaku undangan bergabung ke organisasi
+const OrganizationInvitationTTL = 7 * 24 * time.Hour
+
+// maxSlugAttempts membatasi percobaan akhiran acak untuk slug yang dibuat dari nama organisasi
+const maxSlugAttempts = 5
+
+// organizationSlugPattern: huruf kecil, angka dan tanda hubung, tidak diawali atau diakhiri tanda hubung
+var organizationSlugPattern = regexp.MustCompile(`^[a-z0-9](?:[a-z0-9-]{1,48}[a-z0-9])$`)
+
+// OrganizationService mengelola organisasi, anggotanya, undangan dan organisasi aktif per session login.
+// actor adalah keanggotaan user yang menjalankan operasi di organisasi tersebut (lihat OrganizationMiddleware).
+type OrganizationService interface {
+	// Create membuat organisasi dengan user sebagai owner
+	Create(userID int, input model.CreateOrganizationInput) (*model.Organization, error)
+	// ListForUser mengembalikan organisasi tempat user menjadi anggota beserta role-nya
+	ListForUser(userID int) ([]model.Organization, error)
+	// Get mengembalikan organisasi beserta role actor di dalamnya
+	Get(actor *model.Membership) (*model.Organization, error)
+	// Delete menghapus organisasi beserta anggota dan undangannya; hanya owner
+	Delete(actor *model.Membership) error
+
+	// Membership mengembalikan keanggotaan user di organisasi, nil jika bukan anggota
+	Membership(orgID, userID int) (*model.Membership, error)
+	// ListMembers mengembalikan semua anggota organisasi
+	ListMembers(orgID int) ([]model.Membership, error)
+	// UpdateMemberRole mengubah role anggota. Hanya owner yang bisa menunjuk atau mengubah owner,
+	// dan owner terakhir tidak bisa diturunkan.
+	UpdateMemberRole(actor *model.Membership, userID int, role string) error
+	// RemoveMember mengeluarkan anggota, atau keluar dari organisasi jika userID adalah actor sendiri.
+	// Owner terakhir tidak bisa dikeluarkan.
+	RemoveMember(actor *model.Membership, userID int) error
+
+	// Invite mengundang alamat email bergabung ke organisasi actor dan mengirim pemberitahuan lewat email
+	Invite(actor *model.Membership, input model.InviteMemberInput) (*model.Invitation, error)
+	// ListInvitations mengembalikan undangan organisasi yang masih berlaku
+	ListInvitations(orgID int) ([]model.Invitation, error)
+	// RevokeInvitation membatalkan undangan organisasi
+	RevokeInvitation(orgID int, invitationID int64) error
+	// PendingInvitations mengembalikan undangan untuk email user; email user harus sudah dikonfirmasi
+	PendingInvitations(userID int) ([]model.Invitation, error)
+	// AcceptInvitation menerima undangan untuk email user dan mengembalikan organisasi yang diikuti
+	AcceptInvitation(userID int, invitationID int64) (*model.Organization, error)
+	// DeclineInvitation menolak undangan untuk email user
+	DeclineInvitation(userID int, invitationID int64) error
+
+	// ActiveOrganization mengembalikan ID organisasi aktif session login (0 jika user tidak punya organisasi).
+	// Jika belum dipilih atau user sudah bukan anggota lagi, organisasi pertama yang diikuti user dipakai.
+	ActiveOrganization(userID int, sessionID string) (int, error)
+	// Switch mengganti organisasi aktif session login. Access token baru yang membawa klaim organisasi
+	// didapat dengan refresh token.
+	Switch(userID int, sessionID string, orgID int) (*model.Organization, error)
+}
+
+// organizationService struct mengimplementasikan OrganizationService
+type organizationService struct {
+	orgRepo       repository.OrganizationRepository
+	userRepo      repository.UserRepository
+	mailer        mail.Mailer
+	invitationURL string // Halaman frontend yang menampilkan undangan milik user yang login
+}
+
+// NewOrganizationService adalah constructor untuk organizationService
+func NewOrganizationService(orgRepo repository.OrganizationRepository, userRepo repository.UserRepository,
+	mailer mail.Mailer, invitationURL string) OrganizationService {
+	return &organizationService{
+		orgRepo:       orgRepo,
+		userRepo:      userRepo,
+		mailer:        mailer,
+		invitationURL: invitationURL,
+	}
+}
+
+// Implementasi Create
+func (s *organizationService) Create(userID int, input model.CreateOrganizationInput) (*model.Organization, error) {
+	logFields := logrus.Fields{
+		"service": "OrganizationService",
+		"method":  "Create",
+		"user_id": userID,
+	}
+
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, errors.New("invalid organization name")
+	}
+	slug := input.Slug
+	generated := slug == ""
+	if generated {
+		slug = slugFromName(name)
+	}
+	if !organizationSlugPattern.MatchString(slug) {
+		return nil, errors.New("invalid slug")
+	}
+
+	for attempt := 0; attempt < maxSlugAttempts; attempt++ {
+		org := &model.Organization{Name: name, Slug: slug}
+		if attempt > 0 {
+			org.Slug = fmt.Sprintf("%s-%d", slug, 1000+rand.IntN(9000))
+		}
+		err := s.orgRepo.Create(org, userID)
+		if err == nil {
+			logFields["organization_id"] = org.ID
+			logger.Log.WithFields(logFields).Info("Organization created.")
+			return org, nil
+		}
+		if err.Error() != "slug already exists" {
+			logger.Log.WithFields(logFields).Errorf("Error creating organization: %v", err)
+			return nil, errors.New("failed to create organization")
+		}
+		// Slug pilihan user tidak diubah diam-diam
+		if !generated {
+			return nil, err
+		}
+	}
+	return nil, errors.New("slug already exists")
+}
+
+// slugFromName membuat slug dari nama organisasi; sisa panjang disisakan untuk akhiran angka jika slug sudah dipakai
+func slugFromName(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+		if b.Len() >= 44 {
+			break
+		}
+	}
+	slug := strings.TrimRight(b.String(), "-")
+	if len(slug) < 3 {
+		slug = strings.TrimRight("org-"+slug, "-")
+	}
+	return slug
+}
+
+// Implementasi ListForUser
+func (s *organizationService) ListForUser(userID int) ([]model.Organization, error) {
+	orgs, err := s.orgRepo.ListForUser(userID)
+	if err != nil {
+		logger.Log.WithFields(logrus.Fields{
+			"service": "OrganizationService",
+			"method":  "ListForUser",
+			"user_id": userID,
+		}).Errorf("Error listing organizations: %v", err)
+		return nil, errors.New("failed to list organizations")
+	}
+	return orgs, nil
+}
+
+// Implementasi Get
+func (s *organizationService) Get(actor *model.Membership) (*model.Organization, error) {
+	org, err := s.orgRepo.GetByID(actor.OrganizationID)
+	if err != nil {
+		logger.Log.WithFields(logrus.Fields{
+			"service":         "OrganizationService",
+			"method":          "Get",
+			"organization_id": actor.OrganizationID,
+		}).Errorf("Error loading organization: %v", err)
+		return nil, errors.New("failed to load organization")
+	}
+	if org == nil {
+		return nil, errors.New("organization not found")
+	}
+	org.Role = actor.Role
+	return org, nil
+}
+
+// Implementasi Delete
+func (s *organizationService) Delete(actor *model.Membership) error {
+	logFields := logrus.Fields{
+		"service":         "OrganizationService",
+		"method":          "Delete",
+		"organization_id": actor.OrganizationID,
+		"user_id":         actor.UserID,
+	}
+
+	if actor.Role != model.OrgRoleOwner {
+		return errors.New("insufficient organization role")
+	}
+	if err := s.orgRepo.Delete(actor.OrganizationID); err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error deleting organization: %v", err)
+		return errors.New("failed to delete organization")
+	}
+	logger.Log.WithFields(logFields).Info("Organization deleted.")
+	return nil
+}
+
+// Implementasi Membership
+func (s *organizationService) Membership(orgID, userID int) (*model.Membership, error) {
+	membership, err := s.orgRepo.GetMembership(orgID, userID)
+	if err != nil {
+		logger.Log.WithFields(logrus.Fields{
+			"service":         "OrganizationService",
+			"method":          "Membership",
+			"organization_id": orgID,
+			"user_id":         userID,
+		}).Errorf("Error loading membership: %v", err)
+		return nil, errors.New("failed to load membership")
+	}
+	return membership, nil
+}
+
+// Implementasi ListMembers
+func (s *organizationService) ListMembers(orgID int) ([]model.Membership, error) {
+	members, err := s.orgRepo.ListMembers(orgID)
+	if err != nil {
+		logger.Log.WithFields(logrus.Fields{
+			"service":         "OrganizationService",
+			"method":          "ListMembers",
+			"organization_id": orgID,
+		}).Errorf("Error listing members: %v", err)
+		return nil, errors.New("failed to list members")
+	}
+	return members, nil
+}
+
+// Implementasi UpdateMemberRole
+func (s *organizationService) UpdateMemberRole(actor *model.Membership, userID int, role string) error {
+	logFields := logrus.Fields{
+		"service":         "OrganizationService",
+		"method":          "UpdateMemberRole",
+		"organization_id": actor.OrganizationID,
+		"user_id":         actor.UserID,
+		"member_id":       userID,
+		"role":            role,
+	}
+
+	target, err := s.orgRepo.GetMembership(actor.OrganizationID, userID)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error loading member: %v", err)
+		return errors.New("failed to update member role")
+	}
+	if target == nil {
+		return errors.New("member not found")
+	}
+	if (role == model.OrgRoleOwner || target.Role == model.OrgRoleOwner) && actor.Role != model.OrgRoleOwner {
+		return errors.New("insufficient organization role")
+	}
+	if target.Role == role {
+		return nil
+	}
+	if target.Role == model.OrgRoleOwner {
+		if err := s.requireAnotherOwner(actor.OrganizationID, logFields); err != nil {
+			return err
+		}
+	}
+
+	updated, err := s.orgRepo.UpdateMemberRole(actor.OrganizationID, userID, role)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error updating member role: %v", err)
+		return errors.New("failed to update member role")
+	}
+	if !updated {
+		return errors.New("member not found")
+	}
+	logger.Log.WithFields(logFields).Info("Member role updated.")
+	return nil
+}
+
+// Implementasi RemoveMember
+func (s *organizationService) RemoveMember(actor *model.Membership, userID int) error {
+	logFields := logrus.Fields{
+		"service":         "OrganizationService",
+		"method":          "RemoveMember",
+		"organization_id": actor.OrganizationID,
+		"user_id":         actor.UserID,
+		"member_id":       userID,
+	}
+
+	target, err := s.orgRepo.GetMembership(actor.OrganizationID, userID)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error loading member: %v", err)
+		return errors.New("failed to remove member")
+	}
+	if target == nil {
+		return errors.New("member not found")
+	}
+	// Setiap anggota boleh keluar sendiri; mengeluarkan orang lain membutuhkan role admin (owner untuk mengeluarkan owner)
+	if target.UserID != actor.UserID {
+		if !model.OrgRoleAtLeast(actor.Role, model.OrgRoleAdmin) ||
+			(target.Role == model.OrgRoleOwner && actor.Role != model.OrgRoleOwner) {
+			return errors.New("insufficient organization role")
+		}
+	}
+	if target.Role == model.OrgRoleOwner {
+		if err := s.requireAnotherOwner(actor.OrganizationID, logFields); err != nil {
+			return err
+		}
+	}
+
+	removed, err := s.orgRepo.RemoveMember(actor.OrganizationID, userID)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error removing member: %v", err)
+		return errors.New("failed to remove member")
+	}
+	if !removed {
+		return errors.New("member not found")
+	}
+	logger.Log.WithFields(logFields).Info("Member removed.")
+	return nil
+}
+
+// requireAnotherOwner menolak perubahan yang membuat organisasi tidak punya owner lagi
+func (s *organizationService) requireAnotherOwner(orgID int, logFields logrus.Fields) error {
+	owners, err := s.orgRepo.CountMembersWithRole(orgID, model.OrgRoleOwner)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error counting owners: %v", err)
+		return errors.New("failed to update membership")
+	}
+	if owners <= 1 {
+		return errors.New("cannot remove last owner")
+	}
+	return nil
+}
+
+// Implementasi Invite
+func (s *organizationService) Invite(actor *model.Membership, input model.InviteMemberInput) (*model.Invitation, error) {
+	logFields := logrus.Fields{
+		"service":         "OrganizationService",
+		"method":          "Invite",
+		"organization_id": actor.OrganizationID,
+		"user_id":         actor.UserID,
+	}
+
+	email := auth.NormalizeIdentity(input.Email)
+	existing, err := s.userRepo.GetByEmail(email)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error looking up invited email: %v", err)
+		return nil, errors.New("failed to create invitation")
+	}
+	if existing != nil {
+		membership, err := s.orgRepo.GetMembership(actor.OrganizationID, existing.ID)
+		if err != nil {
+			logger.Log.WithFields(logFields).Errorf("Error checking existing membership: %v", err)
+			return nil, errors.New("failed to create invitation")
+		}
+		if membership != nil {
+			return nil, errors.New("already a member")
+		}
+	}
+
+	org, err := s.orgRepo.GetByID(actor.OrganizationID)
+	if err != nil || org == nil {
+		logger.Log.WithFields(logFields).Errorf("Error loading organization: %v", err)
+		return nil, errors.New("failed to create invitation")
+	}
+
+	invitedBy := actor.UserID
+	invitation := &model.Invitation{
+		OrganizationID:   org.ID,
+		OrganizationName: org.Name,
+		Email:            email,
+		Role:             input.Role,
+		InvitedBy:        &invitedBy,
+		InvitedByName:    actor.Username,
+		ExpiresAt:        time.Now().Add(OrganizationInvitationTTL),
+	}
+	if err := s.orgRepo.CreateInvitation(invitation); err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error storing invitation: %v", err)
+		return nil, errors.New("failed to create invitation")
+	}
+
+	msg := mail.Message{
+		To:      email,
+		Subject: fmt.Sprintf("You have been invited to join %s", org.Name),
+		Body: fmt.Sprintf("Hi,\n\n%s invited you to join %s as %s.\n\n"+
+			"Log in (or create an account) with this email address, confirm the address, and accept the invitation here:\n\n%s\n\n"+
+			"The invitation expires on %s. If you were not expecting it, you can ignore this email.\n",
+			actor.Username, org.Name, input.Role, s.invitationURL, invitation.ExpiresAt.UTC().Format("2 January 2006 15:04 MST")),
+	}
+	go func() {
+		if err := s.mailer.Send(msg); err != nil {
+			logger.Log.WithFields(logFields).Errorf("Error sending invitation email: %v", err)
+		}
+	}()
+
+	logFields["invitation_id"] = invitation.ID
+	logger.Log.WithFields(logFields).Info("Invitation created.")
+	return invitation, nil
+}
+
+// Implementasi ListInvitations
+func (s *organizationService) ListInvitations(orgID int) ([]model.Invitation, error) {
+	invitations, err := s.orgRepo.ListInvitations(orgID)
+	if err != nil {
+		logger.Log.WithFields(logrus.Fields{
+			"service":         "OrganizationService",
+			"method":          "ListInvitations",
+			"organization_id": orgID,
+		}).Errorf("Error listing invitations: %v", err)
+		return nil, errors.New("failed to list invitations")
+	}
+	return invitations, nil
+}
+
+// Implementasi RevokeInvitation
+func (s *organizationService) RevokeInvitation(orgID int, invitationID int64) error {
+	logFields := logrus.Fields{
+		"service":         "OrganizationService",
+		"method":          "RevokeInvitation",
+		"organization_id": orgID,
+		"invitation_id":   invitationID,
+	}
+
+	invitation, err := s.orgRepo.GetInvitation(invitationID)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error loading invitation: %v", err)
+		return errors.New("failed to revoke invitation")
+	}
+	if invitation == nil || invitation.OrganizationID != orgID {
+		return errors.New("invitation not found")
+	}
+	if _, err := s.orgRepo.DeleteInvitation(invitationID); err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error deleting invitation: %v", err)
+		return errors.New("failed to revoke invitation")
+	}
+	logger.Log.WithFields(logFields).Info("Invitation revoked.")
+	return nil
+}
+
+// Implementasi PendingInvitations
+func (s *organizationService) PendingInvitations(userID int) ([]model.Invitation, error) {
+	logFields := logrus.Fields{
+		"service": "OrganizationService",
+		"method":  "PendingInvitations",
+		"user_id": userID,
+	}
+
+	user, err := s.invitee(userID, logFields)
+	if err != nil {
+		return nil, err
+	}
+	invitations, err := s.orgRepo.ListInvitationsForEmail(user.Email)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error listing invitations: %v", err)
+		return nil, errors.New("failed to list invitations")
+	}
+	return invitations, nil
+}
+
+// Implementasi AcceptInvitation
+func (s *organizationService) AcceptInvitation(userID int, invitationID int64) (*model.Organization, error) {
+	logFields := logrus.Fields{
+		"service":       "OrganizationService",
+		"method":        "AcceptInvitation",
+		"user_id":       userID,
+		"invitation_id": invitationID,
+	}
+
+	user, err := s.invitee(userID, logFields)
+	if err != nil {
+		return nil, err
+	}
+	invitation, err := s.invitationFor(user, invitationID, logFields)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.orgRepo.AcceptInvitation(invitation, user.ID); err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error accepting invitation: %v", err)
+		return nil, errors.New("failed to accept invitation")
+	}
+
+	org, err := s.orgRepo.GetByID(invitation.OrganizationID)
+	if err != nil || org == nil {
+		logger.Log.WithFields(logFields).Errorf("Error loading joined organization: %v", err)
+		return nil, errors.New("failed to accept invitation")
+	}
+	membership, err := s.orgRepo.GetMembership(org.ID, user.ID)
+	if err == nil && membership != nil {
+		org.Role = membership.Role
+	}
+
+	logFields["organization_id"] = org.ID
+	logger.Log.WithFields(logFields).Info("Invitation accepted.")
+	return org, nil
+}
+
+// Implementasi DeclineInvitation
+func (s *organizationService) DeclineInvitation(userID int, invitationID int64) error {
+	logFields := logrus.Fields{
+		"service":       "OrganizationService",
+		"method":        "DeclineInvitation",
+		"user_id":       userID,
+		"invitation_id": invitationID,
+	}
+
+	user, err := s.invitee(userID, logFields)
+	if err != nil {
+		return err
+	}
+	if _, err := s.invitationFor(user, invitationID, logFields); err != nil {
+		return err
+	}
+	if _, err := s.orgRepo.DeleteInvitation(invitationID); err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error declining invitation: %v", err)
+		return errors.New("failed to decline invitation")
+	}
+	logger.Log.WithFields(logFields).Info("Invitation declined.")
+	return nil
+}
+
+// invitee memuat user penerima undangan. Undangan dialamatkan ke email, jadi hanya email yang sudah
+// dikonfirmasi yang boleh melihat dan menjawabnya.
+func (s *organizationService) invitee(userID int, logFields logrus.Fields) (*model.User, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error loading user: %v", err)
+		return nil, errors.New("failed to load invitations")
+	}
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+	if !user.IsEmailVerified() {
+		return nil, errors.New("email not verified")
+	}
+	return user, nil
+}
+
+// invitationFor memuat undangan yang dialamatkan ke email user; undangan untuk email lain dianggap tidak ada
+func (s *organizationService) invitationFor(user *model.User, invitationID int64, logFields logrus.Fields) (*model.Invitation, error) {
+	invitation, err := s.orgRepo.GetInvitation(invitationID)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error loading invitation: %v", err)
+		return nil, errors.New("failed to load invitations")
+	}
+	if invitation == nil || auth.CanonicalIdentity(invitation.Email) != auth.CanonicalIdentity(user.Email) {
+		return nil, errors.New("invitation not found")
+	}
+	return invitation, nil
+}
+
+// Implementasi ActiveOrganization
+func (s *organizationService) ActiveOrganization(userID int, sessionID string) (int, error) {
+	if sessionID != "" {
+		orgID, err := s.orgRepo.GetActiveOrganization(sessionID)
+		if err != nil {
+			return 0, err
+		}
+		if orgID != 0 {
+			membership, err := s.orgRepo.GetMembership(orgID, userID)
+			if err != nil {
+				return 0, err
+			}
+			if membership != nil {
+				return orgID, nil
+			}
+		}
+	}
+
+	orgs, err := s.orgRepo.ListForUser(userID)
+	if err != nil {
+		return 0, err
+	}
+	if len(orgs) == 0 {
+		return 0, nil
+	}
+	return orgs[0].ID, nil
+}
+
+// Implementasi Switch
+func (s *organizationService) Switch(userID int, sessionID string, orgID int) (*model.Organization, error) {
+	logFields := logrus.Fields{
+		"service":         "OrganizationService",
+		"method":          "Switch",
+		"user_id":         userID,
+		"organization_id": orgID,
+	}
+
+	if sessionID == "" {
+		return nil, errors.New("session required")
+	}
+	membership, err := s.orgRepo.GetMembership(orgID, userID)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error loading membership: %v", err)
+		return nil, errors.New("failed to switch organization")
+	}
+	if membership == nil {
+		return nil, errors.New("organization not found")
+	}
+	org, err := s.Get(membership)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.orgRepo.SetActiveOrganization(sessionID, userID, orgID); err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error storing active organization: %v", err)
+		return nil, errors.New("failed to switch organization")
+	}
+
+	logger.Log.WithFields(logFields).Info("Active organization switched.")
+	return org, nil
+}
@@ -0,0 +1,451 @@
+package service
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"go-auth-example/internal/auth"
+	"go-auth-example/internal/logger"
+	"go-auth-example/internal/mail"
+	"go-auth-example/internal/model"
+	"go-auth-example/internal/repository"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// PasskeyCeremonyTTL adalah batas waktu antara langkah begin dan finish (termasuk waktu user menyentuh authenticator)
+	PasskeyCeremonyTTL = 5 * time.Minute
+	// maxPasskeysPerUser membatasi jumlah passkey yang bisa didaftarkan satu user
+	maxPasskeysPerUser = 20
+	// defaultPasskeyName dipakai jika user tidak memberi nama saat mendaftarkan passkey
+	defaultPasskeyName = "Passkey"
+)
+
+// PasskeyService mengelola pendaftaran passkey (WebAuthn) dan verifikasi login dengan passkey.
+// Setiap ceremony terdiri dari dua langkah: begin membuat challenge, finish memverifikasi respons authenticator.
+type PasskeyService interface {
+	// List mengembalikan semua passkey milik user
+	List(userID int) ([]model.Passkey, error)
+	// BeginRegistration membuat opsi navigator.credentials.create() untuk user yang sedang login
+	BeginRegistration(userID int) (*model.PasskeyCeremony, error)
+	// FinishRegistration memverifikasi attestation dan menyimpan passkey baru
+	FinishRegistration(userID int, input model.FinishPasskeyRegistrationInput) (*model.Passkey, error)
+	// Rename mengganti nama passkey milik user
+	Rename(userID int, id int64, name string) error
+	// Delete menghapus passkey milik user
+	Delete(userID int, id int64) error
+
+	// BeginLogin membuat opsi navigator.credentials.get() untuk login tanpa username (discoverable credential)
+	BeginLogin() (*model.PasskeyCeremony, error)
+	// FinishLogin memverifikasi assertion dan mengembalikan pemilik passkey.
+	// Status akun tidak diperiksa di sini; itu tugas AuthService sebelum menerbitkan token.
+	FinishLogin(input model.PasskeyLoginInput) (*model.User, error)
+}
+
+// passkeyService struct mengimplementasikan PasskeyService
+type passkeyService struct {
+	userRepo    repository.UserRepository
+	passkeyRepo repository.PasskeyRepository
+	webAuthn    *webauthn.WebAuthn // Konfigurasi relying party (RP ID dan origin frontend)
+	mailer      mail.Mailer
+}
+
+// NewPasskeyService adalah constructor untuk passkeyService
+func NewPasskeyService(userRepo repository.UserRepository, passkeyRepo repository.PasskeyRepository, webAuthn *webauthn.WebAuthn,
+	mailer mail.Mailer) PasskeyService {
+	return &passkeyService{
+		userRepo:    userRepo,
+		passkeyRepo: passkeyRepo,
+		webAuthn:    webAuthn,
+		mailer:      mailer,
+	}
+}
+
+// webAuthnUser mengadaptasi model.User ke interface webauthn.User
+type webAuthnUser struct {
+	user     *model.User
+	passkeys []model.Passkey
+}
+
+// passkeyUserHandle adalah user handle WebAuthn: ID user dalam 8 byte big-endian.
+// ID user tidak memuat data pribadi, dan dipakai untuk menemukan pemilik passkey saat login tanpa username.
+func passkeyUserHandle(userID int) []byte {
+	handle := make([]byte, 8)
+	binary.BigEndian.PutUint64(handle, uint64(userID))
+	return handle
+}
+
+func (u *webAuthnUser) WebAuthnID() []byte {
+	return passkeyUserHandle(u.user.ID)
+}
+
+func (u *webAuthnUser) WebAuthnName() string {
+	return u.user.Email
+}
+
+func (u *webAuthnUser) WebAuthnDisplayName() string {
+	return u.user.Username
+}
+
+func (u *webAuthnUser) WebAuthnCredentials() []webauthn.Credential {
+	credentials := make([]webauthn.Credential, len(u.passkeys))
+	for i, passkey := range u.passkeys {
+		transports := make([]protocol.AuthenticatorTransport, len(passkey.Transports))
+		for j, transport := range passkey.Transports {
+			transports[j] = protocol.AuthenticatorTransport(transport)
+		}
+		credentials[i] = webauthn.Credential{
+			ID:              passkey.CredentialID,
+			PublicKey:       passkey.PublicKey,
+			AttestationType: passkey.AttestationType,
+			Transport:       transports,
+			Flags: webauthn.CredentialFlags{
+				BackupEligible: passkey.BackupEligible,
+				BackupState:    passkey.BackupState,
+			},
+			// CloneWarning sengaja tidak diisi agar hanya mencerminkan assertion yang sedang diverifikasi
+			Authenticator: webauthn.Authenticator{
+				AAGUID:    passkey.AAGUID,
+				SignCount: passkey.SignCount,
+			},
+		}
+	}
+	return credentials
+}
+
+// Implementasi List
+func (s *passkeyService) List(userID int) ([]model.Passkey, error) {
+	passkeys, err := s.passkeyRepo.ListByUser(userID)
+	if err != nil {
+		return nil, errors.New("failed to list passkeys")
+	}
+	return passkeys, nil
+}
+
+// Implementasi BeginRegistration
+func (s *passkeyService) BeginRegistration(userID int) (*model.PasskeyCeremony, error) {
+	logFields := logrus.Fields{
+		"service": "PasskeyService",
+		"method":  "BeginRegistration",
+		"user_id": userID,
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error loading user: %v", err)
+		return nil, errors.New("failed to start passkey registration")
+	}
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+	passkeys, err := s.passkeyRepo.ListByUser(userID)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error listing passkeys: %v", err)
+		return nil, errors.New("failed to start passkey registration")
+	}
+	if len(passkeys) >= maxPasskeysPerUser {
+		return nil, errors.New("too many passkeys")
+	}
+
+	waUser := &webAuthnUser{user: user, passkeys: passkeys}
+	// Passkey harus discoverable (login tanpa username) dan selalu memverifikasi user (PIN/biometrik),
+	// sehingga login dengan passkey sudah memenuhi dua faktor dan tidak perlu kode TOTP lagi
+	creation, sessionData, err := s.webAuthn.BeginRegistration(waUser,
+		webauthn.WithAuthenticatorSelection(protocol.AuthenticatorSelection{
+			RequireResidentKey: protocol.ResidentKeyRequired(),
+			ResidentKey:        protocol.ResidentKeyRequirementRequired,
+			UserVerification:   protocol.VerificationRequired,
+		}),
+		webauthn.WithExclusions(webauthn.Credentials(waUser.WebAuthnCredentials()).CredentialDescriptors()),
+	)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error creating registration options: %v", err)
+		return nil, errors.New("failed to start passkey registration")
+	}
+
+	token, err := s.saveSession(&userID, model.WebAuthnCeremonyRegistration, sessionData)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error saving registration session: %v", err)
+		return nil, errors.New("failed to start passkey registration")
+	}
+	return &model.PasskeyCeremony{SessionToken: token, Options: creation}, nil
+}
+
+// Implementasi FinishRegistration
+func (s *passkeyService) FinishRegistration(userID int, input model.FinishPasskeyRegistrationInput) (*model.Passkey, error) {
+	logFields := logrus.Fields{
+		"service": "PasskeyService",
+		"method":  "FinishRegistration",
+		"user_id": userID,
+	}
+
+	sessionData, session, err := s.takeSession(input.SessionToken, model.WebAuthnCeremonyRegistration)
+	if err != nil {
+		return nil, err
+	}
+	// Session pendaftaran hanya berlaku untuk user yang memulainya
+	if session.UserID == nil || *session.UserID != userID {
+		logger.Log.WithFields(logFields).Warn("Passkey registration session belongs to another user.")
+		return nil, errors.New("invalid passkey session")
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error loading user: %v", err)
+		return nil, errors.New("failed to register passkey")
+	}
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+	passkeys, err := s.passkeyRepo.ListByUser(userID)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error listing passkeys: %v", err)
+		return nil, errors.New("failed to register passkey")
+	}
+
+	parsed, err := protocol.ParseCredentialCreationResponseBytes(input.Credential)
+	if err != nil {
+		logger.Log.WithFields(logFields).Warnf("Invalid passkey registration response: %v", describeWebAuthnError(err))
+		return nil, errors.New("passkey verification failed")
+	}
+	credential, err := s.webAuthn.CreateCredential(&webAuthnUser{user: user, passkeys: passkeys}, *sessionData, parsed)
+	if err != nil {
+		logger.Log.WithFields(logFields).Warnf("Passkey attestation rejected: %v", describeWebAuthnError(err))
+		return nil, errors.New("passkey verification failed")
+	}
+
+	existing, err := s.passkeyRepo.GetByCredentialID(credential.ID)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error checking existing passkey: %v", err)
+		return nil, errors.New("failed to register passkey")
+	}
+	if existing != nil {
+		return nil, errors.New("passkey already registered")
+	}
+
+	transports := make([]string, len(credential.Transport))
+	for i, transport := range credential.Transport {
+		transports[i] = string(transport)
+	}
+	name := input.Name
+	if name == "" {
+		name = defaultPasskeyName
+	}
+	passkey := &model.Passkey{
+		UserID:          userID,
+		CredentialID:    credential.ID,
+		PublicKey:       credential.PublicKey,
+		AttestationType: credential.AttestationType,
+		AAGUID:          credential.Authenticator.AAGUID,
+		Transports:      transports,
+		SignCount:       credential.Authenticator.SignCount,
+		BackupEligible:  credential.Flags.BackupEligible,
+		BackupState:     credential.Flags.BackupState,
+		Name:            name,
+	}
+	if err := s.passkeyRepo.Create(passkey); err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error saving passkey: %v", err)
+		return nil, errors.New("failed to register passkey")
+	}
+
+	logFields["passkey_id"] = passkey.ID
+	s.notify(user, "Passkey added",
+		fmt.Sprintf("A passkey named %q was just added to your account. It can be used to log in without your password.", name),
+		logFields)
+	logger.Log.WithFields(logFields).Info("Passkey registered.")
+	return passkey, nil
+}
+
+// Implementasi Rename
+func (s *passkeyService) Rename(userID int, id int64, name string) error {
+	found, err := s.passkeyRepo.Rename(userID, id, name)
+	if err != nil {
+		return errors.New("failed to rename passkey")
+	}
+	if !found {
+		return errors.New("passkey not found")
+	}
+	return nil
+}
+
+// Implementasi Delete
+func (s *passkeyService) Delete(userID int, id int64) error {
+	logFields := logrus.Fields{
+		"service":    "PasskeyService",
+		"method":     "Delete",
+		"user_id":    userID,
+		"passkey_id": id,
+	}
+
+	found, err := s.passkeyRepo.Delete(userID, id)
+	if err != nil {
+		return errors.New("failed to delete passkey")
+	}
+	if !found {
+		return errors.New("passkey not found")
+	}
+
+	if user, err := s.userRepo.GetByID(userID); err == nil && user != nil {
+		s.notify(user, "Passkey removed",
+			"A passkey was just removed from your account. It can no longer be used to log in.", logFields)
+	}
+	logger.Log.WithFields(logFields).Info("Passkey deleted.")
+	return nil
+}
+
+// Implementasi BeginLogin
+func (s *passkeyService) BeginLogin() (*model.PasskeyCeremony, error) {
+	logFields := logrus.Fields{
+		"service": "PasskeyService",
+		"method":  "BeginLogin",
+	}
+
+	assertion, sessionData, err := s.webAuthn.BeginDiscoverableLogin(webauthn.WithUserVerification(protocol.VerificationRequired))
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error creating login options: %v", err)
+		return nil, errors.New("failed to start passkey login")
+	}
+	token, err := s.saveSession(nil, model.WebAuthnCeremonyLogin, sessionData)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error saving login session: %v", err)
+		return nil, errors.New("failed to start passkey login")
+	}
+	return &model.PasskeyCeremony{SessionToken: token, Options: assertion}, nil
+}
+
+// Implementasi FinishLogin
+func (s *passkeyService) FinishLogin(input model.PasskeyLoginInput) (*model.User, error) {
+	logFields := logrus.Fields{
+		"service": "PasskeyService",
+		"method":  "FinishLogin",
+	}
+
+	sessionData, _, err := s.takeSession(input.SessionToken, model.WebAuthnCeremonyLogin)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := protocol.ParseCredentialRequestResponseBytes(input.Credential)
+	if err != nil {
+		logger.Log.WithFields(logFields).Warnf("Invalid passkey login response: %v", describeWebAuthnError(err))
+		return nil, errors.New("passkey verification failed")
+	}
+
+	// Pemilik passkey dicari dari credential ID, lalu dicocokkan dengan user handle yang dikirim authenticator
+	var passkey *model.Passkey
+	var user *model.User
+	lookup := func(rawID, userHandle []byte) (webauthn.User, error) {
+		found, err := s.passkeyRepo.GetByCredentialID(rawID)
+		if err != nil {
+			return nil, err
+		}
+		if found == nil {
+			return nil, errors.New("unknown credential")
+		}
+		if !bytes.Equal(userHandle, passkeyUserHandle(found.UserID)) {
+			return nil, errors.New("user handle does not match credential owner")
+		}
+		owner, err := s.userRepo.GetByID(found.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if owner == nil {
+			return nil, errors.New("credential owner not found")
+		}
+		passkey, user = found, owner
+		return &webAuthnUser{user: owner, passkeys: []model.Passkey{*found}}, nil
+	}
+
+	_, credential, err := s.webAuthn.ValidatePasskeyLogin(lookup, *sessionData, parsed)
+	if err != nil {
+		logger.Log.WithFields(logFields).Warnf("Passkey assertion rejected: %v", describeWebAuthnError(err))
+		return nil, errors.New("passkey verification failed")
+	}
+	logFields["user_id"] = user.ID
+	logFields["passkey_id"] = passkey.ID
+
+	passkey.SignCount = credential.Authenticator.SignCount
+	passkey.CloneWarning = passkey.CloneWarning || credential.Authenticator.CloneWarning
+	passkey.BackupState = credential.Flags.BackupState
+	if err := s.passkeyRepo.UpdateAfterLogin(passkey); err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error updating passkey after login: %v", err)
+		return nil, errors.New("failed to verify passkey")
+	}
+
+	// Sign counter yang tidak naik menandakan private key mungkin sudah disalin ke perangkat lain.
+	// Login ditolak; counter yang tersimpan tidak berubah sehingga authenticator asli tetap bisa dipakai.
+	if credential.Authenticator.CloneWarning {
+		logger.Log.WithFields(logFields).Warn("Passkey sign counter did not increase, possible cloned authenticator.")
+		return nil, errors.New("passkey verification failed")
+	}
+	return user, nil
+}
+
+// saveSession menyimpan SessionData WebAuthn dan mengembalikan token yang dikirim ke client
+func (s *passkeyService) saveSession(userID *int, ceremony string, sessionData *webauthn.SessionData) (string, error) {
+	data, err := json.Marshal(sessionData)
+	if err != nil {
+		return "", err
+	}
+	token, err := auth.GenerateOpaqueToken(32)
+	if err != nil {
+		return "", err
+	}
+	session := &model.WebAuthnSession{
+		UserID:    userID,
+		Ceremony:  ceremony,
+		TokenHash: auth.HashToken(token),
+		Data:      data,
+		ExpiresAt: time.Now().Add(PasskeyCeremonyTTL),
+	}
+	if err := s.passkeyRepo.CreateSession(session); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// takeSession mengambil session ceremony dari token client; session langsung dihapus sehingga challenge hanya bisa dipakai sekali
+func (s *passkeyService) takeSession(token, ceremony string) (*webauthn.SessionData, *model.WebAuthnSession, error) {
+	session, err := s.passkeyRepo.TakeSession(auth.HashToken(token), ceremony)
+	if err != nil {
+		return nil, nil, errors.New("failed to verify passkey")
+	}
+	if session == nil {
+		return nil, nil, errors.New("invalid passkey session")
+	}
+	sessionData := &webauthn.SessionData{}
+	if err := json.Unmarshal(session.Data, sessionData); err != nil {
+		return nil, nil, errors.New("failed to verify passkey")
+	}
+	return sessionData, session, nil
+}
+
+// notify mengirim pemberitahuan keamanan ke email user di background
+func (s *passkeyService) notify(user *model.User, subject, text string, logFields logrus.Fields) {
+	msg := mail.Message{
+		To:      user.Email,
+		Subject: subject,
+		Body:    fmt.Sprintf("Hi %s,\n\n%s\n", user.Username, text),
+	}
+	go func() {
+		if err := s.mailer.Send(msg); err != nil {
+			logger.Log.WithFields(logFields).Warnf("Could not send passkey notice: %v", err)
+		}
+	}()
+}
+
+// describeWebAuthnError menyertakan DevInfo dari *protocol.Error untuk log; pesan utamanya sering terlalu umum
+func describeWebAuthnError(err error) string {
+	var protocolErr *protocol.Error
+	if errors.As(err, &protocolErr) && protocolErr.DevInfo != "" {
+		return protocolErr.Details + " (" + protocolErr.DevInfo + ")"
+	}
+	return err.Error()
+}
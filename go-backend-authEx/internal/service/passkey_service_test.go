@@ -0,0 +1,454 @@
+package service
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+
+	"go-auth-example/internal/mail"
+	"go-auth-example/internal/model"
+	"go-auth-example/internal/repository/memrepo"
+
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+	"github.com/go-webauthn/webauthn/protocol/webauthncose"
+	"github.com/go-webauthn/webauthn/webauthn"
+)
+
+const (
+	testRPID     = "localhost"
+	testRPOrigin = "http://localhost:5173"
+)
+
+var b64url = base64.RawURLEncoding
+
+// softwareAuthenticator adalah authenticator WebAuthn di dalam proses: key ECDSA P-256 yang menandatangani
+// clientDataJSON dan authenticatorData, dengan format attestation "none"
+type softwareAuthenticator struct {
+	t            *testing.T
+	key          *ecdsa.PrivateKey
+	credentialID []byte
+	userHandle   []byte // Diisi saat pendaftaran dari opsi create(), dikirim kembali saat login
+	signCount    uint32
+}
+
+func newSoftwareAuthenticator(t *testing.T) *softwareAuthenticator {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	credentialID := make([]byte, 32)
+	if _, err := rand.Read(credentialID); err != nil {
+		t.Fatalf("rand.Read: %v", err)
+	}
+	return &softwareAuthenticator{t: t, key: key, credentialID: credentialID}
+}
+
+// browserOptions adalah bagian opsi WebAuthn yang dibaca browser, setelah melalui JSON seperti di frontend
+type browserOptions struct {
+	PublicKey struct {
+		Challenge string `json:"challenge"`
+		RP        struct {
+			ID string `json:"id"`
+		} `json:"rp"`
+		RPID string `json:"rpId"`
+		User struct {
+			ID string `json:"id"`
+		} `json:"user"`
+		ExcludeCredentials []struct {
+			ID string `json:"id"`
+		} `json:"excludeCredentials"`
+	} `json:"publicKey"`
+}
+
+func (a *softwareAuthenticator) readOptions(ceremony *model.PasskeyCeremony) browserOptions {
+	a.t.Helper()
+	encoded, err := json.Marshal(ceremony)
+	if err != nil {
+		a.t.Fatalf("marshal ceremony: %v", err)
+	}
+	var wrapper struct {
+		Options browserOptions `json:"options"`
+	}
+	if err := json.Unmarshal(encoded, &wrapper); err != nil {
+		a.t.Fatalf("unmarshal ceremony: %v", err)
+	}
+	return wrapper.Options
+}
+
+func (a *softwareAuthenticator) clientData(ceremonyType, challenge string) []byte {
+	data, err := json.Marshal(map[string]any{
+		"type":        ceremonyType,
+		"challenge":   challenge,
+		"origin":      testRPOrigin,
+		"crossOrigin": false,
+	})
+	if err != nil {
+		a.t.Fatalf("marshal clientDataJSON: %v", err)
+	}
+	return data
+}
+
+// authenticatorData membangun rpIdHash | flags | signCount, ditambah attested credential data jika diberikan
+func authenticatorData(rpID string, flags byte, signCount uint32, attestedCredential []byte) []byte {
+	rpIDHash := sha256.Sum256([]byte(rpID))
+	data := append([]byte{}, rpIDHash[:]...)
+	data = append(data, flags)
+	data = binary.BigEndian.AppendUint32(data, signCount)
+	return append(data, attestedCredential...)
+}
+
+const (
+	flagUserPresent  = 0x01
+	flagUserVerified = 0x04
+	flagAttestedData = 0x40
+)
+
+// create menjawab navigator.credentials.create() dan mengembalikan PublicKeyCredential dalam bentuk JSON
+func (a *softwareAuthenticator) create(ceremony *model.PasskeyCeremony) json.RawMessage {
+	a.t.Helper()
+	options := a.readOptions(ceremony)
+	userHandle, err := b64url.DecodeString(options.PublicKey.User.ID)
+	if err != nil {
+		a.t.Fatalf("decode user handle: %v", err)
+	}
+	a.userHandle = userHandle
+
+	point, err := a.key.PublicKey.ECDH()
+	if err != nil {
+		a.t.Fatalf("ECDH: %v", err)
+	}
+	uncompressed := point.Bytes()
+	coseKey, err := webauthncbor.Marshal(webauthncose.EC2PublicKeyData{
+		PublicKeyData: webauthncose.PublicKeyData{
+			KeyType:   int64(webauthncose.EllipticKey),
+			Algorithm: int64(webauthncose.AlgES256),
+		},
+		Curve:  int64(webauthncose.P256),
+		XCoord: uncompressed[1:33],
+		YCoord: uncompressed[33:65],
+	})
+	if err != nil {
+		a.t.Fatalf("marshal COSE key: %v", err)
+	}
+
+	attested := make([]byte, 16) // AAGUID kosong
+	attested = binary.BigEndian.AppendUint16(attested, uint16(len(a.credentialID)))
+	attested = append(attested, a.credentialID...)
+	attested = append(attested, coseKey...)
+	authData := authenticatorData(options.PublicKey.RP.ID, flagUserPresent|flagUserVerified|flagAttestedData, a.signCount, attested)
+
+	attestationObject, err := webauthncbor.Marshal(map[string]any{
+		"fmt":      "none",
+		"attStmt":  map[string]any{},
+		"authData": authData,
+	})
+	if err != nil {
+		a.t.Fatalf("marshal attestation object: %v", err)
+	}
+
+	return a.credential(map[string]any{
+		"clientDataJSON":    b64url.EncodeToString(a.clientData("webauthn.create", options.PublicKey.Challenge)),
+		"attestationObject": b64url.EncodeToString(attestationObject),
+		"transports":        []string{"internal"},
+	})
+}
+
+// get menjawab navigator.credentials.get() dengan sign counter berikutnya
+func (a *softwareAuthenticator) get(ceremony *model.PasskeyCeremony) json.RawMessage {
+	a.t.Helper()
+	a.signCount++
+	return a.getWithCounter(ceremony, a.signCount)
+}
+
+// getWithCounter menandatangani assertion dengan sign counter tertentu, misal untuk meniru authenticator hasil kloning
+func (a *softwareAuthenticator) getWithCounter(ceremony *model.PasskeyCeremony, signCount uint32) json.RawMessage {
+	a.t.Helper()
+	options := a.readOptions(ceremony)
+	clientData := a.clientData("webauthn.get", options.PublicKey.Challenge)
+	authData := authenticatorData(options.PublicKey.RPID, flagUserPresent|flagUserVerified, signCount, nil)
+
+	clientDataHash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(append([]byte{}, authData...), clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	if err != nil {
+		a.t.Fatalf("SignASN1: %v", err)
+	}
+
+	return a.credential(map[string]any{
+		"clientDataJSON":    b64url.EncodeToString(clientData),
+		"authenticatorData": b64url.EncodeToString(authData),
+		"signature":         b64url.EncodeToString(signature),
+		"userHandle":        b64url.EncodeToString(a.userHandle),
+	})
+}
+
+func (a *softwareAuthenticator) credential(response map[string]any) json.RawMessage {
+	a.t.Helper()
+	encoded, err := json.Marshal(map[string]any{
+		"id":                      b64url.EncodeToString(a.credentialID),
+		"rawId":                   b64url.EncodeToString(a.credentialID),
+		"type":                    "public-key",
+		"authenticatorAttachment": "platform",
+		"response":                response,
+		"clientExtensionResults":  map[string]any{},
+	})
+	if err != nil {
+		a.t.Fatalf("marshal credential: %v", err)
+	}
+	return encoded
+}
+
+type passkeyTestEnv struct {
+	t        *testing.T
+	service  PasskeyService
+	passkeys *memrepo.Passkeys
+}
+
+func newPasskeyTestEnv(t *testing.T, users ...model.User) *passkeyTestEnv {
+	t.Helper()
+	webAuthn, err := webauthn.New(&webauthn.Config{
+		RPID:          testRPID,
+		RPDisplayName: "Go Auth Example",
+		RPOrigins:     []string{testRPOrigin},
+	})
+	if err != nil {
+		t.Fatalf("webauthn.New: %v", err)
+	}
+	passkeys := memrepo.NewPasskeys()
+	return &passkeyTestEnv{
+		t:        t,
+		service:  NewPasskeyService(memrepo.NewUsers(users...), passkeys, webAuthn, mail.NewMemoryMailer()),
+		passkeys: passkeys,
+	}
+}
+
+func (e *passkeyTestEnv) register(userID int, authenticator *softwareAuthenticator, name string) *model.Passkey {
+	e.t.Helper()
+	ceremony, err := e.service.BeginRegistration(userID)
+	if err != nil {
+		e.t.Fatalf("BeginRegistration: %v", err)
+	}
+	passkey, err := e.service.FinishRegistration(userID, model.FinishPasskeyRegistrationInput{
+		SessionToken: ceremony.SessionToken,
+		Name:         name,
+		Credential:   authenticator.create(ceremony),
+	})
+	if err != nil {
+		e.t.Fatalf("FinishRegistration: %v", err)
+	}
+	return passkey
+}
+
+func (e *passkeyTestEnv) login(authenticator *softwareAuthenticator) (*model.User, error) {
+	e.t.Helper()
+	ceremony, err := e.service.BeginLogin()
+	if err != nil {
+		e.t.Fatalf("BeginLogin: %v", err)
+	}
+	return e.service.FinishLogin(model.PasskeyLoginInput{SessionToken: ceremony.SessionToken, Credential: authenticator.get(ceremony)})
+}
+
+func (e *passkeyTestEnv) stored(id int64) model.Passkey {
+	e.t.Helper()
+	passkey, ok := e.passkeys.Get(id)
+	if !ok {
+		e.t.Fatalf("passkey %d not stored", id)
+	}
+	return passkey
+}
+
+func expectError(t *testing.T, err error, want string) {
+	t.Helper()
+	if err == nil || err.Error() != want {
+		t.Fatalf("error = %v; want %q", err, want)
+	}
+}
+
+var (
+	alice = model.User{ID: 1, Username: "alice", Email: "alice@example.com", Status: model.UserStatusActive}
+	bob   = model.User{ID: 2, Username: "bob", Email: "bob@example.com", Status: model.UserStatusActive}
+)
+
+func TestPasskeyRegistrationAndLogin(t *testing.T) {
+	env := newPasskeyTestEnv(t, alice)
+	authenticator := newSoftwareAuthenticator(t)
+
+	passkey := env.register(alice.ID, authenticator, "Laptop")
+	if passkey.ID == 0 || passkey.Name != "Laptop" || len(passkey.Transports) != 1 || passkey.Transports[0] != "internal" {
+		t.Fatalf("unexpected passkey: %+v", passkey)
+	}
+	if string(authenticator.userHandle) != string(passkeyUserHandle(alice.ID)) {
+		t.Fatalf("registration options carried user handle %x", authenticator.userHandle)
+	}
+
+	user, err := env.login(authenticator)
+	if err != nil {
+		t.Fatalf("FinishLogin: %v", err)
+	}
+	if user.ID != alice.ID {
+		t.Fatalf("logged in as user %d; want %d", user.ID, alice.ID)
+	}
+	stored := env.stored(passkey.ID)
+	if stored.SignCount != authenticator.signCount || stored.LastUsedAt == nil || stored.CloneWarning {
+		t.Fatalf("stored passkey after login: sign count %d, last used %v, clone warning %v",
+			stored.SignCount, stored.LastUsedAt, stored.CloneWarning)
+	}
+}
+
+func TestPasskeyChallengeCannotBeReused(t *testing.T) {
+	env := newPasskeyTestEnv(t, alice)
+	authenticator := newSoftwareAuthenticator(t)
+
+	t.Run("registration", func(t *testing.T) {
+		ceremony, err := env.service.BeginRegistration(alice.ID)
+		if err != nil {
+			t.Fatalf("BeginRegistration: %v", err)
+		}
+		input := model.FinishPasskeyRegistrationInput{SessionToken: ceremony.SessionToken, Credential: authenticator.create(ceremony)}
+		if _, err := env.service.FinishRegistration(alice.ID, input); err != nil {
+			t.Fatalf("FinishRegistration: %v", err)
+		}
+		_, err = env.service.FinishRegistration(alice.ID, input)
+		expectError(t, err, "invalid passkey session")
+	})
+
+	t.Run("login replay", func(t *testing.T) {
+		ceremony, err := env.service.BeginLogin()
+		if err != nil {
+			t.Fatalf("BeginLogin: %v", err)
+		}
+		input := model.PasskeyLoginInput{SessionToken: ceremony.SessionToken, Credential: authenticator.get(ceremony)}
+		if _, err := env.service.FinishLogin(input); err != nil {
+			t.Fatalf("FinishLogin: %v", err)
+		}
+		_, err = env.service.FinishLogin(input)
+		expectError(t, err, "invalid passkey session")
+	})
+
+	t.Run("assertion for an old challenge", func(t *testing.T) {
+		old, err := env.service.BeginLogin()
+		if err != nil {
+			t.Fatalf("BeginLogin: %v", err)
+		}
+		staleAssertion := authenticator.get(old)
+		fresh, err := env.service.BeginLogin()
+		if err != nil {
+			t.Fatalf("BeginLogin: %v", err)
+		}
+		_, err = env.service.FinishLogin(model.PasskeyLoginInput{SessionToken: fresh.SessionToken, Credential: staleAssertion})
+		expectError(t, err, "passkey verification failed")
+	})
+}
+
+func TestPasskeySignCounterRegressionRejected(t *testing.T) {
+	env := newPasskeyTestEnv(t, alice)
+	authenticator := newSoftwareAuthenticator(t)
+	passkey := env.register(alice.ID, authenticator, "")
+
+	for i := 0; i < 3; i++ {
+		if _, err := env.login(authenticator); err != nil {
+			t.Fatalf("login %d: %v", i+1, err)
+		}
+	}
+	counter := env.stored(passkey.ID).SignCount
+
+	// Salinan authenticator dengan counter yang tertinggal
+	ceremony, err := env.service.BeginLogin()
+	if err != nil {
+		t.Fatalf("BeginLogin: %v", err)
+	}
+	_, err = env.service.FinishLogin(model.PasskeyLoginInput{
+		SessionToken: ceremony.SessionToken,
+		Credential:   authenticator.getWithCounter(ceremony, counter-1),
+	})
+	expectError(t, err, "passkey verification failed")
+
+	stored := env.stored(passkey.ID)
+	if stored.SignCount != counter || !stored.CloneWarning {
+		t.Fatalf("after regression: sign count %d (want %d), clone warning %v", stored.SignCount, counter, stored.CloneWarning)
+	}
+
+	// Authenticator asli dengan counter yang terus naik tetap bisa login
+	if _, err := env.login(authenticator); err != nil {
+		t.Fatalf("login with original authenticator after regression: %v", err)
+	}
+}
+
+func TestPasskeyMultipleCredentialsPerUser(t *testing.T) {
+	env := newPasskeyTestEnv(t, alice, bob)
+	laptop, phone := newSoftwareAuthenticator(t), newSoftwareAuthenticator(t)
+
+	laptopKey := env.register(alice.ID, laptop, "Laptop")
+
+	// Opsi pendaftaran berikutnya mengecualikan passkey yang sudah terdaftar
+	ceremony, err := env.service.BeginRegistration(alice.ID)
+	if err != nil {
+		t.Fatalf("BeginRegistration: %v", err)
+	}
+	excluded := phone.readOptions(ceremony).PublicKey.ExcludeCredentials
+	if len(excluded) != 1 || excluded[0].ID != b64url.EncodeToString(laptop.credentialID) {
+		t.Fatalf("excludeCredentials = %+v; want the laptop credential", excluded)
+	}
+	phoneKey, err := env.service.FinishRegistration(alice.ID, model.FinishPasskeyRegistrationInput{
+		SessionToken: ceremony.SessionToken,
+		Name:         "Phone",
+		Credential:   phone.create(ceremony),
+	})
+	if err != nil {
+		t.Fatalf("FinishRegistration: %v", err)
+	}
+
+	passkeys, err := env.service.List(alice.ID)
+	if err != nil || len(passkeys) != 2 {
+		t.Fatalf("List = %d passkeys, err %v; want 2", len(passkeys), err)
+	}
+	for _, authenticator := range []*softwareAuthenticator{laptop, phone} {
+		if user, err := env.login(authenticator); err != nil || user.ID != alice.ID {
+			t.Fatalf("login: user %v, err %v", user, err)
+		}
+	}
+
+	t.Run("rename", func(t *testing.T) {
+		if err := env.service.Rename(alice.ID, phoneKey.ID, "Work phone"); err != nil {
+			t.Fatalf("Rename: %v", err)
+		}
+		if name := env.stored(phoneKey.ID).Name; name != "Work phone" {
+			t.Fatalf("name = %q after rename", name)
+		}
+		expectError(t, env.service.Rename(bob.ID, phoneKey.ID, "Stolen"), "passkey not found")
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		expectError(t, env.service.Delete(bob.ID, laptopKey.ID), "passkey not found")
+		if err := env.service.Delete(alice.ID, laptopKey.ID); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		passkeys, err := env.service.List(alice.ID)
+		if err != nil || len(passkeys) != 1 || passkeys[0].ID != phoneKey.ID {
+			t.Fatalf("List after delete = %+v, err %v", passkeys, err)
+		}
+		_, err = env.login(laptop)
+		expectError(t, err, "passkey verification failed")
+		if _, err := env.login(phone); err != nil {
+			t.Fatalf("login with remaining passkey: %v", err)
+		}
+	})
+
+	t.Run("same authenticator twice", func(t *testing.T) {
+		ceremony, err := env.service.BeginRegistration(bob.ID)
+		if err != nil {
+			t.Fatalf("BeginRegistration: %v", err)
+		}
+		_, err = env.service.FinishRegistration(bob.ID, model.FinishPasskeyRegistrationInput{
+			SessionToken: ceremony.SessionToken,
+			Credential:   phone.create(ceremony),
+		})
+		expectError(t, err, "passkey already registered")
+	})
+}
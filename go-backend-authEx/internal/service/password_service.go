@@ -0,0 +1,284 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go-auth-example/internal/auth"
+	"go-auth-example/internal/logger"
+	"go-auth-example/internal/mail"
+	"go-auth-example/internal/model"
+	"go-auth-example/internal/repository"
+
+	"github.com/sirupsen/logrus"
+)
+
+// PasswordResetTTL adalah masa berlaku link reset password
+const PasswordResetTTL = 30 * time.Minute
+
+// PasswordService mengelola pemulihan dan penggantian password
+type PasswordService interface {
+	// ForgotPassword mengirim link reset ke email user. Tidak mengembalikan error untuk email yang tidak terdaftar
+	// agar tidak membocorkan daftar user.
+	ForgotPassword(email string) error
+	// ResetPassword mengganti password dengan token dari email reset lalu mencabut semua token user
+	ResetPassword(input model.ResetPasswordInput) error
+	// ChangePassword mengganti password setelah memeriksa password saat ini,
+	// lalu mencabut semua session dan token user kecuali session currentSessionID
+	ChangePassword(userID int, currentSessionID string, input model.ChangePasswordInput) error
+	// ForceReset (operasi admin) mengosongkan password user, mencabut semua session dan token-nya,
+	// lalu mengirim link reset supaya user memilih password baru
+	ForceReset(userID int) error
+}
+
+// passwordService struct mengimplementasikan PasswordService
+type passwordService struct {
+	userRepo       repository.UserRepository
+	emailTokenRepo repository.EmailTokenRepository
+	credentials    credentialRevoker
+	mailer         mail.Mailer
+	resetURL       string // Halaman frontend yang menerima ?token=... lalu memanggil POST /auth/password/reset
+}
+
+// NewPasswordService adalah constructor untuk passwordService
+func NewPasswordService(userRepo repository.UserRepository, emailTokenRepo repository.EmailTokenRepository,
+	refreshTokenRepo repository.RefreshTokenRepository, patRepo repository.PersonalAccessTokenRepository,
+	revocationService TokenRevocationService, sessionService SessionService, mailer mail.Mailer, resetURL string) PasswordService {
+	return &passwordService{
+		userRepo:       userRepo,
+		emailTokenRepo: emailTokenRepo,
+		credentials:    credentialRevoker{sessionService: sessionService, revocationService: revocationService, refreshTokenRepo: refreshTokenRepo, patRepo: patRepo},
+		mailer:         mailer,
+		resetURL:       resetURL,
+	}
+}
+
+// Implementasi ForgotPassword
+func (s *passwordService) ForgotPassword(email string) error {
+	email = auth.NormalizeIdentity(email)
+	logFields := logrus.Fields{
+		"service": "PasswordService",
+		"method":  "ForgotPassword",
+	}
+
+	user, err := s.userRepo.GetByEmail(email)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error loading user for password reset: %v", err)
+		return errors.New("failed to process password reset")
+	}
+	if user == nil {
+		logger.Log.WithFields(logFields).Info("Password reset requested for unknown email.")
+		return nil
+	}
+	logFields["user_id"] = user.ID
+
+	latest, err := s.emailTokenRepo.LatestCreatedAt(user.ID, model.EmailTokenPurposeResetPassword)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error checking previous reset email: %v", err)
+		return errors.New("failed to process password reset")
+	}
+	if latest != nil && time.Since(*latest) < emailResendCooldown {
+		logger.Log.WithFields(logFields).Info("Password reset email throttled.")
+		return nil
+	}
+
+	rawToken, err := issueEmailToken(s.emailTokenRepo, user, user.Email, model.EmailTokenPurposeResetPassword, PasswordResetTTL)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error issuing reset token: %v", err)
+		return errors.New("failed to process password reset")
+	}
+	link, err := linkWithToken(s.resetURL, rawToken)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error building reset link: %v", err)
+		return errors.New("failed to process password reset")
+	}
+
+	msg := mail.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nSomeone asked to reset the password of your account. Open the link below to choose a new password:\n\n%s\n\n"+
+			"The link expires in %d minutes and can only be used once. If you did not ask for this, you can ignore this email.\n",
+			user.Username, link, int(PasswordResetTTL.Minutes())),
+	}
+	// Dikirim di background: waktu respons dan kegagalan pengiriman tidak boleh membedakan email terdaftar
+	go func() {
+		if err := s.mailer.Send(msg); err != nil {
+			logger.Log.WithFields(logFields).Errorf("Error sending password reset email: %v", err)
+			return
+		}
+		logger.Log.WithFields(logFields).Info("Password reset email sent.")
+	}()
+	return nil
+}
+
+// Implementasi ResetPassword
+func (s *passwordService) ResetPassword(input model.ResetPasswordInput) error {
+	logFields := logrus.Fields{
+		"service": "PasswordService",
+		"method":  "ResetPassword",
+	}
+
+	emailToken, err := s.emailTokenRepo.Consume(auth.HashToken(input.Token), model.EmailTokenPurposeResetPassword)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error consuming reset token: %v", err)
+		return errors.New("failed to reset password")
+	}
+	if emailToken == nil {
+		return errors.New("invalid or expired reset token")
+	}
+	logFields["user_id"] = emailToken.UserID
+
+	user, err := s.userRepo.GetByID(emailToken.UserID)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error loading user for password reset: %v", err)
+		return errors.New("failed to reset password")
+	}
+	if user == nil || user.Email != emailToken.Email {
+		return errors.New("invalid or expired reset token")
+	}
+
+	if err := s.setPassword(user, input.Password, "", logFields); err != nil {
+		return errors.New("failed to reset password")
+	}
+
+	// Pemilik email yang terbukti boleh langsung login lagi walaupun akunnya sedang terkunci
+	if err := s.userRepo.ResetFailedLogins(user.ID); err != nil {
+		logger.Log.WithFields(logFields).Warnf("Could not unlock account after reset: %v", err)
+	}
+
+	// Link reset yang sampai ke inbox membuktikan kepemilikan email
+	if !user.IsEmailVerified() {
+		if err := s.userRepo.MarkEmailVerified(user.ID); err != nil {
+			logger.Log.WithFields(logFields).Warnf("Could not mark email as verified after reset: %v", err)
+		}
+	}
+
+	logger.Log.WithFields(logFields).Info("Password reset successfully.")
+	return nil
+}
+
+// Implementasi ChangePassword
+func (s *passwordService) ChangePassword(userID int, currentSessionID string, input model.ChangePasswordInput) error {
+	logFields := logrus.Fields{
+		"service": "PasswordService",
+		"method":  "ChangePassword",
+		"user_id": userID,
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error loading user for password change: %v", err)
+		return errors.New("failed to change password")
+	}
+	if user == nil {
+		return errors.New("user not found")
+	}
+
+	if !auth.CheckPasswordHash(input.CurrentPassword, user.PasswordHash) {
+		logger.Log.WithFields(logFields).Warn("Password change with wrong current password.")
+		return errors.New("invalid current password")
+	}
+	if auth.CheckPasswordHash(input.NewPassword, user.PasswordHash) {
+		return errors.New("new password must differ from current password")
+	}
+
+	if err := s.setPassword(user, input.NewPassword, currentSessionID, logFields); err != nil {
+		return errors.New("failed to change password")
+	}
+
+	msg := mail.Message{
+		To:      user.Email,
+		Subject: "Your password was changed",
+		Body: fmt.Sprintf("Hi %s,\n\nThe password of your account was just changed and your other devices were signed out.\n\n"+
+			"If you did not do this, reset your password immediately.\n", user.Username),
+	}
+	go func() {
+		if err := s.mailer.Send(msg); err != nil {
+			logger.Log.WithFields(logFields).Warnf("Could not send password change notice: %v", err)
+		}
+	}()
+
+	logger.Log.WithFields(logFields).Info("Password changed successfully.")
+	return nil
+}
+
+// Implementasi ForceReset
+func (s *passwordService) ForceReset(userID int) error {
+	logFields := logrus.Fields{
+		"service": "PasswordService",
+		"method":  "ForceReset",
+		"user_id": userID,
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error loading user for forced reset: %v", err)
+		return errors.New("failed to reset password")
+	}
+	if user == nil {
+		return errors.New("user not found")
+	}
+
+	// Password kosong tidak pernah cocok, jadi password lama langsung berhenti berlaku
+	if err := s.userRepo.UpdatePassword(user.ID, ""); err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error clearing password: %v", err)
+		return errors.New("failed to reset password")
+	}
+	if err := s.credentials.revokeAll(user.ID, ""); err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error revoking credentials: %v", err)
+		return errors.New("failed to reset password")
+	}
+
+	rawToken, err := issueEmailToken(s.emailTokenRepo, user, user.Email, model.EmailTokenPurposeResetPassword, PasswordResetTTL)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error issuing reset token: %v", err)
+		return errors.New("failed to reset password")
+	}
+	link, err := linkWithToken(s.resetURL, rawToken)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error building reset link: %v", err)
+		return errors.New("failed to reset password")
+	}
+
+	msg := mail.Message{
+		To:      user.Email,
+		Subject: "Please choose a new password",
+		Body: fmt.Sprintf("Hi %s,\n\nAn administrator has reset the password of your account and signed you out everywhere. "+
+			"Open the link below to choose a new password:\n\n%s\n\n"+
+			"The link expires in %d minutes. If it expires, use \"Forgot password\" on the login page to get a new one.\n",
+			user.Username, link, int(PasswordResetTTL.Minutes())),
+	}
+	// Password sudah dikosongkan; jika email gagal terkirim user masih bisa memakai "Forgot password"
+	if err := s.mailer.Send(msg); err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error sending forced password reset email: %v", err)
+		return errors.New("failed to send password reset email")
+	}
+
+	logger.Log.WithFields(logFields).Info("Password cleared and reset link sent.")
+	return nil
+}
+
+// setPassword menyimpan hash password baru lalu mencabut semua kredensial user kecuali session keepSessionID
+func (s *passwordService) setPassword(user *model.User, password string, keepSessionID string, logFields logrus.Fields) error {
+	passwordHash, err := auth.HashPassword(password)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error hashing new password: %v", err)
+		return err
+	}
+	if err := s.userRepo.UpdatePassword(user.ID, passwordHash); err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error storing new password: %v", err)
+		return err
+	}
+
+	// Link reset lain yang masih beredar tidak boleh dipakai lagi
+	if err := s.emailTokenRepo.InvalidateForUser(user.ID, model.EmailTokenPurposeResetPassword); err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error invalidating reset tokens: %v", err)
+		return err
+	}
+	if err := s.credentials.revokeAll(user.ID, keepSessionID); err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error revoking credentials: %v", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"go-auth-example/internal/auth"
+	"go-auth-example/internal/logger"
+	"go-auth-example/internal/model"
+	"go-auth-example/internal/repository"
+
+	"github.com/sirupsen/logrus"
+)
+
+// PersonalAccessTokenScopes adalah scope yang boleh diberikan ke personal access token
+var PersonalAccessTokenScopes = []string{ScopeProfile, ScopeEmail}
+
+// PersonalAccessTokenService mengelola personal access token dan memverifikasinya untuk AuthMiddleware
+type PersonalAccessTokenService interface {
+	// Create membuat token baru; token asli hanya dikembalikan sekali
+	Create(userID int, input model.CreatePersonalAccessTokenInput) (*model.PersonalAccessToken, string, error)
+	List(userID int) ([]model.PersonalAccessToken, error)
+	Revoke(userID int, tokenID int64) error
+	// Authenticate memverifikasi token dari header Authorization dan mencatat pemakaiannya
+	Authenticate(rawToken, clientIP string) (*auth.Principal, error)
+}
+
+// personalAccessTokenService struct mengimplementasikan PersonalAccessTokenService
+type personalAccessTokenService struct {
+	tokenRepo repository.PersonalAccessTokenRepository
+	userRepo  repository.UserRepository
+}
+
+// NewPersonalAccessTokenService adalah constructor untuk personalAccessTokenService
+func NewPersonalAccessTokenService(tokenRepo repository.PersonalAccessTokenRepository, userRepo repository.UserRepository) PersonalAccessTokenService {
+	return &personalAccessTokenService{tokenRepo: tokenRepo, userRepo: userRepo}
+}
+
+// Implementasi Create
+func (s *personalAccessTokenService) Create(userID int, input model.CreatePersonalAccessTokenInput) (*model.PersonalAccessToken, string, error) {
+	logFields := logrus.Fields{
+		"service": "PersonalAccessTokenService",
+		"method":  "Create",
+		"user_id": userID,
+	}
+
+	token := &model.PersonalAccessToken{UserID: userID, Name: input.Name, ExpiresAt: input.ExpiresAt}
+	for _, scope := range input.Scopes {
+		if !hasScope(PersonalAccessTokenScopes, scope) {
+			return nil, "", fmt.Errorf("unsupported scope %q", scope)
+		}
+		token.Scopes = appendUnique(token.Scopes, scope)
+	}
+	if token.ExpiresAt != nil && !token.ExpiresAt.After(time.Now()) {
+		return nil, "", errors.New("expiry must be in the future")
+	}
+
+	rawToken, prefix, tokenHash, err := auth.GeneratePersonalAccessToken()
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error generating personal access token: %v", err)
+		return nil, "", errors.New("failed to create token")
+	}
+	token.Prefix = prefix
+	token.TokenHash = tokenHash
+
+	if err := s.tokenRepo.Create(token); err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error creating personal access token in repository: %v", err)
+		return nil, "", errors.New("failed to create token")
+	}
+
+	logFields["token_id"] = token.ID
+	logger.Log.WithFields(logFields).Info("Personal access token created.")
+	return token, rawToken, nil
+}
+
+// Implementasi List
+func (s *personalAccessTokenService) List(userID int) ([]model.PersonalAccessToken, error) {
+	tokens, err := s.tokenRepo.ListByUser(userID)
+	if err != nil {
+		logger.Log.WithFields(logrus.Fields{
+			"service": "PersonalAccessTokenService",
+			"method":  "List",
+			"user_id": userID,
+		}).Errorf("Error listing personal access tokens: %v", err)
+		return nil, errors.New("failed to list tokens")
+	}
+	return tokens, nil
+}
+
+// Implementasi Revoke
+func (s *personalAccessTokenService) Revoke(userID int, tokenID int64) error {
+	logFields := logrus.Fields{
+		"service":  "PersonalAccessTokenService",
+		"method":   "Revoke",
+		"user_id":  userID,
+		"token_id": tokenID,
+	}
+
+	revoked, err := s.tokenRepo.Revoke(tokenID, userID)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error revoking personal access token: %v", err)
+		return errors.New("failed to revoke token")
+	}
+	if !revoked {
+		return errors.New("token not found")
+	}
+
+	logger.Log.WithFields(logFields).Info("Personal access token revoked.")
+	return nil
+}
+
+// Implementasi Authenticate
+func (s *personalAccessTokenService) Authenticate(rawToken, clientIP string) (*auth.Principal, error) {
+	logFields := logrus.Fields{
+		"service": "PersonalAccessTokenService",
+		"method":  "Authenticate",
+	}
+
+	token, err := s.tokenRepo.GetByHash(auth.HashToken(rawToken))
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Database error while looking up personal access token: %v", err)
+		return nil, errors.New("failed to verify personal access token")
+	}
+	if token == nil {
+		return nil, errors.New("invalid personal access token")
+	}
+	logFields["token_id"] = token.ID
+	logFields["user_id"] = token.UserID
+
+	if token.IsExpired(time.Now()) {
+		return nil, errors.New("personal access token expired")
+	}
+
+	user, err := s.userRepo.GetByID(token.UserID)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Database error while loading token owner: %v", err)
+		return nil, errors.New("failed to verify personal access token")
+	}
+	if user == nil || !user.IsActive() {
+		return nil, errors.New("invalid personal access token")
+	}
+
+	// Gagal mencatat pemakaian tidak boleh menggagalkan request
+	if err := s.tokenRepo.Touch(token.ID, clientIP); err != nil {
+		logger.Log.WithFields(logFields).Warnf("Could not record personal access token usage: %v", err)
+	}
+
+	principal := &auth.Principal{
+		Subject:               strconv.Itoa(user.ID),
+		UserID:                user.ID,
+		Username:              user.Username,
+		Email:                 user.Email,
+		Scopes:                token.Scopes,
+		IssuedAt:              token.CreatedAt,
+		AuthTime:              token.CreatedAt,
+		AuthMethod:            auth.AuthMethodPersonalAccessToken,
+		PersonalAccessTokenID: token.ID,
+	}
+	if token.ExpiresAt != nil {
+		principal.ExpiresAt = *token.ExpiresAt
+	}
+	return principal, nil
+}
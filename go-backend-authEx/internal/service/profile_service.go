@@ -0,0 +1,197 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go-auth-example/internal/auth"
+	"go-auth-example/internal/logger"
+	"go-auth-example/internal/mail"
+	"go-auth-example/internal/model"
+	"go-auth-example/internal/repository"
+
+	"github.com/sirupsen/logrus"
+)
+
+// EmailChangeTTL adalah masa berlaku link konfirmasi alamat email baru
+const EmailChangeTTL = 24 * time.Hour
+
+// ProfileService mengelola perubahan profil user
+type ProfileService interface {
+	// UpdateProfile mengganti username secara langsung. Perubahan email hanya mengirim link konfirmasi
+	// ke alamat baru; alamat tersebut dikembalikan sebagai pendingEmail.
+	UpdateProfile(userID int, input model.UpdateProfileInput) (user *model.User, pendingEmail string, err error)
+	// ConfirmEmailChange menerapkan email baru dari link konfirmasi dan memberi tahu alamat lama
+	ConfirmEmailChange(token string) (*model.User, error)
+}
+
+// profileService struct mengimplementasikan ProfileService
+type profileService struct {
+	userRepo       repository.UserRepository
+	emailTokenRepo repository.EmailTokenRepository
+	mailer         mail.Mailer
+	confirmURL     string // Halaman frontend yang menerima ?token=... lalu memanggil POST /auth/email/confirm
+}
+
+// NewProfileService adalah constructor untuk profileService
+func NewProfileService(userRepo repository.UserRepository, emailTokenRepo repository.EmailTokenRepository,
+	mailer mail.Mailer, confirmURL string) ProfileService {
+	return &profileService{
+		userRepo:       userRepo,
+		emailTokenRepo: emailTokenRepo,
+		mailer:         mailer,
+		confirmURL:     confirmURL,
+	}
+}
+
+// Implementasi UpdateProfile
+func (s *profileService) UpdateProfile(userID int, input model.UpdateProfileInput) (*model.User, string, error) {
+	logFields := logrus.Fields{
+		"service": "ProfileService",
+		"method":  "UpdateProfile",
+		"user_id": userID,
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error loading user for profile update: %v", err)
+		return nil, "", errors.New("failed to update profile")
+	}
+	if user == nil {
+		return nil, "", errors.New("user associated with token not found")
+	}
+
+	if input.Username != nil {
+		username := auth.NormalizeIdentity(*input.Username)
+		if username != user.Username {
+			if err := s.userRepo.UpdateUsername(user.ID, username); err != nil {
+				if err.Error() == "username already exists" {
+					return nil, "", err
+				}
+				logger.Log.WithFields(logFields).Errorf("Error updating username: %v", err)
+				return nil, "", errors.New("failed to update profile")
+			}
+			logger.Log.WithFields(logFields).Info("Username changed.")
+			user.Username = username
+		}
+	}
+
+	var pendingEmail string
+	if input.Email != nil {
+		email := auth.NormalizeIdentity(*input.Email)
+		switch {
+		case email == user.Email:
+		case auth.CanonicalIdentity(email) == auth.CanonicalIdentity(user.Email) && user.IsEmailVerified():
+			// Hanya huruf besar/kecil yang berubah: alamat terverifikasinya tetap sama, jadi tidak perlu konfirmasi ulang
+			if err := s.userRepo.UpdateEmail(user.ID, email); err != nil {
+				logger.Log.WithFields(logFields).Errorf("Error updating email casing: %v", err)
+				return nil, "", errors.New("failed to update profile")
+			}
+			user.Email = email
+		default:
+			if err := s.requestEmailChange(user, email, logFields); err != nil {
+				return nil, "", err
+			}
+			pendingEmail = email
+		}
+	}
+
+	user.PasswordHash = ""
+	return user, pendingEmail, nil
+}
+
+// requestEmailChange mengirim link konfirmasi ke alamat baru; email user belum berubah sampai link dipakai
+func (s *profileService) requestEmailChange(user *model.User, newEmail string, logFields logrus.Fields) error {
+	existing, err := s.userRepo.GetByEmail(newEmail)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error checking email existence: %v", err)
+		return errors.New("failed to update profile")
+	}
+	// existing bisa user itu sendiri jika hanya huruf besar/kecil yang berubah
+	if existing != nil && existing.ID != user.ID {
+		return errors.New("email already registered")
+	}
+
+	rawToken, err := issueEmailToken(s.emailTokenRepo, user, newEmail, model.EmailTokenPurposeChangeEmail, EmailChangeTTL)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error issuing email change token: %v", err)
+		return errors.New("failed to update profile")
+	}
+	link, err := linkWithToken(s.confirmURL, rawToken)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error building email change link: %v", err)
+		return errors.New("failed to update profile")
+	}
+
+	msg := mail.Message{
+		To:      newEmail,
+		Subject: "Confirm your new email address",
+		Body: fmt.Sprintf("Hi %s,\n\nPlease confirm that you want to use this address for your account by opening the link below:\n\n%s\n\n"+
+			"The link expires in %d hours. Until then your account keeps using its current address.\n",
+			user.Username, link, int(EmailChangeTTL.Hours())),
+	}
+	if err := s.mailer.Send(msg); err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error sending email change confirmation: %v", err)
+		return errors.New("failed to update profile")
+	}
+
+	logger.Log.WithFields(logFields).Info("Email change requested.")
+	return nil
+}
+
+// Implementasi ConfirmEmailChange
+func (s *profileService) ConfirmEmailChange(token string) (*model.User, error) {
+	logFields := logrus.Fields{
+		"service": "ProfileService",
+		"method":  "ConfirmEmailChange",
+	}
+
+	emailToken, err := s.emailTokenRepo.Consume(auth.HashToken(token), model.EmailTokenPurposeChangeEmail)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error consuming email change token: %v", err)
+		return nil, errors.New("failed to change email")
+	}
+	if emailToken == nil {
+		return nil, errors.New("invalid or expired email change token")
+	}
+	logFields["user_id"] = emailToken.UserID
+
+	user, err := s.userRepo.GetByID(emailToken.UserID)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error loading user for email change: %v", err)
+		return nil, errors.New("failed to change email")
+	}
+	if user == nil {
+		return nil, errors.New("invalid or expired email change token")
+	}
+
+	oldEmail := user.Email
+	if err := s.userRepo.UpdateEmail(user.ID, emailToken.Email); err != nil {
+		if err.Error() == "email already exists" {
+			return nil, errors.New("email already registered")
+		}
+		logger.Log.WithFields(logFields).Errorf("Error updating email: %v", err)
+		return nil, errors.New("failed to change email")
+	}
+	now := time.Now()
+	user.Email = emailToken.Email
+	user.EmailVerifiedAt = &now
+
+	// Pemberitahuan ke alamat lama agar pemilik akun tahu jika perubahan ini bukan ulahnya
+	msg := mail.Message{
+		To:      oldEmail,
+		Subject: "Your email address was changed",
+		Body: fmt.Sprintf("Hi %s,\n\nThe email address of your account was changed to %s.\n\n"+
+			"If you did not make this change, reset your password and contact support.\n", user.Username, user.Email),
+	}
+	go func() {
+		if err := s.mailer.Send(msg); err != nil {
+			logger.Log.WithFields(logFields).Warnf("Could not notify previous email address: %v", err)
+		}
+	}()
+
+	logger.Log.WithFields(logFields).Info("Email changed.")
+	user.PasswordHash = ""
+	return user, nil
+}
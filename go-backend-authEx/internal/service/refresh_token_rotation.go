@@ -0,0 +1,97 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"go-auth-example/internal/auth"
+	"go-auth-example/internal/logger"
+	"go-auth-example/internal/model"
+	"go-auth-example/internal/repository"
+
+	"github.com/sirupsen/logrus"
+)
+
+// refreshTokenRotator berisi logika rotasi refresh token yang dipakai bersama oleh AuthService dan OAuthService.
+// Setiap refresh token hanya boleh dipakai sekali; pemakaian ulang mencabut seluruh family-nya.
+type refreshTokenRotator struct {
+	repo repository.RefreshTokenRepository
+}
+
+// consume memvalidasi refresh token lalu mengklaimnya secara atomik.
+// clientID harus sama dengan client pemilik token ("" untuk token first-party dari /login).
+// Error yang dikembalikan: "invalid refresh token", "refresh token reuse detected",
+// atau "an error occurred during token refresh" untuk error internal.
+func (r refreshTokenRotator) consume(refreshToken, clientID string, logFields logrus.Fields) (*model.RefreshToken, error) {
+	stored, err := r.repo.GetByHash(auth.HashToken(refreshToken))
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Database error while looking up refresh token: %v", err)
+		return nil, errors.New("an error occurred during token refresh")
+	}
+	if stored == nil {
+		logger.Log.WithFields(logFields).Warn("Refresh attempt with unknown token.")
+		return nil, errors.New("invalid refresh token")
+	}
+	logFields["user_id"] = stored.UserID
+	logFields["family_id"] = stored.FamilyID
+
+	// Token milik client lain ditolak tanpa diklaim, agar tidak bisa dipakai untuk membakar token orang lain
+	if stored.ClientID != clientID {
+		logger.Log.WithFields(logFields).Warn("Refresh token presented by a different client.")
+		return nil, errors.New("invalid refresh token")
+	}
+	if stored.RevokedAt != nil {
+		logger.Log.WithFields(logFields).Warn("Refresh attempt with revoked token.")
+		return nil, errors.New("invalid refresh token")
+	}
+	if stored.UsedAt != nil {
+		return nil, r.handleReuse(stored, logFields)
+	}
+	if time.Now().After(stored.ExpiresAt) {
+		logger.Log.WithFields(logFields).Info("Refresh attempt with expired token.")
+		return nil, errors.New("invalid refresh token")
+	}
+
+	// Klaim token secara atomik, sehingga dua request paralel dengan token yang sama tidak bisa sama-sama berhasil
+	claimed, err := r.repo.MarkUsed(stored.ID)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error marking refresh token as used: %v", err)
+		return nil, errors.New("an error occurred during token refresh")
+	}
+	if !claimed {
+		return nil, r.handleReuse(stored, logFields)
+	}
+	return stored, nil
+}
+
+// handleReuse mencabut seluruh family ketika refresh token lama dipakai ulang
+func (r refreshTokenRotator) handleReuse(stored *model.RefreshToken, logFields logrus.Fields) error {
+	logger.Log.WithFields(logFields).Warn("Refresh token reuse detected, revoking token family.")
+	if err := r.repo.RevokeFamily(stored.FamilyID); err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error revoking refresh token family: %v", err)
+	}
+	return errors.New("refresh token reuse detected")
+}
+
+// issue membuat refresh token baru berdasarkan template (user, family, client, scope) dan mengembalikan token aslinya
+func (r refreshTokenRotator) issue(template model.RefreshToken) (string, error) {
+	refreshToken, refreshTokenHash, err := auth.GenerateRefreshToken()
+	if err != nil {
+		return "", err
+	}
+
+	template.TokenHash = refreshTokenHash
+	template.ExpiresAt = time.Now().Add(RefreshTokenTTL)
+	if template.AuthTime.IsZero() {
+		template.AuthTime = time.Now()
+	}
+	if err := r.repo.Create(&template); err != nil {
+		return "", err
+	}
+	return refreshToken, nil
+}
+
+// newTokenFamilyID membuat ID family refresh token baru
+func newTokenFamilyID() (string, error) {
+	return auth.GenerateOpaqueToken(16)
+}
@@ -0,0 +1,224 @@
+package service
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"go-auth-example/internal/logger"
+	"go-auth-example/internal/model"
+	"go-auth-example/internal/repository"
+
+	"github.com/sirupsen/logrus"
+)
+
+// rolePermissionCacheTTL adalah lama pemetaan role -> permission di-cache sebelum dibaca ulang dari database
+const rolePermissionCacheTTL = time.Minute
+
+// RoleService mengelola role user dan permission yang dimiliki tiap role.
+// Role ikut ditanam di access token first-party, tetapi AuthMiddleware membaca ulang role user lewat UserRoles
+// sehingga perubahan role langsung berlaku.
+type RoleService interface {
+	// List mengembalikan semua role beserta permission-nya
+	List() ([]model.Role, error)
+	// UserRoles mengembalikan nama role milik user
+	UserRoles(userID int) ([]string, error)
+	// Assign memberikan role ke user
+	Assign(userID int, role string) error
+	// Remove mencabut role dari user; role admin terakhir tidak bisa dicabut
+	Remove(userID int, role string) error
+	// Permissions mengembalikan gabungan permission dari daftar role (dari cache)
+	Permissions(roles []string) ([]string, error)
+}
+
+// roleService struct mengimplementasikan RoleService
+type roleService struct {
+	roleRepo repository.RoleRepository
+	userRepo repository.UserRepository
+
+	mu          sync.Mutex
+	permissions map[string][]string // nama role -> permission
+	loadedAt    time.Time
+}
+
+// NewRoleService adalah constructor untuk roleService
+func NewRoleService(roleRepo repository.RoleRepository, userRepo repository.UserRepository) RoleService {
+	return &roleService{
+		roleRepo: roleRepo,
+		userRepo: userRepo,
+	}
+}
+
+// Implementasi List
+func (s *roleService) List() ([]model.Role, error) {
+	roles, err := s.roleRepo.List()
+	if err != nil {
+		logger.Log.WithFields(logrus.Fields{
+			"service": "RoleService",
+			"method":  "List",
+		}).Errorf("Error listing roles: %v", err)
+		return nil, errors.New("failed to list roles")
+	}
+	return roles, nil
+}
+
+// Implementasi UserRoles
+func (s *roleService) UserRoles(userID int) ([]string, error) {
+	roles, err := s.roleRepo.ListUserRoles(userID)
+	if err != nil {
+		logger.Log.WithFields(logrus.Fields{
+			"service": "RoleService",
+			"method":  "UserRoles",
+			"user_id": userID,
+		}).Errorf("Error listing user roles: %v", err)
+		return nil, errors.New("failed to load roles")
+	}
+	return roles, nil
+}
+
+// Implementasi Assign
+func (s *roleService) Assign(userID int, roleName string) error {
+	logFields := logrus.Fields{
+		"service": "RoleService",
+		"method":  "Assign",
+		"user_id": userID,
+		"role":    roleName,
+	}
+
+	role, err := s.lookup(userID, roleName, logFields)
+	if err != nil {
+		return err
+	}
+	if err := s.roleRepo.Assign(userID, role.ID); err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error assigning role: %v", err)
+		return errors.New("failed to assign role")
+	}
+	logger.Log.WithFields(logFields).Info("Role assigned.")
+	return nil
+}
+
+// Implementasi Remove
+func (s *roleService) Remove(userID int, roleName string) error {
+	logFields := logrus.Fields{
+		"service": "RoleService",
+		"method":  "Remove",
+		"user_id": userID,
+		"role":    roleName,
+	}
+
+	role, err := s.lookup(userID, roleName, logFields)
+	if err != nil {
+		return err
+	}
+	// Tanpa admin tersisa, tidak ada yang bisa memberikan role lagi selain lewat database
+	if role.Name == model.RoleAdmin {
+		count, err := s.roleRepo.CountUsers(role.ID)
+		if err != nil {
+			logger.Log.WithFields(logFields).Errorf("Error counting admins: %v", err)
+			return errors.New("failed to remove role")
+		}
+		if count <= 1 {
+			roles, err := s.roleRepo.ListUserRoles(userID)
+			if err != nil {
+				logger.Log.WithFields(logFields).Errorf("Error loading user roles: %v", err)
+				return errors.New("failed to remove role")
+			}
+			if containsString(roles, model.RoleAdmin) {
+				return errors.New("cannot remove last admin")
+			}
+		}
+	}
+
+	removed, err := s.roleRepo.Remove(userID, role.ID)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error removing role: %v", err)
+		return errors.New("failed to remove role")
+	}
+	if !removed {
+		return errors.New("role not assigned")
+	}
+	logger.Log.WithFields(logFields).Info("Role removed.")
+	return nil
+}
+
+// lookup memastikan user dan role ada
+func (s *roleService) lookup(userID int, roleName string, logFields logrus.Fields) (*model.Role, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error loading user: %v", err)
+		return nil, errors.New("failed to load user")
+	}
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+	role, err := s.roleRepo.GetByName(roleName)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error loading role: %v", err)
+		return nil, errors.New("failed to load role")
+	}
+	if role == nil {
+		return nil, errors.New("role not found")
+	}
+	return role, nil
+}
+
+// Implementasi Permissions
+func (s *roleService) Permissions(roles []string) ([]string, error) {
+	if len(roles) == 0 {
+		return nil, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.permissions == nil || time.Since(s.loadedAt) > rolePermissionCacheTTL {
+		if err := s.reloadLocked(); err != nil {
+			// Cache lama tetap dipakai jika database sedang bermasalah
+			if s.permissions == nil {
+				return nil, err
+			}
+			logger.Log.WithFields(logrus.Fields{
+				"service": "RoleService",
+				"method":  "Permissions",
+			}).Warnf("Using stale role permissions: %v", err)
+		}
+	}
+
+	seen := make(map[string]bool)
+	var permissions []string
+	for _, role := range roles {
+		for _, permission := range s.permissions[role] {
+			if !seen[permission] {
+				seen[permission] = true
+				permissions = append(permissions, permission)
+			}
+		}
+	}
+	sort.Strings(permissions)
+	return permissions, nil
+}
+
+// reloadLocked membaca ulang pemetaan role -> permission; s.mu harus sudah dikunci
+func (s *roleService) reloadLocked() error {
+	roles, err := s.roleRepo.List()
+	if err != nil {
+		return errors.New("failed to load role permissions")
+	}
+	permissions := make(map[string][]string, len(roles))
+	for _, role := range roles {
+		permissions[role.Name] = role.Permissions
+	}
+	s.permissions = permissions
+	s.loadedAt = time.Now()
+	return nil
+}
+
+// containsString mengecek apakah slice berisi nilai tertentu
+func containsString(values []string, wanted string) bool {
+	for _, v := range values {
+		if v == wanted {
+			return true
+		}
+	}
+	return false
+}
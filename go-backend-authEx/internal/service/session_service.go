@@ -0,0 +1,255 @@
+package service
+
+import (
+	"crypto/subtle"
+	"errors"
+	"strings"
+	"time"
+
+	"go-auth-example/internal/auth"
+	"go-auth-example/internal/logger"
+	"go-auth-example/internal/model"
+	"go-auth-example/internal/repository"
+
+	"github.com/sirupsen/logrus"
+)
+
+// maxUserAgentLength membatasi user agent yang disimpan
+const maxUserAgentLength = 512
+
+// SessionService mengelola session login. ID session sama dengan family refresh token-nya,
+// sehingga mencabut session juga mencabut refresh token yang terkait.
+type SessionService interface {
+	// Start mencatat session baru untuk login
+	Start(userID int, sessionID string, client model.ClientInfo) error
+	// Resume dipanggil saat refresh: memperbarui last seen, atau mencatat session untuk family lama yang belum punya session.
+	// Mengembalikan "session revoked" jika session sudah dicabut.
+	Resume(userID int, sessionID string, client model.ClientInfo) error
+	// Validate dipanggil AuthMiddleware untuk setiap request dengan klaim sid.
+	// Pembatasan penulisan last seen diserahkan ke SessionRepository.Touch.
+	// Mengembalikan "session revoked" jika session tidak ada atau sudah dicabut.
+	Validate(sessionID string, client model.ClientInfo) error
+	List(userID int, currentSessionID string) ([]model.Session, error)
+	Revoke(userID int, sessionID string) error
+	// RevokeOthers mencabut semua session user kecuali session saat ini ("sign out everywhere else")
+	RevokeOthers(userID int, currentSessionID string) (int, error)
+	// CSRFToken mengembalikan token CSRF synchronizer milik session (dibuat jika belum ada)
+	CSRFToken(sessionID string) (string, error)
+	// VerifyCSRFToken mengembalikan "invalid csrf token" jika token tidak cocok dengan milik session
+	VerifyCSRFToken(sessionID string, token string) error
+}
+
+// sessionService struct mengimplementasikan SessionService
+type sessionService struct {
+	sessionRepo      repository.SessionRepository
+	refreshTokenRepo repository.RefreshTokenRepository
+}
+
+// NewSessionService adalah constructor untuk sessionService
+func NewSessionService(sessionRepo repository.SessionRepository, refreshTokenRepo repository.RefreshTokenRepository) SessionService {
+	return &sessionService{sessionRepo: sessionRepo, refreshTokenRepo: refreshTokenRepo}
+}
+
+// Implementasi Start
+func (s *sessionService) Start(userID int, sessionID string, client model.ClientInfo) error {
+	logFields := logrus.Fields{
+		"service": "SessionService",
+		"method":  "Start",
+		"user_id": userID,
+	}
+
+	csrfToken, err := auth.GenerateOpaqueToken(32)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error generating CSRF token: %v", err)
+		return errors.New("failed to start session")
+	}
+
+	session := &model.Session{
+		ID:        sessionID,
+		UserID:    userID,
+		UserAgent: truncate(client.UserAgent, maxUserAgentLength),
+		IP:        client.IP,
+		CSRFToken: csrfToken,
+	}
+	if err := s.sessionRepo.Create(session); err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error creating session: %v", err)
+		return errors.New("failed to start session")
+	}
+	return nil
+}
+
+// Implementasi Resume
+func (s *sessionService) Resume(userID int, sessionID string, client model.ClientInfo) error {
+	session, err := s.sessionRepo.GetByID(sessionID)
+	if err != nil {
+		logger.Log.WithFields(logrus.Fields{
+			"service": "SessionService",
+			"method":  "Resume",
+			"user_id": userID,
+		}).Errorf("Error loading session: %v", err)
+		return errors.New("failed to load session")
+	}
+	if session == nil {
+		// Family refresh token dari sebelum fitur session ada
+		return s.Start(userID, sessionID, client)
+	}
+	if session.RevokedAt != nil || session.UserID != userID {
+		return errors.New("session revoked")
+	}
+	return s.touch(sessionID, client)
+}
+
+// Implementasi Validate
+func (s *sessionService) Validate(sessionID string, client model.ClientInfo) error {
+	session, err := s.sessionRepo.GetByID(sessionID)
+	if err != nil {
+		logger.Log.WithFields(logrus.Fields{
+			"service":    "SessionService",
+			"method":     "Validate",
+			"session_id": sessionID,
+		}).Errorf("Error loading session: %v", err)
+		return errors.New("failed to load session")
+	}
+	if session == nil || session.RevokedAt != nil {
+		return errors.New("session revoked")
+	}
+	return s.touch(sessionID, client)
+}
+
+// touch memperbarui last seen; kegagalan hanya dicatat karena tidak boleh menggagalkan request
+func (s *sessionService) touch(sessionID string, client model.ClientInfo) error {
+	client.UserAgent = truncate(client.UserAgent, maxUserAgentLength)
+	if err := s.sessionRepo.Touch(sessionID, client); err != nil {
+		logger.Log.WithField("session_id", sessionID).Warnf("Could not update session last seen: %v", err)
+	}
+	return nil
+}
+
+// Implementasi List
+func (s *sessionService) List(userID int, currentSessionID string) ([]model.Session, error) {
+	// Session yang tidak dipakai lebih lama dari masa berlaku refresh token sudah tidak bisa dilanjutkan
+	sessions, err := s.sessionRepo.ListActive(userID, time.Now().Add(-RefreshTokenTTL))
+	if err != nil {
+		logger.Log.WithFields(logrus.Fields{
+			"service": "SessionService",
+			"method":  "List",
+			"user_id": userID,
+		}).Errorf("Error listing sessions: %v", err)
+		return nil, errors.New("failed to list sessions")
+	}
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == currentSessionID
+	}
+	return sessions, nil
+}
+
+// Implementasi Revoke
+func (s *sessionService) Revoke(userID int, sessionID string) error {
+	logFields := logrus.Fields{
+		"service":    "SessionService",
+		"method":     "Revoke",
+		"user_id":    userID,
+		"session_id": sessionID,
+	}
+
+	revoked, err := s.sessionRepo.Revoke(sessionID, userID)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error revoking session: %v", err)
+		return errors.New("failed to revoke session")
+	}
+	if !revoked {
+		return errors.New("session not found")
+	}
+	if err := s.refreshTokenRepo.RevokeFamily(sessionID); err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error revoking refresh tokens of session: %v", err)
+		return errors.New("failed to revoke session")
+	}
+
+	logger.Log.WithFields(logFields).Info("Session revoked.")
+	return nil
+}
+
+// Implementasi RevokeOthers
+func (s *sessionService) RevokeOthers(userID int, currentSessionID string) (int, error) {
+	logFields := logrus.Fields{
+		"service":    "SessionService",
+		"method":     "RevokeOthers",
+		"user_id":    userID,
+		"session_id": currentSessionID,
+	}
+
+	revokedIDs, err := s.sessionRepo.RevokeAllExcept(userID, currentSessionID)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error revoking other sessions: %v", err)
+		return 0, errors.New("failed to revoke sessions")
+	}
+	for _, id := range revokedIDs {
+		if err := s.refreshTokenRepo.RevokeFamily(id); err != nil {
+			logger.Log.WithFields(logFields).Errorf("Error revoking refresh tokens of session %s: %v", id, err)
+			return 0, errors.New("failed to revoke sessions")
+		}
+	}
+
+	logFields["revoked"] = len(revokedIDs)
+	logger.Log.WithFields(logFields).Info("Other sessions revoked.")
+	return len(revokedIDs), nil
+}
+
+// Implementasi CSRFToken
+func (s *sessionService) CSRFToken(sessionID string) (string, error) {
+	logFields := logrus.Fields{
+		"service":    "SessionService",
+		"method":     "CSRFToken",
+		"session_id": sessionID,
+	}
+
+	session, err := s.sessionRepo.GetByID(sessionID)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error loading session: %v", err)
+		return "", errors.New("failed to load session")
+	}
+	if session == nil || session.RevokedAt != nil {
+		return "", errors.New("session revoked")
+	}
+	if session.CSRFToken != "" {
+		return session.CSRFToken, nil
+	}
+
+	// Session dari sebelum mode cookie ada belum punya token CSRF
+	csrfToken, err := auth.GenerateOpaqueToken(32)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error generating CSRF token: %v", err)
+		return "", errors.New("failed to issue csrf token")
+	}
+	if err := s.sessionRepo.SetCSRFToken(sessionID, csrfToken); err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error storing CSRF token: %v", err)
+		return "", errors.New("failed to issue csrf token")
+	}
+	return csrfToken, nil
+}
+
+// Implementasi VerifyCSRFToken
+func (s *sessionService) VerifyCSRFToken(sessionID string, token string) error {
+	session, err := s.sessionRepo.GetByID(sessionID)
+	if err != nil {
+		logger.Log.WithFields(logrus.Fields{
+			"service":    "SessionService",
+			"method":     "VerifyCSRFToken",
+			"session_id": sessionID,
+		}).Errorf("Error loading session: %v", err)
+		return errors.New("failed to load session")
+	}
+	if session == nil || session.CSRFToken == "" || token == "" ||
+		subtle.ConstantTimeCompare([]byte(session.CSRFToken), []byte(token)) != 1 {
+		return errors.New("invalid csrf token")
+	}
+	return nil
+}
+
+func truncate(value string, max int) string {
+	if len(value) <= max {
+		return value
+	}
+	// Jangan memotong di tengah karakter UTF-8
+	return strings.ToValidUTF8(value[:max], "")
+}
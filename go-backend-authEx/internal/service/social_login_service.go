@@ -0,0 +1,322 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"go-auth-example/internal/auth"
+	"go-auth-example/internal/logger"
+	"go-auth-example/internal/mail"
+	"go-auth-example/internal/model"
+	"go-auth-example/internal/repository"
+	"go-auth-example/internal/social"
+
+	"github.com/sirupsen/logrus"
+)
+
+// SocialLoginStateTTL adalah batas waktu antara redirect ke provider dan callback
+const SocialLoginStateTTL = 10 * time.Minute
+
+// socialExchangeTimeout membatasi total waktu penukaran code dan pengambilan profil di provider
+const socialExchangeTimeout = 20 * time.Second
+
+// SocialLoginService menjalankan login lewat identity provider eksternal (authorization code + PKCE)
+// dan menghubungkan akun provider dengan user lokal
+type SocialLoginService interface {
+	// Providers mengembalikan provider yang aktif
+	Providers() []model.SocialProvider
+	// Begin membuat state, code_verifier dan nonce lalu mengembalikan URL authorization provider beserta state mentahnya.
+	// State mentah disimpan di cookie browser agar callback hanya diterima dari browser yang memulai login.
+	Begin(provider string) (*model.SocialLoginStart, string, error)
+	// Complete memverifikasi state, menukar code di provider lalu mengembalikan user yang terhubung.
+	// User baru dibuat jika belum ada; akun dengan email terverifikasi yang sama dihubungkan otomatis.
+	Complete(provider string, input model.SocialCallbackInput, browserState string) (*model.User, error)
+}
+
+// socialLoginService struct mengimplementasikan SocialLoginService
+type socialLoginService struct {
+	userRepo     repository.UserRepository
+	identityRepo repository.IdentityRepository
+	providers    *social.Registry
+	mailer       mail.Mailer
+}
+
+// NewSocialLoginService adalah constructor untuk socialLoginService
+func NewSocialLoginService(userRepo repository.UserRepository, identityRepo repository.IdentityRepository,
+	providers *social.Registry, mailer mail.Mailer) SocialLoginService {
+	return &socialLoginService{
+		userRepo:     userRepo,
+		identityRepo: identityRepo,
+		providers:    providers,
+		mailer:       mailer,
+	}
+}
+
+// Implementasi Providers
+func (s *socialLoginService) Providers() []model.SocialProvider {
+	providers := []model.SocialProvider{}
+	for _, p := range s.providers.List() {
+		providers = append(providers, model.SocialProvider{Name: p.Name(), DisplayName: p.DisplayName()})
+	}
+	return providers
+}
+
+// Implementasi Begin
+func (s *socialLoginService) Begin(providerName string) (*model.SocialLoginStart, string, error) {
+	logFields := logrus.Fields{
+		"service":  "SocialLoginService",
+		"method":   "Begin",
+		"provider": providerName,
+	}
+
+	provider, ok := s.providers.Get(providerName)
+	if !ok {
+		return nil, "", errors.New("unknown provider")
+	}
+
+	rawState, err := auth.GenerateOpaqueToken(32)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error generating state: %v", err)
+		return nil, "", errors.New("failed to start social login")
+	}
+	codeVerifier, err := auth.GenerateOpaqueToken(32) // 43 karakter base64url, sesuai RFC 7636
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error generating code verifier: %v", err)
+		return nil, "", errors.New("failed to start social login")
+	}
+	nonce, err := auth.GenerateOpaqueToken(16)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error generating nonce: %v", err)
+		return nil, "", errors.New("failed to start social login")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), socialExchangeTimeout)
+	defer cancel()
+	authURL, err := provider.AuthCodeURL(ctx, rawState, codeVerifier, nonce)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error building authorization URL: %v", err)
+		return nil, "", errors.New("social provider unavailable")
+	}
+
+	state := &model.SocialLoginState{
+		StateHash:    auth.HashToken(rawState),
+		Provider:     provider.Name(),
+		CodeVerifier: codeVerifier,
+		Nonce:        nonce,
+		ExpiresAt:    time.Now().Add(SocialLoginStateTTL),
+	}
+	if err := s.identityRepo.CreateState(state); err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error storing social login state: %v", err)
+		return nil, "", errors.New("failed to start social login")
+	}
+	return &model.SocialLoginStart{AuthorizationURL: authURL}, rawState, nil
+}
+
+// Implementasi Complete
+func (s *socialLoginService) Complete(providerName string, input model.SocialCallbackInput, browserState string) (*model.User, error) {
+	logFields := logrus.Fields{
+		"service":  "SocialLoginService",
+		"method":   "Complete",
+		"provider": providerName,
+	}
+
+	provider, ok := s.providers.Get(providerName)
+	if !ok {
+		return nil, errors.New("unknown provider")
+	}
+
+	// State dari callback harus sama dengan cookie browser: mencegah penyerang menyisipkan code miliknya
+	// ke browser korban (login CSRF)
+	if browserState == "" || subtle.ConstantTimeCompare([]byte(input.State), []byte(browserState)) != 1 {
+		logger.Log.WithFields(logFields).Warn("Social login callback state does not match the browser.")
+		return nil, errors.New("invalid social login state")
+	}
+	state, err := s.identityRepo.TakeState(auth.HashToken(input.State))
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error loading social login state: %v", err)
+		return nil, errors.New("failed to complete social login")
+	}
+	if state == nil || state.Provider != provider.Name() {
+		return nil, errors.New("invalid social login state")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), socialExchangeTimeout)
+	defer cancel()
+	profile, err := provider.Exchange(ctx, input.Code, state.CodeVerifier, state.Nonce)
+	if err != nil {
+		logger.Log.WithFields(logFields).Warnf("Social login exchange failed: %v", err)
+		return nil, errors.New("social login failed")
+	}
+	if profile.Subject == "" {
+		logger.Log.WithFields(logFields).Warn("Provider returned a profile without subject.")
+		return nil, errors.New("social login failed")
+	}
+
+	return s.resolveUser(provider, profile, logFields)
+}
+
+// resolveUser mencari user untuk profil provider: lewat identity yang sudah terhubung, lewat email yang sama
+// (hanya jika provider dan akun lokal sama-sama sudah memverifikasi email tersebut), atau membuat user baru
+func (s *socialLoginService) resolveUser(provider social.Provider, profile *social.Profile, logFields logrus.Fields) (*model.User, error) {
+	identity, err := s.identityRepo.GetByProviderSubject(provider.Name(), profile.Subject)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error loading identity: %v", err)
+		return nil, errors.New("failed to complete social login")
+	}
+	if identity != nil {
+		logFields["user_id"] = identity.UserID
+		user, err := s.userRepo.GetByID(identity.UserID)
+		if err != nil || user == nil {
+			logger.Log.WithFields(logFields).Errorf("Error loading user of identity: %v", err)
+			return nil, errors.New("failed to complete social login")
+		}
+		if err := s.identityRepo.TouchLastUsed(identity.ID); err != nil {
+			logger.Log.WithFields(logFields).Warnf("Could not record identity use: %v", err)
+		}
+		return user, nil
+	}
+
+	email := auth.NormalizeIdentity(profile.Email)
+	if email == "" {
+		logger.Log.WithFields(logFields).Info("Provider did not return an email address.")
+		return nil, errors.New("social email missing")
+	}
+	if !profile.EmailVerified {
+		// Email yang belum diverifikasi provider tidak boleh dipakai untuk menghubungkan atau mendaftarkan akun
+		logger.Log.WithFields(logFields).Info("Provider email is not verified.")
+		return nil, errors.New("social email not verified")
+	}
+
+	existing, err := s.userRepo.GetByEmail(email)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error loading user by email: %v", err)
+		return nil, errors.New("failed to complete social login")
+	}
+	if existing != nil {
+		logFields["user_id"] = existing.ID
+		// Akun yang emailnya belum diverifikasi bisa saja didaftarkan orang lain dengan email korban;
+		// menghubungkannya akan memberi pendaftar itu akses ke akun pemilik email sebenarnya
+		if !existing.IsEmailVerified() {
+			logger.Log.WithFields(logFields).Warn("Refusing to link identity to an account with an unverified email.")
+			return nil, errors.New("email already registered")
+		}
+		if err := s.link(existing, provider, profile, email); err != nil {
+			logger.Log.WithFields(logFields).Errorf("Error linking identity: %v", err)
+			return nil, errors.New("failed to complete social login")
+		}
+		logger.Log.WithFields(logFields).Info("Identity linked to existing account by verified email.")
+		s.notify(existing, provider, logFields)
+		return existing, nil
+	}
+
+	user, err := s.createUser(profile, email, logFields)
+	if err != nil {
+		return nil, err
+	}
+	logFields["user_id"] = user.ID
+	if err := s.link(user, provider, profile, email); err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error linking identity to new user: %v", err)
+		return nil, errors.New("failed to complete social login")
+	}
+	logger.Log.WithFields(logFields).Info("User registered with social login.")
+	return user, nil
+}
+
+func (s *socialLoginService) link(user *model.User, provider social.Provider, profile *social.Profile, email string) error {
+	return s.identityRepo.Create(&model.Identity{
+		UserID:   user.ID,
+		Provider: provider.Name(),
+		Subject:  profile.Subject,
+		Email:    email,
+	})
+}
+
+// maxUsernameAttempts membatasi percobaan mencari username yang belum dipakai
+const maxUsernameAttempts = 10
+
+// createUser mendaftarkan user baru dari profil provider. User ini tidak punya password;
+// password bisa dibuat lewat alur lupa password jika user ingin login tanpa provider.
+func (s *socialLoginService) createUser(profile *social.Profile, email string, logFields logrus.Fields) (*model.User, error) {
+	base := usernameFromProfile(profile, email)
+	for attempt := 0; attempt < maxUsernameAttempts; attempt++ {
+		username := base
+		if attempt > 0 {
+			username = fmt.Sprintf("%s%d", base, 1000+rand.IntN(9000))
+		}
+		taken, err := s.userRepo.GetByUsername(username)
+		if err != nil {
+			logger.Log.WithFields(logFields).Errorf("Error checking username: %v", err)
+			return nil, errors.New("failed to complete social login")
+		}
+		if taken != nil {
+			continue
+		}
+
+		user := &model.User{Username: username, Email: email}
+		if err := s.userRepo.Create(user); err != nil {
+			switch err.Error() {
+			case "username already exists":
+				continue
+			case "email already exists":
+				return nil, errors.New("email already registered")
+			}
+			logger.Log.WithFields(logFields).Errorf("Error creating user: %v", err)
+			return nil, errors.New("failed to complete social login")
+		}
+		// Email sudah diverifikasi oleh provider
+		if err := s.userRepo.MarkEmailVerified(user.ID); err != nil {
+			logger.Log.WithFields(logFields).Errorf("Error marking email verified: %v", err)
+			return nil, errors.New("failed to complete social login")
+		}
+		created, err := s.userRepo.GetByID(user.ID)
+		if err != nil || created == nil {
+			logger.Log.WithFields(logFields).Errorf("Error loading created user: %v", err)
+			return nil, errors.New("failed to complete social login")
+		}
+		return created, nil
+	}
+	logger.Log.WithFields(logFields).Error("Could not find a free username for social login.")
+	return nil, errors.New("failed to complete social login")
+}
+
+// usernameFromProfile mengusulkan username dari profil provider yang memenuhi aturan registrasi (alfanumerik, 3-30 karakter).
+// Sisa panjang disisakan untuk akhiran angka jika username sudah dipakai.
+func usernameFromProfile(profile *social.Profile, email string) string {
+	localPart, _, _ := strings.Cut(email, "@")
+	for _, candidate := range []string{profile.Username, localPart, profile.Name} {
+		var b strings.Builder
+		for _, r := range candidate {
+			if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+				b.WriteRune(r)
+			}
+		}
+		username := b.String()
+		if len(username) > 26 {
+			username = username[:26]
+		}
+		if len(username) >= 3 {
+			return username
+		}
+	}
+	return "user"
+}
+
+// notify memberi tahu pemilik akun bahwa akun provider baru terhubung, agar penautan yang tidak dikenal bisa dilaporkan
+func (s *socialLoginService) notify(user *model.User, provider social.Provider, logFields logrus.Fields) {
+	msg := mail.Message{
+		To:      user.Email,
+		Subject: fmt.Sprintf("%s account linked", provider.DisplayName()),
+		Body: fmt.Sprintf("Hi %s,\n\nA %s account was linked to your account and can now be used to log in. "+
+			"If this wasn't you, reset your password and contact support.\n", user.Username, provider.DisplayName()),
+	}
+	go func() {
+		if err := s.mailer.Send(msg); err != nil {
+			logger.Log.WithFields(logFields).Warnf("Could not send identity linked notice: %v", err)
+		}
+	}()
+}
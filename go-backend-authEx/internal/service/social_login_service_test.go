@@ -0,0 +1,545 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go-auth-example/internal/auth"
+	"go-auth-example/internal/mail"
+	"go-auth-example/internal/model"
+	"go-auth-example/internal/repository/memrepo"
+	"go-auth-example/internal/social"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testSocialClientID     = "social-client"
+	testSocialClientSecret = "social-secret"
+	testSocialRedirectURL  = "http://localhost:5173/auth/callback"
+)
+
+// stubProfile adalah akun user di stub identity provider
+type stubProfile struct {
+	Subject       string // Untuk GitHub harus berupa angka
+	Email         string
+	EmailVerified bool
+	Name          string
+	Username      string
+	// UserInfoOnly menghilangkan email dari ID token sehingga harus diambil dari endpoint userinfo
+	UserInfoOnly bool
+}
+
+// stubGrant adalah authorization code yang diterbitkan /authorize
+type stubGrant struct {
+	challenge   string
+	nonce       string
+	redirectURI string
+	scope       string
+	profile     stubProfile
+}
+
+// stubIdentityProvider adalah provider OpenID Connect sekaligus GitHub-style di dalam proses:
+// discovery, authorize (langsung disetujui), token dengan PKCE S256, userinfo, JWKS dan REST API /user
+type stubIdentityProvider struct {
+	t      *testing.T
+	server *httptest.Server
+	key    *auth.SigningKey
+	jwks   auth.JWKS
+
+	mu            sync.Mutex
+	profile       stubProfile
+	nonceOverride string // Jika diisi, ID token membawa nonce ini alih-alih nonce dari request authorize
+	grants        map[string]stubGrant
+	accessTokens  map[string]stubProfile
+	pkceFailures  int
+}
+
+func newStubIdentityProvider(t *testing.T) *stubIdentityProvider {
+	t.Helper()
+	key, err := auth.GenerateSigningKey(auth.AlgES256)
+	if err != nil {
+		t.Fatalf("GenerateSigningKey: %v", err)
+	}
+	keySet, err := auth.NewKeySet(key.ID, key)
+	if err != nil {
+		t.Fatalf("NewKeySet: %v", err)
+	}
+	jwks, err := keySet.PublicJWKS()
+	if err != nil {
+		t.Fatalf("PublicJWKS: %v", err)
+	}
+
+	idp := &stubIdentityProvider{
+		t:            t,
+		key:          key,
+		jwks:         jwks,
+		grants:       map[string]stubGrant{},
+		accessTokens: map[string]stubProfile{},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", idp.discovery)
+	mux.HandleFunc("GET /authorize", idp.authorize)
+	mux.HandleFunc("POST /token", idp.token)
+	mux.HandleFunc("GET /userinfo", idp.userInfo)
+	mux.HandleFunc("GET /jwks", idp.keys)
+	mux.HandleFunc("GET /api/user", idp.gitHubUser)
+	mux.HandleFunc("GET /api/user/emails", idp.gitHubEmails)
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+func (p *stubIdentityProvider) setProfile(profile stubProfile) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.profile = profile
+}
+
+func (p *stubIdentityProvider) setNonceOverride(nonce string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.nonceOverride = nonce
+}
+
+func (p *stubIdentityProvider) pkceFailureCount() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.pkceFailures
+}
+
+func (p *stubIdentityProvider) writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		p.t.Errorf("encode stub provider response: %v", err)
+	}
+}
+
+func (p *stubIdentityProvider) discovery(w http.ResponseWriter, r *http.Request) {
+	issuer := p.server.URL
+	p.writeJSON(w, http.StatusOK, map[string]any{
+		"issuer":                                issuer,
+		"authorization_endpoint":                issuer + "/authorize",
+		"token_endpoint":                        issuer + "/token",
+		"userinfo_endpoint":                     issuer + "/userinfo",
+		"jwks_uri":                              issuer + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{auth.AlgES256},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+// authorize menyetujui login tanpa interaksi dan me-redirect ke aplikasi dengan code dan state
+func (p *stubIdentityProvider) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("client_id") != testSocialClientID || query.Get("response_type") != "code" ||
+		query.Get("redirect_uri") != testSocialRedirectURL || query.Get("code_challenge_method") != "S256" ||
+		query.Get("code_challenge") == "" || query.Get("state") == "" {
+		http.Error(w, "invalid authorization request", http.StatusBadRequest)
+		return
+	}
+
+	code := rand.Text()
+	p.mu.Lock()
+	p.grants[code] = stubGrant{
+		challenge:   query.Get("code_challenge"),
+		nonce:       query.Get("nonce"),
+		redirectURI: query.Get("redirect_uri"),
+		scope:       query.Get("scope"),
+		profile:     p.profile,
+	}
+	p.mu.Unlock()
+
+	redirect, _ := url.Parse(query.Get("redirect_uri"))
+	values := redirect.Query()
+	values.Set("code", code)
+	values.Set("state", query.Get("state"))
+	redirect.RawQuery = values.Encode()
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+func (p *stubIdentityProvider) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		p.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientID, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	if clientID != testSocialClientID || clientSecret != testSocialClientSecret {
+		p.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+	if r.PostForm.Get("grant_type") != "authorization_code" {
+		p.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "unsupported_grant_type"})
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	// Code hanya berlaku sekali, juga jika penukarannya gagal
+	grant, ok := p.grants[r.PostForm.Get("code")]
+	delete(p.grants, r.PostForm.Get("code"))
+	if !ok || grant.redirectURI != r.PostForm.Get("redirect_uri") {
+		p.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+	verifierHash := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if base64.RawURLEncoding.EncodeToString(verifierHash[:]) != grant.challenge {
+		p.pkceFailures++
+		p.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant", "error_description": "PKCE verification failed"})
+		return
+	}
+
+	accessToken := rand.Text()
+	p.accessTokens[accessToken] = grant.profile
+	response := map[string]any{"access_token": accessToken, "token_type": "Bearer", "expires_in": 3600}
+	if strings.Contains(" "+grant.scope+" ", " openid ") {
+		nonce := grant.nonce
+		if p.nonceOverride != "" {
+			nonce = p.nonceOverride
+		}
+		response["id_token"] = p.idToken(grant.profile, nonce)
+	}
+	p.writeJSON(w, http.StatusOK, response)
+}
+
+func (p *stubIdentityProvider) idToken(profile stubProfile, nonce string) string {
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":   p.server.URL,
+		"sub":   profile.Subject,
+		"aud":   testSocialClientID,
+		"iat":   now.Unix(),
+		"exp":   now.Add(5 * time.Minute).Unix(),
+		"nonce": nonce,
+	}
+	if !profile.UserInfoOnly {
+		for name, value := range profileClaims(profile) {
+			claims[name] = value
+		}
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+	token.Header["kid"] = p.key.ID
+	signed, err := token.SignedString(p.key.PrivateKey)
+	if err != nil {
+		p.t.Fatalf("sign id_token: %v", err)
+	}
+	return signed
+}
+
+func profileClaims(profile stubProfile) map[string]any {
+	return map[string]any{
+		"email":              profile.Email,
+		"email_verified":     profile.EmailVerified,
+		"name":               profile.Name,
+		"preferred_username": profile.Username,
+	}
+}
+
+// bearerProfile mengembalikan profil pemilik access token di header Authorization
+func (p *stubIdentityProvider) bearerProfile(r *http.Request) (stubProfile, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	profile, ok := p.accessTokens[strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")]
+	return profile, ok
+}
+
+func (p *stubIdentityProvider) userInfo(w http.ResponseWriter, r *http.Request) {
+	profile, ok := p.bearerProfile(r)
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	claims := profileClaims(profile)
+	claims["sub"] = profile.Subject
+	p.writeJSON(w, http.StatusOK, claims)
+}
+
+func (p *stubIdentityProvider) keys(w http.ResponseWriter, r *http.Request) {
+	p.writeJSON(w, http.StatusOK, p.jwks)
+}
+
+func (p *stubIdentityProvider) gitHubUser(w http.ResponseWriter, r *http.Request) {
+	profile, ok := p.bearerProfile(r)
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	id, err := strconv.ParseInt(profile.Subject, 10, 64)
+	if err != nil {
+		p.t.Errorf("GitHub profile subject %q is not numeric", profile.Subject)
+	}
+	p.writeJSON(w, http.StatusOK, map[string]any{"id": id, "login": profile.Username, "name": profile.Name})
+}
+
+func (p *stubIdentityProvider) gitHubEmails(w http.ResponseWriter, r *http.Request) {
+	profile, ok := p.bearerProfile(r)
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	p.writeJSON(w, http.StatusOK, []map[string]any{
+		{"email": "noreply-" + profile.Subject + "@users.noreply.example.com", "primary": false, "verified": true},
+		{"email": profile.Email, "primary": true, "verified": profile.EmailVerified},
+	})
+}
+
+type socialTestEnv struct {
+	t          *testing.T
+	idp        *stubIdentityProvider
+	service    SocialLoginService
+	users      *memrepo.Users
+	identities *memrepo.Identities
+	mailer     *mail.MemoryMailer
+	browser    *http.Client
+}
+
+func newSocialTestEnv(t *testing.T, users ...model.User) *socialTestEnv {
+	t.Helper()
+	idp := newStubIdentityProvider(t)
+	client := social.ClientConfig{ClientID: testSocialClientID, ClientSecret: testSocialClientSecret, RedirectURL: testSocialRedirectURL}
+	oidcProvider, err := social.NewOIDCProvider(idp.server.URL, client)
+	if err != nil {
+		t.Fatalf("NewOIDCProvider: %v", err)
+	}
+	gitHubProvider, err := social.NewGitHubProvider(client, social.GitHubEndpoints{
+		AuthURL:  idp.server.URL + "/authorize",
+		TokenURL: idp.server.URL + "/token",
+		APIURL:   idp.server.URL + "/api",
+	})
+	if err != nil {
+		t.Fatalf("NewGitHubProvider: %v", err)
+	}
+
+	env := &socialTestEnv{
+		t:          t,
+		idp:        idp,
+		users:      memrepo.NewUsers(users...),
+		identities: memrepo.NewIdentities(),
+		mailer:     mail.NewMemoryMailer(),
+		// Browser yang tidak mengikuti redirect, agar callback ke frontend bisa dibaca dari header Location
+		browser: &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }},
+	}
+	env.service = NewSocialLoginService(env.users, env.identities, social.NewRegistry(oidcProvider, gitHubProvider), env.mailer)
+	return env
+}
+
+// authorize menjalankan Begin lalu membuka URL authorization seperti browser, dan mengembalikan code dan state dari callback
+func (e *socialTestEnv) authorize(provider string) (code, state string) {
+	e.t.Helper()
+	start, rawState, err := e.service.Begin(provider)
+	if err != nil {
+		e.t.Fatalf("Begin(%s): %v", provider, err)
+	}
+	resp, err := e.browser.Get(start.AuthorizationURL)
+	if err != nil {
+		e.t.Fatalf("GET authorization URL: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		e.t.Fatalf("authorization status %d; want 302", resp.StatusCode)
+	}
+	callback, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil || !strings.HasPrefix(callback.String(), testSocialRedirectURL+"?") {
+		e.t.Fatalf("unexpected callback %q (%v)", resp.Header.Get("Location"), err)
+	}
+	if callback.Query().Get("state") != rawState {
+		e.t.Fatalf("callback state %q; want %q", callback.Query().Get("state"), rawState)
+	}
+	return callback.Query().Get("code"), rawState
+}
+
+// login menjalankan seluruh alur dari browser yang sama: state di callback cocok dengan cookie
+func (e *socialTestEnv) login(provider string) (*model.User, error) {
+	e.t.Helper()
+	code, state := e.authorize(provider)
+	return e.service.Complete(provider, model.SocialCallbackInput{Code: code, State: state}, state)
+}
+
+func (e *socialTestEnv) identitiesOf(userID int) []model.Identity {
+	e.t.Helper()
+	identities, err := e.identities.ListByUser(userID)
+	if err != nil {
+		e.t.Fatalf("ListByUser: %v", err)
+	}
+	return identities
+}
+
+func verifiedUser(id int, username, email string) model.User {
+	verifiedAt := time.Now().Add(-24 * time.Hour)
+	return model.User{ID: id, Username: username, Email: email, Status: model.UserStatusActive, EmailVerifiedAt: &verifiedAt}
+}
+
+func TestSocialLoginCreatesNewAccount(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider string
+		profile  stubProfile
+	}{
+		{"oidc", "oidc", stubProfile{Subject: "idp-carol", Email: "carol@example.com", EmailVerified: true, Name: "Carol", Username: "carol"}},
+		{"oidc email from userinfo", "oidc",
+			stubProfile{Subject: "idp-carol", Email: "carol@example.com", EmailVerified: true, Username: "carol", UserInfoOnly: true}},
+		{"github", "github", stubProfile{Subject: "4242", Email: "carol@example.com", EmailVerified: true, Name: "Carol", Username: "carol"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newSocialTestEnv(t, verifiedUser(1, "alice", "alice@example.com"))
+			env.idp.setProfile(tt.profile)
+
+			user, err := env.login(tt.provider)
+			if err != nil {
+				t.Fatalf("Complete: %v", err)
+			}
+			if user.ID == 1 || user.Username != "carol" || user.Email != "carol@example.com" || !user.IsEmailVerified() {
+				t.Fatalf("unexpected new user: %+v", user)
+			}
+			identities := env.identitiesOf(user.ID)
+			if len(identities) != 1 || identities[0].Provider != tt.provider || identities[0].Subject != tt.profile.Subject {
+				t.Fatalf("identities of new user = %+v", identities)
+			}
+
+			// Login berikutnya memakai identity yang sudah terhubung
+			again, err := env.login(tt.provider)
+			if err != nil {
+				t.Fatalf("second Complete: %v", err)
+			}
+			if again.ID != user.ID || len(env.identitiesOf(user.ID)) != 1 {
+				t.Fatalf("second login returned user %d; want %d", again.ID, user.ID)
+			}
+		})
+	}
+}
+
+func TestSocialLoginLinksVerifiedEmail(t *testing.T) {
+	env := newSocialTestEnv(t, verifiedUser(1, "alice", "alice@example.com"))
+	env.idp.setProfile(stubProfile{Subject: "idp-alice", Email: "Alice@Example.com", EmailVerified: true, Username: "alice.w"})
+
+	user, err := env.login("oidc")
+	if err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	if user.ID != 1 {
+		t.Fatalf("linked to user %d; want the existing account", user.ID)
+	}
+	identities := env.identitiesOf(1)
+	if len(identities) != 1 || identities[0].Subject != "idp-alice" {
+		t.Fatalf("identities = %+v", identities)
+	}
+
+	// Pemilik akun diberi tahu di background
+	deadline := time.Now().Add(2 * time.Second)
+	for len(env.mailer.Messages()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("no identity linked notice sent")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if msg := env.mailer.Messages()[0]; msg.To != "alice@example.com" || msg.Subject != "Single sign-on account linked" {
+		t.Fatalf("unexpected notice: %+v", msg)
+	}
+}
+
+func TestSocialLoginRefusesUnverifiedEmail(t *testing.T) {
+	t.Run("local account unverified", func(t *testing.T) {
+		unverified := model.User{ID: 1, Username: "alice", Email: "alice@example.com", Status: model.UserStatusActive}
+		env := newSocialTestEnv(t, unverified)
+		env.idp.setProfile(stubProfile{Subject: "idp-alice", Email: "alice@example.com", EmailVerified: true})
+
+		_, err := env.login("oidc")
+		expectError(t, err, "email already registered")
+		if len(env.identitiesOf(1)) != 0 {
+			t.Fatal("identity linked to an account with an unverified email")
+		}
+		if user, _ := env.users.GetByID(1); user.IsEmailVerified() {
+			t.Fatal("local email marked verified by a refused social login")
+		}
+	})
+
+	for _, provider := range []string{"oidc", "github"} {
+		t.Run(provider+" email unverified", func(t *testing.T) {
+			env := newSocialTestEnv(t, verifiedUser(1, "alice", "alice@example.com"))
+			env.idp.setProfile(stubProfile{Subject: "77", Email: "alice@example.com", EmailVerified: false})
+
+			_, err := env.login(provider)
+			expectError(t, err, "social email not verified")
+			if len(env.identitiesOf(1)) != 0 {
+				t.Fatal("identity linked with an email the provider did not verify")
+			}
+		})
+	}
+}
+
+func TestSocialLoginRejectsStateAndPKCEMismatch(t *testing.T) {
+	env := newSocialTestEnv(t)
+	env.idp.setProfile(stubProfile{Subject: "idp-carol", Email: "carol@example.com", EmailVerified: true, Username: "carol"})
+
+	t.Run("state does not match browser", func(t *testing.T) {
+		code, state := env.authorize("oidc")
+		_, err := env.service.Complete("oidc", model.SocialCallbackInput{Code: code, State: state}, "")
+		expectError(t, err, "invalid social login state")
+		_, err = env.service.Complete("oidc", model.SocialCallbackInput{Code: code, State: state}, "cookie-of-another-login")
+		expectError(t, err, "invalid social login state")
+	})
+
+	t.Run("unknown state", func(t *testing.T) {
+		code, _ := env.authorize("oidc")
+		_, err := env.service.Complete("oidc", model.SocialCallbackInput{Code: code, State: "forged"}, "forged")
+		expectError(t, err, "invalid social login state")
+	})
+
+	t.Run("state from another provider", func(t *testing.T) {
+		code, state := env.authorize("oidc")
+		_, err := env.service.Complete("github", model.SocialCallbackInput{Code: code, State: state}, state)
+		expectError(t, err, "invalid social login state")
+	})
+
+	t.Run("state reused", func(t *testing.T) {
+		code, state := env.authorize("oidc")
+		if _, err := env.service.Complete("oidc", model.SocialCallbackInput{Code: code, State: state}, state); err != nil {
+			t.Fatalf("Complete: %v", err)
+		}
+		_, err := env.service.Complete("oidc", model.SocialCallbackInput{Code: code, State: state}, state)
+		expectError(t, err, "invalid social login state")
+	})
+
+	// Code yang dicuri dari login lain disisipkan ke callback browser ini: state cocok, tetapi code_verifier tidak
+	for _, provider := range []string{"oidc", "github"} {
+		t.Run(provider+" code verifier mismatch", func(t *testing.T) {
+			failures := env.idp.pkceFailureCount()
+			stolenCode, _ := env.authorize(provider)
+			_, state := env.authorize(provider)
+
+			_, err := env.service.Complete(provider, model.SocialCallbackInput{Code: stolenCode, State: state}, state)
+			expectError(t, err, "social login failed")
+			if env.idp.pkceFailureCount() != failures+1 {
+				t.Fatal("provider did not reject the code verifier")
+			}
+		})
+	}
+}
+
+func TestSocialLoginRejectsNonceMismatch(t *testing.T) {
+	env := newSocialTestEnv(t)
+	env.idp.setProfile(stubProfile{Subject: "idp-carol", Email: "carol@example.com", EmailVerified: true, Username: "carol"})
+	env.idp.setNonceOverride("nonce-of-a-replayed-id-token")
+
+	_, err := env.login("oidc")
+	expectError(t, err, "social login failed")
+	if user, _ := env.users.GetByEmail("carol@example.com"); user != nil {
+		t.Fatal("user created from an ID token with a mismatched nonce")
+	}
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"go-auth-example/internal/logger"
+	"go-auth-example/internal/model"
+	"go-auth-example/internal/repository"
+
+	"github.com/sirupsen/logrus"
+)
+
+// TokenRevocationService mengelola denylist access token berdasarkan klaim jti
+type TokenRevocationService interface {
+	Revoke(jti string, userID int, expiresAt time.Time) error
+	IsRevoked(jti string) (bool, error)
+	// RevokeUserTokens mencabut semua access token user yang diterbitkan sebelum waktu tersebut,
+	// termasuk token OAuth yang tidak punya session untuk dicabut
+	RevokeUserTokens(userID int, before time.Time) error
+	// IsRevokedForUser mengecek apakah token user yang diterbitkan pada issuedAt sudah dicabut lewat RevokeUserTokens
+	IsRevokedForUser(userID int, issuedAt time.Time) (bool, error)
+	// LoadActive mengisi cache dari database, dipanggil sekali saat startup
+	LoadActive() error
+	// Sweep menghapus entri yang sudah melewati masa berlaku token aslinya
+	Sweep() error
+	// StartSweeper menjalankan Sweep secara periodik di background; panggil fungsi yang dikembalikan untuk berhenti
+	StartSweeper(interval time.Duration) (stop func())
+}
+
+// tokenRevocationService menyimpan denylist di PostgreSQL dengan cache in-memory.
+// Hanya hasil positif (token dicabut) yang di-cache, sehingga pencabutan dari instance lain tetap terlihat lewat database.
+type tokenRevocationService struct {
+	repo repository.RevokedTokenRepository
+
+	mu    sync.RWMutex
+	cache map[string]time.Time // jti -> expires_at
+}
+
+// NewTokenRevocationService adalah constructor untuk tokenRevocationService
+func NewTokenRevocationService(repo repository.RevokedTokenRepository) TokenRevocationService {
+	return &tokenRevocationService{
+		repo:  repo,
+		cache: make(map[string]time.Time),
+	}
+}
+
+func (s *tokenRevocationService) Revoke(jti string, userID int, expiresAt time.Time) error {
+	if jti == "" {
+		return errors.New("token has no jti")
+	}
+	// Token yang sudah kedaluwarsa tidak perlu masuk denylist
+	if time.Now().After(expiresAt) {
+		return nil
+	}
+
+	err := s.repo.Add(&model.RevokedToken{JTI: jti, UserID: userID, ExpiresAt: expiresAt})
+	if err != nil {
+		logger.Log.WithFields(logrus.Fields{
+			"service": "TokenRevocationService",
+			"method":  "Revoke",
+			"user_id": userID,
+		}).Errorf("Error storing revoked token: %v", err)
+		return errors.New("failed to revoke token")
+	}
+
+	s.mu.Lock()
+	s.cache[jti] = expiresAt
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *tokenRevocationService) IsRevoked(jti string) (bool, error) {
+	s.mu.RLock()
+	_, cached := s.cache[jti]
+	s.mu.RUnlock()
+	if cached {
+		return true, nil
+	}
+
+	revoked, err := s.repo.Exists(jti)
+	if err != nil {
+		return false, err
+	}
+	if revoked {
+		// Waktu kedaluwarsa pastinya tidak diketahui di sini; Sweep akan membersihkan entri ini
+		// setelah LoadActive berikutnya atau ketika database tidak lagi memilikinya.
+		s.mu.Lock()
+		s.cache[jti] = time.Time{}
+		s.mu.Unlock()
+	}
+	return revoked, nil
+}
+
+func (s *tokenRevocationService) RevokeUserTokens(userID int, before time.Time) error {
+	if err := s.repo.SetUserCutoff(userID, before); err != nil {
+		logger.Log.WithFields(logrus.Fields{
+			"service": "TokenRevocationService",
+			"method":  "RevokeUserTokens",
+			"user_id": userID,
+		}).Errorf("Error storing user token cutoff: %v", err)
+		return errors.New("failed to revoke tokens")
+	}
+	return nil
+}
+
+// IsRevokedForUser tidak memakai cache: batas bisa dimajukan instance lain kapan saja,
+// dan pengecekannya hanya satu lookup primary key.
+func (s *tokenRevocationService) IsRevokedForUser(userID int, issuedAt time.Time) (bool, error) {
+	cutoff, err := s.repo.GetUserCutoff(userID)
+	if err != nil {
+		return false, err
+	}
+	// Klaim iat hanya berpresisi detik, jadi token yang terbit di detik yang sama dengan pencabutan ikut ditolak
+	return cutoff != nil && !issuedAt.After(cutoff.Truncate(time.Second)), nil
+}
+
+func (s *tokenRevocationService) LoadActive() error {
+	tokens, err := s.repo.ListActive()
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	for _, t := range tokens {
+		s.cache[t.JTI] = t.ExpiresAt
+	}
+	s.mu.Unlock()
+
+	logger.Log.WithFields(logrus.Fields{
+		"service": "TokenRevocationService",
+		"method":  "LoadActive",
+	}).Infof("Loaded %d revoked tokens into cache.", len(tokens))
+	return nil
+}
+
+func (s *tokenRevocationService) Sweep() error {
+	now := time.Now()
+	deleted, err := s.repo.DeleteExpired(now)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	for jti, expiresAt := range s.cache {
+		// Entri tanpa waktu kedaluwarsa (berasal dari IsRevoked) dibuang dari cache;
+		// jika masih aktif, ia akan dimuat ulang dari database pada pengecekan berikutnya.
+		if expiresAt.IsZero() || !expiresAt.After(now) {
+			delete(s.cache, jti)
+		}
+	}
+	s.mu.Unlock()
+
+	if deleted > 0 {
+		logger.Log.WithFields(logrus.Fields{
+			"service": "TokenRevocationService",
+			"method":  "Sweep",
+		}).Infof("Pruned %d expired revoked tokens.", deleted)
+	}
+	return nil
+}
+
+func (s *tokenRevocationService) StartSweeper(interval time.Duration) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				if err := s.Sweep(); err != nil {
+					logger.Log.WithFields(logrus.Fields{
+						"service": "TokenRevocationService",
+						"method":  "StartSweeper",
+					}).Errorf("Error sweeping revoked tokens: %v", err)
+				}
+			case <-done:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"go-auth-example/internal/repository/memrepo"
+)
+
+func TestRevokeUserTokensRejectsTokensIssuedBefore(t *testing.T) {
+	revocation := NewTokenRevocationService(memrepo.NewRevokedTokens())
+	// Klaim iat berpresisi detik
+	issuedAt := time.Now().Add(-time.Minute).Truncate(time.Second)
+
+	if revoked, err := revocation.IsRevokedForUser(1, issuedAt); err != nil || revoked {
+		t.Fatalf("IsRevokedForUser before revocation = %v, %v; want false", revoked, err)
+	}
+
+	cutoff := time.Now()
+	if err := revocation.RevokeUserTokens(1, cutoff); err != nil {
+		t.Fatalf("RevokeUserTokens: %v", err)
+	}
+	if revoked, _ := revocation.IsRevokedForUser(1, issuedAt); !revoked {
+		t.Fatal("token issued before the cutoff is still accepted")
+	}
+	if revoked, _ := revocation.IsRevokedForUser(1, cutoff.Truncate(time.Second)); !revoked {
+		t.Fatal("token issued in the same second as the cutoff is still accepted")
+	}
+	if revoked, _ := revocation.IsRevokedForUser(1, cutoff.Truncate(time.Second).Add(time.Second)); revoked {
+		t.Fatal("token issued after the cutoff is rejected")
+	}
+	if revoked, _ := revocation.IsRevokedForUser(2, issuedAt); revoked {
+		t.Fatal("cutoff applied to another user")
+	}
+
+	// Batas tidak dimundurkan oleh pencabutan dengan waktu lebih lama
+	if err := revocation.RevokeUserTokens(1, issuedAt.Add(-time.Hour)); err != nil {
+		t.Fatalf("RevokeUserTokens: %v", err)
+	}
+	if revoked, _ := revocation.IsRevokedForUser(1, issuedAt); !revoked {
+		t.Fatal("older revocation moved the cutoff back")
+	}
+}
@@ -0,0 +1,262 @@
+package service
+
+import (
+	"errors"
+
+	"go-auth-example/internal/logger"
+	"go-auth-example/internal/model"
+	"go-auth-example/internal/repository"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Ukuran halaman daftar user jika per_page tidak dikirim
+const DefaultUserListPageSize = 20
+
+// UserAdminService menjalankan operasi admin terhadap akun user lain.
+// actorID adalah admin yang menjalankan operasi. Operasi yang mengubah akun ditolak untuk akun admin itu sendiri;
+// akun sendiri dikelola lewat endpoint akun biasa.
+type UserAdminService interface {
+	// List mengembalikan satu halaman user sesuai filter dan urutan query
+	List(query model.UserListQuery) (*model.UserList, error)
+	// Get mengembalikan detail user beserta role, status MFA, session aktif dan akun sosialnya
+	Get(userID int) (*model.UserDetail, error)
+	// Disable menonaktifkan akun dan mencabut semua session dan token-nya
+	Disable(actorID, userID int) error
+	// Enable mengaktifkan kembali akun yang dinonaktifkan
+	Enable(actorID, userID int) error
+	// ForcePasswordReset mengosongkan password user dan mengirim link reset
+	ForcePasswordReset(actorID, userID int) error
+	// RevokeSessions mencabut semua session dan token user
+	RevokeSessions(actorID, userID int) error
+	// Delete menghapus akun secara permanen tanpa masa tenggang
+	Delete(actorID, userID int) error
+}
+
+// userAdminService struct mengimplementasikan UserAdminService
+type userAdminService struct {
+	userRepo        repository.UserRepository
+	identityRepo    repository.IdentityRepository
+	credentials     credentialRevoker
+	sessionService  SessionService
+	passwordService PasswordService
+	mfaService      MFAService
+	roleService     RoleService
+}
+
+// NewUserAdminService adalah constructor untuk userAdminService
+func NewUserAdminService(userRepo repository.UserRepository, identityRepo repository.IdentityRepository,
+	refreshTokenRepo repository.RefreshTokenRepository, patRepo repository.PersonalAccessTokenRepository,
+	revocationService TokenRevocationService, sessionService SessionService, passwordService PasswordService,
+	mfaService MFAService, roleService RoleService) UserAdminService {
+	return &userAdminService{
+		userRepo:        userRepo,
+		identityRepo:    identityRepo,
+		credentials:     credentialRevoker{sessionService: sessionService, revocationService: revocationService, refreshTokenRepo: refreshTokenRepo, patRepo: patRepo},
+		sessionService:  sessionService,
+		passwordService: passwordService,
+		mfaService:      mfaService,
+		roleService:     roleService,
+	}
+}
+
+// Implementasi List
+func (s *userAdminService) List(query model.UserListQuery) (*model.UserList, error) {
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.PerPage <= 0 {
+		query.PerPage = DefaultUserListPageSize
+	}
+
+	users, total, err := s.userRepo.List(query)
+	if err != nil {
+		logger.Log.WithFields(logrus.Fields{
+			"service": "UserAdminService",
+			"method":  "List",
+		}).Errorf("Error listing users: %v", err)
+		return nil, errors.New("failed to list users")
+	}
+	return &model.UserList{
+		Users:      users,
+		Page:       query.Page,
+		PerPage:    query.PerPage,
+		Total:      total,
+		TotalPages: (total + query.PerPage - 1) / query.PerPage,
+	}, nil
+}
+
+// Implementasi Get
+func (s *userAdminService) Get(userID int) (*model.UserDetail, error) {
+	logFields := logrus.Fields{
+		"service": "UserAdminService",
+		"method":  "Get",
+		"user_id": userID,
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error loading user: %v", err)
+		return nil, errors.New("failed to load user")
+	}
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+
+	detail := &model.UserDetail{User: user}
+	if user.Roles, err = s.roleService.UserRoles(user.ID); err != nil {
+		return nil, errors.New("failed to load user")
+	}
+	if detail.MFA, err = s.mfaService.Status(user.ID); err != nil {
+		return nil, errors.New("failed to load user")
+	}
+	if detail.Sessions, err = s.sessionService.List(user.ID, ""); err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error loading sessions: %v", err)
+		return nil, errors.New("failed to load user")
+	}
+	if detail.Identities, err = s.identityRepo.ListByUser(user.ID); err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error loading identities: %v", err)
+		return nil, errors.New("failed to load user")
+	}
+	return detail, nil
+}
+
+// Implementasi Disable
+func (s *userAdminService) Disable(actorID, userID int) error {
+	logFields := logrus.Fields{
+		"service":  "UserAdminService",
+		"method":   "Disable",
+		"actor_id": actorID,
+		"user_id":  userID,
+	}
+
+	user, err := s.target(actorID, userID, logFields)
+	if err != nil {
+		return err
+	}
+	// Menonaktifkan akun yang menunggu penghapusan akan membatalkan penghapusan yang diminta user
+	if user.Status == model.UserStatusPendingDeletion {
+		return errors.New("account pending deletion")
+	}
+	if err := s.userRepo.SetStatus(user.ID, model.UserStatusDisabled, nil); err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error disabling account: %v", err)
+		return errors.New("failed to disable account")
+	}
+	if err := s.credentials.revokeAll(user.ID, ""); err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error revoking credentials of disabled account: %v", err)
+		return errors.New("failed to disable account")
+	}
+
+	logger.Log.WithFields(logFields).Info("Account disabled by admin.")
+	return nil
+}
+
+// Implementasi Enable
+func (s *userAdminService) Enable(actorID, userID int) error {
+	logFields := logrus.Fields{
+		"service":  "UserAdminService",
+		"method":   "Enable",
+		"actor_id": actorID,
+		"user_id":  userID,
+	}
+
+	user, err := s.target(actorID, userID, logFields)
+	if err != nil {
+		return err
+	}
+	if user.Status == model.UserStatusPendingDeletion {
+		return errors.New("account pending deletion")
+	}
+	if err := s.userRepo.SetStatus(user.ID, model.UserStatusActive, nil); err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error enabling account: %v", err)
+		return errors.New("failed to enable account")
+	}
+
+	logger.Log.WithFields(logFields).Info("Account enabled by admin.")
+	return nil
+}
+
+// Implementasi ForcePasswordReset
+func (s *userAdminService) ForcePasswordReset(actorID, userID int) error {
+	logFields := logrus.Fields{
+		"service":  "UserAdminService",
+		"method":   "ForcePasswordReset",
+		"actor_id": actorID,
+		"user_id":  userID,
+	}
+
+	if _, err := s.target(actorID, userID, logFields); err != nil {
+		return err
+	}
+	if err := s.passwordService.ForceReset(userID); err != nil {
+		return err
+	}
+
+	logger.Log.WithFields(logFields).Info("Password reset forced by admin.")
+	return nil
+}
+
+// Implementasi RevokeSessions
+func (s *userAdminService) RevokeSessions(actorID, userID int) error {
+	logFields := logrus.Fields{
+		"service":  "UserAdminService",
+		"method":   "RevokeSessions",
+		"actor_id": actorID,
+		"user_id":  userID,
+	}
+
+	user, err := s.target(actorID, userID, logFields)
+	if err != nil {
+		return err
+	}
+	if err := s.credentials.revokeAll(user.ID, ""); err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error revoking credentials: %v", err)
+		return errors.New("failed to revoke sessions")
+	}
+
+	logger.Log.WithFields(logFields).Info("All sessions revoked by admin.")
+	return nil
+}
+
+// Implementasi Delete
+func (s *userAdminService) Delete(actorID, userID int) error {
+	logFields := logrus.Fields{
+		"service":  "UserAdminService",
+		"method":   "Delete",
+		"actor_id": actorID,
+		"user_id":  userID,
+	}
+
+	user, err := s.target(actorID, userID, logFields)
+	if err != nil {
+		return err
+	}
+	// Session, refresh token dan personal access token ikut terhapus, sehingga semua token user langsung ditolak
+	deleted, err := s.userRepo.Delete(user.ID)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error deleting account: %v", err)
+		return errors.New("failed to delete account")
+	}
+	if !deleted {
+		return errors.New("user not found")
+	}
+
+	logger.Log.WithFields(logFields).Info("Account deleted by admin.")
+	return nil
+}
+
+// target memuat user yang akan diubah; admin tidak boleh menjalankan operasi ini terhadap akunnya sendiri
+func (s *userAdminService) target(actorID, userID int, logFields logrus.Fields) (*model.User, error) {
+	if actorID == userID {
+		return nil, errors.New("cannot modify own account")
+	}
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		logger.Log.WithFields(logFields).Errorf("Error loading user: %v", err)
+		return nil, errors.New("failed to load user")
+	}
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+	return user, nil
+}
@@ -0,0 +1,151 @@
+// internal/social/github.go
+package social
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/github"
+)
+
+// GitHubAPIURL adalah base URL REST API GitHub.com
+const GitHubAPIURL = "https://api.github.com"
+
+// GitHubProvider login dengan akun GitHub. GitHub tidak mendukung OpenID Connect untuk login user,
+// jadi profil diambil dari REST API dengan access token hasil OAuth2.
+type GitHubProvider struct {
+	oauth  *oauth2.Config
+	config ClientConfig
+	apiURL string
+}
+
+// GitHubEndpoints berisi URL GitHub yang bisa diganti untuk GitHub Enterprise Server; kosong = GitHub.com
+type GitHubEndpoints struct {
+	AuthURL  string
+	TokenURL string
+	APIURL   string
+}
+
+// NewGitHubProvider constructor untuk GitHubProvider; nama default "github"
+func NewGitHubProvider(config ClientConfig, endpoints GitHubEndpoints) (*GitHubProvider, error) {
+	if config.ClientID == "" || config.RedirectURL == "" {
+		return nil, fmt.Errorf("GitHub client ID and redirect URL are required")
+	}
+	if config.Name == "" {
+		config.Name = "github"
+	}
+	if config.DisplayName == "" {
+		config.DisplayName = "GitHub"
+	}
+	if len(config.Scopes) == 0 {
+		config.Scopes = []string{"read:user", "user:email"}
+	}
+
+	endpoint := github.Endpoint
+	if endpoints.AuthURL != "" {
+		endpoint.AuthURL = endpoints.AuthURL
+	}
+	if endpoints.TokenURL != "" {
+		endpoint.TokenURL = endpoints.TokenURL
+	}
+	apiURL := GitHubAPIURL
+	if endpoints.APIURL != "" {
+		apiURL = strings.TrimSuffix(endpoints.APIURL, "/")
+	}
+
+	return &GitHubProvider{
+		oauth: &oauth2.Config{
+			ClientID:     config.ClientID,
+			ClientSecret: config.ClientSecret,
+			RedirectURL:  config.RedirectURL,
+			Endpoint:     endpoint,
+			Scopes:       config.Scopes,
+		},
+		config: config,
+		apiURL: apiURL,
+	}, nil
+}
+
+func (p *GitHubProvider) Name() string        { return p.config.Name }
+func (p *GitHubProvider) DisplayName() string { return p.config.DisplayName }
+
+// AuthCodeURL tidak memakai nonce karena tidak ada ID token; state dan PKCE tetap melindungi callback
+func (p *GitHubProvider) AuthCodeURL(ctx context.Context, state, codeVerifier, nonce string) (string, error) {
+	return p.oauth.AuthCodeURL(state, oauth2.S256ChallengeOption(codeVerifier)), nil
+}
+
+type gitHubUser struct {
+	ID    int64  `json:"id"`
+	Login string `json:"login"`
+	Name  string `json:"name"`
+}
+
+type gitHubEmail struct {
+	Email    string `json:"email"`
+	Primary  bool   `json:"primary"`
+	Verified bool   `json:"verified"`
+}
+
+func (p *GitHubProvider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*Profile, error) {
+	ctx = withHTTPClient(ctx)
+	token, err := p.oauth.Exchange(ctx, code, oauth2.VerifierOption(codeVerifier))
+	if err != nil {
+		return nil, fmt.Errorf("token exchange failed: %w", err)
+	}
+	client := p.oauth.Client(ctx, token)
+
+	var user gitHubUser
+	if err := p.getJSON(ctx, client, "/user", &user); err != nil {
+		return nil, err
+	}
+	if user.ID == 0 {
+		return nil, fmt.Errorf("GitHub user response has no id")
+	}
+
+	// Email di /user bisa kosong atau belum diverifikasi; status verifikasi hanya ada di /user/emails
+	var emails []gitHubEmail
+	if err := p.getJSON(ctx, client, "/user/emails", &emails); err != nil {
+		return nil, err
+	}
+	profile := &Profile{
+		Subject:  strconv.FormatInt(user.ID, 10), // Login GitHub bisa diganti, ID numerik tidak
+		Name:     user.Name,
+		Username: user.Login,
+	}
+	for _, e := range emails {
+		if e.Primary {
+			profile.Email = e.Email
+			profile.EmailVerified = e.Verified
+			break
+		}
+	}
+	return profile, nil
+}
+
+// getJSON memanggil endpoint REST API GitHub dan men-decode respons JSON-nya
+func (p *GitHubProvider) getJSON(ctx context.Context, client *http.Client, path string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.apiURL+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("GitHub API request %s failed: %w", path, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GitHub API request %s returned status %d", path, resp.StatusCode)
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(out); err != nil {
+		return fmt.Errorf("could not decode GitHub API response %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+// internal/social/oidc.go
+package social
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"sync"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+// GoogleIssuer adalah issuer OpenID Connect milik Google
+const GoogleIssuer = "https://accounts.google.com"
+
+// OIDCProvider adalah provider OpenID Connect generik (Google, Keycloak, Auth0, dll).
+// Endpoint diambil dari discovery <issuer>/.well-known/openid-configuration saat pertama kali dipakai,
+// sehingga server tetap bisa start walaupun provider sedang tidak bisa dihubungi.
+type OIDCProvider struct {
+	config ClientConfig
+	issuer string
+
+	mu       sync.Mutex
+	provider *oidc.Provider
+}
+
+// NewOIDCProvider constructor untuk OIDCProvider; nama default "oidc"
+func NewOIDCProvider(issuer string, config ClientConfig) (*OIDCProvider, error) {
+	if issuer == "" || config.ClientID == "" || config.RedirectURL == "" {
+		return nil, fmt.Errorf("OIDC issuer, client ID and redirect URL are required")
+	}
+	if config.Name == "" {
+		config.Name = "oidc"
+	}
+	if config.DisplayName == "" {
+		config.DisplayName = "Single sign-on"
+	}
+	if len(config.Scopes) == 0 {
+		config.Scopes = []string{oidc.ScopeOpenID, "email", "profile"}
+	}
+	return &OIDCProvider{config: config, issuer: issuer}, nil
+}
+
+// NewGoogleProvider constructor untuk login dengan akun Google
+func NewGoogleProvider(config ClientConfig) (*OIDCProvider, error) {
+	if config.Name == "" {
+		config.Name = "google"
+	}
+	if config.DisplayName == "" {
+		config.DisplayName = "Google"
+	}
+	return NewOIDCProvider(GoogleIssuer, config)
+}
+
+func (p *OIDCProvider) Name() string        { return p.config.Name }
+func (p *OIDCProvider) DisplayName() string { return p.config.DisplayName }
+
+// discover mengambil metadata provider sekali lalu menyimpannya; kegagalan tidak disimpan agar dicoba lagi
+func (p *OIDCProvider) discover(ctx context.Context) (*oidc.Provider, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.provider != nil {
+		return p.provider, nil
+	}
+	// Context discovery juga dipakai untuk mengambil JWKS nanti, jadi tidak boleh ikut dibatalkan bersama request
+	provider, err := oidc.NewProvider(withHTTPClient(context.WithoutCancel(ctx)), p.issuer)
+	if err != nil {
+		return nil, fmt.Errorf("OIDC discovery for %s failed: %w", p.issuer, err)
+	}
+	p.provider = provider
+	return provider, nil
+}
+
+func (p *OIDCProvider) oauth2Config(provider *oidc.Provider) *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     p.config.ClientID,
+		ClientSecret: p.config.ClientSecret,
+		RedirectURL:  p.config.RedirectURL,
+		Endpoint:     provider.Endpoint(),
+		Scopes:       p.config.Scopes,
+	}
+}
+
+func (p *OIDCProvider) AuthCodeURL(ctx context.Context, state, codeVerifier, nonce string) (string, error) {
+	provider, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	return p.oauth2Config(provider).AuthCodeURL(state, oauth2.S256ChallengeOption(codeVerifier), oidc.Nonce(nonce)), nil
+}
+
+// oidcClaims adalah claim profil dari ID token atau endpoint userinfo
+type oidcClaims struct {
+	Email             string      `json:"email"`
+	EmailVerified     interface{} `json:"email_verified"` // Sebagian provider mengirim string "true"
+	Name              string      `json:"name"`
+	PreferredUsername string      `json:"preferred_username"`
+}
+
+func (c oidcClaims) emailVerified() bool {
+	switch v := c.EmailVerified.(type) {
+	case bool:
+		return v
+	case string:
+		return v == "true"
+	}
+	return false
+}
+
+func (p *OIDCProvider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*Profile, error) {
+	provider, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx = withHTTPClient(ctx)
+	config := p.oauth2Config(provider)
+
+	token, err := config.Exchange(ctx, code, oauth2.VerifierOption(codeVerifier))
+	if err != nil {
+		return nil, fmt.Errorf("token exchange failed: %w", err)
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return nil, fmt.Errorf("token response has no id_token")
+	}
+	// Verify mengecek signature, issuer, audience (client ID) dan masa berlaku
+	idToken, err := provider.Verifier(&oidc.Config{ClientID: p.config.ClientID}).Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id_token: %w", err)
+	}
+	if subtle.ConstantTimeCompare([]byte(idToken.Nonce), []byte(nonce)) != 1 {
+		return nil, fmt.Errorf("id_token nonce mismatch")
+	}
+
+	var claims oidcClaims
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("could not parse id_token claims: %w", err)
+	}
+	// Sebagian provider hanya mengirim email lewat endpoint userinfo
+	if claims.Email == "" && provider.UserInfoEndpoint() != "" {
+		userInfo, err := provider.UserInfo(ctx, oauth2.StaticTokenSource(token))
+		if err != nil {
+			return nil, fmt.Errorf("userinfo request failed: %w", err)
+		}
+		if userInfo.Subject != idToken.Subject {
+			return nil, fmt.Errorf("userinfo subject does not match id_token")
+		}
+		if err := userInfo.Claims(&claims); err != nil {
+			return nil, fmt.Errorf("could not parse userinfo claims: %w", err)
+		}
+	}
+
+	return &Profile{
+		Subject:       idToken.Subject,
+		Email:         claims.Email,
+		EmailVerified: claims.emailVerified(),
+		Name:          claims.Name,
+		Username:      claims.PreferredUsername,
+	}, nil
+}
@@ -0,0 +1,83 @@
+// internal/social/provider.go
+package social
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// Profile adalah data user yang dikembalikan identity provider setelah login berhasil
+type Profile struct {
+	Subject       string // ID user yang stabil di provider (claim "sub" atau ID numerik GitHub)
+	Email         string
+	EmailVerified bool // Hanya true jika provider menjamin kepemilikan email
+	Name          string
+	Username      string // Usulan username (preferred_username, login GitHub); boleh kosong
+}
+
+// Provider adalah identity provider eksternal (OAuth2/OIDC) untuk login sosial
+type Provider interface {
+	// Name adalah kunci provider di URL dan database, misal "google"
+	Name() string
+	// DisplayName adalah nama yang ditampilkan di tombol login
+	DisplayName() string
+	// AuthCodeURL membangun URL authorization dengan state, PKCE (S256) dan nonce
+	AuthCodeURL(ctx context.Context, state, codeVerifier, nonce string) (string, error)
+	// Exchange menukar authorization code dengan token lalu mengambil profil user.
+	// Untuk OIDC, ID token diverifikasi termasuk nonce-nya.
+	Exchange(ctx context.Context, code, codeVerifier, nonce string) (*Profile, error)
+}
+
+// ClientConfig berisi kredensial client OAuth yang didaftarkan di provider
+type ClientConfig struct {
+	Name         string // Kunci provider; default sesuai jenis provider
+	DisplayName  string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string   // Halaman frontend yang menerima ?code=...&state=...
+	Scopes       []string // Kosong = scope default provider
+}
+
+// httpTimeout membatasi request ke provider (discovery, token, userinfo)
+const httpTimeout = 10 * time.Second
+
+// withHTTPClient memasang HTTP client dengan timeout untuk request oauth2 dan go-oidc
+func withHTTPClient(ctx context.Context) context.Context {
+	return context.WithValue(ctx, oauth2.HTTPClient, &http.Client{Timeout: httpTimeout})
+}
+
+// Registry menyimpan provider yang aktif sesuai urutan konfigurasi
+type Registry struct {
+	providers map[string]Provider
+	order     []string
+}
+
+// NewRegistry constructor untuk Registry; provider dengan nama sama menimpa yang sebelumnya
+func NewRegistry(providers ...Provider) *Registry {
+	r := &Registry{providers: make(map[string]Provider)}
+	for _, p := range providers {
+		if _, exists := r.providers[p.Name()]; !exists {
+			r.order = append(r.order, p.Name())
+		}
+		r.providers[p.Name()] = p
+	}
+	return r
+}
+
+// Get mengembalikan provider berdasarkan nama
+func (r *Registry) Get(name string) (Provider, bool) {
+	p, ok := r.providers[name]
+	return p, ok
+}
+
+// List mengembalikan semua provider aktif
+func (r *Registry) List() []Provider {
+	list := make([]Provider, 0, len(r.order))
+	for _, name := range r.order {
+		list = append(list, r.providers[name])
+	}
+	return list
+}
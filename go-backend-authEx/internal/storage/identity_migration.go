@@ -0,0 +1,115 @@
+// internal/storage/identity_migration.go
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+
+	"go-auth-example/internal/auth"
+)
+
+// migrateCanonicalIdentities mengisi username_canonical dan email_canonical untuk user lama lalu memasang
+// unique index tidak peka huruf besar/kecil. Normalisasi dilakukan di Go (auth.CanonicalIdentity) agar sama
+// persis dengan yang dipakai aplikasi. Jika data lama berisi akun yang bentrok (misal Alice@x.com dan
+// alice@x.com), migrasi berhenti dengan daftar ID yang bentrok; akun tersebut harus digabung atau diganti
+// manual sebelum server bisa berjalan.
+func migrateCanonicalIdentities(db *sql.DB) error {
+	var pending int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM users WHERE username_canonical IS NULL OR email_canonical IS NULL`).Scan(&pending); err != nil {
+		return fmt.Errorf("could not count users to migrate: %w", err)
+	}
+
+	if pending > 0 {
+		if err := backfillCanonicalIdentities(db); err != nil {
+			return err
+		}
+	}
+
+	indexSQL := `
+    CREATE UNIQUE INDEX IF NOT EXISTS users_username_canonical_key ON users (username_canonical);
+    CREATE UNIQUE INDEX IF NOT EXISTS users_email_canonical_key ON users (email_canonical);
+    ALTER TABLE users ALTER COLUMN username_canonical SET NOT NULL;
+    ALTER TABLE users ALTER COLUMN email_canonical SET NOT NULL;`
+	if _, err := db.Exec(indexSQL); err != nil {
+		return fmt.Errorf("could not create canonical identity indexes: %w", err)
+	}
+	return nil
+}
+
+// backfillCanonicalIdentities menghitung nilai canonical semua user dan menyimpannya dalam satu transaksi.
+// Semua user dibaca (bukan hanya yang belum terisi) agar bentrokan dengan user yang sudah dimigrasi ikut terdeteksi.
+func backfillCanonicalIdentities(db *sql.DB) error {
+	rows, err := db.Query(`SELECT id, username, email FROM users ORDER BY id`)
+	if err != nil {
+		return fmt.Errorf("could not load users: %w", err)
+	}
+
+	type identity struct {
+		id                int
+		username, email   string
+		usernameCanonical string
+		emailCanonical    string
+	}
+	var users []identity
+	usernames := map[string][]int{}
+	emails := map[string][]int{}
+	for rows.Next() {
+		var u identity
+		if err := rows.Scan(&u.id, &u.username, &u.email); err != nil {
+			rows.Close()
+			return fmt.Errorf("could not scan user: %w", err)
+		}
+		u.usernameCanonical = auth.CanonicalIdentity(u.username)
+		u.emailCanonical = auth.CanonicalIdentity(u.email)
+		usernames[u.usernameCanonical] = append(usernames[u.usernameCanonical], u.id)
+		emails[u.emailCanonical] = append(emails[u.emailCanonical], u.id)
+		users = append(users, u)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("could not load users: %w", err)
+	}
+
+	collisions := append(identityCollisions("username", usernames), identityCollisions("email", emails)...)
+	if len(collisions) > 0 {
+		return fmt.Errorf("found %d case-insensitive duplicate identities, resolve them before restarting: %s",
+			len(collisions), strings.Join(collisions, "; "))
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("could not begin identity migration: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`UPDATE users SET username_canonical = $2, email_canonical = $3 WHERE id = $1`)
+	if err != nil {
+		return fmt.Errorf("could not prepare identity migration: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, u := range users {
+		if _, err := stmt.Exec(u.id, u.usernameCanonical, u.emailCanonical); err != nil {
+			return fmt.Errorf("could not migrate identity of user %d: %w", u.id, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("could not commit identity migration: %w", err)
+	}
+	fmt.Printf("Canonical identities migrated for %d users.\n", len(users))
+	return nil
+}
+
+// identityCollisions mendaftar nilai canonical yang dimiliki lebih dari satu user, misal `email "alice@x.com": users [3 9]`
+func identityCollisions(field string, byCanonical map[string][]int) []string {
+	var collisions []string
+	for canonical, ids := range byCanonical {
+		if len(ids) > 1 {
+			collisions = append(collisions, fmt.Sprintf("%s %q: users %v", field, canonical, ids))
+		}
+	}
+	sort.Strings(collisions)
+	return collisions
+}
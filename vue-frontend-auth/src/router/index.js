@@ -7,6 +7,13 @@ import LoginView from '../views/LoginView.vue'
 import RegisterView from '../views/RegisterView.vue'
 import DashboardView from '../views/DashboardView.vue'
 import ProfileView from '../views/ProfileView.vue'
+import OAuthConsentView from '../views/OAuthConsentView.vue'
+import VerifyEmailView from '../views/VerifyEmailView.vue'
+import ForgotPasswordView from '../views/ForgotPasswordView.vue'
+import ResetPasswordView from '../views/ResetPasswordView.vue'
+import ConfirmEmailView from '../views/ConfirmEmailView.vue'
+import MagicLinkView from '../views/MagicLinkView.vue'
+import SocialCallbackView from '../views/SocialCallbackView.vue'
 
 const routes = [
     {
@@ -33,6 +40,45 @@ const routes = [
         component: ProfileView,
         meta: { requiresAuth: true } // Membutuhkan login
     },
+    {
+        path: '/oauth/consent',
+        name: 'OAuthConsent',
+        component: OAuthConsentView,
+        meta: { requiresAuth: true } // Halaman consent OAuth, dibuka lewat redirect dari /oauth/authorize
+    },
+    {
+        path: '/verify-email',
+        name: 'VerifyEmail',
+        component: VerifyEmailView // Dibuka dari link di email verifikasi, bisa diakses dengan atau tanpa login
+    },
+    {
+        path: '/forgot-password',
+        name: 'ForgotPassword',
+        component: ForgotPasswordView,
+        meta: { requiresGuest: true }
+    },
+    {
+        path: '/reset-password',
+        name: 'ResetPassword',
+        component: ResetPasswordView // Dibuka dari link di email reset password
+    },
+    {
+        path: '/confirm-email',
+        name: 'ConfirmEmail',
+        component: ConfirmEmailView // Dibuka dari link yang dikirim ke alamat email baru
+    },
+    {
+        path: '/magic-link',
+        name: 'MagicLink',
+        component: MagicLinkView, // Dibuka dari link login yang dikirim ke email
+        meta: { requiresGuest: true }
+    },
+    {
+        path: '/social/:provider/callback',
+        name: 'SocialCallback',
+        component: SocialCallbackView, // Provider login sosial mengembalikan user ke sini dengan ?code=...&state=...
+        meta: { requiresGuest: true }
+    },
     {
         // Redirect ke dashboard jika path root diakses dan sudah login,
         // atau ke login jika belum.
@@ -64,7 +110,8 @@ router.beforeEach((to, from, next) => {
 
     if (to.meta.requiresAuth && !authStore.isAuthenticated) {
         // Jika rute butuh login dan user belum login, redirect ke Login
-        next({ name: 'Login' })
+        // lalu kembali ke rute asal setelah login (misal halaman consent OAuth)
+        next({ name: 'Login', query: { redirect: to.fullPath } })
     } else if (to.meta.requiresGuest && authStore.isAuthenticated) {
         // Jika rute hanya untuk tamu (belum login) dan user sudah login, redirect ke Dashboard
         next({ name: 'Dashboard' })
@@ -6,9 +6,13 @@ const ApiService = axios.create({
     baseURL: 'http://localhost:8080', // URL backend Go Anda
     headers: {
         'Content-Type': 'application/json'
-    }
+    },
+    // Diperlukan agar cookie HttpOnly terkirim saat backend berjalan dengan AUTH_MODE=cookie
+    withCredentials: true
 })
 
+const SAFE_METHODS = ['get', 'head', 'options']
+
 // Request Interceptor: Menambahkan token JWT ke setiap request jika ada
 ApiService.interceptors.request.use(
     (config) => {
@@ -17,6 +21,11 @@ ApiService.interceptors.request.use(
         if (token) {
             config.headers.Authorization = `Bearer ${token}`
         }
+        // Mode cookie: request yang mengubah state wajib membawa token CSRF
+        const csrfToken = authStore.getCsrfToken
+        if (csrfToken && !SAFE_METHODS.includes((config.method || 'get').toLowerCase())) {
+            config.headers['X-CSRF-Token'] = csrfToken
+        }
         return config
     },
     (error) => {
@@ -27,10 +36,25 @@ ApiService.interceptors.request.use(
 // Response Interceptor (opsional, untuk penanganan error global)
 ApiService.interceptors.response.use(
     (response) => response,
-    (error) => {
+    async (error) => {
+        const originalRequest = error.config
         if (error.response && error.response.status === 401) {
             // Jika error 401 (Unauthorized), mungkin token expired atau tidak valid
             const authStore = useAuthStore()
+            // Coba perbarui access token sekali menggunakan refresh token sebelum logout
+            const isRefreshCall = originalRequest && originalRequest.url === '/auth/refresh'
+            if (originalRequest && !originalRequest._retry && !isRefreshCall && (authStore.getRefreshToken || authStore.cookieMode)) {
+                originalRequest._retry = true
+                try {
+                    const newToken = await authStore.refresh()
+                    if (newToken) {
+                        originalRequest.headers.Authorization = `Bearer ${newToken}`
+                    }
+                    return ApiService(originalRequest)
+                } catch (refreshError) {
+                    // Refresh gagal, lanjutkan ke logout di bawah
+                }
+            }
             authStore.logout() // Panggil logout untuk membersihkan state
             // Tidak perlu redirect di sini karena navigation guard akan menangani
         }
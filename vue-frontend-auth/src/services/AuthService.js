@@ -5,13 +5,109 @@ export default {
     login(credentials) {
         return ApiService.post('/login', credentials)
     },
+    verifyMfa(mfaToken, code) {
+        return ApiService.post('/auth/mfa/verify', { mfa_token: mfaToken, code })
+    },
+    passkeyLoginOptions() {
+        return ApiService.post('/auth/passkey/options')
+    },
+    passkeyLogin(sessionToken, credential) {
+        return ApiService.post('/auth/passkey/login', { session_token: sessionToken, credential })
+    },
+    requestMagicLink(email) {
+        return ApiService.post('/auth/magic-link', { email })
+    },
+    consumeMagicLink(token) {
+        return ApiService.post('/auth/magic-link/consume', { token })
+    },
+    socialProviders() {
+        return ApiService.get('/auth/social/providers')
+    },
+    socialAuthorize(provider) {
+        return ApiService.post(`/auth/social/${encodeURIComponent(provider)}/authorize`)
+    },
+    socialCallback(provider, code, state) {
+        return ApiService.post(`/auth/social/${encodeURIComponent(provider)}/callback`, { code, state })
+    },
     register(userData) {
         return ApiService.post('/register', userData)
     },
+    refresh(refreshToken) {
+        // Mode cookie: refresh token dikirim browser lewat cookie HttpOnly
+        return ApiService.post('/auth/refresh', refreshToken ? { refresh_token: refreshToken } : undefined)
+    },
+    verifyEmail(token) {
+        return ApiService.post('/auth/verify-email', { token })
+    },
+    resendVerificationEmail(email) {
+        return ApiService.post('/auth/verify-email/resend', { email })
+    },
+    forgotPassword(email) {
+        return ApiService.post('/auth/password/forgot', { email })
+    },
+    resetPassword(token, password) {
+        return ApiService.post('/auth/password/reset', { token, password })
+    },
+    changePassword(currentPassword, newPassword) {
+        return ApiService.put('/api/password', { current_password: currentPassword, new_password: newPassword })
+    },
+    updateProfile(profile) {
+        return ApiService.patch('/api/profile', profile)
+    },
+    confirmEmailChange(token) {
+        return ApiService.post('/auth/email/confirm', { token })
+    },
+    getMfaStatus() {
+        return ApiService.get('/api/mfa')
+    },
+    enrollTotp() {
+        return ApiService.post('/api/mfa/totp')
+    },
+    confirmTotp(code) {
+        return ApiService.post('/api/mfa/totp/confirm', { code })
+    },
+    regenerateRecoveryCodes(password) {
+        return ApiService.post('/api/mfa/recovery-codes', { password })
+    },
+    disableMfa(password, code) {
+        return ApiService.delete('/api/mfa', { data: { password, code } })
+    },
+    listPasskeys() {
+        return ApiService.get('/api/passkeys')
+    },
+    passkeyRegistrationOptions() {
+        return ApiService.post('/api/passkeys/options')
+    },
+    registerPasskey(sessionToken, credential, name) {
+        return ApiService.post('/api/passkeys', { session_token: sessionToken, credential, name })
+    },
+    renamePasskey(id, name) {
+        return ApiService.patch(`/api/passkeys/${id}`, { name })
+    },
+    deletePasskey(id) {
+        return ApiService.delete(`/api/passkeys/${id}`)
+    },
+    exportAccount() {
+        return ApiService.get('/api/account/export')
+    },
+    deleteAccount(password) {
+        return ApiService.delete('/api/account', { data: { password } })
+    },
+    logout() {
+        return ApiService.post('/api/logout')
+    },
     getProfile() {
         // Pastikan endpoint ini ada di backend Anda dan diproteksi (membutuhkan JWT)
         // Endpoint yang kita buat di backend adalah /api/profile
         return ApiService.get('/api/profile')
+    },
+    // Detail authorization request OAuth untuk halaman consent
+    getOAuthConsent(params) {
+        return ApiService.get('/api/oauth/authorize', { params })
+    },
+    // Mengirim keputusan user; backend mengembalikan { redirect_to }
+    submitOAuthConsent(params, approve) {
+        return ApiService.post('/api/oauth/authorize', { ...params, approve })
     }
     // Anda bisa menambahkan fungsi lain di sini, misal forgotPassword, resetPassword, dll.
 }
\ No newline at end of file
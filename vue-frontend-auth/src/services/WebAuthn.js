@@ -0,0 +1,21 @@
+// src/services/WebAuthn.js
+// Pembungkus navigator.credentials untuk passkey. Options dari backend berbentuk JSON
+// ({ publicKey: ... }) dan hasilnya dikirim balik ke backend juga dalam bentuk JSON.
+
+export function isPasskeySupported() {
+    return typeof window !== 'undefined'
+        && !!window.PublicKeyCredential
+        && typeof PublicKeyCredential.parseCreationOptionsFromJSON === 'function'
+}
+
+export async function createPasskey(options) {
+    const publicKey = PublicKeyCredential.parseCreationOptionsFromJSON(options.publicKey)
+    const credential = await navigator.credentials.create({ publicKey })
+    return credential.toJSON()
+}
+
+export async function getPasskey(options) {
+    const publicKey = PublicKeyCredential.parseRequestOptionsFromJSON(options.publicKey)
+    const credential = await navigator.credentials.get({ publicKey })
+    return credential.toJSON()
+}
@@ -2,32 +2,40 @@
 import { defineStore } from 'pinia'
 import AuthService from '../services/AuthService' // Kita akan buat service ini nanti
 import router from '../router' // Impor router untuk navigasi
+import { getPasskey } from '../services/WebAuthn'
+
+// Mode cookie (VITE_AUTH_MODE=cookie): token disimpan backend di cookie HttpOnly,
+// frontend hanya menyimpan token CSRF dan data user
+const COOKIE_MODE = import.meta.env.VITE_AUTH_MODE === 'cookie'
 
 export const useAuthStore = defineStore('auth', {
     state: () => ({
         token: localStorage.getItem('authToken') || null,
+        refreshToken: localStorage.getItem('authRefreshToken') || null,
         user: JSON.parse(localStorage.getItem('authUser')) || null,
+        csrfToken: localStorage.getItem('authCsrfToken') || null,
+        cookieMode: COOKIE_MODE,
         // isAuthenticated akan dihitung berdasarkan token
     }),
     getters: {
-        isAuthenticated: (state) => !!state.token,
+        isAuthenticated: (state) => (state.cookieMode ? !!state.user : !!state.token),
         currentUser: (state) => state.user,
         getToken: (state) => state.token,
+        getRefreshToken: (state) => state.refreshToken,
+        getCsrfToken: (state) => state.csrfToken,
     },
     actions: {
         async login(credentials) {
             try {
                 const response = await AuthService.login(credentials)
-                const token = response.data.token
+                // User dengan MFA harus memasukkan kode dulu; token baru didapat dari verifyMfa
+                if (response.data.mfa_required) {
+                    return { mfaRequired: true, mfaToken: response.data.mfa_token }
+                }
                 // Asumsi backend tidak langsung mengirim data user, kita bisa decode token jika perlu info dasar
                 // atau membuat endpoint /api/profile untuk mengambil data user setelah login
-                this.token = token
-                localStorage.setItem('authToken', token)
-
-                // Ambil data user setelah login berhasil
-                await this.fetchUserProfile() // Kita akan buat fungsi ini
-
-                router.push({ name: 'Dashboard' })
+                this.setTokens(response.data)
+                await this.finishLogin()
                 return true // Sukses
             } catch (error) {
                 console.error('Login failed:', error.response?.data || error.message)
@@ -35,6 +43,50 @@ export const useAuthStore = defineStore('auth', {
                 throw error // Teruskan error agar bisa ditangani di komponen
             }
         },
+        // Menyelesaikan login tahap kedua dengan kode authenticator atau kode pemulihan
+        async verifyMfa(mfaToken, code) {
+            const response = await AuthService.verifyMfa(mfaToken, code)
+            this.setTokens(response.data)
+            await this.finishLogin()
+            return true
+        },
+        // Login tanpa password: browser meminta user memilih passkey lalu backend memverifikasinya
+        async loginWithPasskey() {
+            const options = await AuthService.passkeyLoginOptions()
+            const credential = await getPasskey(options.data.options)
+            const response = await AuthService.passkeyLogin(options.data.session_token, credential)
+            this.setTokens(response.data)
+            await this.finishLogin()
+            return true
+        },
+        // Login dari link di email; akun dengan MFA tetap harus memasukkan kode lewat verifyMfa
+        async loginWithMagicLink(token) {
+            const response = await AuthService.consumeMagicLink(token)
+            if (response.data.mfa_required) {
+                return { mfaRequired: true, mfaToken: response.data.mfa_token }
+            }
+            this.setTokens(response.data)
+            await this.finishLogin()
+            return true
+        },
+        // Menyelesaikan login sosial dengan code dan state yang dikirim provider ke halaman callback
+        async loginWithSocial(provider, code, state) {
+            const response = await AuthService.socialCallback(provider, code, state)
+            if (response.data.mfa_required) {
+                return { mfaRequired: true, mfaToken: response.data.mfa_token }
+            }
+            this.setTokens(response.data)
+            await this.finishLogin()
+            return true
+        },
+        async finishLogin() {
+            // Ambil data user setelah login berhasil
+            await this.fetchUserProfile()
+
+            // Kembali ke halaman asal jika login diminta oleh navigation guard
+            const redirect = router.currentRoute.value.query.redirect
+            router.push(typeof redirect === 'string' && redirect.startsWith('/') ? redirect : { name: 'Dashboard' })
+        },
         async register(userData) {
             try {
                 await AuthService.register(userData)
@@ -48,15 +100,47 @@ export const useAuthStore = defineStore('auth', {
                 throw error
             }
         },
+        setTokens(data) {
+            if (this.cookieMode) {
+                this.csrfToken = data.csrf_token || null
+                if (this.csrfToken) {
+                    localStorage.setItem('authCsrfToken', this.csrfToken)
+                }
+                return
+            }
+            this.token = data.access_token || data.token
+            this.refreshToken = data.refresh_token || null
+            localStorage.setItem('authToken', this.token)
+            if (this.refreshToken) {
+                localStorage.setItem('authRefreshToken', this.refreshToken)
+            }
+        },
+        // Menukar refresh token dengan pasangan token baru. Mengembalikan access token baru.
+        async refresh() {
+            if (!this.refreshToken && !this.cookieMode) {
+                throw new Error('No refresh token available')
+            }
+            const response = await AuthService.refresh(this.refreshToken)
+            this.setTokens(response.data)
+            return this.token
+        },
         logout() {
+            if (this.cookieMode && this.user) {
+                // Hapus cookie di backend; kegagalan diabaikan karena state lokal tetap dibersihkan
+                AuthService.logout().catch(() => {})
+            }
             this.token = null
+            this.csrfToken = null
+            this.refreshToken = null
             this.user = null
             localStorage.removeItem('authToken')
+            localStorage.removeItem('authRefreshToken')
             localStorage.removeItem('authUser')
+            localStorage.removeItem('authCsrfToken')
             router.push({ name: 'Login' })
         },
         async fetchUserProfile() {
-            if (!this.token) return; // Jangan fetch jika tidak ada token
+            if (!this.token && !this.cookieMode) return; // Jangan fetch jika tidak ada token
             try {
                 // Kita akan buat AuthService.getProfile() nanti
                 const response = await AuthService.getProfile();